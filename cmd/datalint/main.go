@@ -0,0 +1,44 @@
+// datalint 扫描课程数据并输出数据质量报告，导入教务数据后运行：
+//
+//	go run ./cmd/datalint -format text
+package main
+
+import (
+	"cengkeHelperBackGo/internal/services"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	format := flag.String("format", "text", "输出格式：text 或 json")
+	failOnError := flag.Bool("fail-on-error", false, "存在 error 级别异常时以非零状态退出")
+	flag.Parse()
+
+	report, err := services.NewDataLintService().Run(context.Background())
+	if err != nil {
+		log.Fatalf("数据质量检查失败: %v", err)
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("输出报告失败: %v", err)
+		}
+	default:
+		fmt.Print(services.FormatDataLintReport(report))
+	}
+
+	if *failOnError {
+		for _, category := range report.Categories {
+			if category.Severity == services.LintSeverityError {
+				os.Exit(1)
+			}
+		}
+	}
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/jobs"
 	"cengkeHelperBackGo/internal/router"
+	"context"
 )
 
 func main() {
+	jobs.Start(context.Background())
+
 	if err := router.Routers().Run(":" + config.Conf.Server.Port); err != nil {
 		panic(err)
 		return
@@ -40,6 +40,48 @@ var Conf struct {
 	Agent          struct {
 		ServiceURL string `yaml:"service_url" json:"serviceUrl"`
 	} `yaml:"agent" json:"agent"`
+	Clock struct {
+		// AllowHeaderOverride 为 true 时（测试环境）任何请求都可以通过 X-Clock-Now 覆盖当前时间；
+		// 为 false 时只有管理员的请求可以覆盖
+		AllowHeaderOverride bool `yaml:"allow_header_override" json:"allowHeaderOverride"`
+	} `yaml:"clock" json:"clock"`
+	Recommendation struct {
+		// RefreshIntervalMinutes 离线重算所有用户推荐的间隔（分钟），<=0 表示不启动定时任务
+		RefreshIntervalMinutes int `yaml:"refresh_interval_minutes" json:"refreshIntervalMinutes"`
+		// TopN 每个用户保留的推荐条数
+		TopN int `yaml:"top_n" json:"topN"`
+	} `yaml:"recommendation" json:"recommendation"`
+	RoomQR struct {
+		// DeepLinkTemplate 教室二维码指向的链接，{roomId} 会被替换为转义后的教室ID；
+		// 为空时指向 PublicBaseURL 下的 /api/v1/rooms/{roomId}/now
+		DeepLinkTemplate string `yaml:"deep_link_template" json:"deepLinkTemplate"`
+		// PublicBaseURL 本服务的外部访问地址，如 https://api.example.com；两项都未配置时不能生成二维码
+		PublicBaseURL string `yaml:"public_base_url" json:"publicBaseUrl"`
+	} `yaml:"room_qr" json:"roomQr"`
+	Materials struct {
+		// Storage 课程资料的存储后端，目前支持 local
+		Storage string `yaml:"storage" json:"storage"`
+		// LocalDir 本地存储的根目录
+		LocalDir string `yaml:"local_dir" json:"localDir"`
+		// MaxSizeMB 单个文件大小上限（MB）
+		MaxSizeMB int `yaml:"max_size_mb" json:"maxSizeMb"`
+		// TrustedMinCorrections 勘误被采纳达到该次数的用户可上传资料，管理员不受限制
+		TrustedMinCorrections int `yaml:"trusted_min_corrections" json:"trustedMinCorrections"`
+	} `yaml:"materials" json:"materials"`
+	Counters struct {
+		// ReconcileIntervalMinutes 帖子、评论、用户计数对账的间隔（分钟），<=0 表示不启动定时任务
+		ReconcileIntervalMinutes int `yaml:"reconcile_interval_minutes" json:"reconcileIntervalMinutes"`
+	} `yaml:"counters" json:"counters"`
+	Reports struct {
+		// AutoHideThreshold 同一内容的举报人数达到该值时自动隐藏，等待版主处理；<=0 表示不自动隐藏
+		AutoHideThreshold int `yaml:"auto_hide_threshold" json:"autoHideThreshold"`
+	} `yaml:"reports" json:"reports"`
+	Notifications struct {
+		// QueueSize 待投递通知的队列长度，队列满时丢弃新的点赞、评论通知，不阻塞请求
+		QueueSize int `yaml:"queue_size" json:"queueSize"`
+		// RetentionDays 已读通知的保留天数，<=0 表示不清理
+		RetentionDays int `yaml:"retention_days" json:"retentionDays"`
+	} `yaml:"notifications" json:"notifications"`
 }
 
 // LoadConfig 加载配置文件
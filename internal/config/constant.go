@@ -27,4 +27,50 @@ const (
 	MsgUserNotFound            = "用户不存在"    // 用户不存在
 	MsgCourseForReviewNotFound = "课程待审核不存在" // 课程待审核不存在
 	MsgUserForReviewNotFound   = "用户待审核不存在" // 用户待审核不存在
+	MsgProposalNotFound        = "提议不存在"
+	MsgProposalAlreadyExists   = "该课程已有你提交的待审核提议"
+	MsgProposalReviewed        = "该提议已被审核"
+	MsgAlreadyCheckedIn        = "今天已经在这门课打过卡了"
+	MsgCorrectionNotFound      = "勘误不存在"
+	MsgCorrectionReviewed      = "该勘误已被审核"
+	MsgFacultyNotFound         = "学院不存在"
+	MsgMajorNotFound           = "专业不存在"
+	MsgMajorNotInFaculty       = "专业不属于所选学院"
+	MsgProfileMajorMissing     = "请先在个人资料中设置学院或专业"
+	MsgRoomNotFound            = "教室不存在"
+	MsgBuildingNotFound        = "教学楼不存在"
+	MsgRoomQRNotConfigured     = "未配置教室二维码的链接地址"
+	MsgLintReportNotFound      = "还没有数据质量报告"
+	MsgMaterialNotFound        = "课程资料不存在"
+	MsgMaterialNotTrusted      = "只有管理员或勘误多次被采纳的用户可以上传课程资料"
+	MsgMaterialFileRequired    = "请上传文件或填写链接"
+	MsgMaterialFileAndLink     = "上传文件和填写链接只能二选一"
+	MsgMaterialLinkInvalid     = "链接只支持 http 或 https 地址"
+	MsgMaterialTooLarge        = "文件超过大小限制"
+	MsgMaterialTypeNotAllowed  = "不支持的文件类型"
+	MsgMaterialIsLink          = "链接类资料没有文件版本"
+	MsgSyncVersionInvalid      = "同步版本号无效，请重新下载全量快照"
+	MsgTrendingUnavailable     = "热门课程统计暂不可用"
+	MsgTagNotFound             = "标签未找到"
+	MsgTagMergeInvalid         = "合并的目标标签无效或与被合并标签重复"
+	MsgCursorInvalid           = "游标无效或与排序方式不匹配"
+	MsgPostNotFound            = "帖子未找到"
+	MsgPostNotAuthor           = "无权修改此帖子"
+	MsgPostAlreadyPublished    = "帖子已发布"
+	MsgPostLocked              = "帖子已锁定，无法评论"
+	MsgPinCategoryMissing      = "帖子没有分类，不能在分类内置顶"
+	MsgPinExpiryInvalid        = "置顶到期时间必须晚于当前时间"
+	MsgContentBlocked          = "内容包含违规词汇，请修改后再提交"
+	MsgUsernameSensitive       = "用户名包含违规词汇"
+	MsgSensitiveWordNotFound   = "敏感词不存在"
+	MsgContentReviewNotFound   = "审核记录不存在"
+	MsgContentReviewResolved   = "该内容已审核"
+	MsgReportTargetNotFound    = "举报的内容不存在"
+	MsgReportSelf              = "不能举报自己或自己发布的内容"
+	MsgReportCaseNotFound      = "举报单不存在"
+	MsgReportCaseResolved      = "该举报已处理"
+	MsgReportActionInvalid     = "该处理方式不适用于用户举报"
+	MsgUserBanned              = "账号已被封禁"
+	MsgNotificationNotFound    = "通知不存在"
+	MsgNotificationTypeInvalid = "不支持设置该类型的通知"
 )
@@ -58,6 +58,37 @@ func TableAutoMigrate() {
 		&dto.UserPostLike{},
 		&dto.UserCommentLike{},
 		&dto.CourseReviewModel{},
+		&dto.UserCourseFavorite{},
+		&dto.PostCourse{},
+		&dto.Room{},
+		&dto.CourseAuditPolicyProposal{},
+		&dto.CourseCheckIn{},
+		&dto.CourseCorrection{},
+		&dto.CourseCorrectionItem{},
+		&dto.CourseDataOverride{},
+		&dto.UserCourseRecommendation{},
+		&dto.Faculty{},
+		&dto.Major{},
+		&dto.CatalogAlias{},
+		&dto.CourseSyncVersion{},
+		&dto.CourseSyncState{},
+		&dto.CourseExam{},
+		&dto.CourseMaterial{},
+		&dto.CourseMaterialVersion{},
+		&dto.Tag{},
+		&dto.PostTag{},
+		&dto.SensitiveWord{},
+		&dto.ContentReview{},
+		&dto.ReportCase{},
+		&dto.ContentReport{},
+		&dto.Notification{},
+		&dto.NotificationActor{},
+		&dto.NotificationPreference{},
+	}
+
+	// 自动迁移无法处理的结构变更需要先于自动迁移执行
+	if err := migrateCourseDataOverrides(); err != nil {
+		panic(fmt.Errorf("数据库迁移失败: %v", err))
 	}
 
 	// 批量执行自动迁移
@@ -66,3 +97,27 @@ func TableAutoMigrate() {
 	}
 
 }
+
+// migrateCourseDataOverrides 旧版数据覆盖以 course_num 为键，会改动同一课程的所有教学班。
+// 按来源勘误补齐教学班ID，并删除旧的唯一索引，之后由自动迁移建立按教学班的唯一索引
+func migrateCourseDataOverrides() error {
+	m := Client.Migrator()
+	override := &dto.CourseDataOverride{}
+	if !m.HasTable(override) || m.HasColumn(override, "CourseInfoID") {
+		return nil
+	}
+	if err := m.AddColumn(override, "CourseInfoID"); err != nil {
+		return fmt.Errorf("添加 course_data_overrides.course_info_id 失败: %w", err)
+	}
+	if err := Client.Exec(`UPDATE course_data_overrides o
+		JOIN course_corrections c ON c.id = o.correction_id
+		SET o.course_info_id = c.course_id`).Error; err != nil {
+		return fmt.Errorf("回填数据覆盖的教学班ID失败: %w", err)
+	}
+	if m.HasIndex(override, "idx_override_key") {
+		if err := m.DropIndex(override, "idx_override_key"); err != nil {
+			return fmt.Errorf("删除旧的数据覆盖索引失败: %w", err)
+		}
+	}
+	return nil
+}
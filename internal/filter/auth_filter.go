@@ -1,8 +1,11 @@
 package filter
 
 import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
 	"cengkeHelperBackGo/internal/models/dto"
 	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
 	"cengkeHelperBackGo/pkg/utils"
 	"errors"
 	"net/http"
@@ -84,3 +87,63 @@ func AdminAuthChecker() gin.HandlerFunc {
 		}
 	}
 }
+
+// ModeratorAuthChecker 只允许版主和管理员访问，需在 UserAuthChecker 之后使用
+func ModeratorAuthChecker() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		switch role {
+		case dto.UserRoleAdmin, dto.UserRoleModerator:
+			c.Next()
+		default:
+			c.JSON(http.StatusForbidden, vo.RespData{
+				Code: 403,
+				Data: nil,
+				Msg:  "没有权限访问该资源",
+			})
+			c.Abort()
+		}
+	}
+}
+
+// OptionalUserAuth 用于公开接口：携带有效 token 时与 UserAuthChecker 一样写入用户信息，
+// 未携带或 token 无效时按游客继续处理，不拦截请求
+func OptionalUserAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.GetHeader("Authorization")
+		if tokenString != "" {
+			if claims, err := utils.ParseUserJwt(tokenString); err == nil {
+				c.Set("username", claims.Username)
+				c.Set("role", claims.Role)
+				c.Set("userId", claims.UserId)
+			}
+		}
+		c.Next()
+	}
+}
+
+// BannedUserChecker 拒绝被封禁用户的写操作（非 GET 请求），封禁到期后自动恢复；需在 UserAuthChecker 之后使用
+func BannedUserChecker() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		var user dto.User
+		err := database.Client.Select("id", "banned_until").Where("id = ?", c.GetString("userId")).First(&user).Error
+		if err == nil && user.IsBanned(clock.Now(c.Request.Context())) {
+			msg := config.MsgUserBanned
+			if !user.BannedUntil.Equal(dto.PermanentBanUntil()) {
+				msg += "，解封时间 " + user.BannedUntil.Local().Format("2006-01-02 15:04")
+			}
+			c.JSON(http.StatusForbidden, vo.RespData{
+				Code: 403,
+				Data: nil,
+				Msg:  msg,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
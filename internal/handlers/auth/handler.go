@@ -5,6 +5,8 @@ import (
 	database "cengkeHelperBackGo/internal/db" // 如果 checkUser 没有返回完整的用户 DTO，可能需要这个
 	"cengkeHelperBackGo/internal/models/dto"
 	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"cengkeHelperBackGo/pkg/clock"
 	"cengkeHelperBackGo/pkg/utils"
 	"fmt"
 	"log"
@@ -36,6 +38,10 @@ func UserLoginHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, vo.NewBadResp("邮箱或密码错误"))
 		return
 	}
+	if user.IsBanned(clock.Now(c.Request.Context())) {
+		c.JSON(http.StatusForbidden, vo.RespData{Code: config.CodeForbidden, Msg: config.MsgUserBanned})
+		return
+	}
 
 	// 确保敏感数据（如密码哈希）不会包含在响应中。
 	// dto.User 结构体最好是为安全的 API 响应而设计的，
@@ -126,6 +132,11 @@ func UserRegisterHandler(c *gin.Context) {
 		usernameToCheck = strings.Split(req.Email, "@")[0] // 简单示例：邮箱前缀作为用户名，需进一步处理唯一性
 	}
 
+	if err := services.NewContentReviewService().CheckUsername(usernameToCheck); err != nil {
+		c.JSON(http.StatusBadRequest, vo.NewBadResp(err.Error()))
+		return
+	}
+
 	var usernameCount int64
 	if err := database.Client.Model(&dto.User{}).
 		Where("username = ?", usernameToCheck).
@@ -70,10 +70,13 @@ func getUserRoleFromContext(c *gin.Context) string {
 // @Tags Comments
 // @Accept json
 // @Produce json
+// @Param Authorization header string false "Bearer <token>，可选，携带时返回当前用户的点赞状态"
 // @Param postId path string true "帖子ID"
 // @Param page query int false "页码" default(1)
 // @Param limit query int false "每页数量" default(10)
 // @Param sortBy query string false "排序 (eg: createdAt_desc)"
+// @Param cursor query string false "上一页返回的 nextCursor，传入时忽略 page"
+// @Param skipTotal query bool false "为 true 时不统计总数"
 // @Success 200 {object} vo.RespData{data=vo.GetCommentsResponseDataVO} "成功"
 // @Failure 400 {object} vo.RespData "请求参数错误"
 // @Failure 404 {object} vo.RespData "帖子未找到"
@@ -104,9 +107,12 @@ func (h *CommentHandler) GetCommentsByPostID(c *gin.Context) {
 
 	responseVO, serviceErr := h.commentService.GetCommentsByPostID(postID, &params, currentUserID)
 	if serviceErr != nil {
-		if serviceErr.Error() == "帖子未找到" {
+		switch serviceErr.Error() {
+		case "帖子未找到":
 			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
-		} else {
+		case config.MsgCursorInvalid:
+			vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, serviceErr.Error(), nil)
+		default:
 			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取评论列表失败", serviceErr)
 		}
 		return
@@ -125,6 +131,7 @@ func (h *CommentHandler) GetCommentsByPostID(c *gin.Context) {
 // @Success 201 {object} vo.RespData{data=vo.CommentVO} "评论创建成功"
 // @Failure 400 {object} vo.RespData "请求参数错误"
 // @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 403 {object} vo.RespData "帖子已锁定"
 // @Failure 404 {object} vo.RespData "相关资源未找到 (帖子/父评论/回复用户)"
 // @Failure 500 {object} vo.RespData "服务器内部错误"
 // @Router /comments [post]
@@ -154,6 +161,10 @@ func (h *CommentHandler) AddComment(c *gin.Context) {
 			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, errMsg, nil)
 		case strings.Contains(errMsg, "不匹配"): // "父评论与当前帖子不匹配"
 			vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, errMsg, nil)
+		case errMsg == config.MsgContentBlocked:
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, errMsg, nil)
+		case errMsg == config.MsgPostLocked:
+			vo.RespondError(c, http.StatusForbidden, config.CodeForbidden, errMsg, nil)
 		default:
 			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "添加评论失败", serviceErr)
 		}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ContentFilterHandler struct {
+	sensitiveWordService *services.SensitiveWordService
+	contentReviewService *services.ContentReviewService
+}
+
+func NewContentFilterHandler() *ContentFilterHandler {
+	return &ContentFilterHandler{
+		sensitiveWordService: services.NewSensitiveWordService(),
+		contentReviewService: services.NewContentReviewService(),
+	}
+}
+
+// parseUint32Param 解析路径中的ID参数，失败时已写入错误响应
+func parseUint32Param(c *gin.Context, name, msg string) (uint32, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, msg, err)
+		return 0, false
+	}
+	return uint32(id), true
+}
+
+// GetSensitiveWordsHandler godoc
+// @Summary 敏感词列表
+// @Description 分页查询敏感词，可按分类和关键词筛选。需要管理员权限
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每页数量" default(50)
+// @Param category query string false "分类"
+// @Param keyword query string false "关键词"
+// @Success 200 {object} vo.RespData{data=vo.SensitiveWordListVO} "获取成功"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/sensitive-words [get]
+func (h *ContentFilterHandler) GetSensitiveWordsHandler(c *gin.Context) {
+	var params dto.GetSensitiveWordsParamsDTO
+	if err := c.ShouldBindQuery(&params); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 200 {
+		params.Limit = 50
+	}
+	words, err := h.sensitiveWordService.List(c.Request.Context(), params)
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取敏感词列表失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取敏感词列表成功", words)
+}
+
+// ImportSensitiveWordsHandler godoc
+// @Summary 批量添加敏感词
+// @Description 批量添加敏感词，每个词指定分类和处理方式：block 拒绝提交、mask 替换为 ***、review 保存后转人工审核。
+// @Description 全角字符、大小写与插入的空格符号不影响匹配，归一化后相同的词会覆盖原有设置。保存后立即生效，其他实例在一分钟内同步
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param payload body dto.SensitiveWordBatchDTO true "敏感词列表"
+// @Success 200 {object} vo.RespData{data=vo.SensitiveWordImportVO} "保存成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/sensitive-words [post]
+func (h *ContentFilterHandler) ImportSensitiveWordsHandler(c *gin.Context) {
+	var payload dto.SensitiveWordBatchDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	result, err := h.sensitiveWordService.Import(c.Request.Context(), payload)
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "保存敏感词失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "敏感词已保存", result)
+}
+
+// UpdateSensitiveWordHandler godoc
+// @Summary 修改敏感词
+// @Description 修改敏感词的分类或处理方式，立即生效。需要管理员权限
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param wordId path int true "敏感词ID"
+// @Param payload body dto.UpdateSensitiveWordDTO true "分类或处理方式"
+// @Success 200 {object} vo.RespData{data=vo.SensitiveWordVO} "修改成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "敏感词不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/sensitive-words/{wordId} [put]
+func (h *ContentFilterHandler) UpdateSensitiveWordHandler(c *gin.Context) {
+	wordID, ok := parseUint32Param(c, "wordId", "无效的敏感词ID")
+	if !ok {
+		return
+	}
+	var payload dto.UpdateSensitiveWordDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	word, err := h.sensitiveWordService.Update(c.Request.Context(), wordID, payload)
+	if err != nil {
+		if err.Error() == config.MsgSensitiveWordNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+			return
+		}
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "修改敏感词失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "敏感词已修改", word)
+}
+
+// DeleteSensitiveWordHandler godoc
+// @Summary 删除敏感词
+// @Description 删除敏感词，立即生效。需要管理员权限
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param wordId path int true "敏感词ID"
+// @Success 200 {object} vo.RespData "删除成功"
+// @Failure 404 {object} vo.RespData "敏感词不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/sensitive-words/{wordId} [delete]
+func (h *ContentFilterHandler) DeleteSensitiveWordHandler(c *gin.Context) {
+	wordID, ok := parseUint32Param(c, "wordId", "无效的敏感词ID")
+	if !ok {
+		return
+	}
+	if err := h.sensitiveWordService.Delete(c.Request.Context(), wordID); err != nil {
+		if err.Error() == config.MsgSensitiveWordNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+			return
+		}
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "删除敏感词失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "敏感词已删除", nil)
+}
+
+// GetContentReviewsHandler godoc
+// @Summary 内容审核队列
+// @Description 命中审核类敏感词的帖子、评论、课程评价和个人简介。默认只返回待审核的记录，按提交时间先后排列。需要版主或管理员权限
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每页数量" default(20)
+// @Param status query int false "0待审核 1通过 2驳回" default(0)
+// @Param targetType query string false "post/comment/review/user"
+// @Success 200 {object} vo.RespData{data=vo.ContentReviewListVO} "获取成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/content-reviews [get]
+func (h *ContentFilterHandler) GetContentReviewsHandler(c *gin.Context) {
+	var params dto.GetContentReviewsParamsDTO
+	if err := c.ShouldBindQuery(&params); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+	reviews, err := h.contentReviewService.List(c.Request.Context(), params)
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取审核队列失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取审核队列成功", reviews)
+}
+
+// resolveContentReview 审核通过或驳回
+func (h *ContentFilterHandler) resolveContentReview(c *gin.Context, approve bool) {
+	reviewID, ok := parseUint32Param(c, "reviewId", "无效的审核记录ID")
+	if !ok {
+		return
+	}
+	reviewerID, ok := getUserIDFromContext(c)
+	if !ok || reviewerID == nil {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	if err := h.contentReviewService.Resolve(c.Request.Context(), reviewID, *reviewerID, approve); err != nil {
+		switch err.Error() {
+		case config.MsgContentReviewNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+		case config.MsgContentReviewResolved:
+			vo.RespondError(c, http.StatusConflict, config.CodeConflict, err.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "审核失败", err)
+		}
+		return
+	}
+	if approve {
+		vo.RespondSuccess(c, "已通过审核", nil)
+	} else {
+		vo.RespondSuccess(c, "已驳回", nil)
+	}
+}
+
+// ApproveContentReviewHandler godoc
+// @Summary 审核通过
+// @Description 内容恢复展示，个人简介写入用户资料；同一内容的其他待审核记录一并处理。需要版主或管理员权限
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param reviewId path int true "审核记录ID"
+// @Success 200 {object} vo.RespData "操作成功"
+// @Failure 404 {object} vo.RespData "审核记录不存在"
+// @Failure 409 {object} vo.RespData "该内容已审核"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/content-reviews/{reviewId}/approve [post]
+func (h *ContentFilterHandler) ApproveContentReviewHandler(c *gin.Context) {
+	h.resolveContentReview(c, true)
+}
+
+// RejectContentReviewHandler godoc
+// @Summary 审核驳回
+// @Description 内容保持隐藏，个人简介不生效。需要版主或管理员权限
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param reviewId path int true "审核记录ID"
+// @Success 200 {object} vo.RespData "操作成功"
+// @Failure 404 {object} vo.RespData "审核记录不存在"
+// @Failure 409 {object} vo.RespData "该内容已审核"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/content-reviews/{reviewId}/reject [post]
+func (h *ContentFilterHandler) RejectContentReviewHandler(c *gin.Context) {
+	h.resolveContentReview(c, false)
+}
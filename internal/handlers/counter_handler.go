@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CounterHandler struct {
+	counterService *services.CounterService
+}
+
+func NewCounterHandler() *CounterHandler {
+	return &CounterHandler{
+		counterService: services.NewCounterService(),
+	}
+}
+
+// ReconcileCountersHandler godoc
+// @Summary 立即执行计数对账
+// @Description 从点赞、收藏、评论明细表重新统计帖子、评论与用户的计数，修正偏差并返回各计数列修正的行数。定时任务也会周期执行。需要管理员权限
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} vo.RespData{data=vo.CounterReconcileVO} "对账完成"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/counters/reconcile [post]
+func (h *CounterHandler) ReconcileCountersHandler(c *gin.Context) {
+	fixed, err := h.counterService.Reconcile(c.Request.Context())
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "计数对账失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "计数对账完成", vo.CounterReconcileVO{Fixed: fixed})
+}
@@ -0,0 +1,95 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditViewOptions 结构化课表的旁听相关展示选项
+type auditViewOptions struct {
+	HideClosed  bool // 隐藏老师明确不欢迎旁听的课程
+	SortByScore bool // 楼层内按蹭课友好度降序排列
+}
+
+// parseAuditViewOptions 解析 hideClosed 与 sortBy=auditorScore 查询参数
+func parseAuditViewOptions(c *gin.Context) auditViewOptions {
+	return auditViewOptions{
+		HideClosed:  c.Query("hideClosed") == "true",
+		SortByScore: c.Query("sortBy") == "auditorScore",
+	}
+}
+
+// applyAuditViewOptions 在缓存结果之上做过滤与排序，Rooms 与 Courses 按下标一一对应，需同步处理；
+// 过滤后没有课程的楼层和教学楼一并去掉，并重新统计数量
+func applyAuditViewOptions(divisions []vo.DivisionVO, opts auditViewOptions) []vo.DivisionVO {
+	if !opts.HideClosed && !opts.SortByScore {
+		return divisions
+	}
+
+	for i := range divisions {
+		division := &divisions[i]
+		division.TotalCourses = 0
+		buildings := make([]*vo.BuildingVO, 0, len(division.Buildings))
+		for _, building := range division.Buildings {
+			building.TotalCourses = 0
+			building.TotalRooms = 0
+			floors := make([]*vo.FloorVO, 0, len(building.Floors))
+			for _, floor := range building.Floors {
+				filterAndSortFloor(floor, opts)
+				if len(floor.Courses) == 0 {
+					continue
+				}
+				floors = append(floors, floor)
+				building.TotalCourses += len(floor.Courses)
+				building.TotalRooms += len(floor.Rooms)
+			}
+			if len(floors) == 0 {
+				continue
+			}
+			building.Floors = floors
+			building.TotalFloors = len(floors)
+			buildings = append(buildings, building)
+			division.TotalCourses += building.TotalCourses
+		}
+		division.Buildings = buildings
+		division.TotalBuildings = len(buildings)
+	}
+	return divisions
+}
+
+// filterAndSortFloor 处理单个楼层的课程与教室
+func filterAndSortFloor(floor *vo.FloorVO, opts auditViewOptions) {
+	type pair struct {
+		room   *vo.RoomVO
+		course *vo.CourseInfoVO
+	}
+	pairs := make([]pair, 0, len(floor.Courses))
+	for idx, c := range floor.Courses {
+		if opts.HideClosed && c.AuditPolicy == dto.AuditPolicyClosed {
+			continue
+		}
+		var room *vo.RoomVO
+		if idx < len(floor.Rooms) {
+			room = floor.Rooms[idx]
+		}
+		pairs = append(pairs, pair{room: room, course: c})
+	}
+
+	if opts.SortByScore {
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return pairs[i].course.AuditorScore > pairs[j].course.AuditorScore
+		})
+	}
+
+	floor.Courses = make([]*vo.CourseInfoVO, 0, len(pairs))
+	floor.Rooms = make([]*vo.RoomVO, 0, len(pairs))
+	for _, p := range pairs {
+		floor.Courses = append(floor.Courses, p.course)
+		if p.room != nil {
+			floor.Rooms = append(floor.Rooms, p.room)
+		}
+	}
+}
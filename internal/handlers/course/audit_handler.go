@@ -0,0 +1,267 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseCourseIDParam 解析路径中的 courseId，失败时直接写回 400
+func parseCourseIDParam(c *gin.Context) (uint32, bool) {
+	courseIDUint64, err := strconv.ParseUint(c.Param("courseId"), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的课程ID格式", err)
+		return 0, false
+	}
+	return uint32(courseIDUint64), true
+}
+
+// parsePageLimit 解析审核队列类接口的分页参数，默认每页20条，最多100条
+func parsePageLimit(c *gin.Context) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return page, limit
+}
+
+// SubmitAuditPolicyProposalHandler godoc
+// @Summary 提议修改课程的旁听态度
+// @Description 用户可提议课程的旁听态度（welcome/neutral/closed），提交后进入管理员审核队列。需要用户认证。
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param courseId path uint true "课程ID"
+// @Param proposal body dto.AuditPolicyProposalCreateDTO true "提议内容"
+// @Success 201 {object} vo.RespData{data=vo.AuditPolicyProposalVO} "提交成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 409 {object} vo.RespData "已有待审核的提议"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/{courseId}/audit-policy/proposals [post]
+func (h *CourseHandler) SubmitAuditPolicyProposalHandler(c *gin.Context) {
+	courseID, ok := parseCourseIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	var payload dto.AuditPolicyProposalCreateDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请求参数错误: "+err.Error(), err)
+		return
+	}
+
+	proposalVO, serviceErr := h.courseAuditService.SubmitProposal(courseID, *userID, payload)
+	if serviceErr != nil {
+		switch serviceErr.Error() {
+		case config.MsgCourseNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		case config.MsgProposalAlreadyExists:
+			vo.RespondError(c, http.StatusConflict, config.CodeConflict, serviceErr.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "提交旁听态度提议失败", serviceErr)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, vo.NewSuccessResp("提议已提交，等待审核", proposalVO))
+}
+
+// CheckInCourseHandler godoc
+// @Summary 蹭课打卡
+// @Description 用户在课程现场打卡，每人每门课每天一次；打卡数据用于计算课程的蹭课友好度。需要用户认证。
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param courseId path uint true "课程ID"
+// @Success 201 {object} vo.RespData{data=vo.CourseCheckInVO} "打卡成功"
+// @Failure 400 {object} vo.RespData "请求参数错误 (无效的课程ID)"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 409 {object} vo.RespData "今天已打过卡"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/{courseId}/check-in [post]
+func (h *CourseHandler) CheckInCourseHandler(c *gin.Context) {
+	courseID, ok := parseCourseIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	checkInVO, serviceErr := h.courseAuditService.CheckIn(c.Request.Context(), courseID, *userID)
+	if serviceErr != nil {
+		switch serviceErr.Error() {
+		case config.MsgCourseNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		case config.MsgAlreadyCheckedIn:
+			vo.RespondError(c, http.StatusConflict, config.CodeConflict, serviceErr.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "打卡失败", serviceErr)
+		}
+		return
+	}
+
+	h.trendingService.Record(c.Request.Context(), courseID, services.TrendingActor(userID, ""), services.TrendingEventCheckIn)
+	c.JSON(http.StatusCreated, vo.NewSuccessResp("打卡成功", checkInVO))
+}
+
+// SetAuditPolicyHandler godoc
+// @Summary 管理员设置课程旁听态度
+// @Description 直接设置课程的旁听态度（例如老师本人要求不接受旁听），并重新计算蹭课友好度。需要管理员权限。
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param courseId path uint true "课程ID"
+// @Param policy body dto.SetAuditPolicyDTO true "旁听态度"
+// @Success 200 {object} vo.RespData "设置成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/courses/{courseId}/audit-policy [put]
+func (h *CourseHandler) SetAuditPolicyHandler(c *gin.Context) {
+	courseID, ok := parseCourseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var payload dto.SetAuditPolicyDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请求参数错误: "+err.Error(), err)
+		return
+	}
+
+	if err := h.courseAuditService.SetAuditPolicy(c.Request.Context(), courseID, payload.Policy); err != nil {
+		if err.Error() == config.MsgCourseNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+		} else {
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "设置旁听态度失败", err)
+		}
+		return
+	}
+
+	vo.RespondSuccess(c, "设置成功", nil)
+}
+
+// GetAuditPolicyProposalsHandler godoc
+// @Summary 管理员查看旁听态度提议队列
+// @Description 按提交时间正序分页返回提议，可按状态过滤。需要管理员权限。
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param status query string false "状态（pending/approved/rejected，默认 pending）"
+// @Param page query int false "页码，默认1"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} vo.RespData{data=vo.AuditPolicyProposalListVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/audit-policy/proposals [get]
+func (h *CourseHandler) GetAuditPolicyProposalsHandler(c *gin.Context) {
+	status := c.DefaultQuery("status", dto.ProposalStatusPending)
+	switch status {
+	case dto.ProposalStatusPending, dto.ProposalStatusApproved, dto.ProposalStatusRejected:
+	default:
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的提议状态", nil)
+		return
+	}
+
+	page, limit := parsePageLimit(c)
+	proposals, serviceErr := h.courseAuditService.ListProposals(status, page, limit)
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取提议列表失败", serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "获取提议列表成功", proposals)
+}
+
+// ApproveAuditPolicyProposalHandler godoc
+// @Summary 管理员通过旁听态度提议
+// @Description 通过后课程的旁听态度更新为提议值，并重新计算蹭课友好度。需要管理员权限。
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param proposalId path uint true "提议ID"
+// @Success 200 {object} vo.RespData "操作成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "提议不存在"
+// @Failure 409 {object} vo.RespData "提议已被审核"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/audit-policy/proposals/{proposalId}/approve [post]
+func (h *CourseHandler) ApproveAuditPolicyProposalHandler(c *gin.Context) {
+	h.reviewAuditPolicyProposal(c, true)
+}
+
+// RejectAuditPolicyProposalHandler godoc
+// @Summary 管理员驳回旁听态度提议
+// @Description 驳回提议，课程的旁听态度保持不变。需要管理员权限。
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param proposalId path uint true "提议ID"
+// @Success 200 {object} vo.RespData "操作成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "提议不存在"
+// @Failure 409 {object} vo.RespData "提议已被审核"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/audit-policy/proposals/{proposalId}/reject [post]
+func (h *CourseHandler) RejectAuditPolicyProposalHandler(c *gin.Context) {
+	h.reviewAuditPolicyProposal(c, false)
+}
+
+// reviewAuditPolicyProposal 审核提议的公共逻辑
+func (h *CourseHandler) reviewAuditPolicyProposal(c *gin.Context, approve bool) {
+	proposalIDUint64, err := strconv.ParseUint(c.Param("proposalId"), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的提议ID格式", err)
+		return
+	}
+
+	reviewerID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	serviceErr := h.courseAuditService.ReviewProposal(c.Request.Context(), uint32(proposalIDUint64), *reviewerID, approve)
+	if serviceErr != nil {
+		switch serviceErr.Error() {
+		case config.MsgProposalNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		case config.MsgProposalReviewed:
+			vo.RespondError(c, http.StatusConflict, config.CodeConflict, serviceErr.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "审核提议失败", serviceErr)
+		}
+		return
+	}
+
+	vo.RespondSuccess(c, "操作成功", nil)
+}
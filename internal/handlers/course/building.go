@@ -2,9 +2,14 @@ package course
 
 import (
 	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/services/course"
 	"cengkeHelperBackGo/pkg/generator"
+	"context"
+	"fmt"
 	"log"
 	"slices"
+	"sync"
 )
 
 type RespTeachInfo struct {
@@ -25,6 +30,9 @@ type RespTeachInfo struct {
 
 	AverageRating float32 `json:"rating,omitempty"`
 	ReviewCount   uint32  `json:"reviewCount,omitempty"`
+
+	AuditPolicy  string  `json:"auditPolicy,omitempty"`
+	AuditorScore float32 `json:"auditorScore"`
 }
 type MapTeachInfo struct {
 	CourseNum    string
@@ -45,6 +53,9 @@ type MapTeachInfo struct {
 	DayOfWeek int
 
 	CourseType string
+
+	AuditPolicy  string
+	AuditorScore float32
 }
 
 // BuildingTeachInfos 每个学部各个教学楼的课程信息
@@ -53,80 +64,151 @@ type BuildingTeachInfos struct {
 	Infos    []RespTeachInfo `json:"infos"`
 }
 
-var RespTeachInfos = make([][]BuildingTeachInfos, 5)
-
-func searchByAreaAndWeekday(areaNum int, weekday int, weekNum int, lessonNum int) []MapTeachInfo {
+func searchByAreaAndWeekday(ctx context.Context, areaNum int, weekday int, weekNum int, lessonNum int) ([]MapTeachInfo, error) {
 	tempInfo := make([]MapTeachInfo, 0)
-	weekLessonBin := generator.WeekLesson2Bin([]int{weekNum}, []int{lessonNum})
-	if err := database.Client.
-		Raw(queryStr,
-			weekday, areaNum, weekLessonBin, weekLessonBin).
-		Find(&tempInfo).Error; err != nil {
-		log.Fatal(err)
+	// 学期外（不在第 1-19 周）没有课程，只看考试
+	if generator.IsTermWeek(weekNum) {
+		weekLessonBin := generator.WeekLesson2Bin([]int{weekNum}, []int{lessonNum})
+		if err := database.Client.WithContext(ctx).
+			Raw(queryStr,
+				weekday, areaNum, weekLessonBin, weekLessonBin).
+			Find(&tempInfo).Error; err != nil {
+			return nil, fmt.Errorf("查询学部 %d 课程失败: %w", areaNum, err)
+		}
 	}
 
-	return tempInfo
+	// 正在考试的教室同样视为占用
+	exams, err := course.ExamRoomsOn(ctx, areaNum, weekNum, weekday)
+	if err != nil {
+		return nil, err
+	}
+	for _, exam := range exams {
+		if !exam.Covers(lessonNum) {
+			continue
+		}
+		tempInfo = append(tempInfo, examTeachInfo(exam, weekNum, weekday))
+	}
+
+	return tempInfo, nil
 }
 
-func GetInfos(weekNum, weekday, lessonNum int) [][]BuildingTeachInfos {
+// examTeachInfo 将考试占用转换为与课程相同的展示结构
+func examTeachInfo(exam course.ExamRoom, weekNum, weekday int) MapTeachInfo {
+	lessons := make([]int, 0, exam.EndLesson-exam.StartLesson+1)
+	for l := exam.StartLesson; l <= exam.EndLesson; l++ {
+		lessons = append(lessons, l)
+	}
+	weeks := make([]int, 0, 1)
+	if generator.IsTermWeek(weekNum) {
+		weeks = append(weeks, weekNum)
+	}
+	return MapTeachInfo{
+		CourseNum:   exam.CourseNum,
+		ID:          exam.CourseID,
+		Classroom:   exam.Classroom,
+		Faculty:     exam.Faculty,
+		CourseName:  "考试 " + exam.CourseName,
+		Building:    exam.Building,
+		WeekAndTime: generator.WeekLesson2Bin(weeks, lessons),
+		DayOfWeek:   weekday,
+		CourseType:  "考试",
+		AuditPolicy: dto.AuditPolicyClosed,
+	}
+}
+
+// GetInfos 并发查询四个学部的课程，任一学部失败或 ctx 被取消时返回错误
+func GetInfos(ctx context.Context, weekNum, weekday, lessonNum int) ([][]BuildingTeachInfos, error) {
+	ctx, cancel := context.WithTimeout(ctx, courseQueryTimeout)
+	defer cancel()
+
+	respTeachInfos := make([][]BuildingTeachInfos, 5)
 	for i := 0; i < 5; i++ {
-		RespTeachInfos[i] = make([]BuildingTeachInfos, 0)
+		respTeachInfos[i] = make([]BuildingTeachInfos, 0)
 	}
 
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
 	for i := 1; i <= 4; i++ {
-		buildingMap := make(map[string][]RespTeachInfo)
-
-		for _, info := range searchByAreaAndWeekday(i, weekday, weekNum, lessonNum) {
-			// 数据库已经完成过滤，不再需要内存中的二次过滤
-			res := RespTeachInfo{
-				ID:            info.ID,
-				CourseNum:     info.CourseNum,
-				Room:          info.Classroom,
-				Faculty:       info.Faculty,
-				CourseName:    info.CourseName,
-				TeacherName:   info.Teacher,
-				TeacherTitle:  info.TeacherTitle,
-				CourseTime:    generator.NearestToDisplay(lessonNum, info.WeekAndTime),
-				CourseType:    info.CourseType,
-				Credit:        info.Credit,
-				AverageRating: info.AverageRating,
-				ReviewCount:   info.ReviewCount,
-
-				WeekAndTime: info.WeekAndTime,
-				DayOfWeek:   info.DayOfWeek,
+		wg.Add(1)
+		go func(area int) {
+			defer wg.Done()
+			infos, err := searchByAreaAndWeekday(ctx, area, weekday, weekNum, lessonNum)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel() // 其余学部的查询没有意义了，尽快释放连接
+				})
+				return
 			}
-			// 去重：按 courseNum 在同一教学楼内去重，避免同一课程因为不同教室/时段重复出现
-			existing := false
-			for _, ex := range buildingMap[info.Building] {
-				if ex.CourseNum != "" && ex.CourseNum == info.CourseNum {
-					existing = true
-					break
-				}
-				// 作为兜底，如果 CourseNum 缺失，则用 ID 检查去重
-				if ex.CourseNum == "" && ex.ID == info.ID {
-					existing = true
-					break
-				}
+			respTeachInfos[area-1] = groupByBuilding(infos, lessonNum)
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		log.Printf("GetInfos: %v", firstErr)
+		return nil, firstErr
+	}
+	return respTeachInfos, nil
+}
+
+// groupByBuilding 将一个学部的查询结果按教学楼分组，并按课程数量排序
+func groupByBuilding(infos []MapTeachInfo, lessonNum int) []BuildingTeachInfos {
+	buildingMap := make(map[string][]RespTeachInfo)
+
+	for _, info := range infos {
+		// 数据库已经完成过滤，不再需要内存中的二次过滤
+		res := RespTeachInfo{
+			ID:            info.ID,
+			CourseNum:     info.CourseNum,
+			Room:          info.Classroom,
+			Faculty:       info.Faculty,
+			CourseName:    info.CourseName,
+			TeacherName:   info.Teacher,
+			TeacherTitle:  info.TeacherTitle,
+			CourseTime:    generator.NearestToDisplay(lessonNum, info.WeekAndTime),
+			CourseType:    info.CourseType,
+			Credit:        info.Credit,
+			AverageRating: info.AverageRating,
+			ReviewCount:   info.ReviewCount,
+			AuditPolicy:   info.AuditPolicy,
+			AuditorScore:  info.AuditorScore,
+
+			WeekAndTime: info.WeekAndTime,
+			DayOfWeek:   info.DayOfWeek,
+		}
+		// 去重：按 courseNum 在同一教学楼内去重，避免同一课程因为不同教室/时段重复出现
+		existing := false
+		for _, ex := range buildingMap[info.Building] {
+			if ex.CourseNum != "" && ex.CourseNum == info.CourseNum {
+				existing = true
+				break
 			}
-			if !existing {
-				buildingMap[info.Building] = append(buildingMap[info.Building], res)
+			// 作为兜底，如果 CourseNum 缺失，则用 ID 检查去重
+			if ex.CourseNum == "" && ex.ID == info.ID {
+				existing = true
+				break
 			}
 		}
-		for key, infos := range buildingMap {
-			RespTeachInfos[i-1] = append(RespTeachInfos[i-1], BuildingTeachInfos{
-				Building: key,
-				Infos:    infos,
-			})
+		if !existing {
+			buildingMap[info.Building] = append(buildingMap[info.Building], res)
 		}
-
 	}
 
-	// 每个学部的教学楼按照课程数量排序
-	for i := range RespTeachInfos {
-		slices.SortFunc(RespTeachInfos[i], func(a, b BuildingTeachInfos) int {
-			return len(b.Infos) - len(a.Infos)
+	buildings := make([]BuildingTeachInfos, 0, len(buildingMap))
+	for key, infos := range buildingMap {
+		buildings = append(buildings, BuildingTeachInfos{
+			Building: key,
+			Infos:    infos,
 		})
 	}
 
-	return RespTeachInfos
+	// 教学楼按照课程数量排序
+	slices.SortFunc(buildings, func(a, b BuildingTeachInfos) int {
+		return len(b.Infos) - len(a.Infos)
+	})
+	return buildings
 }
@@ -0,0 +1,184 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondCatalogError 将目录相关的业务错误映射为状态码
+func respondCatalogError(c *gin.Context, msg string, err error) {
+	switch err.Error() {
+	case config.MsgFacultyNotFound, config.MsgMajorNotFound, config.MsgUserNotFound:
+		vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+	case config.MsgProfileMajorMissing:
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, err.Error(), nil)
+	default:
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, msg, err)
+	}
+}
+
+// parseCatalogCourseQuery 解析按学院/专业浏览课程的分页与排序参数
+func parseCatalogCourseQuery(c *gin.Context) services.CatalogCourseQuery {
+	page, limit := parsePageLimit(c)
+	return services.CatalogCourseQuery{
+		Page:   page,
+		Limit:  limit,
+		SortBy: c.Query("sortBy"),
+		Grade:  strings.TrimSpace(c.Query("grade")),
+	}
+}
+
+// parseUint32Param 解析路径中的 uint32 参数，失败时直接写回 400
+func parseUint32Param(c *gin.Context, name, errMsg string) (uint32, bool) {
+	v, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, errMsg, err)
+		return 0, false
+	}
+	return uint32(v), true
+}
+
+// GetFacultiesHandler godoc
+// @Summary 获取学院列表
+// @Description 列出所有开课学院（同一学院的不同写法已合并），包含课程数、教学班数、平均评分与专业数。
+// @Tags Catalog
+// @Produce json
+// @Success 200 {object} vo.RespData{data=[]vo.FacultyVO} "成功"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /faculties [get]
+func (h *CourseHandler) GetFacultiesHandler(c *gin.Context) {
+	faculties, err := h.catalogService.ListFaculties(c.Request.Context())
+	if err != nil {
+		respondCatalogError(c, "获取学院列表失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取学院列表成功", faculties)
+}
+
+// GetFacultyMajorsHandler godoc
+// @Summary 获取学院下的专业列表
+// @Description 列出学院下所有开课专业及统计。
+// @Tags Catalog
+// @Produce json
+// @Param facultyId path uint true "学院ID"
+// @Success 200 {object} vo.RespData{data=[]vo.MajorVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "学院不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /faculties/{facultyId}/majors [get]
+func (h *CourseHandler) GetFacultyMajorsHandler(c *gin.Context) {
+	facultyID, ok := parseUint32Param(c, "facultyId", "无效的学院ID格式")
+	if !ok {
+		return
+	}
+	majors, err := h.catalogService.ListMajors(c.Request.Context(), facultyID)
+	if err != nil {
+		respondCatalogError(c, "获取专业列表失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取专业列表成功", majors)
+}
+
+// GetFacultyCoursesHandler godoc
+// @Summary 分页获取学院的课程
+// @Tags Catalog
+// @Produce json
+// @Param facultyId path uint true "学院ID"
+// @Param page query int false "页码，默认1"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Param sortBy query string false "排序：rating（默认）/ auditorScore / name"
+// @Success 200 {object} vo.RespData{data=vo.CatalogCoursePageVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "学院不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /faculties/{facultyId}/courses [get]
+func (h *CourseHandler) GetFacultyCoursesHandler(c *gin.Context) {
+	facultyID, ok := parseUint32Param(c, "facultyId", "无效的学院ID格式")
+	if !ok {
+		return
+	}
+	courses, err := h.catalogService.GetFacultyCourses(c.Request.Context(), facultyID, parseCatalogCourseQuery(c))
+	if err != nil {
+		respondCatalogError(c, "获取学院课程失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取学院课程成功", courses)
+}
+
+// GetMajorCoursesHandler godoc
+// @Summary 分页获取专业的课程
+// @Tags Catalog
+// @Produce json
+// @Param majorId path uint true "专业ID"
+// @Param grade query string false "年级过滤，如 2023"
+// @Param page query int false "页码，默认1"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Param sortBy query string false "排序：rating（默认）/ auditorScore / name"
+// @Success 200 {object} vo.RespData{data=vo.CatalogCoursePageVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "专业不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /majors/{majorId}/courses [get]
+func (h *CourseHandler) GetMajorCoursesHandler(c *gin.Context) {
+	majorID, ok := parseUint32Param(c, "majorId", "无效的专业ID格式")
+	if !ok {
+		return
+	}
+	courses, err := h.catalogService.GetMajorCourses(c.Request.Context(), majorID, parseCatalogCourseQuery(c))
+	if err != nil {
+		respondCatalogError(c, "获取专业课程失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取专业课程成功", courses)
+}
+
+// GetMyMajorAuditableCoursesHandler godoc
+// @Summary 我的专业里可以去蹭的课
+// @Description 根据个人资料中的专业（未设置时用学院），返回不拒绝旁听且与收藏课程时间不冲突的课程，按蹭课友好度降序。需要用户认证。
+// @Tags Catalog
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "页码，默认1"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} vo.RespData{data=vo.CatalogCoursePageVO} "成功"
+// @Failure 400 {object} vo.RespData "未设置学院或专业"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /users/me/major-courses [get]
+func (h *CourseHandler) GetMyMajorAuditableCoursesHandler(c *gin.Context) {
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+	page, limit := parsePageLimit(c)
+	courses, err := h.catalogService.GetAuditableCoursesInMyMajor(c.Request.Context(), *userID, page, limit)
+	if err != nil {
+		respondCatalogError(c, "获取专业课程失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取专业课程成功", courses)
+}
+
+// RebuildCatalogHandler godoc
+// @Summary 重建学院/专业目录
+// @Description 根据当前课程数据重新归一化学院与专业并刷新统计，导入教务数据后调用。需要管理员权限。
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} vo.RespData "成功"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/catalog/rebuild [post]
+func (h *CourseHandler) RebuildCatalogHandler(c *gin.Context) {
+	if err := h.catalogService.Rebuild(c.Request.Context()); err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "重建学院专业目录失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "学院专业目录已重建", nil)
+}
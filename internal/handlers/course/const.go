@@ -1,5 +1,10 @@
 package course
 
+import "time"
+
+// courseQueryTimeout 单次课程查询（四个学部并发）的最长耗时，超时后取消 SQL
+const courseQueryTimeout = 5 * time.Second
+
 var queryStr = `
         SELECT 
             MAX(ci.id) AS id,
@@ -15,7 +20,9 @@ var queryStr = `
             ANY_VALUE(ci.teacher) AS teacher,
             ANY_VALUE(ci.teacher_title) AS teacher_title,
             ANY_VALUE(ti.week_and_time) AS week_and_time,
-            ANY_VALUE(ti.day_of_week) AS day_of_week
+            ANY_VALUE(ti.day_of_week) AS day_of_week,
+            ANY_VALUE(ci.audit_policy) AS audit_policy,
+            ANY_VALUE(ci.auditor_score) AS auditor_score
         FROM time_infos ti 
         JOIN course_infos ci ON ci.id = ti.course_info_id
         WHERE ti.day_of_week = ? 
@@ -0,0 +1,244 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubmitCourseCorrectionHandler godoc
+// @Summary 提交课程勘误
+// @Description 提交对课程信息或上课时间地点的修改建议（如教室变更、停课周次、任课老师有误），审核通过后作为数据覆盖生效，重新导入教务数据后依然保留。需要用户认证。
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param courseId path uint true "课程ID"
+// @Param correction body dto.CourseCorrectionCreateDTO true "勘误内容"
+// @Success 201 {object} vo.RespData{data=vo.CourseCorrectionVO} "提交成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/{courseId}/corrections [post]
+func (h *CourseHandler) SubmitCourseCorrectionHandler(c *gin.Context) {
+	courseID, ok := parseCourseIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	var payload dto.CourseCorrectionCreateDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请求参数错误: "+err.Error(), err)
+		return
+	}
+
+	correctionVO, serviceErr := h.courseCorrectionService.SubmitCorrection(courseID, *userID, payload)
+	if serviceErr != nil {
+		switch {
+		case serviceErr.Error() == config.MsgCourseNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		case errors.Is(serviceErr, services.ErrInvalidCorrection):
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, serviceErr.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "提交勘误失败", serviceErr)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, vo.NewSuccessResp("勘误已提交，等待审核", correctionVO))
+}
+
+// GetCourseContributorsHandler godoc
+// @Summary 获取课程勘误贡献者
+// @Description 返回为该课程贡献过被采纳勘误的用户，按采纳次数降序。
+// @Tags Courses
+// @Produce json
+// @Param courseId path uint true "课程ID"
+// @Success 200 {object} vo.RespData{data=[]vo.CorrectionContributorVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误 (无效的课程ID)"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/{courseId}/contributors [get]
+func (h *CourseHandler) GetCourseContributorsHandler(c *gin.Context) {
+	courseID, ok := parseCourseIDParam(c)
+	if !ok {
+		return
+	}
+
+	contributors, serviceErr := h.courseCorrectionService.GetCourseContributors(courseID)
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取课程贡献者失败", serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "获取课程贡献者成功", contributors)
+}
+
+// GetMyCorrectionsHandler godoc
+// @Summary 获取我提交的勘误
+// @Description 返回当前用户提交的勘误及其审核结果和审核意见，按提交时间倒序。需要用户认证。
+// @Tags Courses
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "页码，默认1"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} vo.RespData{data=vo.CourseCorrectionListVO} "成功"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /users/corrections [get]
+func (h *CourseHandler) GetMyCorrectionsHandler(c *gin.Context) {
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	page, limit := parsePageLimit(c)
+	corrections, serviceErr := h.courseCorrectionService.ListUserCorrections(*userID, page, limit)
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取勘误列表失败", serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "获取勘误列表成功", corrections)
+}
+
+// GetCorrectionsHandler godoc
+// @Summary 管理员查看勘误队列
+// @Description 按提交时间正序分页返回勘误，可按状态过滤。需要管理员权限。
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param status query string false "状态（pending/approved/rejected，默认 pending）"
+// @Param page query int false "页码，默认1"
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Success 200 {object} vo.RespData{data=vo.CourseCorrectionListVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/corrections [get]
+func (h *CourseHandler) GetCorrectionsHandler(c *gin.Context) {
+	status := c.DefaultQuery("status", dto.ProposalStatusPending)
+	switch status {
+	case dto.ProposalStatusPending, dto.ProposalStatusApproved, dto.ProposalStatusRejected:
+	default:
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的勘误状态", nil)
+		return
+	}
+
+	page, limit := parsePageLimit(c)
+	corrections, serviceErr := h.courseCorrectionService.ListCorrections(status, page, limit)
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取勘误列表失败", serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "获取勘误列表成功", corrections)
+}
+
+// ApproveCorrectionHandler godoc
+// @Summary 管理员采纳勘误
+// @Description 采纳后勘误内容写入数据覆盖并立即生效，提交者可在"我的勘误"中看到结果。需要管理员权限。
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param correctionId path uint true "勘误ID"
+// @Param review body dto.ReviewCorrectionDTO false "审核意见"
+// @Success 200 {object} vo.RespData "操作成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "勘误不存在"
+// @Failure 409 {object} vo.RespData "勘误已被审核"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/corrections/{correctionId}/approve [post]
+func (h *CourseHandler) ApproveCorrectionHandler(c *gin.Context) {
+	h.reviewCorrection(c, true)
+}
+
+// RejectCorrectionHandler godoc
+// @Summary 管理员驳回勘误
+// @Description 驳回勘误，课程数据保持不变；审核意见会反馈给提交者。需要管理员权限。
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param correctionId path uint true "勘误ID"
+// @Param review body dto.ReviewCorrectionDTO false "审核意见"
+// @Success 200 {object} vo.RespData "操作成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "勘误不存在"
+// @Failure 409 {object} vo.RespData "勘误已被审核"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/corrections/{correctionId}/reject [post]
+func (h *CourseHandler) RejectCorrectionHandler(c *gin.Context) {
+	h.reviewCorrection(c, false)
+}
+
+// reviewCorrection 审核勘误的公共逻辑
+func (h *CourseHandler) reviewCorrection(c *gin.Context, approve bool) {
+	correctionIDUint64, err := strconv.ParseUint(c.Param("correctionId"), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的勘误ID格式", err)
+		return
+	}
+
+	reviewerID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	// 审核意见可选，请求体为空时忽略
+	var payload dto.ReviewCorrectionDTO
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请求参数错误: "+err.Error(), err)
+			return
+		}
+	}
+
+	serviceErr := h.courseCorrectionService.ReviewCorrection(c.Request.Context(), uint32(correctionIDUint64), *reviewerID, approve, payload.Note)
+	if serviceErr != nil {
+		switch serviceErr.Error() {
+		case config.MsgCorrectionNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		case config.MsgCorrectionReviewed:
+			vo.RespondError(c, http.StatusConflict, config.CodeConflict, serviceErr.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "审核勘误失败", serviceErr)
+		}
+		return
+	}
+
+	vo.RespondSuccess(c, "操作成功", nil)
+}
+
+// ApplyCourseOverridesHandler godoc
+// @Summary 重新套用课程数据覆盖
+// @Description 将所有已采纳的勘误覆盖重新套用到当前课程数据，导入教务数据后调用。需要管理员权限。
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} vo.RespData{data=vo.OverrideApplyResultVO} "成功"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/course-overrides/apply [post]
+func (h *CourseHandler) ApplyCourseOverridesHandler(c *gin.Context) {
+	result, serviceErr := h.courseCorrectionService.ApplyAllOverrides(c.Request.Context())
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "套用数据覆盖失败", serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "数据覆盖套用完成", result)
+}
@@ -0,0 +1,64 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunDataLintHandler godoc
+// @Summary 检查课程数据质量
+// @Description 扫描课程与时间段数据，按类别返回异常（无法推断楼层的教室、无法解析的学分、没有节次的时间段、孤立的时间段、未知学部等），附行ID与修复建议。format=text 时返回便于阅读的文本。导入教务数据后也会自动运行。需要管理员权限。
+// @Tags Admin
+// @Produce json
+// @Produce plain
+// @Param Authorization header string true "Bearer <token>"
+// @Param format query string false "输出格式 json/text，默认 json"
+// @Success 200 {object} vo.RespData{data=vo.DataLintReportVO} "成功"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/data-lint [post]
+func (h *CourseHandler) RunDataLintHandler(c *gin.Context) {
+	report, serviceErr := h.dataLintService.Run(c.Request.Context())
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "数据质量检查失败", serviceErr)
+		return
+	}
+	respondDataLintReport(c, report)
+}
+
+// GetLatestDataLintHandler godoc
+// @Summary 获取最近一次数据质量报告
+// @Description 返回最近一次（通常是导入后自动运行的）数据质量报告。需要管理员权限。
+// @Tags Admin
+// @Produce json
+// @Produce plain
+// @Param Authorization header string true "Bearer <token>"
+// @Param format query string false "输出格式 json/text，默认 json"
+// @Success 200 {object} vo.RespData{data=vo.DataLintReportVO} "成功"
+// @Failure 404 {object} vo.RespData "还没有数据质量报告"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/data-lint/latest [get]
+func (h *CourseHandler) GetLatestDataLintHandler(c *gin.Context) {
+	report, serviceErr := h.dataLintService.LatestReport(c.Request.Context())
+	if serviceErr != nil {
+		if serviceErr.Error() == config.MsgLintReportNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+			return
+		}
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取数据质量报告失败", serviceErr)
+		return
+	}
+	respondDataLintReport(c, report)
+}
+
+// respondDataLintReport 按 format 参数输出 JSON 或文本报告
+func respondDataLintReport(c *gin.Context, report *vo.DataLintReportVO) {
+	if c.Query("format") == "text" {
+		c.String(http.StatusOK, services.FormatDataLintReport(report))
+		return
+	}
+	vo.RespondSuccess(c, "数据质量检查完成", report)
+}
@@ -0,0 +1,163 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCourseExamsHandler godoc
+// @Summary 获取教学班的考试安排
+// @Description 返回教学班的考试日期、起止时间、考场与座位安排说明。
+// @Tags Courses
+// @Produce json
+// @Param courseId path uint true "课程ID"
+// @Success 200 {object} vo.RespData{data=[]vo.ExamVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误 (无效的课程ID)"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/{courseId}/exams [get]
+func (h *CourseHandler) GetCourseExamsHandler(c *gin.Context) {
+	courseID, ok := parseCourseIDParam(c)
+	if !ok {
+		return
+	}
+
+	exams, serviceErr := h.examService.ListCourseExams(c.Request.Context(), courseID)
+	if serviceErr != nil {
+		respondExamError(c, serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "获取考试安排成功", exams)
+}
+
+// GetCourseExamsICSHandler godoc
+// @Summary 导出教学班考试安排（.ics）
+// @Description 将教学班的考试安排导出为 iCalendar 文件，可导入手机日历。
+// @Tags Courses
+// @Produce text/calendar
+// @Param courseId path uint true "课程ID"
+// @Success 200 {file} binary "ics 日历文件"
+// @Failure 400 {object} vo.RespData "请求参数错误 (无效的课程ID)"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/{courseId}/exams.ics [get]
+func (h *CourseHandler) GetCourseExamsICSHandler(c *gin.Context) {
+	courseID, ok := parseCourseIDParam(c)
+	if !ok {
+		return
+	}
+
+	exams, serviceErr := h.examService.ListCourseExams(c.Request.Context(), courseID)
+	if serviceErr != nil {
+		respondExamError(c, serviceErr)
+		return
+	}
+
+	name := "考试安排"
+	if len(exams) > 0 {
+		name = exams[0].CourseName + " 考试安排"
+	}
+	writeCalendar(c, fmt.Sprintf("course-%d-exams.ics", courseID), h.examService.Calendar(c.Request.Context(), name, exams))
+}
+
+// GetMyExamsHandler godoc
+// @Summary 获取我的考试安排
+// @Description 返回个人课表（收藏的课程）中所有教学班的考试安排，按考试时间排序。需要用户认证。
+// @Tags Courses
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} vo.RespData{data=[]vo.ExamVO} "成功"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /users/me/exams [get]
+func (h *CourseHandler) GetMyExamsHandler(c *gin.Context) {
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	exams, serviceErr := h.examService.ListUserExams(c.Request.Context(), *userID)
+	if serviceErr != nil {
+		respondExamError(c, serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "获取考试安排成功", exams)
+}
+
+// GetMyExamsICSHandler godoc
+// @Summary 导出我的考试安排（.ics）
+// @Description 将个人课表中所有考试导出为 iCalendar 文件。需要用户认证。
+// @Tags Courses
+// @Produce text/calendar
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {file} binary "ics 日历文件"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /users/me/exams.ics [get]
+func (h *CourseHandler) GetMyExamsICSHandler(c *gin.Context) {
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	exams, serviceErr := h.examService.ListUserExams(c.Request.Context(), *userID)
+	if serviceErr != nil {
+		respondExamError(c, serviceErr)
+		return
+	}
+
+	writeCalendar(c, "my-exams.ics", h.examService.Calendar(c.Request.Context(), "我的考试安排", exams))
+}
+
+// ImportExamsHandler godoc
+// @Summary 导入考试安排
+// @Description 与教务课程数据一同导入考试安排。每条可指定 courseId，或只指定 courseNum 以应用到该课程在当前学期的所有教学班；同一教学班同一开考时间的记录会被覆盖，replace=true 时先清空已有安排。格式有误或找不到课程的条目会被跳过并在结果中说明。需要管理员权限。
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param exams body dto.ExamImportDTO true "考试安排"
+// @Success 200 {object} vo.RespData{data=vo.ExamImportResultVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/exams/import [post]
+func (h *CourseHandler) ImportExamsHandler(c *gin.Context) {
+	var payload dto.ExamImportDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请求参数错误: "+err.Error(), err)
+		return
+	}
+
+	result, serviceErr := h.examService.ImportExams(c.Request.Context(), payload)
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "导入考试安排失败", serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "考试安排导入完成", result)
+}
+
+// respondExamError 考试安排接口的公共错误处理
+func respondExamError(c *gin.Context, serviceErr error) {
+	if serviceErr.Error() == config.MsgCourseNotFound {
+		vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		return
+	}
+	vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取考试安排失败", serviceErr)
+}
+
+// writeCalendar 以附件形式输出 .ics 文件
+func writeCalendar(c *gin.Context, filename string, data []byte) {
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
+}
@@ -6,7 +6,12 @@ import (
 	"cengkeHelperBackGo/internal/models/dto"
 	"cengkeHelperBackGo/internal/models/vo"
 	"cengkeHelperBackGo/internal/services"
+	courseSvc "cengkeHelperBackGo/internal/services/course"
+	"cengkeHelperBackGo/pkg/clock"
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -16,15 +21,37 @@ import (
 
 // CourseHandler 处理课程相关的HTTP请求
 type CourseHandler struct {
-	courseService          *services.CourseService
-	courseStructureService *services.CourseStructureService
+	courseService           *services.CourseService
+	courseStructureService  *services.CourseStructureService
+	courseAuditService      *services.CourseAuditService
+	courseCorrectionService *services.CourseCorrectionService
+	recommendationService   *services.RecommendationService
+	catalogService          *services.CatalogService
+	timetableService        *services.TimetableService
+	courseSyncService       *services.CourseSyncService
+	roomService             *services.RoomService
+	dataLintService         *services.DataLintService
+	examService             *services.ExamService
+	courseMaterialService   *services.CourseMaterialService
+	trendingService         *services.TrendingService
 }
 
 // NewCourseHandler 创建一个新的 CourseHandler
 func NewCourseHandler() *CourseHandler {
 	return &CourseHandler{
-		courseService:          services.NewCourseService(),
-		courseStructureService: services.NewCourseStructureService(),
+		courseService:           services.NewCourseService(),
+		courseStructureService:  services.NewCourseStructureService(),
+		courseAuditService:      services.NewCourseAuditService(),
+		courseCorrectionService: services.NewCourseCorrectionService(),
+		recommendationService:   services.NewRecommendationService(),
+		catalogService:          services.NewCatalogService(),
+		timetableService:        services.NewTimetableService(),
+		courseSyncService:       services.NewCourseSyncService(),
+		roomService:             services.NewRoomService(),
+		dataLintService:         services.NewDataLintService(),
+		examService:             services.NewExamService(),
+		courseMaterialService:   services.NewCourseMaterialService(),
+		trendingService:         services.NewTrendingService(),
 	}
 }
 
@@ -43,27 +70,82 @@ func getCourseHandlerUserIDFromContext(c *gin.Context) (*uint32, bool) {
 	return &userId, true
 }
 
+// respondCourseQueryError 根据课程查询错误的类型返回合适的状态码
+// 超时返回 504；客户端已断开时不再写响应体；其余视为服务器内部错误
+func respondCourseQueryError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(c.Request.Context().Err(), context.Canceled):
+		log.Printf("%s: 客户端已断开连接: %v", msg, err)
+		c.Abort()
+	case errors.Is(err, context.DeadlineExceeded):
+		vo.RespondError(c, http.StatusGatewayTimeout, config.CodeServiceUnavailable, msg+": 查询超时", err)
+	default:
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, msg, err)
+	}
+}
+
 // GetAllCoursesHandler godoc
 // @Summary 获取所有课程列表 (按学部和教学楼分组)
-// @Description 获取数据库中所有课程的列表，按学部和教学楼进行分组。
+// @Description 获取数据库中所有课程的列表，按学部和教学楼进行分组。数据量较大，新调用方请使用分页的 /all/pages。
 // @Tags Courses
 // @Accept json
 // @Produce json
 // @Success 200 {object} vo.RespData{data=[][]vo.BuildingInfoVO} "成功"
 // @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Failure 504 {object} vo.RespData "查询超时"
 // @Router /courses/all [get]
 func (h *CourseHandler) GetAllCoursesHandler(c *gin.Context) {
-
-	// 调用更新后的 service 方法
-	courses, serviceErr := h.courseService.GetAllCourses()
+	courses, serviceErr := h.courseService.GetAllCoursesGrouped(c.Request.Context())
 	if serviceErr != nil {
-		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取所有课程失败", serviceErr)
+		respondCourseQueryError(c, "获取所有课程失败", serviceErr)
 		return
 	}
 
-	if courses == nil {
-		// 确保返回一个空的嵌套数组，而不是 null
-		courses = [][]vo.BuildingInfoVO{}
+	vo.RespondSuccess(c, "所有课程数据获取成功", courses)
+}
+
+// GetAllCoursesPageHandler godoc
+// @Summary 分页获取所有课程列表 (按学部和教学楼分组)
+// @Description 获取数据库中所有课程的列表，按学部和教学楼进行分组。每个学部分别分页。
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Param divisionId query int false "学部ID（1-4，不传表示所有学部）"
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每个学部每页课程数（最大1000）" default(200)
+// @Success 200 {object} vo.RespData{data=[]vo.AllCoursesPageVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Failure 504 {object} vo.RespData "查询超时"
+// @Router /all/pages [get]
+func (h *CourseHandler) GetAllCoursesPageHandler(c *gin.Context) {
+	params := &services.AllCoursesQueryParams{
+		Page:  1,
+		Limit: 200,
+	}
+	if pageStr := c.Query("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+			params.Page = page
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 1000 {
+			params.Limit = limit
+		}
+	}
+	if divisionIDStr := c.Query("divisionId"); divisionIDStr != "" {
+		divisionID, err := strconv.Atoi(divisionIDStr)
+		if err != nil || divisionID < 1 || divisionID > 4 {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的学部ID", err)
+			return
+		}
+		params.DivisionID = &divisionID
+	}
+
+	courses, serviceErr := h.courseService.GetAllCourses(c.Request.Context(), params)
+	if serviceErr != nil {
+		respondCourseQueryError(c, "获取所有课程失败", serviceErr)
+		return
 	}
 
 	vo.RespondSuccess(c, "所有课程数据获取成功", courses)
@@ -82,33 +164,28 @@ func (h *CourseHandler) GetCoursesHandler(c *gin.Context) {
 	// 不再需要原始 handler 中的 ToVO 和 convertCoursesToVO 辅助函数，
 	// 因为数据转换的逻辑移到了 service 层。
 
-	infos := GetTeachInfos()
+	infos, err := GetTeachInfos(c.Request.Context())
+	if err != nil {
+		respondCourseQueryError(c, "获取课程数据失败", err)
+		return
+	}
 	// 假设 vo.RespondSuccess 存在
 	vo.RespondSuccess(c, "课程数据获取成功", infos)
 }
 
-// GetCurrentCourseTimeHandler godoc
-// @Summary 获取当前课程时间信息
-// @Description 获取当前的周次、星期几、节次等时间信息
-// @Tags Courses
-// @Accept json
-// @Produce json
-// @Success 200 {object} vo.RespData{data=vo.CurrentCourseTimeVO} "成功"
-// @Router /courses/current-time [get]
-func (h *CourseHandler) GetCurrentCourseTimeHandler(c *gin.Context) {
-	weekNum, weekday, lessonNum := h.courseStructureService.GetCurrentCourseTime()
-
-	// 星期名称映射
-	weekdayNames := map[int]string{
-		0: "周日",
-		1: "周一",
-		2: "周二",
-		3: "周三",
-		4: "周四",
-		5: "周五",
-		6: "周六",
-	}
+// 星期名称映射
+var weekdayNames = map[int]string{
+	0: "周日",
+	1: "周一",
+	2: "周二",
+	3: "周三",
+	4: "周四",
+	5: "周五",
+	6: "周六",
+}
 
+// buildCourseTimeVO 将周次/星期/节次组装为前端使用的时间信息
+func buildCourseTimeVO(weekNum, weekday, lessonNum int, t time.Time) vo.CurrentCourseTimeVO {
 	// 节次状态描述
 	var lessonStatus string
 	if lessonNum == -1 {
@@ -117,18 +194,99 @@ func (h *CourseHandler) GetCurrentCourseTimeHandler(c *gin.Context) {
 		lessonStatus = fmt.Sprintf("第%d节", lessonNum)
 	}
 
-	timeInfo := vo.CurrentCourseTimeVO{
+	return vo.CurrentCourseTimeVO{
 		WeekNum:      weekNum,
 		Weekday:      weekday,
 		WeekdayName:  weekdayNames[weekday],
 		LessonNum:    lessonNum,
 		LessonStatus: lessonStatus,
-		Timestamp:    time.Now().Unix(),
+		Timestamp:    t.Unix(),
 	}
+}
+
+// GetCurrentCourseTimeHandler godoc
+// @Summary 获取当前课程时间信息
+// @Description 获取当前的周次、星期几、节次等时间信息
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Success 200 {object} vo.RespData{data=vo.CurrentCourseTimeVO} "成功"
+// @Router /courses/current-time [get]
+func (h *CourseHandler) GetCurrentCourseTimeHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	weekNum, weekday, lessonNum := h.courseStructureService.GetCurrentCourseTime(ctx)
+
+	timeInfo := buildCourseTimeVO(weekNum, weekday, lessonNum, clock.Now(ctx))
 
 	vo.RespondSuccess(c, "当前课程时间获取成功", timeInfo)
 }
 
+// GetCoursesAtHandler godoc
+// @Summary 获取任意时刻的结构化课表
+// @Description 将指定时刻换算为周次/星期/节次，并返回该时刻的结构化课程数据（学部 → 教学楼 → 楼层 → 课程）
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Param datetime query string true "ISO8601 时间，如 2025-09-08T10:00:00+08:00"
+// @Param divisionId query int false "学部ID（1-4，不传表示所有学部）"
+// @Param hideClosed query bool false "是否隐藏不欢迎旁听的课程"
+// @Param sortBy query string false "排序方式（auditorScore=按蹭课友好度降序）"
+// @Success 200 {object} vo.RespData{data=vo.CoursesAtTimeVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/at [get]
+func (h *CourseHandler) GetCoursesAtHandler(c *gin.Context) {
+	datetimeStr := c.Query("datetime")
+	if datetimeStr == "" {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "缺少 datetime 参数", nil)
+		return
+	}
+	at, err := clock.Parse(datetimeStr)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, err.Error(), err)
+		return
+	}
+
+	var divisionID *int
+	if divisionIDStr := c.Query("divisionId"); divisionIDStr != "" {
+		id, err := strconv.Atoi(divisionIDStr)
+		if err != nil || id < 1 || id > 4 {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的学部ID", err)
+			return
+		}
+		divisionID = &id
+	}
+
+	weekNum, weekday, lessonNum := h.courseStructureService.TimeToNums(at, courseSvc.SemesterBeginDate)
+
+	divisions, err := GetStructuredCoursesWithCache(c.Request.Context(), weekday, weekNum, lessonNum)
+	if err != nil {
+		respondCourseQueryError(c, "获取课程数据失败", err)
+		return
+	}
+	if divisionID != nil {
+		divisions = filterDivisions(divisions, *divisionID)
+	}
+	divisions = applyAuditViewOptions(divisions, parseAuditViewOptions(c))
+
+	vo.RespondSuccess(c, "课程数据获取成功", vo.CoursesAtTimeVO{
+		Time:      buildCourseTimeVO(weekNum, weekday, lessonNum, at),
+		Divisions: divisions,
+	})
+}
+
+// filterDivisions 只保留指定学部
+func filterDivisions(divisions []vo.DivisionVO, divisionID int) []vo.DivisionVO {
+	target := fmt.Sprintf("division_%d", divisionID)
+	result := make([]vo.DivisionVO, 0, 1)
+	for _, d := range divisions {
+		if d.DivisionID == target {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
 // GetStructuredCoursesHandler godoc
 // @Summary 获取结构化的课程数据（学部 → 教学楼 → 楼层 → 课程）
 // @Description 获取按照四级结构组织的课程数据。默认返回当前时间的课程。参数说明：-1表示不限（查询所有），0或不传表示使用当前时间
@@ -140,6 +298,8 @@ func (h *CourseHandler) GetCurrentCourseTimeHandler(c *gin.Context) {
 // @Param lessonNum query int false "节次（-1=不限, 0或不传=当前节次）"
 // @Param divisionId query int false "学部ID（1-4，不传表示所有学部）"
 // @Param useCache query bool false "是否使用缓存（默认true）"
+// @Param hideClosed query bool false "是否隐藏不欢迎旁听的课程"
+// @Param sortBy query string false "排序方式（auditorScore=按蹭课友好度降序）"
 // @Success 200 {object} vo.RespData{data=[]vo.DivisionVO} "成功"
 // @Failure 500 {object} vo.RespData "服务器内部错误"
 // @Router /courses/structured [get]
@@ -189,7 +349,7 @@ func (h *CourseHandler) GetStructuredCoursesHandler(c *gin.Context) {
 
 	// 如果是默认查询（使用当前时间）且当前是非上课时间，返回空数据
 	if params.LessonNum == 0 {
-		_, _, lessonNum := h.courseStructureService.GetCurrentCourseTime()
+		_, _, lessonNum := h.courseStructureService.GetCurrentCourseTime(c.Request.Context())
 		if lessonNum == -1 {
 			// 返回包含学部结构但buildings为空的数据，而不是完全空的数组
 			emptyData := h.courseStructureService.GetEmptyDivisionStructure(params.DivisionID)
@@ -198,10 +358,14 @@ func (h *CourseHandler) GetStructuredCoursesHandler(c *gin.Context) {
 		}
 	}
 
-	params = h.courseStructureService.ValidParams(params)
-	divisions := GetStructuredCoursesWithCache(params.Weekday, params.WeekNum, params.LessonNum)
+	params = h.courseStructureService.ValidParams(c.Request.Context(), params)
+	divisions, err := GetStructuredCoursesWithCache(c.Request.Context(), params.Weekday, params.WeekNum, params.LessonNum)
+	if err != nil {
+		respondCourseQueryError(c, "获取课程数据失败", err)
+		return
+	}
 
-	//divisions
+	divisions = applyAuditViewOptions(divisions, parseAuditViewOptions(c))
 	vo.RespondSuccess(c, "课程数据获取成功", divisions)
 }
 
@@ -235,6 +399,8 @@ func (h *CourseHandler) GetCourseDetailHandler(c *gin.Context) {
 		}
 		return
 	}
+	userID, _ := getCourseHandlerUserIDFromContext(c)
+	h.trendingService.Record(c.Request.Context(), uint32(courseID), services.TrendingActor(userID, c.ClientIP()), services.TrendingEventView)
 	vo.RespondSuccess(c, "课程详情获取成功", courseDetail)
 }
 
@@ -277,7 +443,7 @@ func (h *CourseHandler) GetCourseReviewsHandler(c *gin.Context) {
 
 // SubmitCourseReviewHandler godoc
 // @Summary 提交课程评价
-// @Description 为指定课程提交一条新的评价。需要用户认证。
+// @Description 为指定课程提交一条新的评价。需要用户认证。评价内容命中拦截词时返回 400，命中审核词时评分照常计入、文字审核通过后才展示。
 // @Tags Courses
 // @Accept json
 // @Produce json
@@ -310,12 +476,87 @@ func (h *CourseHandler) SubmitCourseReviewHandler(c *gin.Context) {
 		switch {
 		case errMsg == config.MsgCourseForReviewNotFound || errMsg == config.MsgUserForReviewNotFound:
 			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, errMsg, nil)
+		case errMsg == config.MsgContentBlocked:
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, errMsg, nil)
 		default: // 其他所有来自 service 层的错误都视为内部错误
 			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "提交评价处理失败", serviceErr)
 		}
 		return
 	}
 
+	h.trendingService.Record(c.Request.Context(), payload.CourseID, services.TrendingActor(&userID, ""), services.TrendingEventReview)
+
 	// HTTP 201 Created 表示资源成功创建，并返回创建的资源
 	c.JSON(http.StatusCreated, vo.NewSuccessResp("评价提交成功", createdReviewVO))
 }
+
+// ToggleFavoriteCourseHandler godoc
+// @Summary 切换课程收藏状态
+// @Description 收藏或取消收藏一门课程。需要用户认证。
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param courseId path uint true "课程ID"
+// @Success 200 {object} vo.RespData{data=vo.ToggleFavoriteCourseResponseDataVO} "操作成功"
+// @Failure 400 {object} vo.RespData "请求参数错误 (无效的课程ID)"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/{courseId}/toggle-favorite [post]
+func (h *CourseHandler) ToggleFavoriteCourseHandler(c *gin.Context) {
+	courseIDUint64, err := strconv.ParseUint(c.Param("courseId"), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的课程ID格式", err)
+		return
+	}
+
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	responseVO, serviceErr := h.courseService.ToggleFavoriteCourse(uint32(courseIDUint64), *userID)
+	if serviceErr != nil {
+		if serviceErr.Error() == config.MsgCourseNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		} else {
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "切换课程收藏状态失败", serviceErr)
+		}
+		return
+	}
+	actor := services.TrendingActor(userID, "")
+	if responseVO.IsFavorited {
+		h.trendingService.Record(c.Request.Context(), uint32(courseIDUint64), actor, services.TrendingEventFavorite)
+	} else {
+		h.trendingService.Revoke(c.Request.Context(), uint32(courseIDUint64), actor, services.TrendingEventFavorite)
+	}
+	vo.RespondSuccess(c, "操作成功", responseVO)
+}
+
+// GetFavoriteCoursesHandler godoc
+// @Summary 获取我收藏的课程
+// @Description 返回当前用户收藏的课程卡片列表，按收藏时间倒序。需要用户认证。
+// @Tags Courses
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} vo.RespData{data=[]vo.CourseCardVO} "成功"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /users/favorite-courses [get]
+func (h *CourseHandler) GetFavoriteCoursesHandler(c *gin.Context) {
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	courses, serviceErr := h.courseService.GetFavoriteCourses(*userID)
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取收藏课程失败", serviceErr)
+		return
+	}
+	vo.RespondSuccess(c, "收藏课程获取成功", courses)
+}
@@ -0,0 +1,296 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isAdminFromContext 判断当前用户是否为管理员，认证中间件以 uint8 存放角色
+func isAdminFromContext(c *gin.Context) bool {
+	roleVal, exists := c.Get("role")
+	if !exists {
+		return false
+	}
+	role, ok := roleVal.(uint8)
+	return ok && role == dto.UserRoleAdmin
+}
+
+// parseMaterialIDParam 解析路径中的资料ID，失败时直接返回 400
+func parseMaterialIDParam(c *gin.Context) (uint32, bool) {
+	materialIDUint64, err := strconv.ParseUint(c.Param("materialId"), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的资料ID格式", err)
+		return 0, false
+	}
+	return uint32(materialIDUint64), true
+}
+
+// materialUploadFromForm 读取 multipart 中的 file 字段，未上传文件时返回 nil
+func materialUploadFromForm(c *gin.Context) (*services.MaterialUpload, func(), error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return nil, func() {}, nil
+		}
+		return nil, nil, err
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	upload := &services.MaterialUpload{
+		FileName: fileHeader.Filename,
+		Size:     fileHeader.Size,
+		Reader:   file,
+	}
+	return upload, func() { _ = file.Close() }, nil
+}
+
+// GetCourseMaterialsHandler godoc
+// @Summary 获取课程资料列表
+// @Description 返回课程（同一课程编号的所有教学班共享）的教学大纲、课件、阅读书目等资料，教学大纲排在最前，文件类资料附带最新版本信息。
+// @Tags Courses
+// @Produce json
+// @Param courseId path uint true "课程ID"
+// @Success 200 {object} vo.RespData{data=[]vo.CourseMaterialVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误 (无效的课程ID)"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/{courseId}/materials [get]
+func (h *CourseHandler) GetCourseMaterialsHandler(c *gin.Context) {
+	courseID, ok := parseCourseIDParam(c)
+	if !ok {
+		return
+	}
+
+	materials, serviceErr := h.courseMaterialService.ListCourseMaterials(c.Request.Context(), courseID)
+	if serviceErr != nil {
+		respondMaterialError(c, serviceErr, "获取课程资料失败")
+		return
+	}
+
+	vo.RespondSuccess(c, "获取课程资料成功", materials)
+}
+
+// CreateCourseMaterialHandler godoc
+// @Summary 上传课程资料
+// @Description 为课程上传资料文件（multipart 的 file 字段），或只填写 linkUrl 添加外部链接。仅管理员或勘误被采纳达到一定次数的用户可上传；文件大小与类型受配置限制。需要用户认证。
+// @Tags Courses
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param courseId path uint true "课程ID"
+// @Param title formData string true "标题"
+// @Param kind formData string true "类型 (syllabus/slides/reading/other)"
+// @Param description formData string false "说明"
+// @Param linkUrl formData string false "外部链接，仅支持 http/https（不上传文件时必填，与文件二选一）"
+// @Param note formData string false "版本说明"
+// @Param file formData file false "资料文件"
+// @Success 201 {object} vo.RespData{data=vo.CourseMaterialVO} "上传成功"
+// @Failure 400 {object} vo.RespData "请求参数错误 / 同时上传文件和链接 / 链接协议不支持"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 403 {object} vo.RespData "没有上传权限"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 413 {object} vo.RespData "文件过大"
+// @Failure 415 {object} vo.RespData "文件类型不允许"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/{courseId}/materials [post]
+func (h *CourseHandler) CreateCourseMaterialHandler(c *gin.Context) {
+	courseID, ok := parseCourseIDParam(c)
+	if !ok {
+		return
+	}
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	var payload dto.CourseMaterialCreateDTO
+	if err := c.ShouldBind(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请求参数错误: "+err.Error(), err)
+		return
+	}
+	upload, closeFile, err := materialUploadFromForm(c)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "读取上传文件失败", err)
+		return
+	}
+	defer closeFile()
+
+	material, serviceErr := h.courseMaterialService.CreateMaterial(c.Request.Context(), courseID, *userID, isAdminFromContext(c), payload, upload)
+	if serviceErr != nil {
+		respondMaterialError(c, serviceErr, "上传课程资料失败")
+		return
+	}
+
+	c.JSON(http.StatusCreated, vo.NewSuccessResp("上传课程资料成功", material))
+}
+
+// AddCourseMaterialVersionHandler godoc
+// @Summary 上传课程资料新版本
+// @Description 为文件类资料上传新版本，旧版本仍可按版本号下载。权限与文件限制同上传资料。需要用户认证。
+// @Tags Courses
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param materialId path uint true "资料ID"
+// @Param note formData string false "版本说明"
+// @Param file formData file true "资料文件"
+// @Success 201 {object} vo.RespData{data=vo.CourseMaterialVO} "上传成功"
+// @Failure 400 {object} vo.RespData "请求参数错误 / 链接类资料不支持版本"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 403 {object} vo.RespData "没有上传权限"
+// @Failure 404 {object} vo.RespData "资料未找到"
+// @Failure 413 {object} vo.RespData "文件过大"
+// @Failure 415 {object} vo.RespData "文件类型不允许"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /materials/{materialId}/versions [post]
+func (h *CourseHandler) AddCourseMaterialVersionHandler(c *gin.Context) {
+	materialID, ok := parseMaterialIDParam(c)
+	if !ok {
+		return
+	}
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	upload, closeFile, err := materialUploadFromForm(c)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "读取上传文件失败", err)
+		return
+	}
+	defer closeFile()
+	if upload == nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, config.MsgMaterialFileRequired, nil)
+		return
+	}
+
+	material, serviceErr := h.courseMaterialService.AddVersion(c.Request.Context(), materialID, *userID, isAdminFromContext(c), c.PostForm("note"), upload)
+	if serviceErr != nil {
+		respondMaterialError(c, serviceErr, "上传资料新版本失败")
+		return
+	}
+
+	c.JSON(http.StatusCreated, vo.NewSuccessResp("上传资料新版本成功", material))
+}
+
+// GetCourseMaterialVersionsHandler godoc
+// @Summary 获取课程资料的历史版本
+// @Description 返回文件类资料的所有版本，新版本在前。
+// @Tags Courses
+// @Produce json
+// @Param materialId path uint true "资料ID"
+// @Success 200 {object} vo.RespData{data=[]vo.CourseMaterialVersionVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误 (无效的资料ID)"
+// @Failure 404 {object} vo.RespData "资料未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /materials/{materialId}/versions [get]
+func (h *CourseHandler) GetCourseMaterialVersionsHandler(c *gin.Context) {
+	materialID, ok := parseMaterialIDParam(c)
+	if !ok {
+		return
+	}
+
+	versions, serviceErr := h.courseMaterialService.ListVersions(c.Request.Context(), materialID)
+	if serviceErr != nil {
+		respondMaterialError(c, serviceErr, "获取资料版本失败")
+		return
+	}
+
+	vo.RespondSuccess(c, "获取资料版本成功", versions)
+}
+
+// DownloadCourseMaterialHandler godoc
+// @Summary 下载课程资料
+// @Description 下载资料文件并累加下载次数，version 缺省时下载最新版本；链接类资料重定向到外部链接。
+// @Tags Courses
+// @Produce octet-stream
+// @Param materialId path uint true "资料ID"
+// @Param version query int false "版本号，缺省为最新版本"
+// @Success 200 {file} binary "资料文件"
+// @Success 302 "链接类资料重定向"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "资料或版本未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /materials/{materialId}/download [get]
+func (h *CourseHandler) DownloadCourseMaterialHandler(c *gin.Context) {
+	materialID, ok := parseMaterialIDParam(c)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(c.DefaultQuery("version", "0"))
+	if err != nil || version < 0 {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的版本号", err)
+		return
+	}
+
+	download, serviceErr := h.courseMaterialService.Download(c.Request.Context(), materialID, version)
+	if serviceErr != nil {
+		respondMaterialError(c, serviceErr, "下载课程资料失败")
+		return
+	}
+	if download.Reader == nil {
+		c.Redirect(http.StatusFound, download.LinkURL)
+		return
+	}
+	defer download.Reader.Close()
+
+	c.DataFromReader(http.StatusOK, download.Size, download.ContentType, download.Reader, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": download.FileName}),
+	})
+}
+
+// DeleteCourseMaterialHandler godoc
+// @Summary 删除课程资料
+// @Description 删除资料及其所有版本的文件。需要管理员权限。
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param materialId path uint true "资料ID"
+// @Success 200 {object} vo.RespData "删除成功"
+// @Failure 400 {object} vo.RespData "请求参数错误 (无效的资料ID)"
+// @Failure 404 {object} vo.RespData "资料未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/materials/{materialId} [delete]
+func (h *CourseHandler) DeleteCourseMaterialHandler(c *gin.Context) {
+	materialID, ok := parseMaterialIDParam(c)
+	if !ok {
+		return
+	}
+
+	if serviceErr := h.courseMaterialService.DeleteMaterial(c.Request.Context(), materialID); serviceErr != nil {
+		respondMaterialError(c, serviceErr, "删除课程资料失败")
+		return
+	}
+
+	vo.RespondSuccess(c, "删除课程资料成功", nil)
+}
+
+// respondMaterialError 课程资料接口的公共错误处理
+func respondMaterialError(c *gin.Context, serviceErr error, fallback string) {
+	switch msg := serviceErr.Error(); msg {
+	case config.MsgCourseNotFound, config.MsgMaterialNotFound:
+		vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, msg, nil)
+	case config.MsgMaterialNotTrusted:
+		vo.RespondError(c, http.StatusForbidden, config.CodePermissionDenied, msg, nil)
+	case config.MsgMaterialFileRequired, config.MsgMaterialIsLink, config.MsgMaterialFileAndLink, config.MsgMaterialLinkInvalid:
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, msg, nil)
+	case config.MsgMaterialTooLarge:
+		vo.RespondError(c, http.StatusRequestEntityTooLarge, config.CodeInvalidParams, msg, nil)
+	case config.MsgMaterialTypeNotAllowed:
+		vo.RespondError(c, http.StatusUnsupportedMediaType, config.CodeInvalidParams, msg, nil)
+	default:
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, fallback, serviceErr)
+	}
+}
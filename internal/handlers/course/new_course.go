@@ -9,6 +9,7 @@ import (
 	database "cengkeHelperBackGo/internal/db"
 	"cengkeHelperBackGo/internal/models/vo"
 	"cengkeHelperBackGo/internal/services/course"
+	"cengkeHelperBackGo/pkg/coursedata"
 )
 
 var divisionNames = map[int]string{
@@ -18,24 +19,25 @@ var divisionNames = map[int]string{
 	4: "医学部",
 }
 
-func GetStructuredCoursesWithCache(dayOfWeek int, weekNum int, lessonNum int) []vo.DivisionVO {
+func GetStructuredCoursesWithCache(ctx context.Context, dayOfWeek int, weekNum int, lessonNum int) ([]vo.DivisionVO, error) {
 	cacheKey := fmt.Sprintf("structured_courses_w%d_d%d_l%d", weekNum, dayOfWeek, lessonNum)
 
-	ctx := context.Background()
-
 	// try fetch from redis
 	if database.RedisClient != nil {
 		if val, err := database.RedisClient.Get(ctx, cacheKey).Result(); err == nil {
 			var data []vo.DivisionVO
 			if err := json.Unmarshal([]byte(val), &data); err == nil {
-				return data
+				return data, nil
 			}
 			// if unmarshal failed, fallthrough to regenerate
 		}
 	}
 
 	// generate fresh
-	data := GetStructuredCourses(dayOfWeek, weekNum, lessonNum)
+	data, err := GetStructuredCourses(ctx, dayOfWeek, weekNum, lessonNum)
+	if err != nil {
+		return nil, err
+	}
 
 	// save to redis (best-effort)
 	if database.RedisClient != nil {
@@ -44,12 +46,15 @@ func GetStructuredCoursesWithCache(dayOfWeek int, weekNum int, lessonNum int) []
 		}
 	}
 
-	return data
+	return data, nil
 }
 
-func GetStructuredCourses(dayOfWeek int, weekNum int, lessonNum int) []vo.DivisionVO {
+func GetStructuredCourses(ctx context.Context, dayOfWeek int, weekNum int, lessonNum int) ([]vo.DivisionVO, error) {
 
-	infos := GetInfos(weekNum, dayOfWeek, lessonNum)
+	infos, err := GetInfos(ctx, weekNum, dayOfWeek, lessonNum)
+	if err != nil {
+		return nil, err
+	}
 
 	result := make([]vo.DivisionVO, 0, 5)
 
@@ -78,7 +83,7 @@ func GetStructuredCourses(dayOfWeek int, weekNum int, lessonNum int) []vo.Divisi
 			}
 			floors := make(map[int]*vo.FloorVO)
 			for _, info := range building.Infos {
-				floorNumber := course.ExtractFloorNumber(info.Room)
+				floorNumber := coursedata.ExtractFloorNumber(info.Room)
 				if _, exists := floors[floorNumber]; !exists {
 					floors[floorNumber] = &vo.FloorVO{
 						FloorID:     fmt.Sprintf("division_%d_%s_F%d", i+1, building.Building, floorNumber),
@@ -91,7 +96,7 @@ func GetStructuredCourses(dayOfWeek int, weekNum int, lessonNum int) []vo.Divisi
 				}
 
 				floors[floorNumber].Rooms = append(floors[floorNumber].Rooms, &vo.RoomVO{
-					RoomID:     fmt.Sprintf("division_%d_%s_F%d_%s", i+1, building.Building, floorNumber, info.Room),
+					RoomID:     course.BuildRoomID(i+1, building.Building, info.Room),
 					RoomNumber: info.Room,
 					RoomName:   fmt.Sprintf("教室 %s", info.Room),
 					Capacity:   0,
@@ -106,7 +111,7 @@ func GetStructuredCourses(dayOfWeek int, weekNum int, lessonNum int) []vo.Divisi
 					TeacherName:   info.TeacherName,
 					TeacherTitle:  info.TeacherTitle,
 					Faculty:       info.Faculty,
-					Credits:       course.ParseCredits(info.Credit),
+					Credits:       coursedata.ParseCredits(info.Credit),
 					CourseType:    info.CourseType,
 					Room:          info.Room,
 					TimeSlots:     course.ParseTimeSlots(info.WeekAndTime, info.DayOfWeek),
@@ -116,6 +121,8 @@ func GetStructuredCourses(dayOfWeek int, weekNum int, lessonNum int) []vo.Divisi
 					CourseTime:    info.CourseTime,
 					AverageRating: info.AverageRating,
 					ReviewCount:   info.ReviewCount,
+					AuditPolicy:   info.AuditPolicy,
+					AuditorScore:  info.AuditorScore,
 				})
 			}
 			buildingVO.TotalFloors = len(floors)
@@ -129,5 +136,5 @@ func GetStructuredCourses(dayOfWeek int, weekNum int, lessonNum int) []vo.Divisi
 
 	}
 
-	return result
+	return result, nil
 }
@@ -0,0 +1,67 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/vo"
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRecommendationsHandler godoc
+// @Summary 获取课程推荐（"蹭什么课"）
+// @Description 返回离线计算好的推荐课程及推荐理由：不与收藏课程时间冲突，匹配收藏/好评/打卡过的学院与老师，并偏好口碑好的老师。首次访问时结果为空且 pending=true，后台开始计算。需要用户认证。
+// @Tags Courses
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param limit query int false "返回条数，默认20，最大50"
+// @Success 200 {object} vo.RespData{data=vo.CourseRecommendationListVO} "成功"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/recommendations [get]
+func (h *CourseHandler) GetRecommendationsHandler(c *gin.Context) {
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	recommendations, serviceErr := h.recommendationService.GetRecommendations(c.Request.Context(), *userID, limit)
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取课程推荐失败", serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "获取课程推荐成功", recommendations)
+}
+
+// RebuildRecommendationsHandler godoc
+// @Summary 重新计算所有用户的课程推荐
+// @Description 在后台触发一次全量推荐计算，立即返回。需要管理员权限。
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 202 {object} vo.RespData "已开始计算"
+// @Router /admins/recommendations/rebuild [post]
+func (h *CourseHandler) RebuildRecommendationsHandler(c *gin.Context) {
+	go func() {
+		processed, err := h.recommendationService.RebuildAll(context.Background())
+		if err != nil {
+			log.Printf("RebuildRecommendationsHandler: 重算推荐失败: %v", err)
+		}
+		log.Printf("RebuildRecommendationsHandler: 已为 %d 位用户重算课程推荐", processed)
+	}()
+
+	c.JSON(http.StatusAccepted, vo.NewSuccessResp("已开始重新计算课程推荐", nil))
+}
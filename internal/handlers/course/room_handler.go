@@ -0,0 +1,122 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoomStatusHandler godoc
+// @Summary 获取教室实时状态
+// @Description 返回教室当前正在上的课、下一节课、空闲/占用状态，以及当前课程今天的打卡人数。门牌二维码指向此接口对应的页面。
+// @Tags Courses
+// @Produce json
+// @Param roomId path string true "教室ID，如 division_1_教一楼_F3_3-101"
+// @Success 200 {object} vo.RespData{data=vo.RoomStatusVO} "成功"
+// @Failure 404 {object} vo.RespData "教室不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /rooms/{roomId}/now [get]
+func (h *CourseHandler) GetRoomStatusHandler(c *gin.Context) {
+	status, serviceErr := h.roomService.GetRoomStatus(c.Request.Context(), c.Param("roomId"))
+	if serviceErr != nil {
+		if serviceErr.Error() == config.MsgRoomNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+			return
+		}
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取教室状态失败", serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "获取教室状态成功", status)
+}
+
+// GetRoomQRCodeHandler godoc
+// @Summary 生成教室门牌二维码
+// @Description 本地生成指向教室实时状态页面的二维码，用于打印贴在教室门口。需要管理员权限。
+// @Tags Admin
+// @Produce image/png
+// @Produce image/svg+xml
+// @Param Authorization header string true "Bearer <token>"
+// @Param roomId path string true "教室ID，如 division_1_教一楼_F3_3-101"
+// @Param format query string false "图片格式 png/svg，默认 png"
+// @Success 200 {file} binary "二维码图片"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "教室不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误 / 未配置二维码链接地址"
+// @Router /admins/rooms/{roomId}/qrcode [get]
+func (h *CourseHandler) GetRoomQRCodeHandler(c *gin.Context) {
+	format, ok := parseQRFormat(c)
+	if !ok {
+		return
+	}
+
+	data, serviceErr := h.roomService.RoomQRCode(c.Request.Context(), c.Param("roomId"), format)
+	if serviceErr != nil {
+		switch serviceErr.Error() {
+		case config.MsgRoomNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		case config.MsgRoomQRNotConfigured:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, serviceErr.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "生成二维码失败", serviceErr)
+		}
+		return
+	}
+
+	contentType := "image/png"
+	if format == services.QRFormatSVG {
+		contentType = "image/svg+xml; charset=utf-8"
+	}
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// GetBuildingQRCodesHandler godoc
+// @Summary 批量下载教学楼门牌二维码
+// @Description 将教学楼内所有教室的门牌二维码打包为 ZIP 下载。buildingId 为结构化课程数据中的教学楼ID。需要管理员权限。
+// @Tags Admin
+// @Produce application/zip
+// @Param Authorization header string true "Bearer <token>"
+// @Param buildingId path string true "教学楼ID，如 division_1_教一楼"
+// @Param format query string false "图片格式 png/svg，默认 png"
+// @Success 200 {file} binary "ZIP 压缩包"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "教学楼不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误 / 未配置二维码链接地址"
+// @Router /admins/buildings/{buildingId}/qrcodes.zip [get]
+func (h *CourseHandler) GetBuildingQRCodesHandler(c *gin.Context) {
+	format, ok := parseQRFormat(c)
+	if !ok {
+		return
+	}
+
+	buildingID := c.Param("buildingId")
+	data, serviceErr := h.roomService.BuildingQRCodesZip(c.Request.Context(), buildingID, format)
+	if serviceErr != nil {
+		switch serviceErr.Error() {
+		case config.MsgBuildingNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		case config.MsgRoomQRNotConfigured:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, serviceErr.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "生成二维码压缩包失败", serviceErr)
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": buildingID + "-qrcodes.zip"}))
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+// parseQRFormat 解析二维码图片格式，默认 png
+func parseQRFormat(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", services.QRFormatPNG)
+	if format != services.QRFormatPNG && format != services.QRFormatSVG {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的图片格式，应为 png 或 svg", nil)
+		return "", false
+	}
+	return format, true
+}
@@ -0,0 +1,91 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/vo"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCompareCourses 单次对比最多的教学班数量
+const maxCompareCourses = 6
+
+// GetSectionsByCourseNumHandler godoc
+// @Summary 获取同一课程的所有教学班
+// @Description 按课程编号列出所有教学班（不同老师、教室、时间），包含上课时间与评分，按评分降序。
+// @Tags Courses
+// @Produce json
+// @Param courseNum path string true "课程编号"
+// @Success 200 {object} vo.RespData{data=vo.CourseSectionsVO} "成功"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/by-num/{courseNum} [get]
+func (h *CourseHandler) GetSectionsByCourseNumHandler(c *gin.Context) {
+	courseNum := strings.TrimSpace(c.Param("courseNum"))
+	if courseNum == "" {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "课程编号不能为空", nil)
+		return
+	}
+
+	sections, serviceErr := h.courseService.GetSectionsByCourseNum(courseNum)
+	if serviceErr != nil {
+		if serviceErr.Error() == config.MsgCourseNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		} else {
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取教学班列表失败", serviceErr)
+		}
+		return
+	}
+
+	vo.RespondSuccess(c, "获取教学班列表成功", sections)
+}
+
+// CompareCoursesHandler godoc
+// @Summary 教学班并排对比
+// @Description 对比多个教学班的上课时间、地点、老师、学分、评分及评分分布，并标出取值不同的字段。
+// @Tags Courses
+// @Produce json
+// @Param ids query string true "逗号分隔的课程ID，2-6个，如 1,2,3"
+// @Success 200 {object} vo.RespData{data=vo.CourseComparisonVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/compare [get]
+func (h *CourseHandler) CompareCoursesHandler(c *gin.Context) {
+	courseIDs := make([]uint32, 0, maxCompareCourses)
+	seen := make(map[uint32]bool)
+	for _, part := range strings.Split(c.Query("ids"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的课程ID: "+part, err)
+			return
+		}
+		if !seen[uint32(id)] {
+			seen[uint32(id)] = true
+			courseIDs = append(courseIDs, uint32(id))
+		}
+	}
+	if len(courseIDs) < 2 || len(courseIDs) > maxCompareCourses {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请提供 2-6 个不同的课程ID", nil)
+		return
+	}
+
+	comparison, serviceErr := h.courseService.CompareCourses(courseIDs)
+	if serviceErr != nil {
+		if serviceErr.Error() == config.MsgCourseNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		} else {
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取课程对比失败", serviceErr)
+		}
+		return
+	}
+
+	vo.RespondSuccess(c, "获取课程对比成功", comparison)
+}
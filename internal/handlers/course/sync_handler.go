@@ -0,0 +1,90 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSyncSnapshotHandler godoc
+// @Summary 下载离线课表全量快照
+// @Description 返回当前学期全部教学班及其上课时间位编码，响应体为 gzip 压缩的 JSON（Content-Encoding: gzip），结构见 vo.SyncSnapshotVO。版本号同时放在 X-Sync-Version 响应头和 ETag 中，带 If-None-Match 且版本未变时返回 304。
+// @Tags Sync
+// @Produce json
+// @Success 200 {object} vo.SyncSnapshotVO "全量快照（gzip 压缩）"
+// @Success 304 "版本未变化"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /sync/courses/snapshot [get]
+func (h *CourseHandler) GetSyncSnapshotHandler(c *gin.Context) {
+	data, version, serviceErr := h.courseSyncService.Snapshot(c.Request.Context())
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取同步快照失败", serviceErr)
+		return
+	}
+
+	etag := `"v` + strconv.FormatUint(version, 10) + `"`
+	c.Header("ETag", etag)
+	c.Header("X-Sync-Version", strconv.FormatUint(version, 10))
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Header("Content-Encoding", "gzip")
+	c.Header("Vary", "Accept-Encoding")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// GetSyncChangesHandler godoc
+// @Summary 获取离线课表增量变化
+// @Description 返回自 since 版本以来新增或修改的教学班（完整数据）和被删除的教学班ID。since 大于当前版本时返回 400，客户端应重新下载全量快照。
+// @Tags Sync
+// @Produce json
+// @Param since query int true "客户端当前持有的版本号"
+// @Success 200 {object} vo.RespData{data=vo.SyncChangesVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /sync/courses/changes [get]
+func (h *CourseHandler) GetSyncChangesHandler(c *gin.Context) {
+	since, err := strconv.ParseUint(c.Query("since"), 10, 64)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的版本号", err)
+		return
+	}
+
+	changes, serviceErr := h.courseSyncService.Changes(c.Request.Context(), since)
+	if serviceErr != nil {
+		if serviceErr.Error() == config.MsgSyncVersionInvalid {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, serviceErr.Error(), nil)
+			return
+		}
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取同步增量失败", serviceErr)
+		return
+	}
+
+	c.Header("X-Sync-Version", strconv.FormatUint(changes.Version, 10))
+	vo.RespondSuccess(c, "获取同步增量成功", changes)
+}
+
+// RefreshSyncVersionHandler godoc
+// @Summary 检查教学班数据变化并推进同步版本
+// @Description 导入教务数据后调用，数据有变化时生成新的离线同步版本。需要管理员权限。
+// @Tags Admin
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} vo.RespData{data=vo.SyncRefreshResultVO} "成功"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/sync/courses/refresh [post]
+func (h *CourseHandler) RefreshSyncVersionHandler(c *gin.Context) {
+	result, serviceErr := h.courseSyncService.Refresh(c.Request.Context(), dto.SyncReasonImport)
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "检查同步版本失败", serviceErr)
+		return
+	}
+
+	vo.RespondSuccess(c, "同步版本检查完成", result)
+}
@@ -1,25 +1,22 @@
 package course
 
 import (
+	"cengkeHelperBackGo/internal/services/course"
+	"cengkeHelperBackGo/pkg/clock"
+	"context"
 	"time"
 )
 
-func GetTeachInfos() [][]BuildingTeachInfos {
-	return GetInfos(CurCourseTime())
+func GetTeachInfos(ctx context.Context) ([][]BuildingTeachInfos, error) {
+	weekNum, weekday, lessonNum := CurCourseTime(ctx)
+	return GetInfos(ctx, weekNum, weekday, lessonNum)
 }
 
-func CurCourseTime() (weekNum int, weekday int, lessonNum int) {
-	now := time.Now()
-	// now := time.Date(2024, time.September, 10,
-	// 14, 00, 0, 0, time.Local)
-	// 计算第几周
-	// beginDate := time.Date(2024, time.September, 9,
-	// 	0, 0, 0, 0, time.Local)
-	beginDate := time.Date(2025, time.September, 8,
-		0, 0, 0, 0, time.Local)
+// CurCourseTime 根据 ctx 中的时钟计算当前周次、星期与节次
+func CurCourseTime(ctx context.Context) (weekNum int, weekday int, lessonNum int) {
+	now := clock.Now(ctx)
 	// 计算两个日期的差值
-
-	sub := now.Sub(beginDate)
+	sub := now.Sub(course.SemesterBeginDate)
 	durationDay := int(sub.Hours()) / 24
 	weekNum = durationDay/7 + 1
 
@@ -0,0 +1,121 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"cengkeHelperBackGo/pkg/timetable"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyScheduleSVGHandler godoc
+// @Summary 导出个人课表图片（SVG）
+// @Description 将收藏的课程渲染为带课程名、教室和颜色区分的课表网格，可直接分享；指定周次时同时显示该周的考试。需要用户认证。
+// @Tags Courses
+// @Produce image/svg+xml
+// @Param Authorization header string true "Bearer <token>"
+// @Param week query int false "只显示该周有课的时间段，不传则显示全部周次"
+// @Success 200 {file} binary "SVG 图片"
+// @Success 304 "内容未变化"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /users/me/schedule.svg [get]
+func (h *CourseHandler) GetMyScheduleSVGHandler(c *gin.Context) {
+	h.renderMySchedule(c, services.TimetableFormatSVG)
+}
+
+// GetRoomScheduleSVGHandler godoc
+// @Summary 导出教室周课表图片（SVG）
+// @Description 将某间教室一周内的课程渲染为课表网格。roomId 为结构化课程数据中的教室ID。
+// @Tags Courses
+// @Produce image/svg+xml
+// @Param roomId path string true "教室ID，如 division_1_教一楼_F3_3-101"
+// @Param week query int false "只显示该周有课的时间段，不传则显示全部周次"
+// @Success 200 {file} binary "SVG 图片"
+// @Success 304 "内容未变化"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "教室不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /rooms/{roomId}/schedule.svg [get]
+func (h *CourseHandler) GetRoomScheduleSVGHandler(c *gin.Context) {
+	h.renderRoomSchedule(c, services.TimetableFormatSVG)
+}
+
+// renderMySchedule 渲染当前用户的个人课表
+func (h *CourseHandler) renderMySchedule(c *gin.Context, format string) {
+	userID, ok := getCourseHandlerUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权或无法获取用户ID", nil)
+		return
+	}
+	weekNum, ok := parseTimetableWeek(c)
+	if !ok {
+		return
+	}
+
+	t, serviceErr := h.timetableService.UserSchedule(c.Request.Context(), *userID, weekNum)
+	if serviceErr != nil {
+		if serviceErr.Error() == config.MsgUserNotFound {
+			vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, serviceErr.Error(), nil)
+			return
+		}
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取个人课表失败", serviceErr)
+		return
+	}
+	h.writeTimetable(c, t, format)
+}
+
+// renderRoomSchedule 渲染教室周课表
+func (h *CourseHandler) renderRoomSchedule(c *gin.Context, format string) {
+	weekNum, ok := parseTimetableWeek(c)
+	if !ok {
+		return
+	}
+
+	t, serviceErr := h.timetableService.RoomSchedule(c.Request.Context(), c.Param("roomId"), weekNum)
+	if serviceErr != nil {
+		if serviceErr.Error() == config.MsgRoomNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+			return
+		}
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取教室课表失败", serviceErr)
+		return
+	}
+	h.writeTimetable(c, t, format)
+}
+
+// writeTimetable 输出课表图片，课表内容哈希作为 ETag，内容未变化时返回 304
+func (h *CourseHandler) writeTimetable(c *gin.Context, t *timetable.Timetable, format string) {
+	etag := `"` + format + "-" + t.Hash() + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, max-age=300")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	rendered, serviceErr := h.timetableService.Render(c.Request.Context(), t, format)
+	if serviceErr != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "渲染课表图片失败", serviceErr)
+		return
+	}
+	c.Data(http.StatusOK, rendered.ContentType, rendered.Data)
+}
+
+// parseTimetableWeek 解析可选的 week 参数，0 表示全部周次
+func parseTimetableWeek(c *gin.Context) (int, bool) {
+	weekStr := c.Query("week")
+	if weekStr == "" {
+		return 0, true
+	}
+	weekNum, err := strconv.Atoi(weekStr)
+	if err != nil || weekNum < 1 || weekNum > 19 {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的周次，应为 1-19", err)
+		return 0, false
+	}
+	return weekNum, true
+}
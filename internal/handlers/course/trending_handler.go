@@ -0,0 +1,62 @@
+package course
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTrendingCoursesHandler godoc
+// @Summary 获取热门课程
+// @Description 按浏览、收藏、打卡、评价的加权热度，返回统计窗口内相比上一个窗口热度上升最快的课程及其增量。可按学部过滤。
+// @Tags Courses
+// @Produce json
+// @Param window query string false "统计窗口 (24h/7d)" default(24h)
+// @Param divisionId query int false "学部ID（1-4，不传表示所有学部）"
+// @Param limit query int false "返回条数，默认20，最大50"
+// @Success 200 {object} vo.RespData{data=vo.TrendingCoursesVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 503 {object} vo.RespData "热门统计暂不可用"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/trending [get]
+func (h *CourseHandler) GetTrendingCoursesHandler(c *gin.Context) {
+	window := c.DefaultQuery("window", services.TrendingWindow24h)
+	if window != services.TrendingWindow24h && window != services.TrendingWindow7d {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的统计窗口，可选 24h 或 7d", nil)
+		return
+	}
+
+	var divisionID *int
+	if divisionIDStr := c.Query("divisionId"); divisionIDStr != "" {
+		id, err := strconv.Atoi(divisionIDStr)
+		if err != nil || id < 1 || id > 4 {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的学部ID", err)
+			return
+		}
+		divisionID = &id
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	trending, serviceErr := h.trendingService.Trending(c.Request.Context(), window, divisionID, limit)
+	if serviceErr != nil {
+		if serviceErr.Error() == config.MsgTrendingUnavailable {
+			vo.RespondError(c, http.StatusServiceUnavailable, config.CodeServiceUnavailable, serviceErr.Error(), nil)
+		} else {
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取热门课程失败", serviceErr)
+		}
+		return
+	}
+
+	vo.RespondSuccess(c, "获取热门课程成功", trending)
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler() *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: services.NewNotificationService(),
+	}
+}
+
+// GetNotificationsHandler godoc
+// @Summary 我的通知
+// @Description 分页获取当前用户的通知，最近有新动态的在前。同一帖子或评论的点赞、收藏、回复在未读期间聚合为一条，
+// @Description 如"张三等12人赞了你的帖子"；标记已读后的新动态另起一条
+// @Tags Notifications
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每页数量" default(20)
+// @Param unreadOnly query bool false "只看未读" default(false)
+// @Param type query string false "reply/like/collect/mention/moderation/course_update/post_publish"
+// @Success 200 {object} vo.RespData{data=vo.NotificationListVO} "获取成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /notifications [get]
+func (h *NotificationHandler) GetNotificationsHandler(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok || userID == nil {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	var params dto.GetNotificationsParamsDTO
+	if err := c.ShouldBindQuery(&params); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+	notifications, err := h.notificationService.List(c.Request.Context(), *userID, params)
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取通知失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取通知成功", notifications)
+}
+
+// GetUnreadNotificationCountHandler godoc
+// @Summary 未读通知数
+// @Description 当前用户的未读通知总数及各类型的未读数，聚合通知计为一条
+// @Tags Notifications
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} vo.RespData{data=vo.NotificationUnreadVO} "获取成功"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadNotificationCountHandler(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok || userID == nil {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	unread, err := h.notificationService.UnreadCount(c.Request.Context(), *userID)
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取未读通知数失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取未读通知数成功", unread)
+}
+
+// MarkNotificationReadHandler godoc
+// @Summary 标记通知已读
+// @Description 将一条通知标记为已读，已读的通知重复标记也返回成功
+// @Tags Notifications
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param notificationId path int true "通知ID"
+// @Success 200 {object} vo.RespData "操作成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 404 {object} vo.RespData "通知不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /notifications/{notificationId}/read [post]
+func (h *NotificationHandler) MarkNotificationReadHandler(c *gin.Context) {
+	notificationID, ok := parseUint32Param(c, "notificationId", "无效的通知ID")
+	if !ok {
+		return
+	}
+	userID, ok := getUserIDFromContext(c)
+	if !ok || userID == nil {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	if err := h.notificationService.MarkRead(c.Request.Context(), *userID, notificationID); err != nil {
+		if err.Error() == config.MsgNotificationNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+			return
+		}
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "标记已读失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "已标记为已读", nil)
+}
+
+// MarkAllNotificationsReadHandler godoc
+// @Summary 全部标记已读
+// @Description 将当前用户的未读通知全部标记为已读，指定 type 时只处理该类型
+// @Tags Notifications
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param type query string false "reply/like/collect/mention/moderation/course_update/post_publish"
+// @Success 200 {object} vo.RespData{data=vo.NotificationReadAllVO} "操作成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllNotificationsReadHandler(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok || userID == nil {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	var params dto.MarkAllNotificationsReadDTO
+	if err := c.ShouldBindQuery(&params); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	result, err := h.notificationService.MarkAllRead(c.Request.Context(), *userID, params.Type)
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "标记已读失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "已全部标记为已读", result)
+}
+
+// GetNotificationPreferencesHandler godoc
+// @Summary 通知设置
+// @Description 各类通知的开关，未设置过的类型默认开启。举报和审核的处理结果总是通知，不能关闭
+// @Tags Notifications
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Success 200 {object} vo.RespData{data=[]vo.NotificationPreferenceVO} "获取成功"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) GetNotificationPreferencesHandler(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok || userID == nil {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	prefs, err := h.notificationService.GetPreferences(c.Request.Context(), *userID)
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取通知设置失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取通知设置成功", prefs)
+}
+
+// UpdateNotificationPreferencesHandler godoc
+// @Summary 修改通知设置
+// @Description 按类型开关通知，如 {"preferences": {"like": false}}；可设置 reply/like/collect/mention/course_update，只影响之后产生的通知
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param payload body dto.UpdateNotificationPreferencesDTO true "通知类型与开关"
+// @Success 200 {object} vo.RespData{data=[]vo.NotificationPreferenceVO} "修改成功"
+// @Failure 400 {object} vo.RespData "请求参数错误或不支持设置的通知类型"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) UpdateNotificationPreferencesHandler(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok || userID == nil {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	var payload dto.UpdateNotificationPreferencesDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	prefs, err := h.notificationService.UpdatePreferences(c.Request.Context(), *userID, payload)
+	if err != nil {
+		if err.Error() == config.MsgNotificationTypeInvalid {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, err.Error(), nil)
+			return
+		}
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "修改通知设置失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "通知设置已修改", prefs)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDraftsHandler godoc
+// @Summary 获取我的草稿
+// @Description 获取当前用户的草稿与定时发布的帖子，按最近修改排序。草稿详情可通过 GET /posts/{id} 预览
+// @Tags Posts
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每页数量" default(10)
+// @Success 200 {object} vo.RespData{data=vo.GetPostsResponseDataVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /posts/drafts [get]
+func (h *PostHandler) GetDraftsHandler(c *gin.Context) {
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	var params dto.GetDraftsParamsDTO
+	if err := c.ShouldBindQuery(&params); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 10
+	}
+
+	drafts, err := h.postService.ListDrafts(c.Request.Context(), *userID, params)
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取草稿失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "草稿获取成功", drafts)
+}
+
+// PublishPostHandler godoc
+// @Summary 发布草稿
+// @Description 立即发布自己的草稿；publishAt 为将来的时间时改为定时发布，到时由后台任务发布并通知作者
+// @Tags Posts
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path int true "帖子ID"
+// @Param payload body dto.PublishPostDTO false "定时发布时间，不传则立即发布"
+// @Success 200 {object} vo.RespData{data=vo.PostVO} "发布成功或已设置定时发布"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 403 {object} vo.RespData "无权操作"
+// @Failure 404 {object} vo.RespData "帖子未找到"
+// @Failure 409 {object} vo.RespData "帖子已发布"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /posts/{id}/publish [post]
+func (h *PostHandler) PublishPostHandler(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "无效的帖子ID格式", err)
+		return
+	}
+	userID, ok := getUserIDFromContext(c)
+	if !ok {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	var payload dto.PublishPostDTO
+	if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效: "+err.Error(), nil)
+		return
+	}
+
+	post, err := h.postService.PublishPost(c.Request.Context(), uint32(postID), *userID, payload)
+	if err != nil {
+		switch err.Error() {
+		case config.MsgPostNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+		case config.MsgPostNotAuthor:
+			vo.RespondError(c, http.StatusForbidden, config.CodeForbidden, err.Error(), nil)
+		case config.MsgPostAlreadyPublished:
+			vo.RespondError(c, http.StatusConflict, config.CodeConflict, err.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "发布帖子失败", err)
+		}
+		return
+	}
+	vo.RespondSuccess(c, "帖子发布成功", post)
+}
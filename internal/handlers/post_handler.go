@@ -29,17 +29,23 @@ func NewPostHandler() *PostHandler {
 
 // GetPosts godoc
 // @Summary 获取帖子列表
-// @Description 根据查询参数获取帖子列表，支持分页、排序和过滤
+// @Description 根据查询参数获取帖子列表，支持分页、排序和过滤。filterText 对标题、内容和标签做全文检索（支持 "短语" 和 -排除词），默认按相关度排序，并在 highlight 中返回高亮的标题与摘要
 // @Tags Posts
 // @Accept  json
 // @Produce  json
+// @Param Authorization header string false "Bearer <token>，可选，携带时返回当前用户的点赞、收藏状态"
 // @Param page query int false "页码" default(1)
 // @Param limit query int false "每页数量" default(10)
-// @Param sortBy query string false "排序字段和顺序 (例如: createdAt_desc, likesCount_asc)"
-// @Param filterText query string false "标题或内容模糊搜索"
+// @Param sortBy query string false "排序字段和顺序 (例如: createdAt_desc, likesCount_asc；搜索时可用 relevance)"
+// @Param cursor query string false "上一页返回的 nextCursor，传入时忽略 page"
+// @Param skipTotal query bool false "为 true 时不统计总数"
+// @Param filterText query string false "全文搜索关键词，例如: 数据结构 -作业（英文双引号内作为短语匹配）"
 // @Param category query string false "分类过滤"
-// @Param tag query string false "标签过滤 (单个标签)"
+// @Param tag query string false "标签过滤 (单个标签，精确匹配)"
+// @Param tags query string false "多标签过滤，逗号分隔"
+// @Param tagMatch query string false "多标签匹配方式：all（默认，同时包含）/ any（包含任一）"
 // @Param authorId query int false "作者ID过滤"
+// @Param featured query bool false "为 true 时只返回精华帖"
 // @Success 200 {object} vo.RespData{data=vo.GetPostsResponseDataVO} "成功"
 // @Failure 400 {object} vo.RespData "请求参数错误"
 // @Failure 500 {object} vo.RespData "服务器内部错误"
@@ -60,11 +66,15 @@ func (h *PostHandler) GetPosts(c *gin.Context) {
 		params.Limit = 10
 	}
 
-	responseVO, err := h.postService.GetPosts(&params)
+	currentUserID, _ := getUserIDFromContext(c) // 未登录时为 nil，点赞、收藏状态均为 false
+	responseVO, err := h.postService.GetPosts(c.Request.Context(), &params, currentUserID)
 	if err != nil {
 		// log.Printf("GetPosts: Failed to get posts from service: %v\n", err) // 记录具体错误
 		// 根据 service 层返回的错误类型判断是客户端错误还是服务端错误
-		// 此处简化为服务器内部错误
+		if err.Error() == config.MsgCursorInvalid {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, err.Error(), nil)
+			return
+		}
 		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取帖子列表失败", err)
 		return
 	}
@@ -72,12 +82,67 @@ func (h *PostHandler) GetPosts(c *gin.Context) {
 	vo.RespondSuccess(c, "帖子列表获取成功", responseVO)
 }
 
+// GetPostsByCourse godoc
+// @Summary 获取课程讨论区帖子
+// @Description 获取关联了指定课程的帖子列表，支持分页与排序
+// @Tags Posts
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string false "Bearer <token>，可选，携带时返回当前用户的点赞、收藏状态"
+// @Param courseId path int true "课程ID"
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每页数量" default(10)
+// @Param sortBy query string false "排序字段和顺序 (例如: createdAt_desc, likesCount_asc)"
+// @Param cursor query string false "上一页返回的 nextCursor，传入时忽略 page"
+// @Param skipTotal query bool false "为 true 时不统计总数"
+// @Success 200 {object} vo.RespData{data=vo.GetPostsResponseDataVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "课程未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /courses/{courseId}/posts [get]
+func (h *PostHandler) GetPostsByCourse(c *gin.Context) {
+	courseIDUint64, err := strconv.ParseUint(c.Param("courseId"), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的课程ID格式", err)
+		return
+	}
+
+	var params dto.GetPostsParamsDTO
+	if err := c.ShouldBindQuery(&params); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 10
+	}
+
+	currentUserID, _ := getUserIDFromContext(c)
+	responseVO, serviceErr := h.postService.GetPostsByCourse(c.Request.Context(), uint32(courseIDUint64), &params, currentUserID)
+	if serviceErr != nil {
+		switch serviceErr.Error() {
+		case config.MsgCourseNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		case config.MsgCursorInvalid:
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, serviceErr.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取课程讨论失败", serviceErr)
+		}
+		return
+	}
+
+	vo.RespondSuccess(c, "课程讨论获取成功", responseVO)
+}
+
 // GetPostByID godoc
 // @Summary 获取单个帖子详情
 // @Description 根据帖子ID获取帖子的详细信息
 // @Tags Posts
 // @Accept  json
 // @Produce  json
+// @Param Authorization header string false "Bearer <token>，可选，携带时返回当前用户的点赞、收藏状态"
 // @Param   id   path      int  true  "帖子ID"
 // @Success 200 {object} vo.RespData{data=vo.PostVO} "成功，返回帖子详情"
 // @Failure 400 {object} vo.RespData "请求参数错误，例如ID格式无效"
@@ -94,20 +159,10 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 	postID := uint32(postIDUint64)
 
 	// --- 获取当前登录用户的ID (如果存在) ---
-	var currentUserID *uint32            // 使用指针，因为用户可能未登录
-	userIDVal, exists := c.Get("userID") // 假设您的 JWT 中间件将 userID 存放在 context 中
-	if exists {
-		if id, ok := userIDVal.(uint32); ok { // 确保类型与 User 模型的 ID 类型一致
-			currentUserID = &id
-		} else if idFloat, ok := userIDVal.(float64); ok { // 有时 JWT 解析出来可能是 float64
-			idUint32 := uint32(idFloat)
-			currentUserID = &idUint32
-		}
-		// 可以添加更多类型断言或转换逻辑
-	}
-	// 如果取不到 userID，currentUserID 将保持为 nil，Service 层会据此判断用户未登录
+	// 公开接口经过 OptionalUserAuth，未登录时 currentUserID 为 nil，Service 层会据此判断用户未登录
+	currentUserID, _ := getUserIDFromContext(c)
 
-	postVO, serviceErr := h.postService.GetPostByID(postID, currentUserID) // ★ 传递 currentUserID
+	postVO, serviceErr := h.postService.GetPostByID(c.Request.Context(), postID, currentUserID) // ★ 传递 currentUserID
 	if serviceErr != nil {
 		if errors.Is(serviceErr, gorm.ErrRecordNotFound) || serviceErr.Error() == "帖子未找到" {
 			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, "帖子未找到", nil)
@@ -122,7 +177,8 @@ func (h *PostHandler) GetPostByID(c *gin.Context) {
 
 // CreatePost godoc
 // @Summary 创建新帖子
-// @Description 用户创建一篇新的帖子
+// @Description 用户创建一篇新的帖子；isDraft 为 true 时保存为草稿，publishAt 为将来的时间时定时发布。
+// @Description 标题和内容命中拦截词时返回 400，命中打码词时打码保存，命中审核词时保存但审核通过前只有作者可见（moderationStatus=1）
 // @Tags Posts
 // @Accept  json
 // @Produce  json
@@ -152,8 +208,16 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 		return
 	}
 
-	createdPostVO, err := h.postService.CreatePost(&postData, authorID)
+	createdPostVO, err := h.postService.CreatePost(c.Request.Context(), &postData, authorID)
 	if err != nil {
+		if err.Error() == config.MsgCourseNotFound {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "关联的课程不存在", nil)
+			return
+		}
+		if err.Error() == config.MsgContentBlocked {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, err.Error(), nil)
+			return
+		}
 		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "创建帖子失败: "+err.Error(), nil)
 		return
 	}
@@ -165,7 +229,7 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 // --- 新增 UpdatePost Handler ---
 // UpdatePost godoc
 // @Summary 更新帖子
-// @Description 更新指定ID的帖子内容
+// @Description 更新指定ID的帖子内容，修改后的标题和内容同样经过敏感词检查
 // @Tags Posts
 // @Accept  json
 // @Produce  json
@@ -207,10 +271,14 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 		return
 	}
 
-	updatedPostVO, serviceErr := h.postService.UpdatePost(postID, authorID, &postData)
+	updatedPostVO, serviceErr := h.postService.UpdatePost(c.Request.Context(), postID, authorID, &postData)
 	if serviceErr != nil {
 		if serviceErr.Error() == "帖子未找到" {
 			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, serviceErr.Error(), nil)
+		} else if serviceErr.Error() == config.MsgCourseNotFound {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "关联的课程不存在", nil)
+		} else if serviceErr.Error() == config.MsgContentBlocked {
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, serviceErr.Error(), nil)
 		} else if serviceErr.Error() == "无权修改此帖子" {
 			vo.RespondError(c, http.StatusForbidden, config.CodeForbidden, serviceErr.Error(), nil)
 		} else {
@@ -393,7 +461,7 @@ func (h *PostHandler) GetActiveUsersHandler(c *gin.Context) {
 		}
 	}
 
-	users, err := h.postService.GetActiveUsers(days, limit)
+	users, err := h.postService.GetActiveUsers(c.Request.Context(), days, limit)
 	if err != nil {
 		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取活跃用户失败", err)
 		return
@@ -411,7 +479,7 @@ func (h *PostHandler) GetActiveUsersHandler(c *gin.Context) {
 // @Failure 500 {object} vo.RespData "服务器内部错误"
 // @Router /community/stats [get]
 func (h *PostHandler) GetCommunityStatsHandler(c *gin.Context) {
-	stats, err := h.postService.GetCommunityStats()
+	stats, err := h.postService.GetCommunityStats(c.Request.Context())
 	if err != nil {
 		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "��取社区统计失败", err)
 		return
@@ -455,7 +523,7 @@ func (h *PostHandler) GetCommunityOverviewHandler(c *gin.Context) {
 	// if params are 0, the course package helpers expect real values; use course package to compute current time
 	if weekNum == 0 || weekday == 0 || lessonNum == 0 {
 		// Get current time info from services package's CourseStructureService
-		w, wd, ln := services.NewCourseStructureService().GetCurrentCourseTime()
+		w, wd, ln := services.NewCourseStructureService().GetCurrentCourseTime(c.Request.Context())
 		if weekNum == 0 {
 			weekNum = w
 		}
@@ -474,7 +542,7 @@ func (h *PostHandler) GetCommunityOverviewHandler(c *gin.Context) {
 	todayCount := courseSvc.GetOneDayNumOfCourses(weekday, weekNum)
 
 	// get today's posts from post service
-	commStats, err := h.postService.GetCommunityStats()
+	commStats, err := h.postService.GetCommunityStats(c.Request.Context())
 	if err != nil {
 		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取社区统计失败", err)
 		return
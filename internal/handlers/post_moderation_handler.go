@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePostIDParam 解析路径中的帖子ID，失败时已写入错误响应
+func parsePostIDParam(c *gin.Context) (uint32, bool) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "无效的帖子ID格式", err)
+		return 0, false
+	}
+	return uint32(postID), true
+}
+
+// respondModeration 输出帖子管理操作的结果
+func respondModeration(c *gin.Context, post *vo.PostVO, err error, msg string) {
+	if err != nil {
+		switch err.Error() {
+		case config.MsgPostNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+		case config.MsgPinCategoryMissing, config.MsgPinExpiryInvalid:
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, err.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "操作失败", err)
+		}
+		return
+	}
+	vo.RespondSuccess(c, msg, post)
+}
+
+// PinPostHandler godoc
+// @Summary 置顶帖子
+// @Description 全站置顶或分类置顶，可设置到期时间；置顶帖在列表第一页排在最前。需要版主或管理员权限
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path int true "帖子ID"
+// @Param payload body dto.PinPostDTO true "置顶范围与到期时间"
+// @Success 200 {object} vo.RespData{data=vo.PostVO} "置顶成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 403 {object} vo.RespData "没有权限"
+// @Failure 404 {object} vo.RespData "帖子未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/posts/{id}/pin [post]
+func (h *PostHandler) PinPostHandler(c *gin.Context) {
+	postID, ok := parsePostIDParam(c)
+	if !ok {
+		return
+	}
+	var payload dto.PinPostDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请求参数错误: "+err.Error(), err)
+		return
+	}
+	post, err := h.postService.PinPost(c.Request.Context(), postID, payload)
+	respondModeration(c, post, err, "置顶成功")
+}
+
+// UnpinPostHandler godoc
+// @Summary 取消置顶
+// @Description 取消帖子置顶。需要版主或管理员权限
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path int true "帖子ID"
+// @Success 200 {object} vo.RespData{data=vo.PostVO} "已取消置顶"
+// @Failure 403 {object} vo.RespData "没有权限"
+// @Failure 404 {object} vo.RespData "帖子未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/posts/{id}/pin [delete]
+func (h *PostHandler) UnpinPostHandler(c *gin.Context) {
+	postID, ok := parsePostIDParam(c)
+	if !ok {
+		return
+	}
+	post, err := h.postService.UnpinPost(c.Request.Context(), postID)
+	respondModeration(c, post, err, "已取消置顶")
+}
+
+// LockPostHandler godoc
+// @Summary 锁定帖子评论
+// @Description 锁定后不能再发表评论。需要版主或管理员权限
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path int true "帖子ID"
+// @Success 200 {object} vo.RespData{data=vo.PostVO} "已锁定"
+// @Failure 403 {object} vo.RespData "没有权限"
+// @Failure 404 {object} vo.RespData "帖子未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/posts/{id}/lock [post]
+func (h *PostHandler) LockPostHandler(c *gin.Context) {
+	postID, ok := parsePostIDParam(c)
+	if !ok {
+		return
+	}
+	post, err := h.postService.SetPostLocked(c.Request.Context(), postID, true)
+	respondModeration(c, post, err, "已锁定评论")
+}
+
+// UnlockPostHandler godoc
+// @Summary 解锁帖子评论
+// @Description 需要版主或管理员权限
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path int true "帖子ID"
+// @Success 200 {object} vo.RespData{data=vo.PostVO} "已解锁"
+// @Failure 403 {object} vo.RespData "没有权限"
+// @Failure 404 {object} vo.RespData "帖子未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/posts/{id}/lock [delete]
+func (h *PostHandler) UnlockPostHandler(c *gin.Context) {
+	postID, ok := parsePostIDParam(c)
+	if !ok {
+		return
+	}
+	post, err := h.postService.SetPostLocked(c.Request.Context(), postID, false)
+	respondModeration(c, post, err, "已解锁评论")
+}
+
+// FeaturePostHandler godoc
+// @Summary 设为精华帖
+// @Description 精华帖可通过 GET /posts?featured=true 筛选。需要版主或管理员权限
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path int true "帖子ID"
+// @Success 200 {object} vo.RespData{data=vo.PostVO} "已加精"
+// @Failure 403 {object} vo.RespData "没有权限"
+// @Failure 404 {object} vo.RespData "帖子未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/posts/{id}/feature [post]
+func (h *PostHandler) FeaturePostHandler(c *gin.Context) {
+	postID, ok := parsePostIDParam(c)
+	if !ok {
+		return
+	}
+	post, err := h.postService.SetPostFeatured(c.Request.Context(), postID, true)
+	respondModeration(c, post, err, "已设为精华")
+}
+
+// UnfeaturePostHandler godoc
+// @Summary 取消精华
+// @Description 需要版主或管理员权限
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path int true "帖子ID"
+// @Success 200 {object} vo.RespData{data=vo.PostVO} "已取消精华"
+// @Failure 403 {object} vo.RespData "没有权限"
+// @Failure 404 {object} vo.RespData "帖子未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/posts/{id}/feature [delete]
+func (h *PostHandler) UnfeaturePostHandler(c *gin.Context) {
+	postID, ok := parsePostIDParam(c)
+	if !ok {
+		return
+	}
+	post, err := h.postService.SetPostFeatured(c.Request.Context(), postID, false)
+	respondModeration(c, post, err, "已取消精华")
+}
+
+// MovePostHandler godoc
+// @Summary 移动帖子分类
+// @Description 将帖子移动到另一个分类，分类置顶随帖子一起移动。需要版主或管理员权限
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param id path int true "帖子ID"
+// @Param payload body dto.MovePostDTO true "目标分类"
+// @Success 200 {object} vo.RespData{data=vo.PostVO} "移动成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 403 {object} vo.RespData "没有权限"
+// @Failure 404 {object} vo.RespData "帖子未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/posts/{id}/move [post]
+func (h *PostHandler) MovePostHandler(c *gin.Context) {
+	postID, ok := parsePostIDParam(c)
+	if !ok {
+		return
+	}
+	var payload dto.MovePostDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请求参数错误: "+err.Error(), err)
+		return
+	}
+	post, err := h.postService.MovePost(c.Request.Context(), postID, payload.Category)
+	respondModeration(c, post, err, "移动成功")
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+func NewReportHandler() *ReportHandler {
+	return &ReportHandler{
+		reportService: services.NewReportService(),
+	}
+}
+
+// CreateReportHandler godoc
+// @Summary 举报
+// @Description 举报帖子、评论、课程评价或用户。同一用户对同一对象重复举报只更新举报理由；
+// @Description 同一内容的举报人数达到阈值时自动隐藏，等待版主处理，处理结果会通知举报人
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param payload body dto.CreateReportDTO true "举报对象与理由：spam/off_topic/misinformation/harassment/sexual/illegal/other"
+// @Success 201 {object} vo.RespData{data=vo.ReportVO} "举报成功"
+// @Failure 400 {object} vo.RespData "请求参数错误或举报自己"
+// @Failure 401 {object} vo.RespData "用户未授权"
+// @Failure 404 {object} vo.RespData "举报的内容不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /reports [post]
+func (h *ReportHandler) CreateReportHandler(c *gin.Context) {
+	reporterID, ok := getUserIDFromContext(c)
+	if !ok || reporterID == nil {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	var payload dto.CreateReportDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	report, err := h.reportService.Report(c.Request.Context(), *reporterID, payload)
+	if err != nil {
+		switch err.Error() {
+		case config.MsgReportTargetNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+		case config.MsgReportSelf:
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, err.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "举报失败", err)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, vo.NewSuccessResp("举报已提交，感谢你的反馈", report))
+}
+
+// GetReportCasesHandler godoc
+// @Summary 举报处理队列
+// @Description 同一对象的举报汇总为一张举报单。未处理的按严重程度、举报人数从高到低排列，已处理的按处理时间倒序。需要版主或管理员权限
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每页数量" default(20)
+// @Param status query string false "open/resolved" default(open)
+// @Param targetType query string false "post/comment/review/user"
+// @Success 200 {object} vo.RespData{data=vo.ReportCaseListVO} "获取成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/reports [get]
+func (h *ReportHandler) GetReportCasesHandler(c *gin.Context) {
+	var params dto.GetReportCasesParamsDTO
+	if err := c.ShouldBindQuery(&params); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+	cases, err := h.reportService.List(c.Request.Context(), params)
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取举报队列失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取举报队列成功", cases)
+}
+
+// ResolveReportCaseHandler godoc
+// @Summary 处理举报单
+// @Description 处理方式：dismiss 驳回举报（自动隐藏的内容恢复展示）、hide 隐藏内容、delete 删除内容、warn 警告作者、ban 封禁作者（banDays 为 0 表示永久）。
+// @Description 用户举报只能驳回、警告或封禁。处理结果会记录并通知所有举报人。需要版主或管理员权限
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param caseId path int true "举报单ID"
+// @Param payload body dto.ResolveReportDTO true "处理方式与说明"
+// @Success 200 {object} vo.RespData "处理成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "举报单不存在"
+// @Failure 409 {object} vo.RespData "该举报已处理"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /moderation/reports/{caseId}/resolve [post]
+func (h *ReportHandler) ResolveReportCaseHandler(c *gin.Context) {
+	caseID, ok := parseUint32Param(c, "caseId", "无效的举报单ID")
+	if !ok {
+		return
+	}
+	moderatorID, ok := getUserIDFromContext(c)
+	if !ok || moderatorID == nil {
+		vo.RespondError(c, http.StatusUnauthorized, config.CodeUnauthorized, "用户未授权", nil)
+		return
+	}
+	var payload dto.ResolveReportDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeBadRequest, "请求参数无效", err)
+		return
+	}
+	if err := h.reportService.Resolve(c.Request.Context(), caseID, *moderatorID, payload); err != nil {
+		switch err.Error() {
+		case config.MsgReportCaseNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+		case config.MsgReportCaseResolved:
+			vo.RespondError(c, http.StatusConflict, config.CodeConflict, err.Error(), nil)
+		case config.MsgReportActionInvalid:
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, err.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "处理举报失败", err)
+		}
+		return
+	}
+	vo.RespondSuccess(c, "举报已处理", nil)
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tagTrendingWindows 热门标签支持的统计窗口
+var tagTrendingWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+type TagHandler struct {
+	tagService *services.TagService
+}
+
+func NewTagHandler() *TagHandler {
+	return &TagHandler{
+		tagService: services.NewTagService(),
+	}
+}
+
+// parseTagLimit 解析 limit 参数，超出范围时使用默认值或上限
+func parseTagLimit(c *gin.Context, def, maxLimit int) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(def)))
+	if err != nil || limit < 1 {
+		return def
+	}
+	return min(limit, maxLimit)
+}
+
+// GetTrendingTagsHandler godoc
+// @Summary 获取热门标签
+// @Description 返回统计窗口内新增使用次数最多的标签
+// @Tags Tags
+// @Produce json
+// @Param window query string false "统计窗口 (24h/7d/30d)" default(7d)
+// @Param limit query int false "返回数量，默认20，最大50"
+// @Success 200 {object} vo.RespData{data=[]vo.TrendingTagVO} "成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /tags/trending [get]
+func (h *TagHandler) GetTrendingTagsHandler(c *gin.Context) {
+	window, ok := tagTrendingWindows[c.DefaultQuery("window", "7d")]
+	if !ok {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的统计窗口，可选 24h、7d 或 30d", nil)
+		return
+	}
+
+	tags, err := h.tagService.Trending(c.Request.Context(), window, parseTagLimit(c, 20, 50))
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取热门标签失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取热门标签成功", tags)
+}
+
+// SuggestTagsHandler godoc
+// @Summary 标签自动补全
+// @Description 按输入返回候选标签，前缀匹配的排在前面，同组内按使用次数排序
+// @Tags Tags
+// @Produce json
+// @Param q query string true "输入的标签片段"
+// @Param limit query int false "返回数量，默认10，最大20"
+// @Success 200 {object} vo.RespData{data=[]vo.TagVO} "成功"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /tags/suggest [get]
+func (h *TagHandler) SuggestTagsHandler(c *gin.Context) {
+	tags, err := h.tagService.Suggest(c.Request.Context(), c.Query("q"), parseTagLimit(c, 10, 20))
+	if err != nil {
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取标签建议失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "获取标签建议成功", tags)
+}
+
+// GetTagDetailHandler godoc
+// @Summary 获取标签页信息
+// @Description 返回标签的使用次数及经常一起出现的标签；标签下的帖子通过 GET /posts?tag= 获取。使用已被合并的旧写法访问时返回合并后的标签
+// @Tags Tags
+// @Produce json
+// @Param tagName path string true "标签名"
+// @Success 200 {object} vo.RespData{data=vo.TagDetailVO} "成功"
+// @Failure 404 {object} vo.RespData "标签未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /tags/{tagName} [get]
+func (h *TagHandler) GetTagDetailHandler(c *gin.Context) {
+	detail, err := h.tagService.GetTagDetail(c.Request.Context(), c.Param("tagName"), 10)
+	if err != nil {
+		if err.Error() == config.MsgTagNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+		} else {
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "获取标签信息失败", err)
+		}
+		return
+	}
+	vo.RespondSuccess(c, "获取标签信息成功", detail)
+}
+
+// MergeTagsHandler godoc
+// @Summary 合并同义标签
+// @Description 将若干标签合并到目标标签：帖子上的旧标签替换为目标标签，之后使用旧写法也会归入目标标签。需要管理员权限
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param merge body dto.TagMergeDTO true "被合并的标签与目标标签"
+// @Success 200 {object} vo.RespData{data=vo.TagMergeResultVO} "合并成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "标签未找到"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/tags/merge [post]
+func (h *TagHandler) MergeTagsHandler(c *gin.Context) {
+	var payload dto.TagMergeDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请求参数错误: "+err.Error(), err)
+		return
+	}
+
+	result, err := h.tagService.Merge(c.Request.Context(), payload)
+	if err != nil {
+		switch err.Error() {
+		case config.MsgTagNotFound:
+			vo.RespondError(c, http.StatusNotFound, config.CodeNotFound, err.Error(), nil)
+		case config.MsgTagMergeInvalid:
+			vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, err.Error(), nil)
+		default:
+			vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "合并标签失败", err)
+		}
+		return
+	}
+	vo.RespondSuccess(c, "合并标签成功", result)
+}
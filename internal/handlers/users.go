@@ -5,7 +5,10 @@ import (
 	database "cengkeHelperBackGo/internal/db"
 	"cengkeHelperBackGo/internal/models/dto"
 	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,28 +37,6 @@ func UserProfileHandler(c *gin.Context) {
 		return
 	}
 
-	// compute counts
-	var postsCount int64
-	_ = database.Client.Model(&dto.Post{}).Where("author_id = ?", uid).Count(&postsCount).Error
-
-	var commentsCount int64
-	_ = database.Client.Model(&dto.Comment{}).Where("author_id = ?", uid).Count(&commentsCount).Error
-
-	// likes count: count of post likes given + comment likes given
-	var postLikesGiven int64
-	_ = database.Client.Model(&dto.UserPostLike{}).Where("user_id = ?", uid).Count(&postLikesGiven).Error
-	var commentLikesGiven int64
-	_ = database.Client.Model(&dto.UserCommentLike{}).Where("user_id = ?", uid).Count(&commentLikesGiven).Error
-
-	likesCount := postLikesGiven + commentLikesGiven
-
-	// likes received: sum of likes_count on user's posts + likes_count on user's comments
-	var postLikesReceived int64
-	_ = database.Client.Model(&dto.Post{}).Select("COALESCE(SUM(likes_count),0)").Where("author_id = ?", uid).Scan(&postLikesReceived).Error
-	var commentLikesReceived int64
-	_ = database.Client.Model(&dto.Comment{}).Select("COALESCE(SUM(likes_count),0)").Where("author_id = ?", uid).Scan(&commentLikesReceived).Error
-	likesReceived := postLikesReceived + commentLikesReceived
-
 	profile := vo.ExtendedUserProfileVO{
 		UserProfileVO: vo.UserProfileVO{
 			ID:        user.Id,
@@ -65,11 +46,29 @@ func UserProfileHandler(c *gin.Context) {
 			CreatedAt: user.CreatedAt,
 			Avatar:    user.Avatar,
 			Bio:       user.Bio,
+			FacultyID: user.FacultyID,
+			MajorID:   user.MajorID,
+			Grade:     user.Grade,
 		},
-		PostsCount:    postsCount,
-		CommentsCount: commentsCount,
-		LikesCount:    likesCount,
-		LikesReceived: likesReceived,
+		// 计数由发帖、评论、点赞时维护，见 services.applyCounterEvent
+		PostsCount:    int64(user.PostsCount),
+		CommentsCount: int64(user.CommentsCount),
+		LikesCount:    int64(user.LikesGiven),
+		LikesReceived: int64(user.LikesReceived),
+	}
+
+	// 学院、专业名称
+	if user.FacultyID != nil {
+		var faculty dto.Faculty
+		if err := database.Client.Select("name").First(&faculty, *user.FacultyID).Error; err == nil {
+			profile.FacultyName = faculty.Name
+		}
+	}
+	if user.MajorID != nil {
+		var major dto.Major
+		if err := database.Client.Select("name").First(&major, *user.MajorID).Error; err == nil {
+			profile.MajorName = major.Name
+		}
 	}
 
 	c.JSON(http.StatusOK, vo.NewSuccessResp("用户信息查询成功", profile))
@@ -97,6 +96,11 @@ func UpdateUserProfileHandler(c *gin.Context) {
 		Avatar   string `json:"avatar"`
 		Bio      string `json:"bio"`
 		Email    string `json:"email"`
+
+		// 学院/专业传 0 表示清空；Grade 传空字符串表示清空
+		FacultyID *uint32 `json:"facultyId"`
+		MajorID   *uint32 `json:"majorId"`
+		Grade     *string `json:"grade" binding:"omitempty,max=20"`
 	}
 
 	if err := c.ShouldBindJSON(&updateData); err != nil {
@@ -104,6 +108,24 @@ func UpdateUserProfileHandler(c *gin.Context) {
 		return
 	}
 
+	// 用户名与简介的敏感词检查：用户名命中即拒绝；简介打码后保存，命中审核词时审核通过后才生效
+	contentReviewService := services.NewContentReviewService()
+	if updateData.Username != "" {
+		if err := contentReviewService.CheckUsername(updateData.Username); err != nil {
+			c.JSON(http.StatusBadRequest, vo.NewBadResp(err.Error()))
+			return
+		}
+	}
+	bioHeld := false
+	if updateData.Bio != "" {
+		bio, held, err := contentReviewService.FilterBio(updateData.Bio)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, vo.NewBadResp(err.Error()))
+			return
+		}
+		updateData.Bio, bioHeld = bio, held
+	}
+
 	// 检查用户名是否已存在（如果请求中提供了用户名）
 	if updateData.Username != "" {
 		var count int64
@@ -131,35 +153,72 @@ func UpdateUserProfileHandler(c *gin.Context) {
 	if updateData.Avatar != "" {
 		updates["avatar"] = updateData.Avatar
 	}
-	if updateData.Bio != "" {
+	if updateData.Bio != "" && !bioHeld {
 		updates["bio"] = updateData.Bio
 	}
 	if updateData.Email != "" {
 		updates["email"] = updateData.Email
 	}
 
-	if len(updates) == 0 {
+	if updateData.FacultyID != nil || updateData.MajorID != nil {
+		if err := services.NewCatalogService().ValidateProfileCatalog(c.Request.Context(), updateData.FacultyID, updateData.MajorID); err != nil {
+			switch err.Error() {
+			case config.MsgFacultyNotFound, config.MsgMajorNotFound, config.MsgMajorNotInFaculty:
+				c.JSON(http.StatusBadRequest, vo.NewBadResp(err.Error()))
+			default:
+				c.JSON(http.StatusInternalServerError, vo.NewBadResp("failed to validate faculty or major"))
+			}
+			return
+		}
+	}
+	if updateData.FacultyID != nil {
+		updates["faculty_id"] = nullableID(*updateData.FacultyID)
+	}
+	if updateData.MajorID != nil {
+		updates["major_id"] = nullableID(*updateData.MajorID)
+	}
+	if updateData.Grade != nil {
+		updates["grade"] = strings.TrimSpace(*updateData.Grade)
+	}
+
+	if len(updates) == 0 && !bioHeld {
 		c.JSON(http.StatusBadRequest, vo.NewBadResp("no fields to update"))
 		return
 	}
 
 	// 执行更新
-	result := database.Client.
-		Model(&dto.User{}).
-		Where("id = ?", uid).
-		Updates(updates)
+	if len(updates) > 0 {
+		result := database.Client.
+			Model(&dto.User{}).
+			Where("id = ?", uid).
+			Updates(updates)
 
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, vo.NewBadResp("failed to update user profile"))
-		return
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, vo.NewBadResp("failed to update user profile"))
+			return
+		}
+
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusNotFound, vo.RespData{
+				Code: config.CodeUserNotFound,
+				Msg:  "user not found",
+			})
+			return
+		}
 	}
 
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, vo.RespData{
-			Code: config.CodeUserNotFound,
-			Msg:  "user not found",
-		})
-		return
+	msg := "用户信息修改成功"
+	if bioHeld {
+		userID, err := strconv.ParseUint(uid, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, vo.NewBadResp("invalid userId"))
+			return
+		}
+		if err := contentReviewService.HoldBio(c.Request.Context(), uint32(userID), updateData.Bio); err != nil {
+			c.JSON(http.StatusInternalServerError, vo.NewBadResp("failed to submit bio for review"))
+			return
+		}
+		msg = "用户信息修改成功，个人简介将在审核通过后生效"
 	}
 
 	// 返回更新后的用户信息
@@ -172,6 +231,51 @@ func UpdateUserProfileHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, vo.NewSuccessResp("用户信息修改成功", updatedUser))
+	c.JSON(http.StatusOK, vo.NewSuccessResp(msg, updatedUser))
 	return
 }
+
+// nullableID 将 0 转换为 NULL，用于清空可选的外键
+func nullableID(id uint32) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// SetUserRoleHandler godoc
+// @Summary 设置用户角色
+// @Description 设置为普通用户(0)、管理员(1)或版主(2)，用户重新登录后生效。需要管理员权限
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <token>"
+// @Param userId path int true "用户ID"
+// @Param payload body dto.SetUserRoleDTO true "角色"
+// @Success 200 {object} vo.RespData "设置成功"
+// @Failure 400 {object} vo.RespData "请求参数错误"
+// @Failure 404 {object} vo.RespData "用户不存在"
+// @Failure 500 {object} vo.RespData "服务器内部错误"
+// @Router /admins/users/{userId}/role [put]
+func SetUserRoleHandler(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "无效的用户ID", err)
+		return
+	}
+	var payload dto.SetUserRoleDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		vo.RespondError(c, http.StatusBadRequest, config.CodeInvalidParams, "请求参数错误: "+err.Error(), err)
+		return
+	}
+
+	if err := services.NewUserService().SetRole(c.Request.Context(), uint32(userID), *payload.Role); err != nil {
+		if err.Error() == config.MsgUserNotFound {
+			vo.RespondError(c, http.StatusNotFound, config.CodeUserNotFound, err.Error(), nil)
+			return
+		}
+		vo.RespondError(c, http.StatusInternalServerError, config.CodeServerError, "设置用户角色失败", err)
+		return
+	}
+	vo.RespondSuccess(c, "设置用户角色成功", nil)
+}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/services"
+	"context"
+	"log"
+	"time"
+)
+
+// catalogRefreshInterval 学院/专业目录统计的刷新间隔
+const catalogRefreshInterval = time.Hour
+
+// auditorScoreInterval 重算课程蹭课友好度的间隔，启动时的首次执行同时为存量课程补齐分数
+const auditorScoreInterval = 6 * time.Hour
+
+// postPublishInterval 检查并发布到期定时帖子的间隔
+const postPublishInterval = time.Minute
+
+// sensitiveWordsInterval 检查敏感词库变化的间隔，本实例的修改会立即生效，这里用于同步其他实例的修改
+const sensitiveWordsInterval = time.Minute
+
+// notificationPurgeInterval 清理过期已读通知的间隔
+const notificationPurgeInterval = 24 * time.Hour
+
+// syncRefreshInterval 检查教学班数据变化、推进离线同步版本的间隔，兜底未调用导入接口的数据变更
+const syncRefreshInterval = 10 * time.Minute
+
+// Start 启动所有后台定时任务，ctx 取消时任务退出
+func Start(ctx context.Context) {
+	catalogService := services.NewCatalogService()
+	go runPeriodically(ctx, "学院专业目录重建", catalogRefreshInterval, catalogService.Rebuild)
+
+	syncService := services.NewCourseSyncService()
+	go runPeriodically(ctx, "离线同步版本检查", syncRefreshInterval, func(ctx context.Context) error {
+		_, err := syncService.Refresh(ctx, dto.SyncReasonScheduled)
+		return err
+	})
+
+	auditService := services.NewCourseAuditService()
+	go runPeriodically(ctx, "蹭课友好度重算", auditorScoreInterval, func(ctx context.Context) error {
+		n, err := auditService.RecomputeAllScores(ctx)
+		log.Printf("Job: 已重算 %d 门课程的蹭课友好度", n)
+		return err
+	})
+
+	// 定时发布的状态保存在数据库中，服务重启后启动时会立即补发错过的帖子
+	postService := services.NewPostService()
+	go runPeriodically(ctx, "定时帖子发布", postPublishInterval, func(ctx context.Context) error {
+		n, err := postService.PublishDue(ctx)
+		if n > 0 {
+			log.Printf("Job: 已发布 %d 篇定时帖子", n)
+		}
+		return err
+	})
+
+	sensitiveWordService := services.NewSensitiveWordService()
+	go runPeriodically(ctx, "敏感词库同步", sensitiveWordsInterval, func(ctx context.Context) error {
+		_, err := sensitiveWordService.ReloadIfChanged(ctx)
+		return err
+	})
+
+	// 将旧的 JSON 标签迁移到标签表，并为建立全文索引前的帖子补齐标签文本，只需执行一次
+	go func() {
+		if n, err := services.NewTagService().MigrateJSONTags(ctx); err != nil {
+			log.Printf("Job: 迁移帖子标签失败: %v", err)
+		} else if n > 0 {
+			log.Printf("Job: 已迁移 %d 篇帖子的标签", n)
+		}
+
+		if n, err := postService.BackfillSearchTags(ctx); err != nil {
+			log.Printf("Job: 回填帖子标签文本失败: %v", err)
+		} else if n > 0 {
+			log.Printf("Job: 已为 %d 篇帖子回填标签文本", n)
+		}
+	}()
+
+	if minutes := config.Conf.Counters.ReconcileIntervalMinutes; minutes > 0 {
+		counterService := services.NewCounterService()
+		go runPeriodically(ctx, "计数对账", time.Duration(minutes)*time.Minute, func(ctx context.Context) error {
+			fixed, err := counterService.Reconcile(ctx)
+			if len(fixed) > 0 {
+				log.Printf("Job: 计数对账修正了 %v", fixed)
+			}
+			return err
+		})
+	}
+
+	if days := config.Conf.Notifications.RetentionDays; days > 0 {
+		notificationService := services.NewNotificationService()
+		go runPeriodically(ctx, "已读通知清理", notificationPurgeInterval, func(ctx context.Context) error {
+			n, err := notificationService.PurgeRead(ctx, days)
+			if n > 0 {
+				log.Printf("Job: 已清理 %d 条过期的已读通知", n)
+			}
+			return err
+		})
+	}
+
+	if minutes := config.Conf.Recommendation.RefreshIntervalMinutes; minutes > 0 {
+		recommendationService := services.NewRecommendationService()
+		go runPeriodically(ctx, "课程推荐重算", time.Duration(minutes)*time.Minute, func(ctx context.Context) error {
+			processed, err := recommendationService.RebuildAll(ctx)
+			log.Printf("Job: 已为 %d 位用户重算课程推荐", processed)
+			return err
+		})
+	}
+}
+
+// runPeriodically 启动后立即执行一次，之后按 interval 周期执行
+func runPeriodically(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := fn(ctx); err != nil {
+			log.Printf("Job: %s 执行失败: %v", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// 归一化目录的类型
+const (
+	CatalogKindFaculty = "faculty"
+	CatalogKindMajor   = "major"
+)
+
+// Faculty 归一化后的学院，由 course_infos.faculty 的各种写法合并而来，统计字段随重建刷新
+type Faculty struct {
+	ID            uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name          string    `gorm:"type:varchar(255);not null;comment:展示名称（出现最多的写法）" json:"name"`
+	NormKey       string    `gorm:"type:varchar(255);not null;uniqueIndex;comment:归一化键" json:"-"`
+	CourseCount   int64     `gorm:"not null;default:0;comment:课程数（按课程编号去重）" json:"courseCount"`
+	SectionCount  int64     `gorm:"not null;default:0;comment:教学班数" json:"sectionCount"`
+	ReviewCount   int64     `gorm:"not null;default:0;comment:评价总数" json:"reviewCount"`
+	AverageRating float32   `gorm:"not null;default:0;comment:按评价数加权的平均分" json:"averageRating"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// Major 归一化后的专业，归属于某个学院
+type Major struct {
+	ID            uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	FacultyID     uint32    `gorm:"not null;index;uniqueIndex:idx_major_faculty_key;comment:所属学院ID" json:"facultyId"`
+	Name          string    `gorm:"type:varchar(255);not null;comment:展示名称" json:"name"`
+	NormKey       string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_major_faculty_key;comment:归一化键" json:"-"`
+	CourseCount   int64     `gorm:"not null;default:0" json:"courseCount"`
+	SectionCount  int64     `gorm:"not null;default:0" json:"sectionCount"`
+	ReviewCount   int64     `gorm:"not null;default:0" json:"reviewCount"`
+	AverageRating float32   `gorm:"not null;default:0" json:"averageRating"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// CatalogAlias 原始文本到归一化学院/专业的映射，用于按学院/专业筛选 course_infos
+type CatalogAlias struct {
+	ID       uint32 `gorm:"primaryKey;autoIncrement" json:"id"`
+	Kind     string `gorm:"type:varchar(10);not null;uniqueIndex:idx_alias_kind_raw;index:idx_alias_target,priority:1" json:"kind"`
+	RawValue string `gorm:"type:varchar(255);not null;uniqueIndex:idx_alias_kind_raw;comment:course_infos 中的原始写法" json:"rawValue"`
+	// 专业的原始写法需要和学院一起确定归属，学院别名此字段为空
+	RawFaculty string `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_alias_kind_raw" json:"rawFaculty"`
+	TargetID   uint32 `gorm:"not null;index:idx_alias_target,priority:2;comment:Faculty 或 Major 的ID" json:"targetId"`
+}
+
+// TableName 自定义表名
+func (CatalogAlias) TableName() string {
+	return "catalog_aliases"
+}
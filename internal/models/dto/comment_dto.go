@@ -7,9 +7,11 @@ import (
 
 // GetCommentsParamsDTO 对应前端 GetCommentsParams，用于获取评论列表的查询参数
 type GetCommentsParamsDTO struct {
-	Page   int    `form:"page,default=1"`
-	Limit  int    `form:"limit,default=10"` // 默认每页10条评论
-	SortBy string `form:"sortBy,omitempty"` // 例如 "createdAt_asc" 或 "likesCount_desc"
+	Page      int    `form:"page,default=1"`
+	Limit     int    `form:"limit,default=10"`    // 默认每页10条评论
+	SortBy    string `form:"sortBy,omitempty"`    // 例如 "createdAt_asc" 或 "likesCount_desc"
+	Cursor    string `form:"cursor,omitempty"`    // 上一页返回的 nextCursor，传入时忽略 page
+	SkipTotal bool   `form:"skipTotal,omitempty"` // 为 true 时不统计总数
 }
 
 // AddCommentDTO 对应前端 AddCommentPayload，用于添加新评论的请求体
@@ -32,6 +34,7 @@ type Comment struct {
 	UpdatedAt            time.Time `gorm:"autoUpdateTime" json:"updatedAt"`           // 虽然前端没直接显示，但通常会有
 	IsLikedByCurrentUser bool      `gorm:"default:false" json:"isLikedByCurrentUser"` // 标记当前用户是否已点赞
 	LikesCount           uint      `gorm:"default:0;comment:评论点赞数量" json:"likesCount"`
+	ModerationStatus     uint8     `gorm:"not null;default:0;index;comment:审核状态 0正常 1待审核 2已隐藏 3举报待处理" json:"moderationStatus"`
 
 	// --- 用于支持回复功能 ---
 	ParentID      *uint32  `gorm:"index;comment:父评论ID (用于回复)" json:"parentId,omitempty"`   // 指针表示可选
@@ -0,0 +1,94 @@
+package dto
+
+import "time"
+
+// 帖子、评论、课程评价的审核状态，只有 ModerationVisible 的内容出现在公开列表中
+const (
+	ModerationVisible  uint8 = 0 // 正常展示
+	ModerationPending  uint8 = 1 // 命中敏感词待审核，仅作者本人可见
+	ModerationHidden   uint8 = 2 // 已被隐藏
+	ModerationReported uint8 = 3 // 被多人举报后暂时隐藏，等待版主处理举报单；敏感词审核通过不会恢复展示
+)
+
+// 被审核或举报的内容类型
+const (
+	ContentTargetPost    = "post"
+	ContentTargetComment = "comment"
+	ContentTargetReview  = "review" // 课程评价
+	ContentTargetUser    = "user"   // 用户资料
+)
+
+// 内容审核记录的状态
+const (
+	ContentReviewPending  uint8 = 0
+	ContentReviewApproved uint8 = 1
+	ContentReviewRejected uint8 = 2
+)
+
+// SensitiveWord 敏感词库，修改后各实例定时检测变化并重建匹配器，无需重启
+type SensitiveWord struct {
+	ID        uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Word      string    `gorm:"type:varchar(100);not null;comment:敏感词原文" json:"word"`
+	NormWord  string    `gorm:"type:varchar(100);not null;uniqueIndex;comment:归一化后的敏感词，用于去重" json:"-"`
+	Category  string    `gorm:"type:varchar(50);not null;index;comment:分类，如 spam、abuse、illegal" json:"category"`
+	Action    string    `gorm:"type:varchar(10);not null;comment:命中后的处理方式 block/mask/review" json:"action"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (SensitiveWord) TableName() string {
+	return "sensitive_words"
+}
+
+// ContentReview 命中需要审核的敏感词而进入人工审核队列的内容
+type ContentReview struct {
+	ID           uint32     `gorm:"primaryKey;autoIncrement" json:"id"`
+	TargetType   string     `gorm:"type:varchar(20);not null;index:idx_content_review_target,priority:1;comment:内容类型 post/comment/review/user" json:"targetType"`
+	TargetID     uint32     `gorm:"not null;index:idx_content_review_target,priority:2;comment:内容ID，用户资料为用户ID" json:"targetId"`
+	AuthorID     uint32     `gorm:"not null;index;comment:内容作者" json:"authorId"`
+	Content      string     `gorm:"type:text;not null;comment:提交时的内容快照；用户简介审核通过后才写入资料" json:"content"`
+	MatchedWords string     `gorm:"type:varchar(500);not null;default:'';comment:命中的敏感词，逗号分隔" json:"matchedWords"`
+	Categories   string     `gorm:"type:varchar(255);not null;default:'';comment:命中的分类，逗号分隔" json:"categories"`
+	Status       uint8      `gorm:"not null;default:0;index;comment:0待审核 1通过 2驳回" json:"status"`
+	ReviewerID   *uint32    `gorm:"comment:审核人" json:"reviewerId,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime;index" json:"createdAt"`
+}
+
+func (ContentReview) TableName() string {
+	return "content_reviews"
+}
+
+// SensitiveWordItemDTO 新增或覆盖的一个敏感词，归一化后相同的词视为同一个
+type SensitiveWordItemDTO struct {
+	Word     string `json:"word" binding:"required,max=100"`
+	Category string `json:"category" binding:"required,max=50"`
+	Action   string `json:"action" binding:"required,oneof=block mask review"`
+}
+
+// SensitiveWordBatchDTO 批量导入敏感词的请求体
+type SensitiveWordBatchDTO struct {
+	Words []SensitiveWordItemDTO `json:"words" binding:"required,min=1,max=1000,dive"`
+}
+
+// UpdateSensitiveWordDTO 修改敏感词分类或处理方式的请求体
+type UpdateSensitiveWordDTO struct {
+	Category *string `json:"category,omitempty" binding:"omitempty,min=1,max=50"`
+	Action   *string `json:"action,omitempty" binding:"omitempty,oneof=block mask review"`
+}
+
+// GetSensitiveWordsParamsDTO 敏感词列表的查询参数
+type GetSensitiveWordsParamsDTO struct {
+	Page     int    `form:"page,default=1"`
+	Limit    int    `form:"limit,default=50"`
+	Category string `form:"category,omitempty"`
+	Keyword  string `form:"keyword,omitempty"`
+}
+
+// GetContentReviewsParamsDTO 内容审核队列的查询参数，Status 默认只看待审核
+type GetContentReviewsParamsDTO struct {
+	Page       int    `form:"page,default=1"`
+	Limit      int    `form:"limit,default=20"`
+	Status     uint8  `form:"status,default=0" binding:"oneof=0 1 2"`
+	TargetType string `form:"targetType,omitempty" binding:"omitempty,oneof=post comment review user"`
+}
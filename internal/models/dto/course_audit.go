@@ -0,0 +1,75 @@
+package dto
+
+import "time"
+
+// 课程对蹭课者的态度
+const (
+	AuditPolicyWelcome = "welcome" // 欢迎旁听
+	AuditPolicyNeutral = "neutral" // 未表态（默认）
+	AuditPolicyClosed  = "closed"  // 不欢迎旁听
+)
+
+// 旁听态度提议的审核状态
+const (
+	ProposalStatusPending  = "pending"
+	ProposalStatusApproved = "approved"
+	ProposalStatusRejected = "rejected"
+)
+
+// IsValidAuditPolicy 判断是否为合法的旁听态度
+func IsValidAuditPolicy(policy string) bool {
+	switch policy {
+	case AuditPolicyWelcome, AuditPolicyNeutral, AuditPolicyClosed:
+		return true
+	}
+	return false
+}
+
+// CourseAuditPolicyProposal 用户提交的课程旁听态度修改提议，需管理员审核
+type CourseAuditPolicyProposal struct {
+	ID         uint32     `gorm:"primaryKey;autoIncrement" json:"id"`
+	CourseID   uint32     `gorm:"not null;index;comment:课程ID" json:"courseId"`
+	UserID     uint32     `gorm:"not null;index;comment:提议用户ID" json:"userId"`
+	Policy     string     `gorm:"type:varchar(20);not null;comment:提议的旁听态度" json:"policy"`
+	Reason     string     `gorm:"type:varchar(500);comment:提议理由" json:"reason"`
+	Status     string     `gorm:"type:varchar(20);not null;default:pending;index;comment:审核状态" json:"status"`
+	ReviewerID *uint32    `gorm:"comment:审核管理员ID" json:"reviewerId,omitempty"`
+	ReviewedAt *time.Time `gorm:"comment:审核时间" json:"reviewedAt,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+
+	Course CourseInfo `gorm:"foreignKey:CourseID" json:"-"`
+	User   User       `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName 自定义表名
+func (CourseAuditPolicyProposal) TableName() string {
+	return "course_audit_policy_proposals"
+}
+
+// CourseCheckIn 用户在某天到某门课蹭课的打卡记录，每人每课每天一条
+type CourseCheckIn struct {
+	ID          uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	CourseID    uint32    `gorm:"not null;uniqueIndex:idx_checkin_course_user_date;index;comment:课程ID" json:"courseId"`
+	UserID      uint32    `gorm:"not null;uniqueIndex:idx_checkin_course_user_date;comment:打卡用户ID" json:"userId"`
+	CheckInDate string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_checkin_course_user_date;comment:打卡日期 YYYY-MM-DD" json:"checkInDate"`
+	WeekNum     int       `gorm:"not null;comment:打卡时的周次" json:"weekNum"`
+	DayOfWeek   int       `gorm:"not null;comment:打卡时的星期" json:"dayOfWeek"`
+	LessonNum   int       `gorm:"not null;comment:打卡时的节次" json:"lessonNum"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;index" json:"createdAt"`
+}
+
+// TableName 自定义表名
+func (CourseCheckIn) TableName() string {
+	return "course_check_ins"
+}
+
+// AuditPolicyProposalCreateDTO 用户提议修改旁听态度的请求体
+type AuditPolicyProposalCreateDTO struct {
+	Policy string `json:"policy" binding:"required,oneof=welcome neutral closed"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// SetAuditPolicyDTO 管理员直接设置旁听态度的请求体
+type SetAuditPolicyDTO struct {
+	Policy string `json:"policy" binding:"required,oneof=welcome neutral closed"`
+}
@@ -0,0 +1,141 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+)
+
+// 勘误作用的数据对象
+const (
+	CorrectionTargetCourse = "course" // 修改 CourseInfo 字段
+	CorrectionTargetTime   = "time"   // 修改 TimeInfo 字段
+)
+
+// CorrectionFieldCancelledWeeks 特殊字段：停课周次，值为逗号分隔的周次（如 "3,5"），应用时从 week_and_time 中清除
+const CorrectionFieldCancelledWeeks = "cancelled_weeks"
+
+// CorrectableCourseFields 允许用户勘误的 CourseInfo 字段（数据库列名）
+var CorrectableCourseFields = map[string]bool{
+	"course_name":   true,
+	"teacher":       true,
+	"teacher_title": true,
+	"credit":        true,
+	"course_type":   true,
+	"faculty":       true,
+}
+
+// CorrectableTimeFields 允许用户勘误的 TimeInfo 字段（数据库列名）
+var CorrectableTimeFields = map[string]bool{
+	"building":                    true,
+	"classroom":                   true,
+	"area":                        true,
+	"day_of_week":                 true,
+	"week_and_time":               true,
+	CorrectionFieldCancelledWeeks: true,
+}
+
+// CourseCorrection 用户提交的一次课程勘误，可包含多处修改，需管理员审核
+type CourseCorrection struct {
+	ID         uint32     `gorm:"primaryKey;autoIncrement" json:"id"`
+	CourseID   uint32     `gorm:"not null;index;comment:课程ID" json:"courseId"`
+	UserID     uint32     `gorm:"not null;index;comment:提交用户ID" json:"userId"`
+	Reason     string     `gorm:"type:varchar(500);comment:勘误说明" json:"reason"`
+	Status     string     `gorm:"type:varchar(20);not null;default:pending;index;comment:审核状态" json:"status"`
+	ReviewerID *uint32    `gorm:"comment:审核管理员ID" json:"reviewerId,omitempty"`
+	ReviewNote string     `gorm:"type:varchar(500);comment:审核意见，会反馈给提交者" json:"reviewNote"`
+	ReviewedAt *time.Time `gorm:"comment:审核时间" json:"reviewedAt,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+
+	Items  []CourseCorrectionItem `gorm:"foreignKey:CorrectionID" json:"items"`
+	Course CourseInfo             `gorm:"foreignKey:CourseID" json:"-"`
+	User   User                   `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName 自定义表名
+func (CourseCorrection) TableName() string {
+	return "course_corrections"
+}
+
+// CourseCorrectionItem 勘误中的单处修改
+type CourseCorrectionItem struct {
+	ID           uint32  `gorm:"primaryKey;autoIncrement" json:"id"`
+	CorrectionID uint32  `gorm:"not null;index;comment:所属勘误ID" json:"correctionId"`
+	Target       string  `gorm:"type:varchar(10);not null;comment:course 或 time" json:"target"`
+	TimeInfoID   *uint32 `gorm:"comment:Target 为 time 时对应的 TimeInfo ID" json:"timeInfoId,omitempty"`
+	Field        string  `gorm:"type:varchar(50);not null;comment:字段列名" json:"field"`
+	OldValue     string  `gorm:"type:varchar(255);comment:提交时的原值" json:"oldValue"`
+	NewValue     string  `gorm:"type:varchar(255);not null;comment:建议的新值" json:"newValue"`
+}
+
+// TableName 自定义表名
+func (CourseCorrectionItem) TableName() string {
+	return "course_correction_items"
+}
+
+// CourseDataOverride 审核通过后生效的数据覆盖。
+// 同一 course_num 下有多个教学班，覆盖只作用于勘误所针对的教学班；
+// 以教学班ID与导入时的原始时间段作为自然键，重新导入教务数据后依然可以重新套用。
+type CourseDataOverride struct {
+	ID           uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	CourseInfoID uint32    `gorm:"not null;default:0;uniqueIndex:idx_override_section_key,priority:1;comment:教学班ID" json:"courseInfoId"`
+	CourseNum    string    `gorm:"type:varchar(255);not null;index;comment:课程编号" json:"courseNum"`
+	Target       string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_override_section_key,priority:2;comment:course 或 time" json:"target"`
+	TimeKey      string    `gorm:"type:varchar(255);not null;default:'';uniqueIndex:idx_override_section_key,priority:3;comment:时间段原始值，格式 day|area|building|classroom|week_and_time" json:"timeKey"`
+	Field        string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_override_section_key,priority:4;comment:字段列名" json:"field"`
+	Value        string    `gorm:"type:varchar(255);not null;comment:覆盖值" json:"value"`
+	TimeInfoID   *uint32   `gorm:"comment:最近一次套用到的 TimeInfo ID" json:"timeInfoId,omitempty"`
+	CorrectionID uint32    `gorm:"not null;comment:来源勘误ID" json:"correctionId"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName 自定义表名
+func (CourseDataOverride) TableName() string {
+	return "course_data_overrides"
+}
+
+// OverrideKey 时间段的自然键，格式 day|area|building|classroom|week_and_time
+func (ti TimeInfo) OverrideKey() string {
+	return fmt.Sprintf("%d|%d|%s|%s|%d", ti.DayOfWeek, ti.Area, ti.Building, ti.Classroom, ti.WeekAndTime)
+}
+
+// LocateOverrideTimeInfo 在覆盖所属教学班的时间段中找到覆盖对应的那一个：
+// 先按原始值匹配（刚导入的数据），再退回到上次套用的 TimeInfo ID（已被覆盖过的数据）。
+// 其他教学班即使有相同的时间地点也不会被选中
+func LocateOverrideTimeInfo(timeInfos []TimeInfo, courseInfoID uint32, timeKey string, group []CourseDataOverride) *TimeInfo {
+	for i := range timeInfos {
+		if timeInfos[i].CourseInfoId == courseInfoID && timeInfos[i].OverrideKey() == timeKey {
+			return &timeInfos[i]
+		}
+	}
+	for _, o := range group {
+		if o.TimeInfoID == nil {
+			continue
+		}
+		for i := range timeInfos {
+			if timeInfos[i].CourseInfoId == courseInfoID && timeInfos[i].ID == *o.TimeInfoID {
+				return &timeInfos[i]
+			}
+		}
+	}
+	return nil
+}
+
+// CourseCorrectionItemDTO 单处修改的请求体
+type CourseCorrectionItemDTO struct {
+	Target     string  `json:"target" binding:"required,oneof=course time"`
+	TimeInfoID *uint32 `json:"timeInfoId"`
+	Field      string  `json:"field" binding:"required"`
+	NewValue   string  `json:"newValue" binding:"required,max=255"`
+}
+
+// CourseCorrectionCreateDTO 提交勘误的请求体
+type CourseCorrectionCreateDTO struct {
+	Items  []CourseCorrectionItemDTO `json:"items" binding:"required,min=1,max=10,dive"`
+	Reason string                    `json:"reason" binding:"max=500"`
+}
+
+// ReviewCorrectionDTO 管理员审核勘误的请求体
+type ReviewCorrectionDTO struct {
+	Note string `json:"note" binding:"max=500"`
+}
@@ -0,0 +1,46 @@
+package dto
+
+import "testing"
+
+func TestLocateOverrideTimeInfoSameCourseNum(t *testing.T) {
+	// 两个教学班同属一个 course_num，且在同一时间同一教室上课
+	sectionA, sectionB := CourseInfo{ID: 1, CourseNum: "2024-2025-1-1001"}, CourseInfo{ID: 2, CourseNum: "2024-2025-1-1001"}
+	timeInfos := []TimeInfo{
+		{ID: 10, CourseInfoId: sectionA.ID, DayOfWeek: 1, Area: 1, Building: "教一", Classroom: "101", WeekAndTime: 0x3},
+		{ID: 20, CourseInfoId: sectionB.ID, DayOfWeek: 1, Area: 1, Building: "教一", Classroom: "101", WeekAndTime: 0x3},
+		{ID: 21, CourseInfoId: sectionB.ID, DayOfWeek: 3, Area: 2, Building: "教二", Classroom: "202", WeekAndTime: 0x5},
+	}
+	shared := timeInfos[0].OverrideKey()
+	movedID := uint32(21)
+
+	tests := []struct {
+		name    string
+		section uint32
+		key     string
+		group   []CourseDataOverride
+		want    uint32 // 0 表示找不到
+	}{
+		{"只匹配教学班A", sectionA.ID, shared, nil, 10},
+		{"只匹配教学班B", sectionB.ID, shared, nil, 20},
+		{"时间段只属于B时A找不到", sectionA.ID, timeInfos[2].OverrideKey(), nil, 0},
+		{"原始值变化后按上次套用的ID找到", sectionB.ID, "9|9|x|y|9", []CourseDataOverride{{TimeInfoID: &movedID}}, 21},
+		{"上次套用的ID属于其他教学班时不选中", sectionA.ID, "9|9|x|y|9", []CourseDataOverride{{TimeInfoID: &movedID}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LocateOverrideTimeInfo(timeInfos, tt.section, tt.key, tt.group)
+			if tt.want == 0 {
+				if got != nil {
+					t.Fatalf("LocateOverrideTimeInfo() = %d, want nil", got.ID)
+				}
+				return
+			}
+			if got == nil || got.ID != tt.want {
+				t.Fatalf("LocateOverrideTimeInfo() = %v, want %d", got, tt.want)
+			}
+			if got.CourseInfoId != tt.section {
+				t.Errorf("picked time info of section %d, want %d", got.CourseInfoId, tt.section)
+			}
+		})
+	}
+}
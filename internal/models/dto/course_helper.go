@@ -23,6 +23,10 @@ type CourseInfo struct {
 
 	AverageRating float32 `gorm:"default:0" json:"rating,omitempty"`
 	ReviewCount   uint32  `gorm:"default:0" json:"reviewCount,omitempty"`
+	FavoriteCount uint32  `gorm:"default:0" json:"favoriteCount,omitempty"`
+
+	AuditPolicy  string  `gorm:"type:varchar(20);not null;default:neutral" json:"auditPolicy"` // welcome/neutral/closed
+	AuditorScore float32 `gorm:"default:0;index" json:"auditorScore"`                          // 蹭课友好度 0-100，由服务层维护
 
 	Reviews []CourseReviewModel `gorm:"foreignKey:CourseID" json:"-"`
 }
@@ -41,13 +45,14 @@ type TimeInfo struct {
 }
 
 type CourseReviewModel struct {
-	ID        uint32    `gorm:"primaryKey" json:"id"`
-	CourseID  uint32    `gorm:"not null;index" json:"courseId"`      // 关联的课程ID
-	UserID    uint32    `gorm:"not null;index" json:"userId"`        // 评价用户ID (关联 User 模型)
-	Rating    int       `gorm:"type:tinyint;not null" json:"rating"` // 评分 (例如 1-5)
-	Comment   string    `gorm:"type:text" json:"comment"`            // 评论内容
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"-"`
+	ID               uint32    `gorm:"primaryKey" json:"id"`
+	CourseID         uint32    `gorm:"not null;index" json:"courseId"`      // 关联的课程ID
+	UserID           uint32    `gorm:"not null;index" json:"userId"`        // 评价用户ID (关联 User 模型)
+	Rating           int       `gorm:"type:tinyint;not null" json:"rating"` // 评分 (例如 1-5)
+	Comment          string    `gorm:"type:text" json:"comment"`            // 评论内容
+	ModerationStatus uint8     `gorm:"not null;default:0;index;comment:审核状态 0正常 1待审核 2已隐藏 3举报待处理" json:"moderationStatus"`
+	CreatedAt        time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"-"`
 
 	Course CourseInfo `gorm:"foreignKey:CourseID" json:"-"` // 反向关联，方便查询，但通常不在 JSON 中序列化
 	User   User       `gorm:"foreignKey:UserID" json:"-"`   // 假设有一个 UserModel
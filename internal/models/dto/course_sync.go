@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// 同步版本产生的原因
+const (
+	SyncReasonImport     = "import"     // 导入教务数据
+	SyncReasonCorrection = "correction" // 勘误生效
+	SyncReasonScheduled  = "scheduled"  // 定时检查
+)
+
+// CourseSyncVersion 离线同步的数据版本，每次教学班数据发生变化时递增
+type CourseSyncVersion struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"version"`
+	Reason    string    `gorm:"type:varchar(20);not null" json:"reason"`
+	Upserted  int       `gorm:"not null;default:0" json:"upserted"` // 新增或修改的教学班数
+	Deleted   int       `gorm:"not null;default:0" json:"deleted"`  // 删除的教学班数
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TableName 自定义表名
+func (CourseSyncVersion) TableName() string {
+	return "course_sync_versions"
+}
+
+// CourseSyncState 每个教学班最近一次同步时的内容哈希，以及最后发生变化的版本
+type CourseSyncState struct {
+	CourseID  uint32    `gorm:"primaryKey;autoIncrement:false" json:"courseId"`
+	Hash      string    `gorm:"type:char(64);not null" json:"hash"`
+	Version   uint64    `gorm:"not null;index" json:"version"`
+	Deleted   bool      `gorm:"not null;default:false" json:"deleted"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName 自定义表名
+func (CourseSyncState) TableName() string {
+	return "course_sync_states"
+}
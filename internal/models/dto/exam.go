@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+// CourseExam 教学班的考试安排
+type CourseExam struct {
+	ID        uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	CourseID  uint32    `gorm:"not null;uniqueIndex:idx_exam_course_date_start;comment:教学班ID" json:"courseId"`
+	ExamType  string    `gorm:"type:varchar(20);not null;default:'';comment:考试类型，如期末/期中" json:"examType"`
+	ExamDate  string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_exam_course_date_start;index:idx_exam_room_date,priority:4;comment:考试日期 YYYY-MM-DD" json:"examDate"`
+	StartTime string    `gorm:"type:varchar(5);not null;uniqueIndex:idx_exam_course_date_start;comment:开始时间 HH:MM" json:"startTime"`
+	EndTime   string    `gorm:"type:varchar(5);not null;comment:结束时间 HH:MM" json:"endTime"`
+	Area      uint8     `gorm:"not null;index:idx_exam_room_date,priority:1" json:"area"`
+	Building  string    `gorm:"type:varchar(255);not null;index:idx_exam_room_date,priority:2" json:"building"`
+	Classroom string    `gorm:"type:varchar(255);not null;index:idx_exam_room_date,priority:3" json:"classroom"`
+	SeatNote  string    `gorm:"type:text;comment:座位安排说明" json:"seatNote"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+
+	Course CourseInfo `gorm:"foreignKey:CourseID" json:"-"`
+}
+
+// TableName 自定义表名
+func (CourseExam) TableName() string {
+	return "course_exams"
+}
+
+// ExamImportItemDTO 导入的一条考试安排；只给 courseNum 时应用到该课程在当前学期的所有教学班
+type ExamImportItemDTO struct {
+	CourseID  uint32 `json:"courseId"`
+	CourseNum string `json:"courseNum"`
+	ExamType  string `json:"examType" binding:"max=20"`
+	ExamDate  string `json:"examDate" binding:"required"`  // YYYY-MM-DD
+	StartTime string `json:"startTime" binding:"required"` // HH:MM
+	EndTime   string `json:"endTime" binding:"required"`   // HH:MM
+	Area      uint8  `json:"area" binding:"min=1,max=4"`
+	Building  string `json:"building" binding:"required,max=255"`
+	Classroom string `json:"classroom" binding:"required,max=255"`
+	SeatNote  string `json:"seatNote"`
+}
+
+// ExamImportDTO 批量导入考试安排
+type ExamImportDTO struct {
+	Replace bool                `json:"replace"` // 为 true 时先清空已有考试安排
+	Exams   []ExamImportItemDTO `json:"exams" binding:"required,dive"`
+}
@@ -52,3 +52,23 @@ type UserCommentLike struct {
 // func (UserCommentLike) TableName() string {
 //  return "user_comment_likes"
 // }
+
+// UserCourseFavorite 记录用户对课程（CourseInfo）的收藏，(user_id, course_id) 复合主键即唯一约束，重复收藏在插入时去重
+// 表名将是 "user_course_favorites"
+type UserCourseFavorite struct {
+	UserID    uint32    `gorm:"primaryKey;not null;comment:收藏用户ID"`
+	CourseID  uint32    `gorm:"primaryKey;not null;index;comment:被收藏的课程ID"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:收藏时间"`
+}
+
+// PostCourse 帖子与课程（CourseInfo）的关联，一个帖子可以关联多门课程
+type PostCourse struct {
+	PostID    uint32    `gorm:"primaryKey;not null;comment:帖子ID"`
+	CourseID  uint32    `gorm:"primaryKey;not null;index;comment:关联的课程ID"`
+	CreatedAt time.Time `gorm:"autoCreateTime;comment:关联时间"`
+}
+
+// TableName 自定义表名
+func (PostCourse) TableName() string {
+	return "post_courses"
+}
@@ -0,0 +1,65 @@
+package dto
+
+import "time"
+
+// 课程资料类型
+const (
+	MaterialKindSyllabus = "syllabus" // 教学大纲
+	MaterialKindSlides   = "slides"   // 课件
+	MaterialKindReading  = "reading"  // 阅读书目
+	MaterialKindOther    = "other"
+)
+
+// CourseMaterial 课程资料，按课程编号挂在课程上，同一课程的所有教学班共享，重新导入后依然保留。
+// 文件类资料的内容保存在 CourseMaterialVersion 中；链接类资料只有 LinkURL
+type CourseMaterial struct {
+	ID             uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	CourseNum      string    `gorm:"type:varchar(255);not null;index;comment:课程编号" json:"courseNum"`
+	Title          string    `gorm:"type:varchar(200);not null" json:"title"`
+	Kind           string    `gorm:"type:varchar(20);not null" json:"kind"`
+	Description    string    `gorm:"type:text" json:"description"`
+	LinkURL        string    `gorm:"type:varchar(1024);not null;default:''" json:"linkUrl"`
+	CurrentVersion int       `gorm:"not null;default:0;comment:最新版本号，链接类资料为 0" json:"currentVersion"`
+	DownloadCount  uint32    `gorm:"not null;default:0" json:"downloadCount"`
+	UploaderID     uint32    `gorm:"not null;index" json:"uploaderId"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+
+	Uploader User `gorm:"foreignKey:UploaderID" json:"-"`
+}
+
+// TableName 自定义表名
+func (CourseMaterial) TableName() string {
+	return "course_materials"
+}
+
+// CourseMaterialVersion 文件类资料的一个版本
+type CourseMaterialVersion struct {
+	ID          uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	MaterialID  uint32    `gorm:"not null;uniqueIndex:idx_material_version" json:"materialId"`
+	Version     int       `gorm:"not null;uniqueIndex:idx_material_version" json:"version"`
+	StorageKey  string    `gorm:"type:varchar(512);not null" json:"-"`
+	FileName    string    `gorm:"type:varchar(255);not null" json:"fileName"`
+	ContentType string    `gorm:"type:varchar(100);not null" json:"contentType"`
+	Size        int64     `gorm:"not null" json:"size"`
+	SHA256      string    `gorm:"type:char(64);not null" json:"sha256"`
+	Note        string    `gorm:"type:varchar(500);not null;default:''" json:"note"`
+	UploaderID  uint32    `gorm:"not null" json:"uploaderId"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"createdAt"`
+
+	Uploader User `gorm:"foreignKey:UploaderID" json:"-"`
+}
+
+// TableName 自定义表名
+func (CourseMaterialVersion) TableName() string {
+	return "course_material_versions"
+}
+
+// CourseMaterialCreateDTO 新建课程资料的表单字段，文件通过 multipart 的 file 字段上传
+type CourseMaterialCreateDTO struct {
+	Title       string `form:"title" binding:"required,max=200"`
+	Kind        string `form:"kind" binding:"required,oneof=syllabus slides reading other"`
+	Description string `form:"description" binding:"max=2000"`
+	LinkURL     string `form:"linkUrl" binding:"omitempty,url,max=1024"`
+	Note        string `form:"note" binding:"max=500"`
+}
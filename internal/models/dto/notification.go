@@ -0,0 +1,101 @@
+package dto
+
+import "time"
+
+// 通知类型
+const (
+	NotificationReply        = "reply"         // 帖子被评论、评论被回复
+	NotificationLike         = "like"          // 帖子或评论被点赞
+	NotificationCollect      = "collect"       // 帖子被收藏
+	NotificationMention      = "mention"       // 在评论中被 @
+	NotificationModeration   = "moderation"    // 举报处理结果、内容审核结果
+	NotificationCourseUpdate = "course_update" // 收藏的课程有勘误、资料、考试安排等更新
+	NotificationPostPublish  = "post_publish"  // 定时发布的帖子已发布
+)
+
+// NotificationTargetCourse 课程更新通知的对象类型，其余通知的对象类型与举报相同
+const NotificationTargetCourse = "course"
+
+// NotificationConfigurableTypes 用户可以关闭的通知类型，处理结果和定时发布结果总是发送
+var NotificationConfigurableTypes = []string{
+	NotificationReply,
+	NotificationLike,
+	NotificationCollect,
+	NotificationMention,
+	NotificationCourseUpdate,
+}
+
+// IsConfigurableNotificationType 是否为用户可以关闭的通知类型
+func IsConfigurableNotificationType(notificationType string) bool {
+	for _, t := range NotificationConfigurableTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification 站内通知。同一对象的同类通知在未读期间聚合为一条，如"12人赞了你的帖子"
+type Notification struct {
+	ID         uint32     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint32     `gorm:"not null;index:idx_notification_inbox,priority:1;comment:接收通知的用户" json:"userId"`
+	Type       string     `gorm:"type:varchar(20);not null;comment:通知类型" json:"type"`
+	TargetType string     `gorm:"type:varchar(20);not null;default:'';comment:通知对象类型 post/comment/review/user/course" json:"targetType"`
+	TargetID   uint32     `gorm:"not null;default:0;comment:通知对象ID" json:"targetId"`
+	PostID     uint32     `gorm:"not null;default:0;comment:对象所在的帖子，用于跳转；与帖子无关时为 0" json:"postId"`
+	ActorID    *uint32    `gorm:"comment:最近一次触发通知的用户，系统通知为空" json:"actorId,omitempty"`
+	Count      uint       `gorm:"not null;default:0;comment:聚合的人数，课程更新为更新次数" json:"count"`
+	Excerpt    string     `gorm:"type:varchar(255);not null;default:'';comment:通知对象的标题或摘要" json:"excerpt"`
+	Message    string     `gorm:"type:varchar(500);not null;default:'';comment:最近一条回复的摘要、处理结果或更新说明" json:"message"`
+	IsRead     bool       `gorm:"not null;default:false;index:idx_notification_inbox,priority:2" json:"isRead"`
+	ReadAt     *time.Time `json:"readAt,omitempty"`
+	// GroupKey 仅在未读时为 "用户:类型:对象类型:对象ID"，借助唯一索引聚合同一对象的通知，已读后置空，之后的通知另起一条
+	GroupKey  *string   `gorm:"type:varchar(80);uniqueIndex;comment:未读时的聚合键，已读后置空" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;index:idx_notification_inbox,priority:3;comment:最近一次聚合的时间" json:"updatedAt"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationActor 聚合通知中的触发用户，同一用户只计一次，取消点赞后重新点赞不会重复计数
+type NotificationActor struct {
+	NotificationID uint32    `gorm:"primaryKey;not null" json:"notificationId"`
+	ActorID        uint32    `gorm:"primaryKey;not null" json:"actorId"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;index" json:"createdAt"`
+}
+
+func (NotificationActor) TableName() string {
+	return "notification_actors"
+}
+
+// NotificationPreference 用户的通知偏好，没有记录的类型默认开启
+type NotificationPreference struct {
+	UserID    uint32    `gorm:"primaryKey;not null" json:"userId"`
+	Type      string    `gorm:"primaryKey;type:varchar(20);not null" json:"type"`
+	Enabled   bool      `gorm:"not null;default:true" json:"enabled"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// GetNotificationsParamsDTO 通知列表的查询参数
+type GetNotificationsParamsDTO struct {
+	Page       int    `form:"page,default=1"`
+	Limit      int    `form:"limit,default=20"`
+	UnreadOnly bool   `form:"unreadOnly,default=false"`
+	Type       string `form:"type,omitempty" binding:"omitempty,oneof=reply like collect mention moderation course_update post_publish"`
+}
+
+// MarkAllNotificationsReadDTO 全部标记已读的查询参数，Type 为空时标记所有类型
+type MarkAllNotificationsReadDTO struct {
+	Type string `form:"type,omitempty" binding:"omitempty,oneof=reply like collect mention moderation course_update post_publish"`
+}
+
+// UpdateNotificationPreferencesDTO 修改通知偏好的请求体，键为通知类型
+type UpdateNotificationPreferencesDTO struct {
+	Preferences map[string]bool `json:"preferences" binding:"required,min=1"`
+}
@@ -12,11 +12,17 @@ import (
 type GetPostsParamsDTO struct {
 	Page       int    `form:"page,default=1"` // gin 中用 form tag 接收 query 参数
 	Limit      int    `form:"limit,default=10"`
-	SortBy     string `form:"sortBy,omitempty"`     // 例如 "createdAt_desc"
-	FilterText string `form:"filterText,omitempty"` // 搜索关键词
+	SortBy     string `form:"sortBy,omitempty"`     // 例如 "createdAt_desc"；有搜索关键词时可用 "relevance"，也是默认排序
+	FilterText string `form:"filterText,omitempty"` // 搜索关键词，支持 "短语" 与 -排除词
+	Featured   bool   `form:"featured,omitempty"`   // 为 true 时只返回精华帖
 	Category   string `form:"category,omitempty"`
-	Tag        string `form:"tag,omitempty"`
+	Tag        string `form:"tag,omitempty"`      // 单个标签，精确匹配
+	Tags       string `form:"tags,omitempty"`     // 逗号分隔的多个标签
+	TagMatch   string `form:"tagMatch,omitempty"` // 多个标签的匹配方式：all（默认，同时包含）/ any（包含任一）
 	AuthorID   uint32 `form:"authorId,omitempty"`
+	CourseID   uint32 `form:"courseId,omitempty"`  // 只返回关联了该课程的帖子
+	Cursor     string `form:"cursor,omitempty"`    // 上一页返回的 nextCursor，传入时忽略 page
+	SkipTotal  bool   `form:"skipTotal,omitempty"` // 为 true 时不统计总数
 }
 
 // CreatePostDTO 对应前端 CreatePostBody，用于创建新帖子的请求体
@@ -24,38 +30,79 @@ type GetPostsParamsDTO struct {
 type CreatePostDTO struct {
 	Title    string   `json:"title" binding:"required,min=1,max=100"`
 	Content  string   `json:"content" binding:"required,min=5"`
-	Tags     []string `json:"tags,omitempty"`
+	Tags     []string `json:"tags,omitempty" binding:"omitempty,max=10"`
 	Category *string  `json:"category,omitempty"` // 使用指针表示可选
 	// AuthorID uint32 `json:"authorId"` // 通常由后端从JWT获取，不由前端传递
+	CourseIDs []uint32 `json:"courseIds,omitempty" binding:"omitempty,max=5"` // 关联的课程 (CourseInfo ID)
+	// 为 true 时保存为草稿；PublishAt 为将来的时间时保存为草稿并在该时间自动发布
+	IsDraft   bool       `json:"isDraft,omitempty"`
+	PublishAt *time.Time `json:"publishAt,omitempty"`
 }
 
 // UpdatePostDTO 对应前端 UpdatePostBody，用于更新帖子的请求体
 type UpdatePostDTO struct {
 	Title    *string  `json:"title,omitempty" binding:"omitempty,min=1,max=100"` // 指针表示可选更新
 	Content  *string  `json:"content,omitempty" binding:"omitempty,min=5"`
-	Tags     []string `json:"tags,omitempty"` // 如果传空数组表示清空，如果 omitempty 且为nil则不更新
+	Tags     []string `json:"tags,omitempty" binding:"omitempty,max=10"` // 如果传空数组表示清空，如果 omitempty 且为nil则不更新
 	Category *string  `json:"category,omitempty"`
 	// isPublished, isPinned, isLocked 等状态的更新也可以放在这里
+	CourseIDs []uint32 `json:"courseIds,omitempty" binding:"omitempty,max=5"` // 非 nil 时整体替换关联课程，空数组表示清空
+}
+
+// 置顶范围
+const (
+	PinScopeGlobal   = "global"   // 全站置顶，出现在所有包含该帖子的列表顶部
+	PinScopeCategory = "category" // 分类置顶，只在按该帖子分类筛选时置顶
+)
+
+// PinPostDTO 置顶帖子的请求体
+type PinPostDTO struct {
+	Scope string     `json:"scope" binding:"required,oneof=global category"`
+	Until *time.Time `json:"until,omitempty"` // 到期时间，为空表示长期置顶
+}
+
+// MovePostDTO 移动帖子分类的请求体
+type MovePostDTO struct {
+	Category string `json:"category" binding:"required,max=100"`
+}
+
+// PublishPostDTO 发布草稿的请求体，PublishAt 为空或不晚于当前时间时立即发布，否则定时发布
+type PublishPostDTO struct {
+	PublishAt *time.Time `json:"publishAt,omitempty"`
+}
+
+// GetDraftsParamsDTO 获取草稿列表的查询参数
+type GetDraftsParamsDTO struct {
+	Page  int `form:"page,default=1"`
+	Limit int `form:"limit,default=10"`
 }
 
 // Post 对应数据库中的 'posts' 表
 type Post struct {
 	ID                       uint32         `gorm:"primaryKey;autoIncrement" json:"id"`
-	Title                    string         `gorm:"type:varchar(255);not null;comment:帖子标题" json:"title"`
-	Content                  string         `gorm:"type:text;not null;comment:帖子内容 (HTML 或 Markdown)" json:"content"`
+	Title                    string         `gorm:"type:varchar(255);not null;index:idx_posts_fulltext,class:FULLTEXT,option:WITH PARSER ngram,priority:1;comment:帖子标题" json:"title"`
+	Content                  string         `gorm:"type:text;not null;index:idx_posts_fulltext,priority:2;comment:帖子内容 (HTML 或 Markdown)" json:"content"`
 	AuthorID                 uint32         `gorm:"not null;type:uint;index;comment:帖子作者的用户ID" json:"authorId"`
 	Author                   User           `gorm:"foreignKey:AuthorID" json:"author"` // 关联作者信息
 	CreatedAt                time.Time      `gorm:"autoCreateTime;index" json:"createdAt"`
 	UpdatedAt                time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
 	Category                 *string        `gorm:"type:varchar(100);index;comment:帖子分类" json:"category,omitempty"`
-	Tags                     datatypes.JSON `gorm:"type:json;comment:帖子标签数组" json:"tags,omitempty"` // 存储为 JSON 字符串或 JSONB
+	Tags                     datatypes.JSON `gorm:"type:json;comment:帖子标签数组" json:"tags,omitempty"`                                   // 存储为 JSON 字符串或 JSONB
+	SearchTags               *string        `gorm:"type:text;index:idx_posts_fulltext,priority:3;comment:空格分隔的标签文本，供全文索引使用" json:"-"` // JSON 列无法建全文索引，写入标签时同步维护
 	ViewCount                uint           `gorm:"default:0;comment:帖子浏览次数" json:"viewCount"`
 	LikesCount               uint           `gorm:"default:0;comment:帖子点赞数量" json:"likesCount"`
 	CollectCount             uint           `gorm:"default:0;comment:帖子收藏数量" json:"collectCount"`
 	CommentsCount            uint           `gorm:"default:0;comment:帖子评论数量" json:"commentsCount"`
 	IsPublished              bool           `gorm:"default:true;comment:是否已发布" json:"isPublished"`
+	PublishAt                *time.Time     `gorm:"index;comment:草稿的定时发布时间，为空表示不自动发布" json:"publishAt,omitempty"`
 	IsPinned                 bool           `gorm:"default:false;comment:是否置顶" json:"isPinned"`
+	PinScope                 string         `gorm:"type:varchar(20);not null;default:'';comment:置顶范围 global/category" json:"pinScope,omitempty"`
+	PinnedAt                 *time.Time     `gorm:"comment:置顶时间，多个置顶帖按此倒序" json:"pinnedAt,omitempty"`
+	PinnedUntil              *time.Time     `gorm:"comment:置顶到期时间，为空表示长期置顶" json:"pinnedUntil,omitempty"`
 	IsLocked                 bool           `gorm:"default:false;comment:是否锁定评论" json:"isLocked"`
+	IsFeatured               bool           `gorm:"default:false;index;comment:是否加精" json:"isFeatured"`
+	FeaturedAt               *time.Time     `gorm:"comment:加精时间" json:"featuredAt,omitempty"`
+	ModerationStatus         uint8          `gorm:"not null;default:0;index;comment:审核状态 0正常 1待审核 2已隐藏 3举报待处理" json:"moderationStatus"`
 	IsLikedByCurrentUser     bool           `gorm:"default:false;comment:当前用户是否点赞" json:"isLikedByCurrentUser"`
 	IsCollectedByCurrentUser bool           `gorm:"default:false;comment:当前用户是否收藏" json:"IsCollectedByCurrentUser"`
 	// 如果需要追踪最后评论信息，可以添加以下字段，但通常这些可以通过查询动态获取或在评论创建时更新
@@ -66,6 +113,8 @@ type Post struct {
 	Comments []Comment `gorm:"foreignKey:PostID" json:"comments,omitempty"` // 帖子的评论列表
 
 	DeletedAt gorm.DeletedAt `gorm:"index"` // 软删除标记
+
+	Relevance float64 `gorm:"->;-:migration" json:"-"` // 全文检索相关度，仅在搜索查询中由 SELECT 计算
 }
 
 // TableName 自定义 Post 模型对应的表名
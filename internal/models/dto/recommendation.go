@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// UserCourseRecommendation 离线计算好的用户课程推荐结果，每个用户保留排名靠前的若干条
+type UserCourseRecommendation struct {
+	ID         uint32         `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint32         `gorm:"not null;uniqueIndex:idx_rec_user_course;index:idx_rec_user_rank,priority:1;comment:用户ID" json:"userId"`
+	CourseID   uint32         `gorm:"not null;uniqueIndex:idx_rec_user_course;comment:推荐的课程ID" json:"courseId"`
+	Rank       int            `gorm:"not null;index:idx_rec_user_rank,priority:2;comment:排名，从1开始" json:"rank"`
+	Score      float32        `gorm:"not null;comment:推荐分" json:"score"`
+	Reasons    datatypes.JSON `gorm:"type:json;comment:推荐理由列表" json:"reasons"`
+	ComputedAt time.Time      `gorm:"not null;comment:计算时间" json:"computedAt"`
+
+	Course CourseInfo `gorm:"foreignKey:CourseID" json:"-"`
+}
+
+// TableName 自定义表名
+func (UserCourseRecommendation) TableName() string {
+	return "user_course_recommendations"
+}
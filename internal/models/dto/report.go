@@ -0,0 +1,102 @@
+package dto
+
+import "time"
+
+// 举报理由
+const (
+	ReportReasonSpam           = "spam"           // 广告、刷屏
+	ReportReasonOffTopic       = "off_topic"      // 与课程或社区无关
+	ReportReasonMisinformation = "misinformation" // 错误的课程信息
+	ReportReasonHarassment     = "harassment"     // 人身攻击、骚扰
+	ReportReasonSexual         = "sexual"         // 色情低俗
+	ReportReasonIllegal        = "illegal"        // 违法违规
+	ReportReasonOther          = "other"
+)
+
+// ReportReasonSeverity 各举报理由的严重程度，举报单的严重程度取其中最高的理由
+var ReportReasonSeverity = map[string]uint8{
+	ReportReasonSpam:           1,
+	ReportReasonOffTopic:       1,
+	ReportReasonOther:          1,
+	ReportReasonMisinformation: 2,
+	ReportReasonHarassment:     3,
+	ReportReasonSexual:         4,
+	ReportReasonIllegal:        5,
+}
+
+// 举报单状态
+const (
+	ReportCaseOpen     = "open"
+	ReportCaseResolved = "resolved"
+)
+
+// 版主对举报单的处理方式
+const (
+	ReportActionDismiss = "dismiss" // 举报不成立，自动隐藏的内容恢复展示
+	ReportActionHide    = "hide"    // 隐藏内容
+	ReportActionDelete  = "delete"  // 删除内容
+	ReportActionWarn    = "warn"    // 警告内容作者
+	ReportActionBan     = "ban"     // 封禁内容作者
+)
+
+// ContentReport 一条举报，同一用户对同一举报单只保留一条，重复举报时更新理由
+type ContentReport struct {
+	ID         uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	CaseID     uint32    `gorm:"not null;uniqueIndex:idx_report_case_reporter,priority:1;comment:所属举报单" json:"caseId"`
+	ReporterID uint32    `gorm:"not null;uniqueIndex:idx_report_case_reporter,priority:2;index;comment:举报人" json:"reporterId"`
+	Reason     string    `gorm:"type:varchar(20);not null;comment:举报理由" json:"reason"`
+	Detail     string    `gorm:"type:varchar(500);not null;default:'';comment:补充说明" json:"detail"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (ContentReport) TableName() string {
+	return "content_reports"
+}
+
+// ReportCase 同一对象的举报汇总成一张举报单，进入版主处理队列；处理后再次被举报会开新的举报单
+type ReportCase struct {
+	ID             uint32 `gorm:"primaryKey;autoIncrement" json:"id"`
+	TargetType     string `gorm:"type:varchar(20);not null;index:idx_report_case_target,priority:1;comment:被举报对象类型 post/comment/review/user" json:"targetType"`
+	TargetID       uint32 `gorm:"not null;index:idx_report_case_target,priority:2;comment:被举报对象ID" json:"targetId"`
+	TargetAuthorID uint32 `gorm:"not null;index;comment:被举报内容的作者，举报用户时为该用户" json:"targetAuthorId"`
+	// OpenKey 仅在未处理时为 "类型:ID"，借助唯一索引保证同一对象同时只有一张未处理的举报单
+	OpenKey        *string    `gorm:"type:varchar(40);uniqueIndex;comment:未处理时为 类型:ID，处理后置空" json:"-"`
+	Status         string     `gorm:"type:varchar(10);not null;default:'open';index:idx_report_case_queue,priority:1" json:"status"`
+	Severity       uint8      `gorm:"not null;default:0;index:idx_report_case_queue,priority:2;comment:举报理由的最高严重程度" json:"severity"`
+	ReportCount    uint       `gorm:"not null;default:0;index:idx_report_case_queue,priority:3;comment:举报人数" json:"reportCount"`
+	AutoHidden     bool       `gorm:"not null;default:false;comment:是否因举报人数达到阈值被自动隐藏" json:"autoHidden"`
+	Action         string     `gorm:"type:varchar(10);not null;default:'';comment:处理方式" json:"action,omitempty"`
+	ResolutionNote string     `gorm:"type:varchar(500);not null;default:'';comment:处理说明" json:"resolutionNote,omitempty"`
+	ResolverID     *uint32    `gorm:"comment:处理人" json:"resolverId,omitempty"`
+	ResolvedAt     *time.Time `json:"resolvedAt,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (ReportCase) TableName() string {
+	return "report_cases"
+}
+
+// CreateReportDTO 举报帖子、评论、课程评价或用户的请求体
+type CreateReportDTO struct {
+	TargetType string `json:"targetType" binding:"required,oneof=post comment review user"`
+	TargetID   uint32 `json:"targetId" binding:"required"`
+	Reason     string `json:"reason" binding:"required,oneof=spam off_topic misinformation harassment sexual illegal other"`
+	Detail     string `json:"detail,omitempty" binding:"omitempty,max=500"`
+}
+
+// ResolveReportDTO 版主处理举报单的请求体，BanDays 为 0 表示永久封禁
+type ResolveReportDTO struct {
+	Action  string `json:"action" binding:"required,oneof=dismiss hide delete warn ban"`
+	Note    string `json:"note,omitempty" binding:"omitempty,max=500"`
+	BanDays int    `json:"banDays,omitempty" binding:"omitempty,min=0,max=3650"`
+}
+
+// GetReportCasesParamsDTO 举报处理队列的查询参数
+type GetReportCasesParamsDTO struct {
+	Page       int    `form:"page,default=1"`
+	Limit      int    `form:"limit,default=20"`
+	Status     string `form:"status,default=open" binding:"oneof=open resolved"`
+	TargetType string `form:"targetType,omitempty" binding:"omitempty,oneof=post comment review user"`
+}
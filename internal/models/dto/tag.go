@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// Tag 归一化后的帖子标签。写法不同但归一化键相同的标签视为同一个；
+// 被管理员合并的同义标签保留记录并指向合并后的标签，之后再使用旧写法会自动归入新标签
+type Tag struct {
+	ID           uint32    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name         string    `gorm:"type:varchar(50);not null;comment:展示名称" json:"name"`
+	NormKey      string    `gorm:"type:varchar(50);not null;uniqueIndex;comment:归一化键" json:"-"`
+	UsageCount   uint32    `gorm:"not null;default:0;index;comment:使用该标签的帖子数" json:"usageCount"`
+	MergedIntoID *uint32   `gorm:"index;comment:合并到的标签ID，为空表示有效标签" json:"mergedIntoId,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// TableName 自定义表名
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// PostTag 帖子与标签的关联，CreatedAt 用于统计近期热门标签
+type PostTag struct {
+	PostID    uint32    `gorm:"primaryKey;autoIncrement:false" json:"postId"`
+	TagID     uint32    `gorm:"primaryKey;autoIncrement:false;index" json:"tagId"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"createdAt"`
+}
+
+// TableName 自定义表名
+func (PostTag) TableName() string {
+	return "post_tags"
+}
+
+// 多标签筛选方式
+const (
+	TagMatchAll = "all" // 同时包含所有标签
+	TagMatchAny = "any" // 包含任一标签
+)
+
+// TagMergeDTO 合并同义标签的请求体
+type TagMergeDTO struct {
+	SourceIDs []uint32 `json:"sourceIds" binding:"required,min=1,max=50"` // 被合并的标签
+	TargetID  uint32   `json:"targetId" binding:"required"`               // 合并到的标签
+}
@@ -5,8 +5,9 @@ import (
 )
 
 const (
-	UserRoleCommon uint8 = 0
-	UserRoleAdmin  uint8 = 1
+	UserRoleCommon    uint8 = 0
+	UserRoleAdmin     uint8 = 1
+	UserRoleModerator uint8 = 2 // 版主：可置顶、锁定、加精、移动帖子
 )
 
 type SimpleUser struct {
@@ -23,6 +24,35 @@ type User struct {
 
 	Avatar string `gorm:"not null;type:varchar(255)" json:"avatar"` // 用户头像路径或 URL
 	Bio    string `gorm:"not null;type:varchar(255)" json:"bio"`    // 用户简介
+
+	FacultyID *uint32 `gorm:"index" json:"facultyId,omitempty"`                  // 所在学院（归一化学院ID）
+	MajorID   *uint32 `gorm:"index" json:"majorId,omitempty"`                    // 所学专业（归一化专业ID）
+	Grade     string  `gorm:"not null;default:'';type:varchar(20)" json:"grade"` // 年级，如 2023
+
+	// 冗余计数，随发帖、评论、点赞在同一事务中维护，并由对账任务定期修正
+	PostsCount    uint `gorm:"not null;default:0;comment:发布的帖子数" json:"postsCount"`
+	CommentsCount uint `gorm:"not null;default:0;comment:发表的评论数" json:"commentsCount"`
+	LikesGiven    uint `gorm:"not null;default:0;comment:点赞帖子和评论的次数" json:"likesGiven"`
+	LikesReceived uint `gorm:"not null;default:0;comment:帖子和评论收到的点赞数" json:"likesReceived"`
+
+	// 举报处理结果
+	WarningsCount uint       `gorm:"not null;default:0;comment:被版主警告的次数" json:"warningsCount"`
+	BannedUntil   *time.Time `gorm:"comment:封禁到期时间，为空表示未封禁" json:"bannedUntil,omitempty"`
+}
+
+// PermanentBanUntil 永久封禁时写入的到期时间
+func PermanentBanUntil() time.Time {
+	return time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+}
+
+// IsBanned 用户在 now 时是否处于封禁中
+func (u User) IsBanned(now time.Time) bool {
+	return u.BannedUntil != nil && u.BannedUntil.After(now)
+}
+
+// SetUserRoleDTO 管理员设置用户角色的请求体
+type SetUserRoleDTO struct {
+	Role *uint8 `json:"role" binding:"required,oneof=0 1 2"`
 }
 
 // RegisterRequestDTO 对应前端注册请求的数据
@@ -7,3 +7,13 @@ type BuildingInfoVO struct {
 	Value    int            `json:"value"`
 	Infos    []CourseInfoVO `json:"infos"` // 对应前端 infos 字段
 }
+
+// AllCoursesPageVO 某个学部全部课程的一页数据（/all 接口按学部分页返回）
+type AllCoursesPageVO struct {
+	DivisionID  int              `json:"divisionId"`
+	Buildings   []BuildingInfoVO `json:"buildings"`
+	Total       int64            `json:"total"` // 该学部课程总数
+	CurrentPage int              `json:"currentPage"`
+	PageSize    int              `json:"pageSize"`
+	HasMore     bool             `json:"hasMore"`
+}
@@ -15,14 +15,17 @@ type CommentVO struct {
 	ReplyToUser          *AuthorInfoVO `json:"replyToUser,omitempty"`          // 如果是回复，被回复的用户信息
 	Children             []CommentVO   `json:"children,omitempty"`             // 嵌套的子回复
 	IsLikedByCurrentUser *bool         `json:"isLikedByCurrentUser,omitempty"` // 当前用户是否点赞此评论
+	ModerationStatus     uint8         `json:"moderationStatus,omitempty"`     // 1 待审核，暂不在评论列表中展示
 }
 
 // GetCommentsResponseDataVO 对应前端 GetCommentsResponseData
 type GetCommentsResponseDataVO struct {
 	Items       []CommentVO `json:"items"`
-	Total       int64       `json:"total"` // 顶级评论的总数
+	Total       *int64      `json:"total,omitempty"` // 顶级评论的总数
 	CurrentPage *int        `json:"currentPage,omitempty"`
 	PageSize    *int        `json:"pageSize,omitempty"`
+	NextCursor  string      `json:"nextCursor,omitempty"` // 下一页的游标，没有更多数据时为空
+	HasMore     bool        `json:"hasMore"`
 }
 
 // ToggleLikeCommentResponseDataVO 对应前端 ToggleLikeCommentResponseData
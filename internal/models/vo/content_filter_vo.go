@@ -0,0 +1,88 @@
+package vo
+
+import "time"
+
+// SensitiveWordVO 敏感词
+type SensitiveWordVO struct {
+	ID        uint32    `json:"id"`
+	Word      string    `json:"word"`
+	Category  string    `json:"category"`
+	Action    string    `json:"action"` // block/mask/review
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SensitiveWordListVO 敏感词分页列表
+type SensitiveWordListVO struct {
+	Items       []SensitiveWordVO `json:"items"`
+	Total       int64             `json:"total"`
+	CurrentPage int               `json:"currentPage"`
+	PageSize    int               `json:"pageSize"`
+}
+
+// SensitiveWordImportVO 批量导入敏感词的结果
+type SensitiveWordImportVO struct {
+	Saved   int `json:"saved"`   // 新增或覆盖的词数
+	Skipped int `json:"skipped"` // 归一化后为空而被忽略的词数
+	Loaded  int `json:"loaded"`  // 重建后匹配器中的词数
+}
+
+// ContentReviewVO 内容审核队列中的一条记录
+type ContentReviewVO struct {
+	ID           uint32        `json:"id"`
+	TargetType   string        `json:"targetType"` // post/comment/review/user
+	TargetID     uint32        `json:"targetId"`
+	Author       *AuthorInfoVO `json:"author,omitempty"`
+	Content      string        `json:"content"`
+	MatchedWords []string      `json:"matchedWords"`
+	Categories   []string      `json:"categories"`
+	Status       uint8         `json:"status"` // 0待审核 1通过 2驳回
+	ReviewerID   *uint32       `json:"reviewerId,omitempty"`
+	ReviewedAt   *time.Time    `json:"reviewedAt,omitempty"`
+	CreatedAt    time.Time     `json:"createdAt"`
+}
+
+// ContentReviewListVO 内容审核队列分页列表
+type ContentReviewListVO struct {
+	Items       []ContentReviewVO `json:"items"`
+	Total       int64             `json:"total"`
+	CurrentPage int               `json:"currentPage"`
+	PageSize    int               `json:"pageSize"`
+}
+
+// ReportVO 举报提交结果
+type ReportVO struct {
+	ID         uint32    `json:"id"`
+	TargetType string    `json:"targetType"`
+	TargetID   uint32    `json:"targetId"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"` // 重复举报时为最近一次修改理由的时间
+}
+
+// ReportCaseVO 举报处理队列中的一张举报单
+type ReportCaseVO struct {
+	ID             uint32         `json:"id"`
+	TargetType     string         `json:"targetType"`
+	TargetID       uint32         `json:"targetId"`
+	TargetAuthor   *AuthorInfoVO  `json:"targetAuthor,omitempty"`
+	Excerpt        string         `json:"excerpt"`  // 被举报内容的摘要，内容已删除时为空
+	Severity       uint8          `json:"severity"` // 举报理由的最高严重程度 1-5
+	ReportCount    uint           `json:"reportCount"`
+	Reasons        map[string]int `json:"reasons"` // 各举报理由的人数
+	AutoHidden     bool           `json:"autoHidden"`
+	Status         string         `json:"status"` // open/resolved
+	Action         string         `json:"action,omitempty"`
+	ResolutionNote string         `json:"resolutionNote,omitempty"`
+	ResolverID     *uint32        `json:"resolverId,omitempty"`
+	ResolvedAt     *time.Time     `json:"resolvedAt,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+// ReportCaseListVO 举报处理队列分页列表
+type ReportCaseListVO struct {
+	Items       []ReportCaseVO `json:"items"`
+	Total       int64          `json:"total"`
+	CurrentPage int            `json:"currentPage"`
+	PageSize    int            `json:"pageSize"`
+}
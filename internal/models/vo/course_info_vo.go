@@ -20,6 +20,8 @@ type CourseInfoVO struct {
 	CourseTime    string       `json:"courseTime,omitempty"` // 保留用于简单展示
 	AverageRating float32      `json:"averageRating,omitempty"`
 	ReviewCount   uint32       `json:"reviewCount,omitempty"`
+	AuditPolicy   string       `json:"auditPolicy,omitempty"` // 旁听态度：welcome/neutral/closed
+	AuditorScore  float32      `json:"auditorScore"`          // 蹭课友好度 0-100
 }
 
 // CourseDetailVO 对应前端的 CourseDetail 接口 (课程详情)
@@ -40,15 +42,273 @@ type CourseDetailVO struct {
 	CourseTime    string       `json:"courseTime,omitempty"`
 	AverageRating float32      `json:"rating,omitempty"`
 	ReviewCount   uint         `json:"reviewCount,omitempty"`
+	AuditPolicy   string       `json:"auditPolicy"`
+	AuditorScore  float32      `json:"auditorScore"`
+	Exams         []ExamVO     `json:"exams"`
 }
 
 // CourseReviewInfoVO 课程评价信息VO
 type CourseReviewInfoVO struct {
-	ID           uint32    `json:"id"`
-	CourseID     uint32    `json:"courseId"`
-	Rating       int       `json:"rating"`
-	Comment      string    `json:"comment"`
-	ReviewerName string    `json:"reviewerName,omitempty"`
-	UserID       uint32    `json:"-"`
+	ID               uint32    `json:"id"`
+	CourseID         uint32    `json:"courseId"`
+	Rating           int       `json:"rating"`
+	Comment          string    `json:"comment"`
+	ReviewerName     string    `json:"reviewerName,omitempty"`
+	UserID           uint32    `json:"-"`
+	CreatedAt        time.Time `json:"createdAt"`
+	ModerationStatus uint8     `json:"moderationStatus,omitempty"` // 1 待审核，暂不在评价列表中展示
+}
+
+// CourseCardVO 课程卡片，用于帖子中展示关联课程、收藏列表等
+type CourseCardVO struct {
+	ID            uint32  `json:"id"`
+	CourseName    string  `json:"courseName"`
+	CourseCode    string  `json:"courseCode,omitempty"`
+	TeacherName   string  `json:"teacherName"`
+	TeacherTitle  string  `json:"teacherTitle"`
+	Faculty       string  `json:"faculty"`
+	CourseType    string  `json:"courseType"`
+	AverageRating float32 `json:"averageRating,omitempty"`
+	ReviewCount   uint32  `json:"reviewCount,omitempty"`
+	FavoriteCount uint32  `json:"favoriteCount"`
+}
+
+// ToggleFavoriteCourseResponseDataVO 切换课程收藏状态的返回
+type ToggleFavoriteCourseResponseDataVO struct {
+	IsFavorited   bool `json:"isFavorited"`
+	FavoriteCount int  `json:"favoriteCount"`
+}
+
+// AuditPolicyProposalVO 旁听态度修改提议
+type AuditPolicyProposalVO struct {
+	ID           uint32     `json:"id"`
+	CourseID     uint32     `json:"courseId"`
+	CourseName   string     `json:"courseName"`
+	TeacherName  string     `json:"teacherName"`
+	UserID       uint32     `json:"userId"`
+	Username     string     `json:"username"`
+	Policy       string     `json:"policy"`       // 提议的旁听态度
+	CurrentValue string     `json:"currentValue"` // 课程当前的旁听态度
+	Reason       string     `json:"reason,omitempty"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+}
+
+// AuditPolicyProposalListVO 提议审核队列
+type AuditPolicyProposalListVO struct {
+	Items       []AuditPolicyProposalVO `json:"items"`
+	Total       int64                   `json:"total"`
+	CurrentPage int                     `json:"currentPage"`
+	PageSize    int                     `json:"pageSize"`
+}
+
+// CourseCheckInVO 课程打卡结果
+type CourseCheckInVO struct {
+	CourseID     uint32  `json:"courseId"`
+	CheckInDate  string  `json:"checkInDate"`
+	TodayCount   int64   `json:"todayCount"`   // 今日该课程打卡人数
+	AuditorScore float32 `json:"auditorScore"` // 更新后的蹭课友好度
+}
+
+// CourseCorrectionItemVO 勘误中的单处修改
+type CourseCorrectionItemVO struct {
+	Target     string  `json:"target"` // course 或 time
+	TimeInfoID *uint32 `json:"timeInfoId,omitempty"`
+	Field      string  `json:"field"`
+	OldValue   string  `json:"oldValue"`
+	NewValue   string  `json:"newValue"`
+}
+
+// CourseCorrectionVO 课程勘误
+type CourseCorrectionVO struct {
+	ID         uint32                   `json:"id"`
+	CourseID   uint32                   `json:"courseId"`
+	CourseName string                   `json:"courseName"`
+	CourseCode string                   `json:"courseCode"`
+	UserID     uint32                   `json:"userId"`
+	Username   string                   `json:"username"`
+	Reason     string                   `json:"reason,omitempty"`
+	Status     string                   `json:"status"`
+	ReviewNote string                   `json:"reviewNote,omitempty"` // 审核意见
+	CreatedAt  time.Time                `json:"createdAt"`
+	ReviewedAt *time.Time               `json:"reviewedAt,omitempty"`
+	Items      []CourseCorrectionItemVO `json:"items"`
+}
+
+// CourseCorrectionListVO 勘误分页列表
+type CourseCorrectionListVO struct {
+	Items       []CourseCorrectionVO `json:"items"`
+	Total       int64                `json:"total"`
+	CurrentPage int                  `json:"currentPage"`
+	PageSize    int                  `json:"pageSize"`
+}
+
+// CorrectionContributorVO 课程勘误贡献者
+type CorrectionContributorVO struct {
+	UserID        uint32 `json:"userId"`
+	Username      string `json:"username"`
+	Avatar        string `json:"avatar"`
+	ApprovedCount int64  `json:"approvedCount"` // 被采纳的勘误数
+}
+
+// OverrideApplyResultVO 重新套用数据覆盖的结果
+type OverrideApplyResultVO struct {
+	Total   int `json:"total"`
+	Applied int `json:"applied"`
+	Missed  int `json:"missed"` // 找不到对应课程或时间段的覆盖数
+}
+
+// RecommendedCourseVO 推荐课程及推荐理由
+type RecommendedCourseVO struct {
+	Course  CourseCardVO `json:"course"`
+	Score   float32      `json:"score"`
+	Reasons []string     `json:"reasons"`
+}
+
+// CourseRecommendationListVO 用户的课程推荐列表
+type CourseRecommendationListVO struct {
+	Items      []RecommendedCourseVO `json:"items"`
+	ComputedAt *time.Time            `json:"computedAt,omitempty"` // 推荐结果的计算时间
+	Pending    bool                  `json:"pending"`              // 为 true 表示尚未计算，已在后台开始计算
+}
+
+// CourseSectionVO 同一课程下的一个教学班
+type CourseSectionVO struct {
+	CourseCardVO
+	Credits      float32      `json:"credits"`
+	TimeSlots    []TimeSlotVO `json:"timeSlots"`
+	Rooms        []string     `json:"rooms"` // 上课地点（教学楼 + 教室）
+	AuditPolicy  string       `json:"auditPolicy"`
+	AuditorScore float32      `json:"auditorScore"`
+}
+
+// CourseSectionsVO 同一课程编号下的所有教学班
+type CourseSectionsVO struct {
+	CourseNum    string            `json:"courseNum"`
+	CourseName   string            `json:"courseName"`
+	SectionCount int               `json:"sectionCount"`
+	Sections     []CourseSectionVO `json:"sections"`
+}
+
+// CourseComparisonItemVO 对比中的一个教学班
+type CourseComparisonItemVO struct {
+	CourseSectionVO
+	RatingDistribution []int     `json:"ratingDistribution"` // 1-5 分各自的评价数，下标 0 对应 1 分
+	RatingShare        []float32 `json:"ratingShare"`        // 1-5 分各自的占比
+}
+
+// CourseComparisonVO 教学班并排对比
+type CourseComparisonVO struct {
+	Items           []CourseComparisonItemVO `json:"items"`
+	DifferingFields []string                 `json:"differingFields"` // 各教学班取值不同的字段
+}
+
+// FacultyVO 学院及其课程统计
+type FacultyVO struct {
+	ID            uint32  `json:"id"`
+	Name          string  `json:"name"`
+	CourseCount   int64   `json:"courseCount"`  // 课程数（按课程编号去重）
+	SectionCount  int64   `json:"sectionCount"` // 教学班数
+	ReviewCount   int64   `json:"reviewCount"`
+	AverageRating float32 `json:"averageRating"`
+	MajorCount    int64   `json:"majorCount"`
+}
+
+// MajorVO 专业及其课程统计
+type MajorVO struct {
+	ID            uint32  `json:"id"`
+	FacultyID     uint32  `json:"facultyId"`
+	Name          string  `json:"name"`
+	CourseCount   int64   `json:"courseCount"`
+	SectionCount  int64   `json:"sectionCount"`
+	ReviewCount   int64   `json:"reviewCount"`
+	AverageRating float32 `json:"averageRating"`
+}
+
+// CatalogCoursePageVO 按学院/专业浏览的课程分页结果
+type CatalogCoursePageVO struct {
+	Items       []CourseCardVO `json:"items"`
+	Total       int64          `json:"total"`
+	CurrentPage int            `json:"currentPage"`
+	PageSize    int            `json:"pageSize"`
+	HasMore     bool           `json:"hasMore"`
+}
+
+// ExamVO 考试安排
+type ExamVO struct {
+	ID         uint32 `json:"id"`
+	CourseID   uint32 `json:"courseId"`
+	CourseName string `json:"courseName"`
+	ExamType   string `json:"examType"`
+	ExamDate   string `json:"examDate"`
+	StartTime  string `json:"startTime"`
+	EndTime    string `json:"endTime"`
+	Area       uint8  `json:"area"`
+	Building   string `json:"building"`
+	Classroom  string `json:"classroom"`
+	RoomID     string `json:"roomId"`
+	SeatNote   string `json:"seatNote,omitempty"`
+}
+
+// ExamImportSkipVO 导入时跳过的一条考试安排
+type ExamImportSkipVO struct {
+	Index  int    `json:"index"` // 在请求 exams 数组中的下标
+	Reason string `json:"reason"`
+}
+
+// ExamImportResultVO 考试安排导入结果
+type ExamImportResultVO struct {
+	Imported int                `json:"imported"` // 写入的考试安排条数（按教学班展开后）
+	Skipped  []ExamImportSkipVO `json:"skipped"`
+}
+
+// CourseMaterialVersionVO 课程资料的一个文件版本
+type CourseMaterialVersionVO struct {
+	Version      int       `json:"version"`
+	FileName     string    `json:"fileName"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	SHA256       string    `json:"sha256"`
+	Note         string    `json:"note,omitempty"`
+	UploaderName string    `json:"uploaderName"`
 	CreatedAt    time.Time `json:"createdAt"`
 }
+
+// CourseMaterialVO 课程资料
+type CourseMaterialVO struct {
+	ID             uint32                   `json:"id"`
+	CourseNum      string                   `json:"courseNum"`
+	Title          string                   `json:"title"`
+	Kind           string                   `json:"kind"` // syllabus/slides/reading/other
+	Description    string                   `json:"description,omitempty"`
+	LinkURL        string                   `json:"linkUrl,omitempty"` // 链接类资料
+	CurrentVersion int                      `json:"currentVersion"`
+	DownloadCount  uint32                   `json:"downloadCount"`
+	UploaderName   string                   `json:"uploaderName"`
+	Latest         *CourseMaterialVersionVO `json:"latest,omitempty"` // 文件类资料的最新版本
+	CreatedAt      time.Time                `json:"createdAt"`
+	UpdatedAt      time.Time                `json:"updatedAt"`
+}
+
+// TrendingCourseVO 热门课程：统计窗口内的热度及相比上一个窗口的变化
+type TrendingCourseVO struct {
+	CourseID      uint32  `json:"courseId"`
+	CourseName    string  `json:"courseName"`
+	CourseCode    string  `json:"courseCode"`
+	TeacherName   string  `json:"teacherName"`
+	Faculty       string  `json:"faculty"`
+	Divisions     []int   `json:"divisions"`     // 上课所在学部
+	Score         float64 `json:"score"`         // 本窗口热度（浏览、收藏、打卡、评价加权）
+	PreviousScore float64 `json:"previousScore"` // 上一个窗口热度
+	Delta         float64 `json:"delta"`         // 热度增量
+	GrowthRate    float64 `json:"growthRate"`    // 增长率，上一窗口热度为 0 时按 1 计算
+}
+
+// TrendingCoursesVO 热门课程列表
+type TrendingCoursesVO struct {
+	Window      string             `json:"window"` // 24h/7d
+	DivisionID  *int               `json:"divisionId,omitempty"`
+	GeneratedAt time.Time          `json:"generatedAt"`
+	Courses     []TrendingCourseVO `json:"courses"`
+}
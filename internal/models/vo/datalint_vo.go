@@ -0,0 +1,31 @@
+package vo
+
+import "time"
+
+// DataLintIssueVO 一条数据异常
+type DataLintIssueVO struct {
+	Table  string `json:"table"` // course_infos / time_infos
+	RowID  uint32 `json:"rowId"`
+	Value  string `json:"value"` // 异常字段的原始值
+	Detail string `json:"detail,omitempty"`
+}
+
+// DataLintCategoryVO 一类数据异常
+type DataLintCategoryVO struct {
+	Code       string            `json:"code"`
+	Title      string            `json:"title"`
+	Severity   string            `json:"severity"` // error/warning
+	Suggestion string            `json:"suggestion"`
+	Count      int               `json:"count"`
+	Truncated  bool              `json:"truncated"` // 条目过多时只列出前若干条
+	Issues     []DataLintIssueVO `json:"issues"`
+}
+
+// DataLintReportVO 课程数据质量报告
+type DataLintReportVO struct {
+	GeneratedAt   time.Time            `json:"generatedAt"`
+	CourseCount   int64                `json:"courseCount"`
+	TimeInfoCount int64                `json:"timeInfoCount"`
+	IssueCount    int                  `json:"issueCount"`
+	Categories    []DataLintCategoryVO `json:"categories"`
+}
@@ -61,3 +61,38 @@ type CurrentCourseTimeVO struct {
 	LessonStatus string `json:"lessonStatus"` // 节次状态描述（如"第3节"或"非上课时间"）
 	Timestamp    int64  `json:"timestamp"`    // 当前时间戳
 }
+
+// CoursesAtTimeVO 任意时刻的课表：换算后的时间信息 + 结构化课程数据
+type CoursesAtTimeVO struct {
+	Time      CurrentCourseTimeVO `json:"time"`
+	Divisions []DivisionVO        `json:"divisions"`
+}
+
+// RoomLessonVO 教室中的一节课
+type RoomLessonVO struct {
+	CourseID    uint32 `json:"courseId"`
+	CourseName  string `json:"courseName"`
+	TeacherName string `json:"teacherName"`
+	AuditPolicy string `json:"auditPolicy"`
+	WeekNum     int    `json:"weekNum"`
+	DayOfWeek   int    `json:"dayOfWeek"`
+	StartLesson int    `json:"startLesson"`
+	EndLesson   int    `json:"endLesson"`
+}
+
+// RoomStatusVO 教室实时状态
+type RoomStatusVO struct {
+	RoomID      string        `json:"roomId"`
+	Area        int           `json:"area"`
+	Building    string        `json:"building"`
+	Classroom   string        `json:"classroom"`
+	WeekNum     int           `json:"weekNum"`
+	DayOfWeek   int           `json:"dayOfWeek"`
+	LessonNum   int           `json:"lessonNum"`
+	Status      string        `json:"status"` // free/occupied
+	Current     *RoomLessonVO `json:"current"`
+	Next        *RoomLessonVO `json:"next"`
+	Headcount   int64         `json:"headcount"`   // 当前课程今天的打卡人数
+	CurrentExam *ExamVO       `json:"currentExam"` // 正在进行的考试，考试期间教室视为占用
+	TodayExams  []ExamVO      `json:"todayExams"`
+}
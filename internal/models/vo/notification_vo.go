@@ -0,0 +1,47 @@
+package vo
+
+import "time"
+
+// NotificationVO 一条站内通知，聚合通知的 Summary 形如"张三等12人赞了你的帖子《标题》"
+type NotificationVO struct {
+	ID         uint32         `json:"id"`
+	Type       string         `json:"type"`       // reply/like/collect/mention/moderation/course_update/post_publish
+	TargetType string         `json:"targetType"` // post/comment/review/user/course
+	TargetID   uint32         `json:"targetId"`
+	PostID     uint32         `json:"postId,omitempty"` // 对象所在的帖子，用于跳转
+	Summary    string         `json:"summary"`
+	Excerpt    string         `json:"excerpt,omitempty"` // 通知对象的标题或摘要
+	Message    string         `json:"message,omitempty"` // 最近一条回复的摘要、处理结果或更新说明
+	Count      uint           `json:"count"`             // 聚合的人数，课程更新为更新次数
+	Actors     []AuthorInfoVO `json:"actors"`            // 最近的几位触发用户，最新的在前
+	IsRead     bool           `json:"isRead"`
+	ReadAt     *time.Time     `json:"readAt,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"` // 最近一次聚合的时间
+}
+
+// NotificationListVO 通知分页列表
+type NotificationListVO struct {
+	Items       []NotificationVO `json:"items"`
+	Total       int64            `json:"total"`
+	Unread      int64            `json:"unread"`
+	CurrentPage int              `json:"currentPage"`
+	PageSize    int              `json:"pageSize"`
+}
+
+// NotificationUnreadVO 未读通知数
+type NotificationUnreadVO struct {
+	Total  int64            `json:"total"`
+	ByType map[string]int64 `json:"byType"`
+}
+
+// NotificationReadAllVO 全部标记已读的结果
+type NotificationReadAllVO struct {
+	Updated int64 `json:"updated"`
+}
+
+// NotificationPreferenceVO 一种通知类型的开关
+type NotificationPreferenceVO struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
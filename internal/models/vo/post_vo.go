@@ -58,31 +58,46 @@ type UserSimpleVO struct {
 
 // PostVO 对应前端的 Post 类型，用于API响应
 type PostVO struct {
-	ID                       uint32       `json:"id"`
-	Title                    string       `json:"title"`
-	Content                  string       `json:"content"` // 后端通常会做XSS清理
-	Author                   AuthorInfoVO `json:"author"`
-	CreatedAt                time.Time    `json:"createdAt"`
-	UpdatedAt                *time.Time   `json:"updatedAt,omitempty"` // 指针表示可选
-	Tags                     []string     `json:"tags,omitempty"`
-	Category                 *string      `json:"category,omitempty"`
-	ViewCount                *int         `json:"viewCount,omitempty"`
-	LikesCount               *int         `json:"likesCount,omitempty"`
-	CollectCount             *int         `json:"collectCount,omitempty"`
-	CommentsCount            *int         `json:"commentsCount,omitempty"`
-	IsPublished              *bool        `json:"isPublished,omitempty"`
-	IsPinned                 *bool        `json:"isPinned,omitempty"`
-	IsLocked                 *bool        `json:"isLocked,omitempty"`
-	IsLikedByCurrentUser     *bool        `json:"isLikedByCurrentUser,omitempty"`     // 当前用户是否点赞
-	IsCollectedByCurrentUser *bool        `json:"isCollectedByCurrentUser,omitempty"` // 当前用户是否收藏
+	ID                       uint32           `json:"id"`
+	Title                    string           `json:"title"`
+	Content                  string           `json:"content"` // 后端通常会做XSS清理
+	Author                   AuthorInfoVO     `json:"author"`
+	CreatedAt                time.Time        `json:"createdAt"`
+	UpdatedAt                *time.Time       `json:"updatedAt,omitempty"` // 指针表示可选
+	Tags                     []string         `json:"tags,omitempty"`
+	Category                 *string          `json:"category,omitempty"`
+	ViewCount                *int             `json:"viewCount,omitempty"`
+	LikesCount               *int             `json:"likesCount,omitempty"`
+	CollectCount             *int             `json:"collectCount,omitempty"`
+	CommentsCount            *int             `json:"commentsCount,omitempty"`
+	IsPublished              *bool            `json:"isPublished,omitempty"`
+	PublishAt                *time.Time       `json:"publishAt,omitempty"`   // 草稿的定时发布时间
+	IsPinned                 *bool            `json:"isPinned,omitempty"`    // 置顶已过期时为 false
+	PinScope                 string           `json:"pinScope,omitempty"`    // 置顶范围 global/category
+	PinnedUntil              *time.Time       `json:"pinnedUntil,omitempty"` // 置顶到期时间
+	IsLocked                 *bool            `json:"isLocked,omitempty"`
+	IsFeatured               bool             `json:"isFeatured"`
+	ModerationStatus         uint8            `json:"moderationStatus,omitempty"`         // 1 待审核，仅作者可见；2 已被隐藏
+	IsLikedByCurrentUser     *bool            `json:"isLikedByCurrentUser,omitempty"`     // 当前用户是否点赞
+	IsCollectedByCurrentUser *bool            `json:"isCollectedByCurrentUser,omitempty"` // 当前用户是否收藏
+	Courses                  []CourseCardVO   `json:"courses,omitempty"`                  // 帖子关联的课程卡片
+	Highlight                *PostHighlightVO `json:"highlight,omitempty"`                // 搜索时命中关键词的高亮
+}
+
+// PostHighlightVO 搜索结果中高亮的标题与内容摘要，命中词以 <mark> 包裹，其余内容已做 HTML 转义
+type PostHighlightVO struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
 }
 
 // GetPostsResponseDataVO 对应前端 GetPostsResponseData
 type GetPostsResponseDataVO struct {
 	Items       []PostVO `json:"items"`
-	Total       int64    `json:"total"` // 通常总数用 int64
+	Total       *int64   `json:"total,omitempty"` // 通常总数用 int64
 	CurrentPage *int     `json:"currentPage,omitempty"`
 	PageSize    *int     `json:"pageSize,omitempty"`
+	NextCursor  string   `json:"nextCursor,omitempty"` // 下一页的游标，没有更多数据时为空
+	HasMore     bool     `json:"hasMore"`
 }
 
 // ToggleLikeResponseDataVO 对应前端 ToggleLikeResponseData
@@ -118,3 +133,34 @@ type CommunityOverviewVO struct {
 	TodayCourses         int   `json:"todayCourses"`         // 今日课程总数
 	TodayPosts           int64 `json:"todayPosts"`           // 今日帖子总数
 }
+
+// TagVO 标签
+type TagVO struct {
+	ID         uint32 `json:"id"`
+	Name       string `json:"name"`
+	UsageCount uint32 `json:"usageCount"` // 使用该标签的帖子数
+}
+
+// TrendingTagVO 热门标签
+type TrendingTagVO struct {
+	TagVO
+	RecentCount int64 `json:"recentCount"` // 统计窗口内新增的使用次数
+}
+
+// TagDetailVO 标签页信息
+type TagDetailVO struct {
+	TagVO
+	RelatedTags []TagVO `json:"relatedTags"` // 经常一起出现的标签
+}
+
+// TagMergeResultVO 合并同义标签的结果
+type TagMergeResultVO struct {
+	Target        TagVO `json:"target"`
+	MergedCount   int   `json:"mergedCount"`   // 被合并的标签数
+	AffectedPosts int   `json:"affectedPosts"` // 标签被改写的帖子数
+}
+
+// CounterReconcileVO 计数对账结果
+type CounterReconcileVO struct {
+	Fixed map[string]int64 `json:"fixed"` // 各计数列本次修正的行数，如 post.likes、user.likes_received
+}
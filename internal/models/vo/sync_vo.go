@@ -0,0 +1,53 @@
+package vo
+
+import "time"
+
+// SyncSlotVO 离线同步中的一个上课时间段，保留原始的周次/节次位编码
+type SyncSlotVO struct {
+	DayOfWeek   uint8  `json:"dayOfWeek"`   // 0-6，0 表示周日
+	WeekAndTime uint32 `json:"weekAndTime"` // 高 19 位为周次，低 13 位为节次
+	Area        uint8  `json:"area"`
+	Building    string `json:"building"`
+	Classroom   string `json:"classroom"`
+}
+
+// SyncSectionVO 离线同步中的一个教学班
+type SyncSectionVO struct {
+	ID           uint32       `json:"id"`
+	CourseNum    string       `json:"courseNum"`
+	CourseName   string       `json:"courseName"`
+	TeacherName  string       `json:"teacherName"`
+	TeacherTitle string       `json:"teacherTitle"`
+	Faculty      string       `json:"faculty"`
+	Major        string       `json:"major"`
+	Grade        string       `json:"grade"`
+	Credits      float32      `json:"credits"`
+	CourseType   string       `json:"courseType"`
+	AuditPolicy  string       `json:"auditPolicy"`
+	Slots        []SyncSlotVO `json:"slots"`
+}
+
+// SyncSnapshotVO 全量快照，接口返回时整体 gzip 压缩
+type SyncSnapshotVO struct {
+	Version     uint64          `json:"version"`
+	Years       string          `json:"years"`
+	Semester    string          `json:"semester"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Sections    []SyncSectionVO `json:"sections"`
+}
+
+// SyncChangesVO 自某个版本以来的增量变化
+type SyncChangesVO struct {
+	Since    uint64          `json:"since"`
+	Version  uint64          `json:"version"` // 当前最新版本，客户端下次以此为 since
+	Upserted []SyncSectionVO `json:"upserted"`
+	Deleted  []uint32        `json:"deleted"`
+}
+
+// SyncRefreshResultVO 一次同步检查的结果
+type SyncRefreshResultVO struct {
+	Version  uint64 `json:"version"`
+	Changed  bool   `json:"changed"` // 是否产生了新版本
+	Upserted int    `json:"upserted"`
+	Deleted  int    `json:"deleted"`
+}
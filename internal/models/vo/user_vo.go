@@ -14,6 +14,12 @@ type UserProfileVO struct {
 	CreatedAt time.Time `json:"createdAt"`
 	Avatar    string    `json:"avatar,omitempty"`
 	Bio       string    `json:"bio,omitempty"`
+
+	FacultyID   *uint32 `json:"facultyId,omitempty"`
+	FacultyName string  `json:"facultyName,omitempty"`
+	MajorID     *uint32 `json:"majorId,omitempty"`
+	MajorName   string  `json:"majorName,omitempty"`
+	Grade       string  `json:"grade,omitempty"`
 }
 
 // ExtendedUserProfileVO 在 UserProfileVO 基础上增加统计字段，返回给前端
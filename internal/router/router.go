@@ -6,6 +6,7 @@ import (
 	"cengkeHelperBackGo/internal/handlers/auth"
 	"cengkeHelperBackGo/internal/handlers/chat"
 	"cengkeHelperBackGo/internal/handlers/course"
+	"expvar"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -17,8 +18,14 @@ var app *gin.Engine
 func Routers() *gin.Engine {
 	postHandler := handlers.NewPostHandler()
 	commentHandler := handlers.NewCommentHandler()
+	tagHandler := handlers.NewTagHandler()
+	counterHandler := handlers.NewCounterHandler()
+	contentFilterHandler := handlers.NewContentFilterHandler()
+	reportHandler := handlers.NewReportHandler()
+	notificationHandler := handlers.NewNotificationHandler()
 	courseHandler := course.NewCourseHandler()
 	chatHandler := chat.NewChatHandler()
+	optionalAuth := filter.OptionalUserAuth() // 公开接口中需要识别当前用户的部分
 	v1 := app.Group("/api/v1")
 	{
 		v1.GET("/ping", handlers.PingHandler)
@@ -28,31 +35,81 @@ func Routers() *gin.Engine {
 		v1.POST("/auth/send-email-code", auth.SendEmailCodeHandler) // 添加发送验证码接口
 		v1.GET("/courses", courseHandler.GetCoursesHandler)
 		v1.GET("/all", courseHandler.GetAllCoursesHandler)
-		v1.GET("/courses/current-time", courseHandler.GetCurrentCourseTimeHandler) // 新增：获取当前课程时间
-		v1.GET("/courses/structured", courseHandler.GetStructuredCoursesHandler)   // 新增：获取结构化课程数据
-		v1.GET("/courses/:courseId", courseHandler.GetCourseDetailHandler)
-		v1.GET("/posts/comments/:postId", commentHandler.GetCommentsByPostID) // GET /api/v1/posts/:id/comments (获取帖子的评论)
-		v1.GET("/posts", postHandler.GetPosts)
+		v1.GET("/all/pages", courseHandler.GetAllCoursesPageHandler)
+		v1.GET("/courses/current-time", courseHandler.GetCurrentCourseTimeHandler)        // 新增：获取当前课程时间
+		v1.GET("/courses/structured", courseHandler.GetStructuredCoursesHandler)          // 新增：获取结构化课程数据
+		v1.GET("/courses/at", courseHandler.GetCoursesAtHandler)                          // 获取任意时刻的结构化课表
+		v1.GET("/courses/compare", courseHandler.CompareCoursesHandler)                   // 教学班并排对比
+		v1.GET("/courses/trending", courseHandler.GetTrendingCoursesHandler)              // 热门课程
+		v1.GET("/courses/by-num/:courseNum", courseHandler.GetSectionsByCourseNumHandler) // 同一课程的所有教学班
+		v1.GET("/courses/:courseId", optionalAuth, courseHandler.GetCourseDetailHandler)
+		v1.GET("/courses/:courseId/posts", optionalAuth, postHandler.GetPostsByCourse)        // 课程讨论区
+		v1.GET("/courses/:courseId/contributors", courseHandler.GetCourseContributorsHandler) // 课程勘误贡献者
+		v1.GET("/courses/:courseId/exams", courseHandler.GetCourseExamsHandler)               // 考试安排
+		v1.GET("/courses/:courseId/exams.ics", courseHandler.GetCourseExamsICSHandler)
+		v1.GET("/courses/:courseId/materials", courseHandler.GetCourseMaterialsHandler) // 课程资料
+		v1.GET("/materials/:materialId/versions", courseHandler.GetCourseMaterialVersionsHandler)
+		v1.GET("/materials/:materialId/download", courseHandler.DownloadCourseMaterialHandler)
+		v1.GET("/faculties", courseHandler.GetFacultiesHandler)
+		v1.GET("/faculties/:facultyId/majors", courseHandler.GetFacultyMajorsHandler)
+		v1.GET("/faculties/:facultyId/courses", courseHandler.GetFacultyCoursesHandler)
+		v1.GET("/majors/:majorId/courses", courseHandler.GetMajorCoursesHandler)
+		v1.GET("/rooms/:roomId/schedule.svg", courseHandler.GetRoomScheduleSVGHandler)      // 教室周课表图片
+		v1.GET("/rooms/:roomId/now", courseHandler.GetRoomStatusHandler)                    // 教室实时状态（门牌二维码落地页）
+		v1.GET("/sync/courses/snapshot", courseHandler.GetSyncSnapshotHandler)              // 离线课表全量快照
+		v1.GET("/sync/courses/changes", courseHandler.GetSyncChangesHandler)                // 离线课表增量
+		v1.GET("/posts/comments/:postId", optionalAuth, commentHandler.GetCommentsByPostID) // GET /api/v1/posts/:id/comments (获取帖子的评论)
+		v1.GET("/posts", optionalAuth, postHandler.GetPosts)
 		v1.GET("/posts/active-users", postHandler.GetActiveUsersHandler)
 		v1.GET("/community/stats", postHandler.GetCommunityStatsHandler)
 		v1.GET("/community/overview", postHandler.GetCommunityOverviewHandler)
-		v1.GET("/posts/:id", postHandler.GetPostByID)
+		v1.GET("/posts/:id", optionalAuth, postHandler.GetPostByID)
 		v1.GET("/courses/reviews/:courseId", courseHandler.GetCourseReviewsHandler)
+		v1.GET("/tags/trending", tagHandler.GetTrendingTagsHandler) // 热门标签
+		v1.GET("/tags/suggest", tagHandler.SuggestTagsHandler)      // 标签自动补全
+		v1.GET("/tags/:tagName", tagHandler.GetTagDetailHandler)    // 标签页
 		v1.POST("/chat/stream", chatHandler.ChatStreamHandler)
 		v1.Use(filter.UserAuthChecker())
+		// 站内通知，被封禁的用户也需要查看处理结果并标记已读
+		notifications := v1.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.GetNotificationsHandler)
+			notifications.GET("/unread-count", notificationHandler.GetUnreadNotificationCountHandler)
+			notifications.POST("/read-all", notificationHandler.MarkAllNotificationsReadHandler)
+			notifications.POST("/:notificationId/read", notificationHandler.MarkNotificationReadHandler)
+			notifications.GET("/preferences", notificationHandler.GetNotificationPreferencesHandler)
+			notifications.PUT("/preferences", notificationHandler.UpdateNotificationPreferencesHandler)
+		}
+		v1.Use(filter.BannedUserChecker()) // 被封禁的用户只能浏览
 
 		v1.GET("/users/echo", handlers.UserEchoHandler)
+		v1.POST("/reports", reportHandler.CreateReportHandler) // 举报帖子、评论、课程评价或用户
 		v1.GET("/users/profile", handlers.UserProfileHandler)
 		v1.PUT("/users/profile", handlers.UpdateUserProfileHandler)
+		v1.GET("/users/favorite-courses", courseHandler.GetFavoriteCoursesHandler)
+		v1.GET("/users/corrections", courseHandler.GetMyCorrectionsHandler)
+		v1.GET("/users/me/major-courses", courseHandler.GetMyMajorAuditableCoursesHandler) // 我的专业里可以蹭的课
+		v1.GET("/users/me/schedule.svg", courseHandler.GetMyScheduleSVGHandler)            // 个人课表图片
+		v1.GET("/users/me/exams", courseHandler.GetMyExamsHandler)                         // 我的考试安排
+		v1.GET("/users/me/exams.ics", courseHandler.GetMyExamsICSHandler)
+		v1.POST("/materials/:materialId/versions", courseHandler.AddCourseMaterialVersionHandler) // 上传资料新版本
 		courses := v1.Group("/courses")
 		{
 
 			courses.POST("/reviews", courseHandler.SubmitCourseReviewHandler)
+			courses.GET("/recommendations", courseHandler.GetRecommendationsHandler) // 个性化课程推荐
+			courses.POST("/:courseId/toggle-favorite", courseHandler.ToggleFavoriteCourseHandler)
+			courses.POST("/:courseId/audit-policy/proposals", courseHandler.SubmitAuditPolicyProposalHandler) // 提议修改旁听态度
+			courses.POST("/:courseId/check-in", courseHandler.CheckInCourseHandler)
+			courses.POST("/:courseId/corrections", courseHandler.SubmitCourseCorrectionHandler) // 提交课程勘误
+			courses.POST("/:courseId/materials", courseHandler.CreateCourseMaterialHandler)     // 上传课程资料
 
 		}
 		posts := v1.Group("/posts") // 应用用户认证中间件
 		{
 
+			posts.GET("/drafts", postHandler.GetDraftsHandler)               // GET /api/v1/posts/drafts (我的草稿)
+			posts.POST("/:id/publish", postHandler.PublishPostHandler)       // POST /api/v1/posts/:id/publish (发布或定时发布草稿)
 			posts.POST("", postHandler.CreatePost)                           // POST /api/v1/posts (创建帖子)
 			posts.PUT("/:id", postHandler.UpdatePost)                        // PUT /api/v1/posts/:id (更新帖子)
 			posts.DELETE("/:id", postHandler.DeletePost)                     // DELETE /api/v1/posts/:id (删除帖子)
@@ -68,8 +125,50 @@ func Routers() *gin.Engine {
 			comments.POST("/:commentId/toggle-like", commentHandler.ToggleLikeComment) // POST /api/v1/posts/:id/toggle-collect
 		} // 应用用户认证中间件
 
+		// 帖子管理，版主和管理员可用
+		moderation := v1.Group("/moderation", filter.ModeratorAuthChecker())
+		{
+			moderation.POST("/posts/:id/pin", postHandler.PinPostHandler)
+			moderation.DELETE("/posts/:id/pin", postHandler.UnpinPostHandler)
+			moderation.POST("/posts/:id/lock", postHandler.LockPostHandler)
+			moderation.DELETE("/posts/:id/lock", postHandler.UnlockPostHandler)
+			moderation.POST("/posts/:id/feature", postHandler.FeaturePostHandler)
+			moderation.DELETE("/posts/:id/feature", postHandler.UnfeaturePostHandler)
+			moderation.POST("/posts/:id/move", postHandler.MovePostHandler)
+			moderation.GET("/content-reviews", contentFilterHandler.GetContentReviewsHandler) // 敏感词审核队列
+			moderation.POST("/content-reviews/:reviewId/approve", contentFilterHandler.ApproveContentReviewHandler)
+			moderation.POST("/content-reviews/:reviewId/reject", contentFilterHandler.RejectContentReviewHandler)
+			moderation.GET("/reports", reportHandler.GetReportCasesHandler) // 举报处理队列
+			moderation.POST("/reports/:caseId/resolve", reportHandler.ResolveReportCaseHandler)
+		}
+
 		v1.Use(filter.AdminAuthChecker())
 		v1.GET("/admins/echo", handlers.AdminEchoHandler)
+		v1.PUT("/admins/users/:userId/role", handlers.SetUserRoleHandler) // 设置管理员、版主
+		v1.PUT("/admins/courses/:courseId/audit-policy", courseHandler.SetAuditPolicyHandler)
+		v1.GET("/admins/audit-policy/proposals", courseHandler.GetAuditPolicyProposalsHandler)
+		v1.POST("/admins/audit-policy/proposals/:proposalId/approve", courseHandler.ApproveAuditPolicyProposalHandler)
+		v1.POST("/admins/audit-policy/proposals/:proposalId/reject", courseHandler.RejectAuditPolicyProposalHandler)
+		v1.GET("/admins/corrections", courseHandler.GetCorrectionsHandler)
+		v1.POST("/admins/corrections/:correctionId/approve", courseHandler.ApproveCorrectionHandler)
+		v1.POST("/admins/corrections/:correctionId/reject", courseHandler.RejectCorrectionHandler)
+		v1.POST("/admins/course-overrides/apply", courseHandler.ApplyCourseOverridesHandler) // 导入教务数据后重新套用勘误
+		v1.POST("/admins/recommendations/rebuild", courseHandler.RebuildRecommendationsHandler)
+		v1.POST("/admins/catalog/rebuild", courseHandler.RebuildCatalogHandler)
+		v1.POST("/admins/sync/courses/refresh", courseHandler.RefreshSyncVersionHandler)             // 导入教务数据后推进同步版本
+		v1.GET("/admins/rooms/:roomId/qrcode", courseHandler.GetRoomQRCodeHandler)                   // 教室门牌二维码
+		v1.GET("/admins/buildings/:buildingId/qrcodes.zip", courseHandler.GetBuildingQRCodesHandler) // 整栋楼门牌二维码打包
+		v1.POST("/admins/data-lint", courseHandler.RunDataLintHandler)                               // 课程数据质量检查
+		v1.GET("/admins/data-lint/latest", courseHandler.GetLatestDataLintHandler)
+		v1.POST("/admins/exams/import", courseHandler.ImportExamsHandler)                     // 导入考试安排
+		v1.POST("/admins/tags/merge", tagHandler.MergeTagsHandler)                            // 合并同义标签
+		v1.DELETE("/admins/materials/:materialId", courseHandler.DeleteCourseMaterialHandler) // 删除课程资料
+		v1.POST("/admins/counters/reconcile", counterHandler.ReconcileCountersHandler)        // 计数对账
+		v1.GET("/admins/metrics", gin.WrapH(expvar.Handler()))                                // 运行指标，含计数对账修正数
+		v1.GET("/admins/sensitive-words", contentFilterHandler.GetSensitiveWordsHandler)      // 敏感词库
+		v1.POST("/admins/sensitive-words", contentFilterHandler.ImportSensitiveWordsHandler)
+		v1.PUT("/admins/sensitive-words/:wordId", contentFilterHandler.UpdateSensitiveWordHandler)
+		v1.DELETE("/admins/sensitive-words/:wordId", contentFilterHandler.DeleteSensitiveWordHandler)
 
 	}
 	return app
@@ -90,4 +189,7 @@ func init() {
 		},
 		MaxAge: 12 * time.Hour,
 	}))
+
+	// 管理员/测试环境可通过 X-Clock-Now 覆盖当前时间
+	app.Use(filter.ClockOverride())
 }
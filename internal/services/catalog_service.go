@@ -0,0 +1,473 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/textnorm"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CatalogService 学院/专业归一化目录与按学院、专业浏览课程
+type CatalogService struct{}
+
+// NewCatalogService 创建 CatalogService 实例
+func NewCatalogService() *CatalogService {
+	return &CatalogService{}
+}
+
+// CatalogCourseQuery 按学院/专业浏览课程的查询参数
+type CatalogCourseQuery struct {
+	Page   int
+	Limit  int
+	SortBy string // rating（默认）/ auditorScore / name
+	Grade  string // 仅专业课程列表使用，按 course_infos.grade 过滤
+}
+
+// catalogRawStats course_infos 按原始写法分组后的统计
+type catalogRawStats struct {
+	Faculty      string
+	Major        string
+	SectionCount int64
+	CourseCount  int64
+	ReviewCount  int64
+	RatingSum    float64
+}
+
+// catalogAggregate 归一化后同一学院/专业的累计统计
+type catalogAggregate struct {
+	displayName  string
+	displayCount int64
+	stats        catalogRawStats
+	raws         []catalogRawStats
+}
+
+func (a *catalogAggregate) add(raw catalogRawStats, display string) {
+	if raw.SectionCount > a.displayCount {
+		a.displayName, a.displayCount = display, raw.SectionCount
+	}
+	a.stats.SectionCount += raw.SectionCount
+	a.stats.CourseCount += raw.CourseCount
+	a.stats.ReviewCount += raw.ReviewCount
+	a.stats.RatingSum += raw.RatingSum
+	a.raws = append(a.raws, raw)
+}
+
+func (a *catalogAggregate) averageRating() float32 {
+	if a.stats.ReviewCount == 0 {
+		return 0
+	}
+	return float32(a.stats.RatingSum / float64(a.stats.ReviewCount))
+}
+
+// Rebuild 根据 course_infos 重建学院/专业目录与别名映射，并刷新统计；导入教务数据后应调用
+func (s *CatalogService) Rebuild(ctx context.Context) error {
+	db := database.Client.WithContext(ctx)
+
+	var facultyRows []catalogRawStats
+	if err := db.Model(&dto.CourseInfo{}).
+		Select("faculty, COUNT(*) AS section_count, COUNT(DISTINCT course_num) AS course_count, " +
+			"COALESCE(SUM(review_count), 0) AS review_count, COALESCE(SUM(average_rating * review_count), 0) AS rating_sum").
+		Group("faculty").
+		Scan(&facultyRows).Error; err != nil {
+		return fmt.Errorf("统计学院数据失败: %w", err)
+	}
+
+	var majorRows []catalogRawStats
+	if err := db.Model(&dto.CourseInfo{}).
+		Select("faculty, major, COUNT(*) AS section_count, COUNT(DISTINCT course_num) AS course_count, " +
+			"COALESCE(SUM(review_count), 0) AS review_count, COALESCE(SUM(average_rating * review_count), 0) AS rating_sum").
+		Group("faculty, major").
+		Scan(&majorRows).Error; err != nil {
+		return fmt.Errorf("统计专业数据失败: %w", err)
+	}
+
+	faculties := make(map[string]*catalogAggregate)
+	for _, row := range facultyRows {
+		key := textnorm.Key(row.Faculty)
+		if key == "" {
+			continue
+		}
+		if faculties[key] == nil {
+			faculties[key] = &catalogAggregate{}
+		}
+		faculties[key].add(row, textnorm.Display(row.Faculty))
+	}
+
+	// 专业按（归一化学院，归一化专业）合并
+	majors := make(map[[2]string]*catalogAggregate)
+	for _, row := range majorRows {
+		facultyKey, majorKey := textnorm.Key(row.Faculty), textnorm.Key(row.Major)
+		if facultyKey == "" || majorKey == "" {
+			continue
+		}
+		key := [2]string{facultyKey, majorKey}
+		if majors[key] == nil {
+			majors[key] = &catalogAggregate{}
+		}
+		majors[key].add(row, textnorm.Display(row.Major))
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// 已不存在于课程数据中的学院/专业保留记录（用户资料可能引用），统计清零
+		if err := tx.Model(&dto.Faculty{}).Where("1 = 1").Updates(map[string]interface{}{
+			"course_count": 0, "section_count": 0, "review_count": 0, "average_rating": 0,
+		}).Error; err != nil {
+			return fmt.Errorf("重置学院统计失败: %w", err)
+		}
+		if err := tx.Model(&dto.Major{}).Where("1 = 1").Updates(map[string]interface{}{
+			"course_count": 0, "section_count": 0, "review_count": 0, "average_rating": 0,
+		}).Error; err != nil {
+			return fmt.Errorf("重置专业统计失败: %w", err)
+		}
+		if err := tx.Where("1 = 1").Delete(&dto.CatalogAlias{}).Error; err != nil {
+			return fmt.Errorf("清理别名映射失败: %w", err)
+		}
+
+		statColumns := []string{"name", "course_count", "section_count", "review_count", "average_rating", "updated_at"}
+		aliases := make([]dto.CatalogAlias, 0, len(facultyRows)+len(majorRows))
+		facultyIDs := make(map[string]uint32, len(faculties))
+
+		for key, agg := range faculties {
+			faculty := dto.Faculty{
+				Name:          agg.displayName,
+				NormKey:       key,
+				CourseCount:   agg.stats.CourseCount,
+				SectionCount:  agg.stats.SectionCount,
+				ReviewCount:   agg.stats.ReviewCount,
+				AverageRating: agg.averageRating(),
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "norm_key"}},
+				DoUpdates: clause.AssignmentColumns(statColumns),
+			}).Create(&faculty).Error; err != nil {
+				return fmt.Errorf("保存学院失败: %w", err)
+			}
+			if err := tx.Select("id").Where("norm_key = ?", key).First(&faculty).Error; err != nil {
+				return fmt.Errorf("读取学院失败: %w", err)
+			}
+			facultyIDs[key] = faculty.ID
+			for _, raw := range agg.raws {
+				aliases = append(aliases, dto.CatalogAlias{Kind: dto.CatalogKindFaculty, RawValue: raw.Faculty, TargetID: faculty.ID})
+			}
+		}
+
+		for key, agg := range majors {
+			facultyID, ok := facultyIDs[key[0]]
+			if !ok {
+				continue
+			}
+			major := dto.Major{
+				FacultyID:     facultyID,
+				Name:          agg.displayName,
+				NormKey:       key[1],
+				CourseCount:   agg.stats.CourseCount,
+				SectionCount:  agg.stats.SectionCount,
+				ReviewCount:   agg.stats.ReviewCount,
+				AverageRating: agg.averageRating(),
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "faculty_id"}, {Name: "norm_key"}},
+				DoUpdates: clause.AssignmentColumns(statColumns),
+			}).Create(&major).Error; err != nil {
+				return fmt.Errorf("保存专业失败: %w", err)
+			}
+			if err := tx.Select("id").Where("faculty_id = ? AND norm_key = ?", facultyID, key[1]).First(&major).Error; err != nil {
+				return fmt.Errorf("读取专业失败: %w", err)
+			}
+			for _, raw := range agg.raws {
+				aliases = append(aliases, dto.CatalogAlias{
+					Kind:       dto.CatalogKindMajor,
+					RawValue:   raw.Major,
+					RawFaculty: raw.Faculty,
+					TargetID:   major.ID,
+				})
+			}
+		}
+
+		if len(aliases) > 0 {
+			if err := tx.CreateInBatches(aliases, 200).Error; err != nil {
+				return fmt.Errorf("保存别名映射失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ensureCatalogBuilt 目录从未构建过时同步构建一次
+func (s *CatalogService) ensureCatalogBuilt(ctx context.Context) error {
+	var count int64
+	if err := database.Client.WithContext(ctx).Model(&dto.Faculty{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("查询学院目录失败: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+	return s.Rebuild(ctx)
+}
+
+// ListFaculties 列出所有有课程的学院及统计
+func (s *CatalogService) ListFaculties(ctx context.Context) ([]vo.FacultyVO, error) {
+	if err := s.ensureCatalogBuilt(ctx); err != nil {
+		return nil, err
+	}
+
+	faculties := make([]vo.FacultyVO, 0)
+	if err := database.Client.WithContext(ctx).Model(&dto.Faculty{}).
+		Select("faculties.id, faculties.name, faculties.course_count, faculties.section_count, faculties.review_count, faculties.average_rating, " +
+			"(SELECT COUNT(*) FROM majors m WHERE m.faculty_id = faculties.id AND m.section_count > 0) AS major_count").
+		Where("faculties.section_count > 0").
+		Order("faculties.course_count DESC, faculties.id ASC").
+		Scan(&faculties).Error; err != nil {
+		return nil, fmt.Errorf("获取学院列表失败: %w", err)
+	}
+	return faculties, nil
+}
+
+// ListMajors 列出学院下所有有课程的专业
+func (s *CatalogService) ListMajors(ctx context.Context, facultyID uint32) ([]vo.MajorVO, error) {
+	if _, err := s.getFaculty(ctx, facultyID); err != nil {
+		return nil, err
+	}
+
+	var majors []dto.Major
+	if err := database.Client.WithContext(ctx).
+		Where("faculty_id = ? AND section_count > 0", facultyID).
+		Order("course_count DESC, id ASC").
+		Find(&majors).Error; err != nil {
+		return nil, fmt.Errorf("获取专业列表失败: %w", err)
+	}
+
+	result := make([]vo.MajorVO, 0, len(majors))
+	for _, m := range majors {
+		result = append(result, toMajorVO(m))
+	}
+	return result, nil
+}
+
+// GetFacultyCourses 分页获取学院的课程
+func (s *CatalogService) GetFacultyCourses(ctx context.Context, facultyID uint32, q CatalogCourseQuery) (*vo.CatalogCoursePageVO, error) {
+	if _, err := s.getFaculty(ctx, facultyID); err != nil {
+		return nil, err
+	}
+
+	db := database.Client.WithContext(ctx)
+	query := db.Model(&dto.CourseInfo{}).Where("faculty IN (?)",
+		db.Model(&dto.CatalogAlias{}).Select("raw_value").Where("kind = ? AND target_id = ?", dto.CatalogKindFaculty, facultyID))
+	return paginateCatalogCourses(query, q)
+}
+
+// GetMajorCourses 分页获取专业的课程，可按年级过滤
+func (s *CatalogService) GetMajorCourses(ctx context.Context, majorID uint32, q CatalogCourseQuery) (*vo.CatalogCoursePageVO, error) {
+	if _, err := s.getMajor(ctx, majorID); err != nil {
+		return nil, err
+	}
+
+	query, err := majorCoursesQuery(database.Client.WithContext(ctx), majorID)
+	if err != nil {
+		return nil, err
+	}
+	if q.Grade != "" {
+		query = query.Where("grade = ?", q.Grade)
+	}
+	return paginateCatalogCourses(query, q)
+}
+
+// GetAuditableCoursesInMyMajor 用户所在专业（未设置专业时用学院）中可以去蹭的课：
+// 不拒绝旁听、且不与个人课表冲突，按蹭课友好度降序
+func (s *CatalogService) GetAuditableCoursesInMyMajor(ctx context.Context, userID uint32, page, limit int) (*vo.CatalogCoursePageVO, error) {
+	db := database.Client.WithContext(ctx)
+
+	var user dto.User
+	if err := db.Select("id", "faculty_id", "major_id").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgUserNotFound)
+		}
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	var query *gorm.DB
+	switch {
+	case user.MajorID != nil:
+		q, err := majorCoursesQuery(db, *user.MajorID)
+		if err != nil {
+			return nil, err
+		}
+		query = q
+	case user.FacultyID != nil:
+		query = db.Model(&dto.CourseInfo{}).Where("faculty IN (?)",
+			db.Model(&dto.CatalogAlias{}).Select("raw_value").Where("kind = ? AND target_id = ?", dto.CatalogKindFaculty, *user.FacultyID))
+	default:
+		return nil, errors.New(config.MsgProfileMajorMissing)
+	}
+
+	var courses []dto.CourseInfo
+	if err := query.Where("audit_policy <> ?", dto.AuditPolicyClosed).
+		Order("auditor_score DESC, average_rating DESC, id ASC").
+		Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("获取专业课程失败: %w", err)
+	}
+
+	busy, err := loadUserBusySlots(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	courseIDs := make([]uint32, 0, len(courses))
+	for _, c := range courses {
+		courseIDs = append(courseIDs, c.ID)
+	}
+	timeInfos := map[uint32][]dto.TimeInfo{}
+	if len(courseIDs) > 0 {
+		if timeInfos, err = loadTimeInfosByCourseIDs(courseIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	fitting := make([]vo.CourseCardVO, 0)
+	for _, c := range courses {
+		if slots := timeInfos[c.ID]; len(slots) > 0 && busy.fits(slots) {
+			fitting = append(fitting, toCourseCardVO(c))
+		}
+	}
+
+	total := int64(len(fitting))
+	start := min((page-1)*limit, len(fitting))
+	end := min(start+limit, len(fitting))
+	return &vo.CatalogCoursePageVO{
+		Items:       fitting[start:end],
+		Total:       total,
+		CurrentPage: page,
+		PageSize:    limit,
+		HasMore:     int64(end) < total,
+	}, nil
+}
+
+// ValidateProfileCatalog 校验用户资料中的学院/专业ID；两者都提供时专业必须属于该学院
+func (s *CatalogService) ValidateProfileCatalog(ctx context.Context, facultyID, majorID *uint32) error {
+	if facultyID != nil && *facultyID != 0 {
+		if _, err := s.getFaculty(ctx, *facultyID); err != nil {
+			return err
+		}
+	}
+	if majorID != nil && *majorID != 0 {
+		major, err := s.getMajor(ctx, *majorID)
+		if err != nil {
+			return err
+		}
+		if facultyID != nil && *facultyID != 0 && major.FacultyID != *facultyID {
+			return errors.New(config.MsgMajorNotInFaculty)
+		}
+	}
+	return nil
+}
+
+// getFaculty 查询学院，不存在时返回 MsgFacultyNotFound
+func (s *CatalogService) getFaculty(ctx context.Context, facultyID uint32) (*dto.Faculty, error) {
+	var faculty dto.Faculty
+	if err := database.Client.WithContext(ctx).First(&faculty, facultyID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgFacultyNotFound)
+		}
+		return nil, fmt.Errorf("查询学院失败: %w", err)
+	}
+	return &faculty, nil
+}
+
+// getMajor 查询专业，不存在时返回 MsgMajorNotFound
+func (s *CatalogService) getMajor(ctx context.Context, majorID uint32) (*dto.Major, error) {
+	var major dto.Major
+	if err := database.Client.WithContext(ctx).First(&major, majorID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgMajorNotFound)
+		}
+		return nil, fmt.Errorf("查询专业失败: %w", err)
+	}
+	return &major, nil
+}
+
+// majorCoursesQuery 构造专业下所有课程的查询：专业原始写法需与学院原始写法成对匹配
+func majorCoursesQuery(db *gorm.DB, majorID uint32) (*gorm.DB, error) {
+	var aliases []dto.CatalogAlias
+	if err := db.Where("kind = ? AND target_id = ?", dto.CatalogKindMajor, majorID).Find(&aliases).Error; err != nil {
+		return nil, fmt.Errorf("查询专业别名失败: %w", err)
+	}
+
+	query := db.Model(&dto.CourseInfo{})
+	if len(aliases) == 0 {
+		return query.Where("1 = 0"), nil
+	}
+	pairs := make([][]interface{}, 0, len(aliases))
+	for _, a := range aliases {
+		pairs = append(pairs, []interface{}{a.RawFaculty, a.RawValue})
+	}
+	return query.Where("(faculty, major) IN ?", pairs), nil
+}
+
+// paginateCatalogCourses 对课程查询排序分页并转换为课程卡片
+func paginateCatalogCourses(query *gorm.DB, q CatalogCourseQuery) (*vo.CatalogCoursePageVO, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计课程数量失败: %w", err)
+	}
+
+	order := "average_rating DESC, review_count DESC, id ASC"
+	switch q.SortBy {
+	case "auditorScore":
+		order = "auditor_score DESC, id ASC"
+	case "name":
+		order = "course_name ASC, id ASC"
+	}
+
+	var courses []dto.CourseInfo
+	if err := query.Order(order).Offset((q.Page - 1) * q.Limit).Limit(q.Limit).Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("获取课程列表失败: %w", err)
+	}
+
+	items := make([]vo.CourseCardVO, 0, len(courses))
+	for _, c := range courses {
+		items = append(items, toCourseCardVO(c))
+	}
+	return &vo.CatalogCoursePageVO{
+		Items:       items,
+		Total:       total,
+		CurrentPage: q.Page,
+		PageSize:    q.Limit,
+		HasMore:     int64(q.Page*q.Limit) < total,
+	}, nil
+}
+
+// catalogRawFaculties 返回学院的所有原始写法
+func catalogRawFaculties(db *gorm.DB, facultyID uint32) ([]string, error) {
+	var raws []string
+	if err := db.Model(&dto.CatalogAlias{}).
+		Where("kind = ? AND target_id = ?", dto.CatalogKindFaculty, facultyID).
+		Pluck("raw_value", &raws).Error; err != nil {
+		return nil, fmt.Errorf("查询学院别名失败: %w", err)
+	}
+	return raws, nil
+}
+
+// toMajorVO 将专业模型转换为 VO
+func toMajorVO(m dto.Major) vo.MajorVO {
+	return vo.MajorVO{
+		ID:            m.ID,
+		FacultyID:     m.FacultyID,
+		Name:          m.Name,
+		CourseCount:   m.CourseCount,
+		SectionCount:  m.SectionCount,
+		ReviewCount:   m.ReviewCount,
+		AverageRating: m.AverageRating,
+	}
+}
+
+// majorAliasKey 专业原始写法在兴趣画像中的键
+func majorAliasKey(rawFaculty, rawMajor string) string {
+	return strings.Join([]string{rawFaculty, rawMajor}, "|")
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"cengkeHelperBackGo/internal/config"
 	database "cengkeHelperBackGo/internal/db" // 确保与 post_service.go 一致
 	"cengkeHelperBackGo/internal/models/dto"
 	"cengkeHelperBackGo/internal/models/vo"
@@ -8,7 +9,6 @@ import (
 	"fmt"
 	"gorm.io/gorm"
 	"log"
-	"strings"
 )
 
 type CommentService struct {
@@ -19,8 +19,51 @@ func NewCommentService() *CommentService {
 	return &CommentService{}
 }
 
-// convertCommentToVO 辅助函数：将 dto.Comment (模型) 转换为 vo.CommentVO
-func convertCommentToVO(comment dto.Comment, currentUserID *uint32, fetchChildren bool, maxDepth int, currentDepth int) (*vo.CommentVO, error) {
+// ErrCommentNotFound 评论不存在或已被删除
+var ErrCommentNotFound = errors.New("评论未找到")
+
+// commentReplyDepth 评论列表中随顶级评论一起返回的回复层数
+const commentReplyDepth = 2
+
+// loadCommentReplies 逐层批量加载评论的回复，每层一次查询，最多加载 maxDepth 层。
+// 返回按父评论ID分组的回复，以及包含 roots 在内的全部评论ID，供批量查询点赞状态
+func loadCommentReplies(db *gorm.DB, roots []dto.Comment, maxDepth int) (map[uint32][]dto.Comment, []uint32) {
+	children := make(map[uint32][]dto.Comment)
+	allIDs := make([]uint32, 0, len(roots))
+	levelIDs := make([]uint32, 0, len(roots))
+	for _, comment := range roots {
+		levelIDs = append(levelIDs, comment.ID)
+	}
+	allIDs = append(allIDs, levelIDs...)
+
+	for depth := 0; depth < maxDepth && len(levelIDs) > 0; depth++ {
+		var replies []dto.Comment
+		if err := db.Model(&dto.Comment{}).
+			Preload("Author").
+			Preload("ReplyToUser").
+			Where("parent_id IN ? AND moderation_status = ?", levelIDs, dto.ModerationVisible).
+			Order("created_at ASC, id ASC").
+			Find(&replies).Error; err != nil {
+			// 即使获取子评论失败，也继续，只是子评论列表为空
+			log.Printf("获取评论 %v 的子评论失败: %v", levelIDs, err)
+			break
+		}
+		levelIDs = nil
+		for _, reply := range replies {
+			if reply.ParentID == nil {
+				continue
+			}
+			children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+			levelIDs = append(levelIDs, reply.ID)
+		}
+		allIDs = append(allIDs, levelIDs...)
+	}
+	return children, allIDs
+}
+
+// convertCommentToVO 辅助函数：将 dto.Comment (模型) 转换为 vo.CommentVO。
+// children 为 loadCommentReplies 加载的回复，liked 为当前用户点赞过的评论ID集合，均可为 nil
+func convertCommentToVO(comment dto.Comment, children map[uint32][]dto.Comment, liked map[uint32]bool) *vo.CommentVO {
 	authorVO := vo.AuthorInfoVO{}
 	if comment.Author.Id != 0 { // 假设 Author 结构体已预加载，且 Id 是其有效性标识
 		authorVO.ID = comment.Author.Id
@@ -38,45 +81,11 @@ func convertCommentToVO(comment dto.Comment, currentUserID *uint32, fetchChildre
 	}
 
 	likesCount := int(comment.LikesCount)
-	isLiked := false
-	if currentUserID != nil && *currentUserID > 0 {
-		var likeRecordCount int64
-		// 使用 database.Client 构建查询
-		queryLike := database.Client.Model(&dto.UserCommentLike{}).Where("user_id = ? AND comment_id = ?", *currentUserID, comment.ID)
-		if err := queryLike.Count(&likeRecordCount).Error; err != nil {
-			log.Printf("检查评论 %d 的点赞状态失败 (用户 %d): %v", comment.ID, *currentUserID, err)
-			// 此处不中断，isLiked 默认为 false
-		}
-		if likeRecordCount > 0 {
-			isLiked = true
-		}
-	}
+	isLiked := liked[comment.ID]
 
 	var childrenVO []vo.CommentVO
-	if fetchChildren && currentDepth < maxDepth {
-		var childModels []dto.Comment
-		// 使用 database.Client 构建查询
-		queryChildren := database.Client.Model(&dto.Comment{}).
-			Preload("Author").
-			Preload("ReplyToUser").
-			Where("parent_id = ?", comment.ID).
-			Order("created_at ASC") // 链式操作
-
-		if err := queryChildren.Find(&childModels).Error; err != nil {
-			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				log.Printf("获取评论 %d 的子评论失败: %v", comment.ID, err)
-			}
-			// 即使获取子评论失败，也继续，只是子评论列表为空
-		}
-
-		for _, childModel := range childModels {
-			childVO, convErr := convertCommentToVO(childModel, currentUserID, fetchChildren, maxDepth, currentDepth+1)
-			if convErr == nil && childVO != nil {
-				childrenVO = append(childrenVO, *childVO)
-			} else if convErr != nil {
-				log.Printf("转换子评论 %d (父评论 %d) 到 VO 失败: %v", childModel.ID, comment.ID, convErr)
-			}
-		}
+	for _, childModel := range children[comment.ID] {
+		childrenVO = append(childrenVO, *convertCommentToVO(childModel, children, liked))
 	}
 
 	commentVO := &vo.CommentVO{
@@ -91,12 +100,19 @@ func convertCommentToVO(comment dto.Comment, currentUserID *uint32, fetchChildre
 		ReplyToUser:          replyToUserVO,
 		IsLikedByCurrentUser: &isLiked,
 		Children:             childrenVO,
+		ModerationStatus:     comment.ModerationStatus,
 	}
 	if comment.UpdatedAt.IsZero() {
 		commentVO.UpdatedAt = nil
 	}
 
-	return commentVO, nil
+	return commentVO
+}
+
+// commentSortColumns 顶级评论列表支持的排序字段
+var commentSortColumns = map[string]keysetColumn{
+	"createdAt":  {"created_at", keysetTime},
+	"likesCount": {"likes_count", keysetInt},
 }
 
 func (s *CommentService) GetCommentsByPostID(postID uint32, params *dto.GetCommentsParamsDTO, currentUserID *uint32) (*vo.GetCommentsResponseDataVO, error) {
@@ -111,67 +127,81 @@ func (s *CommentService) GetCommentsByPostID(postID uint32, params *dto.GetComme
 		}
 		return nil, fmt.Errorf("验证帖子有效性失败: %w", err)
 	}
+	// 与帖子详情一致，草稿、待审核和已隐藏的帖子只有作者本人可以查看评论
+	if !post.IsPublished || post.ModerationStatus != dto.ModerationVisible {
+		if currentUserID == nil || *currentUserID != post.AuthorID {
+			return nil, errors.New("帖子未找到")
+		}
+	}
 
-	// 2. 构建基础查询，查询顶级评论
-	query := database.Client.Model(&dto.Comment{}).Where("post_id = ? AND parent_id IS NULL", postID) // 使用 database.Client
+	// 2. 构建基础查询，查询顶级评论；待审核与已隐藏的评论不出现在列表中
+	query := database.Client.Model(&dto.Comment{}).Where("post_id = ? AND parent_id IS NULL AND moderation_status = ?", postID, dto.ModerationVisible) // 使用 database.Client
 
-	// 3. 计算总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, fmt.Errorf("统计评论总数失败: %w", err)
+	// 3. 计算总数，滚动加载时可通过 skipTotal 跳过
+	if !params.SkipTotal {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, fmt.Errorf("统计评论总数失败: %w", err)
+		}
 	}
 
-	// 4. 应用排序 (确保 query 被重新赋值)
-	if params.SortBy != "" {
-		parts := strings.Split(params.SortBy, "_")
-		if len(parts) == 2 {
-			column := parts[0]
-			order := strings.ToUpper(parts[1])
-			allowedSorts := map[string]string{"createdAt": "created_at", "likesCount": "likes_count"}
-			if dbColumn, ok := allowedSorts[column]; ok {
-				query = query.Order(dbColumn + " " + order)
-			} else {
-				query = query.Order("created_at DESC")
-			}
-		} else {
-			query = query.Order("created_at DESC")
-		}
-	} else {
-		query = query.Order("created_at DESC")
+	// 4. 应用排序，排序键相同时按 id 保证顺序稳定
+	order := parseKeysetOrder(params.SortBy, commentSortColumns, "createdAt")
+	after, err := order.decode(params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if query, err = order.apply(query, after); err != nil {
+		return nil, err
 	}
 
-	// 5. 应用分页 (确保 query 被重新赋值)
-	offset := (params.Page - 1) * params.Limit
-	query = query.Offset(offset).Limit(params.Limit)
+	// 5. 应用分页：传入游标时从游标处续读，否则按页码偏移；多取一条用于判断是否还有下一页
+	if after == nil {
+		query = query.Offset((params.Page - 1) * params.Limit)
+	}
+	query = query.Limit(params.Limit + 1)
 
 	// 6. 执行查询并预加载关联数据
 	if err := query.Preload("Author").Preload("ReplyToUser").Find(&comments).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			currentPage := params.Page
-			pageSize := params.Limit
-			return &vo.GetCommentsResponseDataVO{Items: []vo.CommentVO{}, Total: 0, CurrentPage: &currentPage, PageSize: &pageSize}, nil
-		}
 		return nil, fmt.Errorf("获取评论列表失败: %w", err)
 	}
+	hasMore := len(comments) > params.Limit
+	if hasMore {
+		comments = comments[:params.Limit]
+	}
 
-	// 7. 将查询结果转换为VO
+	// 7. 批量加载回复与当前用户的点赞状态，再转换为VO
+	children, commentIDs := loadCommentReplies(database.Client, comments, commentReplyDepth)
+	liked, err := loadCommentLikedSet(database.Client, currentUserID, commentIDs)
+	if err != nil {
+		return nil, err
+	}
 	itemsVO := make([]vo.CommentVO, 0, len(comments))
 	for _, commentModel := range comments {
-		commentVO, err := convertCommentToVO(commentModel, currentUserID, true, 2, 0) // 获取最多2层子评论
-		if err == nil && commentVO != nil {
-			itemsVO = append(itemsVO, *commentVO)
-		} else if err != nil {
-			log.Printf("转换评论 %d 到 VO 失败: %v", commentModel.ID, err)
-		}
+		itemsVO = append(itemsVO, *convertCommentToVO(commentModel, children, liked))
 	}
 
-	currentPage := params.Page
 	pageSize := params.Limit
-	return &vo.GetCommentsResponseDataVO{
-		Items:       itemsVO,
-		Total:       total,
-		CurrentPage: &currentPage,
-		PageSize:    &pageSize,
-	}, nil
+	result := &vo.GetCommentsResponseDataVO{
+		Items:    itemsVO,
+		PageSize: &pageSize,
+		HasMore:  hasMore,
+	}
+	if !params.SkipTotal {
+		result.Total = &total
+	}
+	if after == nil {
+		currentPage := params.Page
+		result.CurrentPage = &currentPage
+	}
+	if hasMore {
+		last := comments[len(comments)-1]
+		var value interface{} = last.CreatedAt
+		if order.field == "likesCount" {
+			value = last.LikesCount
+		}
+		result.NextCursor = order.encode(value, last.ID)
+	}
+	return result, nil
 }
 
 func (s *CommentService) AddComment(data *dto.AddCommentDTO, authorID uint32) (*vo.CommentVO, error) {
@@ -183,8 +213,15 @@ func (s *CommentService) AddComment(data *dto.AddCommentDTO, authorID uint32) (*
 		}
 		return nil, fmt.Errorf("验证帖子ID失败: %w", err)
 	}
+	if !post.IsPublished || post.ModerationStatus != dto.ModerationVisible { // 草稿、待审核和已隐藏的帖子不能评论
+		return nil, errors.New("关联的帖子未找到")
+	}
+	if post.IsLocked {
+		return nil, errors.New(config.MsgPostLocked)
+	}
 
 	// 2. 如果是回复，验证 ParentID 和 ReplyToUserID
+	var parent *dto.Comment
 	if data.ParentID != nil && *data.ParentID > 0 {
 		var parentComment dto.Comment
 		if err := database.Client.First(&parentComment, *data.ParentID).Error; err != nil { // 使用 database.Client
@@ -196,6 +233,7 @@ func (s *CommentService) AddComment(data *dto.AddCommentDTO, authorID uint32) (*
 		if parentComment.PostID != data.PostID {
 			return nil, errors.New("父评论与当前帖子不匹配")
 		}
+		parent = &parentComment
 	}
 	if data.ReplyToUserID != nil && *data.ReplyToUserID > 0 {
 		var replyToUser dto.User
@@ -207,12 +245,20 @@ func (s *CommentService) AddComment(data *dto.AddCommentDTO, authorID uint32) (*
 		}
 	}
 
+	// 命中审核词的评论保存后暂不在列表中展示
+	check := newContentCheck()
+	content, err := check.filter(data.Content)
+	if err != nil {
+		return nil, err
+	}
+
 	newComment := dto.Comment{
-		PostID:        data.PostID,
-		AuthorID:      authorID,
-		Content:       data.Content,
-		ParentID:      data.ParentID,
-		ReplyToUserID: data.ReplyToUserID,
+		PostID:           data.PostID,
+		AuthorID:         authorID,
+		Content:          content,
+		ParentID:         data.ParentID,
+		ReplyToUserID:    data.ReplyToUserID,
+		ModerationStatus: check.status(),
 	}
 
 	// 3. 使用事务创建评论
@@ -225,45 +271,66 @@ func (s *CommentService) AddComment(data *dto.AddCommentDTO, authorID uint32) (*
 		tx.Rollback()
 		return nil, fmt.Errorf("创建评论记录失败: %w", err)
 	}
+	if check.held() {
+		if err := holdForReview(tx, dto.ContentTargetComment, newComment.ID, authorID, content, check); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 
-	// 可选：在同一事务中更新帖子的评论数 (示例)
-	// if err := tx.Model(&dto.Post{}).Where("id = ?", data.PostID).UpdateColumn("comments_count", gorm.Expr("comments_count + 1")).Error; err != nil {
-	//    tx.Rollback()
-	//    return nil, fmt.Errorf("更新帖子评论数失败: %w", err)
-	// }
+	// 在同一事务中更新帖子评论数与作者的评论数；待审核的评论审核通过后才计入
+	if !check.held() {
+		if err := applyCounterEvent(tx, counterEvent{kind: counterCommentCreated, postID: data.PostID, commentID: newComment.ID, ownerID: authorID}); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 
 	if err := tx.Commit().Error; err != nil {
 		return nil, fmt.Errorf("提交评论事务失败: %w", err)
 	}
+	// 待审核的评论暂不通知，避免违规内容通过通知扩散
+	if !check.held() {
+		notifyCommentCreated(post, newComment, parent)
+	}
 
 	// 4. 创建成功后，重新加载评论以包含关联的Author和ReplyToUser信息
 	var reloadedComment dto.Comment
 	if err := database.Client.Preload("Author").Preload("ReplyToUser").First(&reloadedComment, newComment.ID).Error; err != nil { // 使用 database.Client
 		log.Printf("警告: 评论 %d 创建成功，但重新加载关联信息失败: %v。将返回部分信息。", newComment.ID, err)
 		// 即使重新加载失败，也尝试用已有的 newComment (无预加载) 进行转换
-		return convertCommentToVO(newComment, &authorID, false, 0, 0)
+		return convertCommentToVO(newComment, nil, nil), nil
 	}
 
-	return convertCommentToVO(reloadedComment, &authorID, false, 0, 0)
+	// 新评论不会已被点赞，无需查询点赞状态
+	return convertCommentToVO(reloadedComment, nil, nil), nil
 }
 
 func (s *CommentService) DeleteComment(commentID uint32, userID uint32, userRole string) error {
+	return database.Client.Transaction(func(tx *gorm.DB) error {
+		return deleteComment(tx, commentID)
+	})
+}
+
+// deleteComment 在 tx 中删除评论及其点赞，并更新帖子评论数与受影响用户的计数；评论不存在时返回 ErrCommentNotFound
+func deleteComment(tx *gorm.DB, commentID uint32) error {
 	var comment dto.Comment
-	if err := database.Client.First(&comment, commentID).Error; err != nil { // 使用 database.Client
+	if err := tx.First(&comment, commentID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("评论未找到")
+			return ErrCommentNotFound
 		}
 		return fmt.Errorf("查找待删除评论失败: %w", err)
 	}
 
-	tx := database.Client.Begin() // 使用 database.Client
-	if tx.Error != nil {
-		return fmt.Errorf("开启删除事务失败: %w", tx.Error)
+	// 评论作者与点赞过该评论的用户，删除后需要重新统计计数
+	var affectedUsers []uint32
+	if err := tx.Raw("SELECT ? UNION SELECT user_id FROM user_comment_likes WHERE comment_id = ?", comment.AuthorID, commentID).
+		Scan(&affectedUsers).Error; err != nil {
+		return fmt.Errorf("查询评论关联用户失败: %w", err)
 	}
 
 	// 1. 删除关联的点赞记录 (如果存在且需要手动处理)
 	if err := tx.Where("comment_id = ?", commentID).Delete(&dto.UserCommentLike{}).Error; err != nil {
-		tx.Rollback()
 		return fmt.Errorf("删除评论关联的点赞记录失败: %w", err)
 	}
 
@@ -272,20 +339,15 @@ func (s *CommentService) DeleteComment(commentID uint32, userID uint32, userRole
 
 	// 3. 删除评论本身
 	if err := tx.Delete(&dto.Comment{}, commentID).Error; err != nil {
-		tx.Rollback()
 		return fmt.Errorf("删除评论记录失败: %w", err)
 	}
 
-	// 可选：更新帖子评论数
-	// if err := tx.Model(&dto.Post{}).Where("id = ?", comment.PostID).UpdateColumn("comments_count", gorm.Expr("comments_count - 1")).Error; err != nil {
-	//    tx.Rollback()
-	//    return fmt.Errorf("更新帖子评论数（删除后）失败: %w", err)
-	// }
-
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("提交删除评论事务失败: %w", err)
+	// 4. 更新帖子评论数与受影响用户的计数；待审核和已隐藏的评论本就不计入帖子评论数
+	countedPostID := comment.PostID
+	if comment.ModerationStatus != dto.ModerationVisible {
+		countedPostID = 0
 	}
-	return nil
+	return applyCounterEvent(tx, counterEvent{kind: counterCommentDeleted, postID: countedPostID, commentID: commentID, ownerID: comment.AuthorID, users: affectedUsers})
 }
 
 func (s *CommentService) ToggleLikeComment(commentID uint32, userID uint32) (*vo.ToggleLikeCommentResponseDataVO, error) {
@@ -319,14 +381,10 @@ func (s *CommentService) ToggleLikeComment(commentID uint32, userID uint32) (*vo
 			tx.Rollback()
 			return nil, fmt.Errorf("创建点赞记录失败: %w", errCreate)
 		}
-		if errUpdate := tx.Model(&dto.Comment{}).Where("id = ?", commentID).UpdateColumn("likes_count", gorm.Expr("likes_count + 1")).Error; errUpdate != nil {
+		if errUpdate := applyCounterEvent(tx, counterEvent{kind: counterCommentLiked, postID: comment.PostID, commentID: commentID, actorID: userID, ownerID: comment.AuthorID}); errUpdate != nil {
 			tx.Rollback()
 			return nil, fmt.Errorf("增加评论点赞数失败: %w", errUpdate)
 		}
-		if err := tx.Model(&dto.Post{}).Where("id = ?", commentID).UpdateColumn("is_liked_by_current_user", gorm.Expr("true")).Error; err != nil {
-			tx.Rollback()
-			return nil, err
-		}
 		isLiked = true
 		currentLikesCount++
 	} else if err == nil { // 已点赞 -> 取消点赞
@@ -334,15 +392,11 @@ func (s *CommentService) ToggleLikeComment(commentID uint32, userID uint32) (*vo
 			tx.Rollback()
 			return nil, fmt.Errorf("删除点赞记录失败: %w", errDelete)
 		}
+		if errUpdate := applyCounterEvent(tx, counterEvent{kind: counterCommentUnliked, postID: comment.PostID, commentID: commentID, actorID: userID, ownerID: comment.AuthorID}); errUpdate != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("减少评论点赞数失败: %w", errUpdate)
+		}
 		if currentLikesCount > 0 {
-			if errUpdate := tx.Model(&dto.Comment{}).Where("id = ?", commentID).UpdateColumn("likes_count", gorm.Expr("likes_count - 1")).Error; errUpdate != nil {
-				tx.Rollback()
-				return nil, fmt.Errorf("减少评论点赞数失败: %w", errUpdate)
-			}
-			if err := tx.Model(&dto.Post{}).Where("id = ?", commentID).UpdateColumn("is_liked_by_current_user", gorm.Expr("false")).Error; err != nil {
-				tx.Rollback()
-				return nil, err
-			}
 			currentLikesCount--
 		}
 		isLiked = false
@@ -354,6 +408,17 @@ func (s *CommentService) ToggleLikeComment(commentID uint32, userID uint32) (*vo
 	if errCommit := tx.Commit().Error; errCommit != nil {
 		return nil, fmt.Errorf("提交点赞事务失败: %w", errCommit)
 	}
+	if isLiked {
+		notifyAsync(notificationEvent{
+			userID:     comment.AuthorID,
+			kind:       dto.NotificationLike,
+			targetType: dto.ContentTargetComment,
+			targetID:   commentID,
+			postID:     comment.PostID,
+			actorID:    userID,
+			excerpt:    comment.Content,
+		})
+	}
 
 	return &vo.ToggleLikeCommentResponseDataVO{
 		IsLiked:    isLiked,
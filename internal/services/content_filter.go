@@ -0,0 +1,297 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/wordfilter"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sensitiveWordState 当前生效的敏感词匹配器及其对应的词库版本
+type sensitiveWordState struct {
+	matcher *wordfilter.Matcher
+	version string
+}
+
+var (
+	sensitiveWords     atomic.Pointer[sensitiveWordState]
+	sensitiveWordsInit sync.Once
+	sensitiveWordsMu   sync.Mutex // 串行化重建，避免旧版本覆盖新版本
+)
+
+// currentSensitiveMatcher 返回当前的匹配器，首次使用时从数据库加载；加载失败时不拦截任何内容
+func currentSensitiveMatcher() *wordfilter.Matcher {
+	sensitiveWordsInit.Do(func() {
+		if sensitiveWords.Load() != nil {
+			return
+		}
+		if _, err := reloadSensitiveWords(context.Background(), false); err != nil {
+			log.Printf("Service: 加载敏感词库失败: %v", err)
+		}
+	})
+	if state := sensitiveWords.Load(); state != nil {
+		return state.matcher
+	}
+	return nil
+}
+
+// sensitiveWordsVersion 词库版本：新增、修改、删除都会改变行数、最大修改时间或ID之和中的至少一个
+func sensitiveWordsVersion(db *gorm.DB) (string, error) {
+	var row struct {
+		Count     int64
+		UpdatedAt *time.Time
+		IDSum     int64
+	}
+	if err := db.Model(&dto.SensitiveWord{}).
+		Select("COUNT(*) AS count, MAX(updated_at) AS updated_at, COALESCE(SUM(id), 0) AS id_sum").
+		Scan(&row).Error; err != nil {
+		return "", err
+	}
+	version := fmt.Sprintf("%d-%d", row.Count, row.IDSum)
+	if row.UpdatedAt != nil {
+		version += fmt.Sprintf("-%d", row.UpdatedAt.UnixMilli())
+	}
+	return version, nil
+}
+
+// reloadSensitiveWords 重建匹配器并原子替换，onlyIfChanged 为 true 时词库版本未变化则跳过
+func reloadSensitiveWords(ctx context.Context, onlyIfChanged bool) (bool, error) {
+	sensitiveWordsMu.Lock()
+	defer sensitiveWordsMu.Unlock()
+
+	db := database.Client.WithContext(ctx)
+	version, err := sensitiveWordsVersion(db)
+	if err != nil {
+		return false, fmt.Errorf("查询敏感词库版本失败: %w", err)
+	}
+	if current := sensitiveWords.Load(); onlyIfChanged && current != nil && current.version == version {
+		return false, nil
+	}
+
+	var words []dto.SensitiveWord
+	if err := db.Find(&words).Error; err != nil {
+		return false, fmt.Errorf("读取敏感词库失败: %w", err)
+	}
+	entries := make([]wordfilter.Entry, 0, len(words))
+	for _, w := range words {
+		entries = append(entries, wordfilter.Entry{Word: w.Word, Category: w.Category, Action: wordfilter.Action(w.Action)})
+	}
+	sensitiveWords.Store(&sensitiveWordState{matcher: wordfilter.New(entries), version: version})
+	return true, nil
+}
+
+// contentCheck 一次写操作中各文本字段的检查结果，任一字段命中需要审核的词时整条内容进入审核
+type contentCheck struct {
+	matcher *wordfilter.Matcher
+	matches []wordfilter.Match
+	review  bool
+}
+
+func newContentCheck() *contentCheck {
+	return &contentCheck{matcher: currentSensitiveMatcher()}
+}
+
+// filter 检查一个字段：命中拦截词返回 MsgContentBlocked，命中打码词返回打码后的文本
+func (c *contentCheck) filter(text string) (string, error) {
+	result := c.matcher.Check(text)
+	if result.Action == wordfilter.ActionBlock {
+		return "", errors.New(config.MsgContentBlocked)
+	}
+	if result.Action == wordfilter.ActionReview {
+		c.review = true
+	}
+	c.matches = append(c.matches, result.Matches...)
+	return result.Masked, nil
+}
+
+// held 内容是否需要人工审核
+func (c *contentCheck) held() bool {
+	return c.review
+}
+
+// status 内容写入时的审核状态
+func (c *contentCheck) status() uint8 {
+	if c.review {
+		return dto.ModerationPending
+	}
+	return dto.ModerationVisible
+}
+
+// summary 去重后的命中词与分类，逗号分隔，按列宽截断
+func (c *contentCheck) summary() (string, string) {
+	var words, categories []string
+	seenWord, seenCategory := map[string]bool{}, map[string]bool{}
+	for _, m := range c.matches {
+		if !seenWord[m.Word] {
+			seenWord[m.Word] = true
+			words = append(words, m.Word)
+		}
+		if !seenCategory[m.Category] {
+			seenCategory[m.Category] = true
+			categories = append(categories, m.Category)
+		}
+	}
+	return truncateRunes(strings.Join(words, ","), 500), truncateRunes(strings.Join(categories, ","), 255)
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// holdForReview 将命中审核词的内容加入审核队列，content 为提交时的内容快照
+func holdForReview(tx *gorm.DB, targetType string, targetID, authorID uint32, content string, check *contentCheck) error {
+	words, categories := check.summary()
+	review := dto.ContentReview{
+		TargetType:   targetType,
+		TargetID:     targetID,
+		AuthorID:     authorID,
+		Content:      content,
+		MatchedWords: words,
+		Categories:   categories,
+	}
+	if err := tx.Create(&review).Error; err != nil {
+		return fmt.Errorf("加入审核队列失败: %w", err)
+	}
+	return nil
+}
+
+type SensitiveWordService struct{}
+
+func NewSensitiveWordService() *SensitiveWordService {
+	return &SensitiveWordService{}
+}
+
+func sensitiveWordToVO(w dto.SensitiveWord) vo.SensitiveWordVO {
+	return vo.SensitiveWordVO{ID: w.ID, Word: w.Word, Category: w.Category, Action: w.Action, UpdatedAt: w.UpdatedAt}
+}
+
+// List 分页查询敏感词，可按分类和关键词筛选
+func (s *SensitiveWordService) List(ctx context.Context, params dto.GetSensitiveWordsParamsDTO) (*vo.SensitiveWordListVO, error) {
+	query := database.Client.WithContext(ctx).Model(&dto.SensitiveWord{})
+	if params.Category != "" {
+		query = query.Where("category = ?", params.Category)
+	}
+	if keyword := strings.TrimSpace(params.Keyword); keyword != "" {
+		query = query.Where("word LIKE ?", "%"+keyword+"%")
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计敏感词失败: %w", err)
+	}
+	var words []dto.SensitiveWord
+	if err := query.Order("id DESC").Offset((params.Page - 1) * params.Limit).Limit(params.Limit).Find(&words).Error; err != nil {
+		return nil, fmt.Errorf("查询敏感词失败: %w", err)
+	}
+	items := make([]vo.SensitiveWordVO, 0, len(words))
+	for _, w := range words {
+		items = append(items, sensitiveWordToVO(w))
+	}
+	return &vo.SensitiveWordListVO{Items: items, Total: total, CurrentPage: params.Page, PageSize: params.Limit}, nil
+}
+
+// Import 批量新增敏感词，归一化后已存在的词覆盖其分类与处理方式，完成后立即重建匹配器
+func (s *SensitiveWordService) Import(ctx context.Context, payload dto.SensitiveWordBatchDTO) (*vo.SensitiveWordImportVO, error) {
+	result := &vo.SensitiveWordImportVO{}
+	byNorm := make(map[string]dto.SensitiveWord, len(payload.Words))
+	var order []string
+	for _, item := range payload.Words {
+		norm := wordfilter.Normalize(item.Word)
+		if norm == "" {
+			result.Skipped++
+			continue
+		}
+		if _, ok := byNorm[norm]; !ok {
+			order = append(order, norm)
+		}
+		// 同一批中重复的词以最后一次为准
+		byNorm[norm] = dto.SensitiveWord{
+			Word:     strings.TrimSpace(item.Word),
+			NormWord: norm,
+			Category: strings.TrimSpace(item.Category),
+			Action:   item.Action,
+		}
+	}
+	if len(order) > 0 {
+		words := make([]dto.SensitiveWord, 0, len(order))
+		for _, norm := range order {
+			words = append(words, byNorm[norm])
+		}
+		if err := database.Client.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "norm_word"}},
+			DoUpdates: clause.AssignmentColumns([]string{"word", "category", "action", "updated_at"}),
+		}).CreateInBatches(&words, 200).Error; err != nil {
+			return nil, fmt.Errorf("保存敏感词失败: %w", err)
+		}
+		result.Saved = len(words)
+	}
+	if _, err := reloadSensitiveWords(ctx, false); err != nil {
+		return nil, err
+	}
+	result.Loaded = currentSensitiveMatcher().Len()
+	return result, nil
+}
+
+// Update 修改敏感词的分类或处理方式
+func (s *SensitiveWordService) Update(ctx context.Context, id uint32, payload dto.UpdateSensitiveWordDTO) (*vo.SensitiveWordVO, error) {
+	db := database.Client.WithContext(ctx)
+	var word dto.SensitiveWord
+	if err := db.First(&word, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgSensitiveWordNotFound)
+		}
+		return nil, err
+	}
+	updates := map[string]interface{}{}
+	if payload.Category != nil {
+		updates["category"] = strings.TrimSpace(*payload.Category)
+	}
+	if payload.Action != nil {
+		updates["action"] = *payload.Action
+	}
+	if len(updates) > 0 {
+		if err := db.Model(&word).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("修改敏感词失败: %w", err)
+		}
+		if err := db.First(&word, id).Error; err != nil {
+			return nil, err
+		}
+		if _, err := reloadSensitiveWords(ctx, false); err != nil {
+			return nil, err
+		}
+	}
+	result := sensitiveWordToVO(word)
+	return &result, nil
+}
+
+// Delete 删除敏感词
+func (s *SensitiveWordService) Delete(ctx context.Context, id uint32) error {
+	res := database.Client.WithContext(ctx).Delete(&dto.SensitiveWord{}, id)
+	if res.Error != nil {
+		return fmt.Errorf("删除敏感词失败: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.New(config.MsgSensitiveWordNotFound)
+	}
+	_, err := reloadSensitiveWords(ctx, false)
+	return err
+}
+
+// ReloadIfChanged 词库版本变化时重建匹配器，供定时任务在多实例部署时同步其他实例的修改
+func (s *SensitiveWordService) ReloadIfChanged(ctx context.Context) (bool, error) {
+	return reloadSensitiveWords(ctx, true)
+}
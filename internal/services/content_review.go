@@ -0,0 +1,233 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContentReviewService 敏感词审核队列，以及注册、修改资料时对用户名和简介的检查
+type ContentReviewService struct{}
+
+func NewContentReviewService() *ContentReviewService {
+	return &ContentReviewService{}
+}
+
+// moderationTables 支持审核状态的内容类型对应的表
+var moderationTables = map[string]string{
+	dto.ContentTargetPost:    "posts",
+	dto.ContentTargetComment: "comments",
+	dto.ContentTargetReview:  "course_reviews",
+}
+
+// setModerationStatus 把内容的审核状态改为 to，from 非空时只修改处于其中某个状态的内容，返回是否有修改。
+// 帖子评论数与用户评论数只统计可见的评论，评论在可见与不可见之间切换时在同一事务中同步计数
+func setModerationStatus(tx *gorm.DB, targetType string, targetID uint32, to uint8, from ...uint8) (bool, error) {
+	table, ok := moderationTables[targetType]
+	if !ok {
+		return false, nil
+	}
+	if targetType != dto.ContentTargetComment {
+		query := tx.Table(table).Where("id = ?", targetID)
+		if len(from) > 0 {
+			query = query.Where("moderation_status IN ?", from)
+		}
+		res := query.UpdateColumn("moderation_status", to)
+		return res.RowsAffected > 0, res.Error
+	}
+
+	var comment dto.Comment
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "post_id", "author_id", "moderation_status").Where("id = ?", targetID)
+	if len(from) > 0 {
+		query = query.Where("moderation_status IN ?", from)
+	}
+	if err := query.Take(&comment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("查询评论失败: %w", err)
+	}
+	if comment.ModerationStatus == to {
+		return false, nil
+	}
+	if err := tx.Model(&comment).UpdateColumn("moderation_status", to).Error; err != nil {
+		return false, fmt.Errorf("更新评论审核状态失败: %w", err)
+	}
+
+	kind := counterCommentShown
+	switch {
+	case to == dto.ModerationVisible:
+	case comment.ModerationStatus == dto.ModerationVisible:
+		kind = counterCommentHidden
+	default:
+		return true, nil
+	}
+	return true, applyCounterEvent(tx, counterEvent{kind: kind, postID: comment.PostID, commentID: comment.ID, ownerID: comment.AuthorID})
+}
+
+// CheckUsername 用户名命中任何敏感词都拒绝，打码或先展示后审核对用户名都没有意义
+func (s *ContentReviewService) CheckUsername(username string) error {
+	if len(currentSensitiveMatcher().Find(username)) > 0 {
+		return errors.New(config.MsgUsernameSensitive)
+	}
+	return nil
+}
+
+// FilterBio 检查个人简介，返回打码后的简介；held 为 true 时简介需要审核，调用方不应直接写入，而是调用 HoldBio
+func (s *ContentReviewService) FilterBio(bio string) (string, bool, error) {
+	check := newContentCheck()
+	masked, err := check.filter(bio)
+	if err != nil {
+		return "", false, err
+	}
+	return masked, check.held(), nil
+}
+
+// HoldBio 将待审核的简介加入审核队列，审核通过后才写入用户资料
+func (s *ContentReviewService) HoldBio(ctx context.Context, userID uint32, bio string) error {
+	check := newContentCheck()
+	masked, err := check.filter(bio)
+	if err != nil {
+		return err
+	}
+	return holdForReview(database.Client.WithContext(ctx), dto.ContentTargetUser, userID, userID, masked, check)
+}
+
+func splitSummary(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+// List 分页查询审核队列，待审核的按提交时间先后排列，已处理的按处理时间倒序
+func (s *ContentReviewService) List(ctx context.Context, params dto.GetContentReviewsParamsDTO) (*vo.ContentReviewListVO, error) {
+	db := database.Client.WithContext(ctx)
+	query := db.Model(&dto.ContentReview{}).Where("status = ?", params.Status)
+	if params.TargetType != "" {
+		query = query.Where("target_type = ?", params.TargetType)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计审核记录失败: %w", err)
+	}
+	order := "created_at ASC, id ASC"
+	if params.Status != dto.ContentReviewPending {
+		order = "reviewed_at DESC, id DESC"
+	}
+	var reviews []dto.ContentReview
+	if err := query.Order(order).Offset((params.Page - 1) * params.Limit).Limit(params.Limit).Find(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("查询审核记录失败: %w", err)
+	}
+
+	authorIDs := make([]uint32, 0, len(reviews))
+	for _, r := range reviews {
+		authorIDs = append(authorIDs, r.AuthorID)
+	}
+	authors := make(map[uint32]vo.AuthorInfoVO, len(authorIDs))
+	if len(authorIDs) > 0 {
+		var users []dto.User
+		if err := db.Select("id", "username", "avatar").Where("id IN ?", authorIDs).Find(&users).Error; err != nil {
+			return nil, fmt.Errorf("查询内容作者失败: %w", err)
+		}
+		for _, u := range users {
+			authors[u.Id] = vo.AuthorInfoVO{ID: u.Id, Username: u.Username, Avatar: u.Avatar}
+		}
+	}
+
+	items := make([]vo.ContentReviewVO, 0, len(reviews))
+	for _, r := range reviews {
+		item := vo.ContentReviewVO{
+			ID:           r.ID,
+			TargetType:   r.TargetType,
+			TargetID:     r.TargetID,
+			Content:      r.Content,
+			MatchedWords: splitSummary(r.MatchedWords),
+			Categories:   splitSummary(r.Categories),
+			Status:       r.Status,
+			ReviewerID:   r.ReviewerID,
+			ReviewedAt:   r.ReviewedAt,
+			CreatedAt:    r.CreatedAt,
+		}
+		if author, ok := authors[r.AuthorID]; ok {
+			item.Author = &author
+		}
+		items = append(items, item)
+	}
+	return &vo.ContentReviewListVO{Items: items, Total: total, CurrentPage: params.Page, PageSize: params.Limit}, nil
+}
+
+// Resolve 审核一条记录：通过后内容恢复展示（用户简介写入资料），驳回后内容保持隐藏。
+// 同一内容多次编辑产生的多条待审核记录一并处理；已被版主隐藏的内容不会因审核通过而恢复
+func (s *ContentReviewService) Resolve(ctx context.Context, reviewID, reviewerID uint32, approve bool) error {
+	status, moderation := dto.ContentReviewRejected, dto.ModerationHidden
+	if approve {
+		status, moderation = dto.ContentReviewApproved, dto.ModerationVisible
+	}
+	now := clock.Now(ctx)
+
+	var review dto.ContentReview
+	err := database.Client.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&review, reviewID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(config.MsgContentReviewNotFound)
+			}
+			return err
+		}
+
+		resolve := tx.Model(&dto.ContentReview{}).Where("status = ?", dto.ContentReviewPending)
+		if review.TargetType == dto.ContentTargetUser {
+			resolve = resolve.Where("id = ?", review.ID)
+		} else {
+			resolve = resolve.Where("target_type = ? AND target_id = ?", review.TargetType, review.TargetID)
+		}
+		res := resolve.UpdateColumns(map[string]interface{}{"status": status, "reviewer_id": reviewerID, "reviewed_at": now})
+		if res.Error != nil {
+			return fmt.Errorf("更新审核记录失败: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return errors.New(config.MsgContentReviewResolved)
+		}
+
+		if review.TargetType == dto.ContentTargetUser {
+			if !approve {
+				return nil
+			}
+			return tx.Model(&dto.User{}).Where("id = ?", review.TargetID).UpdateColumn("bio", review.Content).Error
+		}
+		// 内容可能已被删除，此时只记录审核结果
+		_, err := setModerationStatus(tx, review.TargetType, review.TargetID, moderation, dto.ModerationPending)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	label := reportTargetLabels[review.TargetType]
+	if review.TargetType == dto.ContentTargetUser {
+		label = "个人简介"
+	}
+	message := fmt.Sprintf("你提交的%s已通过审核，现已公开展示。", label)
+	if !approve {
+		message = fmt.Sprintf("你提交的%s未通过审核，不会公开展示。请遵守社区规范。", label)
+	}
+	notifyAsync(notificationEvent{
+		userID:     review.AuthorID,
+		kind:       dto.NotificationModeration,
+		targetType: review.TargetType,
+		targetID:   review.TargetID,
+		excerpt:    review.Content,
+		message:    message,
+	})
+	return nil
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	database "cengkeHelperBackGo/internal/db"
+	"context"
+	"expvar"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// counterEventKind 会改变冗余计数的写操作
+type counterEventKind int
+
+const (
+	counterPostPublished counterEventKind = iota
+	counterPostDeleted
+	counterPostViewed
+	counterPostLiked
+	counterPostUnliked
+	counterPostCollected
+	counterPostUncollected
+	counterCommentCreated
+	counterCommentDeleted
+	counterCommentShown  // 待审核或被隐藏的评论恢复可见
+	counterCommentHidden // 可见的评论被隐藏或转入审核
+	counterCommentLiked
+	counterCommentUnliked
+)
+
+// counterEvent 一次写操作引起的计数变化，由 applyCounterEvent 在写操作所在的事务中统一落库
+type counterEvent struct {
+	kind      counterEventKind
+	postID    uint32
+	commentID uint32
+	actorID   uint32   // 点赞、收藏的用户
+	ownerID   uint32   // 被操作的帖子或评论的作者
+	users     []uint32 // 删除操作连带删除了关联数据，这些用户的计数改为从来源表重新统计
+}
+
+// applyCounterEvent 在 tx 中更新事件涉及的帖子、评论与用户计数
+func applyCounterEvent(tx *gorm.DB, ev counterEvent) error {
+	var steps []counterStep
+	switch ev.kind {
+	case counterPostPublished:
+		steps = []counterStep{{"users", "posts_count", ev.ownerID, 1}}
+	case counterPostViewed:
+		steps = []counterStep{{"posts", "view_count", ev.postID, 1}}
+	case counterPostLiked, counterPostUnliked:
+		delta := 1
+		if ev.kind == counterPostUnliked {
+			delta = -1
+		}
+		steps = []counterStep{
+			{"posts", "likes_count", ev.postID, delta},
+			{"users", "likes_received", ev.ownerID, delta},
+			{"users", "likes_given", ev.actorID, delta},
+		}
+	case counterPostCollected:
+		steps = []counterStep{{"posts", "collect_count", ev.postID, 1}}
+	case counterPostUncollected:
+		steps = []counterStep{{"posts", "collect_count", ev.postID, -1}}
+	case counterCommentCreated, counterCommentShown:
+		steps = []counterStep{
+			{"posts", "comments_count", ev.postID, 1},
+			{"users", "comments_count", ev.ownerID, 1},
+		}
+	case counterCommentDeleted:
+		steps = []counterStep{{"posts", "comments_count", ev.postID, -1}}
+	case counterCommentHidden:
+		steps = []counterStep{
+			{"posts", "comments_count", ev.postID, -1},
+			{"users", "comments_count", ev.ownerID, -1},
+		}
+	case counterCommentLiked, counterCommentUnliked:
+		delta := 1
+		if ev.kind == counterCommentUnliked {
+			delta = -1
+		}
+		steps = []counterStep{
+			{"comments", "likes_count", ev.commentID, delta},
+			{"users", "likes_received", ev.ownerID, delta},
+			{"users", "likes_given", ev.actorID, delta},
+		}
+	}
+
+	for _, step := range steps {
+		if err := step.apply(tx); err != nil {
+			return err
+		}
+	}
+	if ev.kind == counterPostDeleted || ev.kind == counterCommentDeleted {
+		return recountUserCounters(tx, ev.users)
+	}
+	return nil
+}
+
+// counterStep 对一行的一个计数列加减
+type counterStep struct {
+	table  string
+	column string
+	id     uint32
+	delta  int
+}
+
+func (s counterStep) apply(tx *gorm.DB) error {
+	if s.id == 0 {
+		return nil
+	}
+	expr := gorm.Expr(s.column+" + ?", s.delta)
+	if s.delta < 0 {
+		// 计数列为无符号整数，最多减到 0，已有的偏差留给对账任务修正
+		expr = gorm.Expr("GREATEST(CAST("+s.column+" AS SIGNED) - ?, 0)", -s.delta)
+	}
+	if err := tx.Table(s.table).Where("id = ?", s.id).UpdateColumn(s.column, expr).Error; err != nil {
+		return fmt.Errorf("更新 %s.%s 失败: %w", s.table, s.column, err)
+	}
+	return nil
+}
+
+// counterColumn 一个冗余计数列及其在来源表上的统计方式，对账任务据此重新统计
+type counterColumn struct {
+	metric string // 对账指标名
+	table  string
+	column string
+	actual string // 从来源表统计的相关子查询，通过表名引用当前行
+	scope  string // 参与对账的行
+}
+
+const (
+	activePostLikes    = "user_post_likes JOIN posts ON posts.id = user_post_likes.post_id AND posts.deleted_at IS NULL"
+	activeCommentLikes = "user_comment_likes JOIN comments ON comments.id = user_comment_likes.comment_id AND comments.deleted_at IS NULL"
+)
+
+// userCounterColumns 用户级计数，只统计未删除的帖子和评论，发帖数不含草稿，评论数不含待审核和已隐藏的评论
+var userCounterColumns = []counterColumn{
+	{"user.posts", "users", "posts_count",
+		"(SELECT COUNT(*) FROM posts WHERE posts.author_id = users.id AND posts.deleted_at IS NULL AND posts.is_published = TRUE)", ""},
+	{"user.comments", "users", "comments_count",
+		"(SELECT COUNT(*) FROM comments WHERE comments.author_id = users.id AND comments.deleted_at IS NULL AND comments.moderation_status = 0)", ""},
+	{"user.likes_given", "users", "likes_given",
+		"((SELECT COUNT(*) FROM " + activePostLikes + " WHERE user_post_likes.user_id = users.id) + " +
+			"(SELECT COUNT(*) FROM " + activeCommentLikes + " WHERE user_comment_likes.user_id = users.id))", ""},
+	{"user.likes_received", "users", "likes_received",
+		"((SELECT COUNT(*) FROM " + activePostLikes + " WHERE posts.author_id = users.id) + " +
+			"(SELECT COUNT(*) FROM " + activeCommentLikes + " WHERE comments.author_id = users.id))", ""},
+}
+
+// counterColumns 参与对账的全部计数列。浏览量没有明细表，无法对账
+var counterColumns = append([]counterColumn{
+	{"post.likes", "posts", "likes_count",
+		"(SELECT COUNT(*) FROM user_post_likes WHERE user_post_likes.post_id = posts.id)", "posts.deleted_at IS NULL"},
+	{"post.collects", "posts", "collect_count",
+		"(SELECT COUNT(*) FROM user_post_collects WHERE user_post_collects.post_id = posts.id)", "posts.deleted_at IS NULL"},
+	{"post.comments", "posts", "comments_count",
+		"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted_at IS NULL AND comments.moderation_status = 0)", "posts.deleted_at IS NULL"},
+	{"comment.likes", "comments", "likes_count",
+		"(SELECT COUNT(*) FROM user_comment_likes WHERE user_comment_likes.comment_id = comments.id)", "comments.deleted_at IS NULL"},
+}, userCounterColumns...)
+
+// recountUserCounters 从来源表重新统计指定用户的全部计数
+func recountUserCounters(tx *gorm.DB, userIDs []uint32) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	updates := make(map[string]interface{}, len(userCounterColumns))
+	for _, col := range userCounterColumns {
+		updates[col.column] = gorm.Expr(col.actual)
+	}
+	if err := tx.Table("users").Where("id IN ?", userIDs).UpdateColumns(updates).Error; err != nil {
+		return fmt.Errorf("重新统计用户计数失败: %w", err)
+	}
+	return nil
+}
+
+// counterReconcileBatch 对账时每批修正的行数
+const counterReconcileBatch = 500
+
+var (
+	// counterCorrections 各计数列累计被对账任务修正的行数
+	counterCorrections = expvar.NewMap("counter_corrections")
+	// counterLastReconciled 最近一次对账完成的时间（Unix 秒）
+	counterLastReconciled = expvar.NewInt("counter_last_reconciled")
+)
+
+type CounterService struct{}
+
+func NewCounterService() *CounterService {
+	return &CounterService{}
+}
+
+// Reconcile 从来源表重新统计所有计数列，修正与存储值不一致的行，返回各计数列本次修正的行数
+func (s *CounterService) Reconcile(ctx context.Context) (map[string]int64, error) {
+	db := database.Client.WithContext(ctx)
+	fixed := make(map[string]int64, len(counterColumns))
+	for _, col := range counterColumns {
+		n, err := reconcileCounterColumn(db, col)
+		if n > 0 {
+			fixed[col.metric] = n
+			counterCorrections.Add(col.metric, n)
+		}
+		if err != nil {
+			return fixed, err
+		}
+	}
+	counterLastReconciled.Set(time.Now().Unix())
+	return fixed, nil
+}
+
+// reconcileCounterColumn 按主键顺序分批找出计数有偏差的行，用来源表的统计值覆盖
+func reconcileCounterColumn(db *gorm.DB, col counterColumn) (int64, error) {
+	where := fmt.Sprintf("%s.id > ? AND %s.%s <> %s", col.table, col.table, col.column, col.actual)
+	if col.scope != "" {
+		where += " AND " + col.scope
+	}
+
+	var fixed int64
+	var lastID uint32
+	for {
+		var ids []uint32
+		if err := db.Table(col.table).Where(where, lastID).
+			Order(col.table+".id").Limit(counterReconcileBatch).
+			Pluck(col.table+".id", &ids).Error; err != nil {
+			return fixed, fmt.Errorf("查找 %s 偏差失败: %w", col.metric, err)
+		}
+		if len(ids) == 0 {
+			return fixed, nil
+		}
+		// 覆盖时重新统计，避免查找与修正之间的新写入被旧值覆盖
+		if err := db.Table(col.table).Where("id IN ?", ids).
+			UpdateColumn(col.column, gorm.Expr(col.actual)).Error; err != nil {
+			return fixed, fmt.Errorf("修正 %s 失败: %w", col.metric, err)
+		}
+		fixed += int64(len(ids))
+		lastID = ids[len(ids)-1]
+	}
+}
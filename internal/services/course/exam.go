@@ -0,0 +1,82 @@
+package course
+
+import (
+	database "cengkeHelperBackGo/internal/db"
+	"context"
+	"fmt"
+	"time"
+)
+
+// ExamTimeLayout 考试日期与时间拼接后的格式，按服务器本地时区解析（与学期开始日期一致）
+const ExamTimeLayout = "2006-01-02 15:04"
+
+// ExamRoom 某天被考试占用的教室及其占用的节次
+type ExamRoom struct {
+	CourseID    uint32
+	CourseNum   string
+	CourseName  string
+	Faculty     string
+	Area        int
+	Building    string
+	Classroom   string
+	StartLesson int
+	EndLesson   int
+}
+
+// Covers 考试是否占用第 lessonNum 节，lessonNum 为 -1 表示全天
+func (e ExamRoom) Covers(lessonNum int) bool {
+	return lessonNum == -1 || (e.StartLesson <= lessonNum && lessonNum <= e.EndLesson)
+}
+
+// examRoomRow 查询考试教室的原始行
+type examRoomRow struct {
+	CourseID   uint32
+	CourseNum  string
+	CourseName string
+	Faculty    string
+	Area       int
+	Building   string
+	Classroom  string
+	ExamDate   string
+	StartTime  string
+	EndTime    string
+}
+
+// ExamRoomsOn 第 weekNum 周星期 weekday 有考试的教室，area 为 0 时不限学部；
+// 空闲教室计算时这些教室在考试所占的节次内视为占用
+func ExamRoomsOn(ctx context.Context, area, weekNum, weekday int) ([]ExamRoom, error) {
+	query := database.Client.WithContext(ctx).Table("course_exams ce").
+		Select("ce.course_id, ci.course_num, ci.course_name, ci.faculty, ce.area, ce.building, ce.classroom, ce.exam_date, ce.start_time, ce.end_time").
+		Joins("JOIN course_infos ci ON ci.id = ce.course_id").
+		Where("ce.exam_date = ?", DateOf(weekNum, weekday).Format(time.DateOnly))
+	if area != 0 {
+		query = query.Where("ce.area = ?", area)
+	}
+	var rows []examRoomRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询考试教室失败: %w", err)
+	}
+
+	rooms := make([]ExamRoom, 0, len(rows))
+	for _, row := range rows {
+		start, errStart := time.ParseInLocation(ExamTimeLayout, row.ExamDate+" "+row.StartTime, time.Local)
+		end, errEnd := time.ParseInLocation(ExamTimeLayout, row.ExamDate+" "+row.EndTime, time.Local)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		_, _, startLesson := TimeToNums(start, SemesterBeginDate)
+		_, _, endLesson := TimeToNums(end.Add(-time.Minute), SemesterBeginDate)
+		rooms = append(rooms, ExamRoom{
+			CourseID:    row.CourseID,
+			CourseNum:   row.CourseNum,
+			CourseName:  row.CourseName,
+			Faculty:     row.Faculty,
+			Area:        row.Area,
+			Building:    row.Building,
+			Classroom:   row.Classroom,
+			StartLesson: startLesson,
+			EndLesson:   max(startLesson, endLesson),
+		})
+	}
+	return rooms, nil
+}
@@ -0,0 +1,65 @@
+package course
+
+import "time"
+
+// SemesterBeginDate 本学期第一周周一零点，周次计算均以此为准
+var SemesterBeginDate = time.Date(2025, time.September, 8, 0, 0, 0, 0, time.Local)
+
+// TimeToNums 将时间换算为周次、星期和节次
+func TimeToNums(t, beginDate time.Time) (weekNum int, weekday int, lessonNum int) {
+	// 计算第几周（学期开始日期：2025年9月8日）
+	sub := t.Sub(beginDate)
+	durationDay := int(sub.Hours()) / 24
+	weekNum = durationDay/7 + 1
+
+	// 计算周几（0=周日, 1=周一, ..., 6=周六）
+	weekday = int(t.Weekday())
+
+	// 计算第几节课
+	hour := t.Hour()
+	minute := t.Minute()
+
+	switch {
+	case hour < 7 || (hour == 7 && minute < 50):
+		lessonNum = 1 //-1 // 早上还没开始上课
+	case hour < 8 || (hour == 8 && minute < 45):
+		lessonNum = 1
+	case hour < 9 || (hour == 9 && minute < 35):
+		lessonNum = 2
+	case hour < 10 || (hour == 10 && minute < 35):
+		lessonNum = 3
+	case hour < 11 || (hour == 11 && minute < 25):
+		lessonNum = 4
+	case hour < 12 || (hour == 12 && minute < 15):
+		lessonNum = 5
+	case hour < 13 || (hour == 13 && minute < 55):
+		lessonNum = 5 //-1 // 中午休息
+	case hour < 14 || (hour == 14 && minute < 50):
+		lessonNum = 6
+	case hour < 15 || (hour == 15 && minute < 40):
+		lessonNum = 7
+	case hour < 16 || (hour == 16 && minute < 35):
+		lessonNum = 8
+	case hour < 17 || (hour == 17 && minute < 25):
+		lessonNum = 9
+	case hour < 18 || (hour == 18 && minute < 15):
+		lessonNum = 10
+	case hour < 18 || (hour == 18 && minute < 20):
+		lessonNum = 10 //-1 // 晚饭时间
+	case hour < 19 || (hour == 19 && minute < 15):
+		lessonNum = 11
+	case hour < 20 || (hour == 20 && minute < 5):
+		lessonNum = 12
+	case hour < 20 || (hour == 20 && minute < 55):
+		lessonNum = 13
+	default:
+		lessonNum = 13 //-1 // 晚上没课了
+	}
+
+	return weekNum, weekday, lessonNum
+}
+
+// DateOf 第 weekNum 周星期 weekday（0=周日）对应的日期
+func DateOf(weekNum, weekday int) time.Time {
+	return SemesterBeginDate.AddDate(0, 0, (weekNum-1)*7+(weekday+6)%7)
+}
@@ -3,27 +3,48 @@ package course
 import (
 	database "cengkeHelperBackGo/internal/db"
 	"cengkeHelperBackGo/pkg/generator"
+	"context"
 	"fmt"
 )
 
 func getNumOfCourses(dayOfWeek, weekNum int, lessonNum []int) int {
-	// 计算 weekAndTime 掩码
-	weekAndTime := generator.WeekLesson2Bin([]int{weekNum}, lessonNum)
+	var courseNums []string
+	// 学期外没有课程，只统计考试
+	if generator.IsTermWeek(weekNum) {
+		// 计算 weekAndTime 掩码
+		weekAndTime := generator.WeekLesson2Bin([]int{weekNum}, lessonNum)
+		if err := database.Client.Raw(
+			`SELECT DISTINCT ci.course_num
+			 FROM time_infos ti 
+			 JOIN course_infos ci ON ci.id = ti.course_info_id
+			 WHERE ti.day_of_week = ? 
+	           AND (ti.week_and_time & ? & (-1<<13)) != 0
+	           AND (ti.week_and_time & ? & 0x1FFF) != 0`,
+			dayOfWeek, weekAndTime, weekAndTime,
+		).Scan(&courseNums).Error; err != nil {
+			fmt.Println(err)
+		}
+	}
 
-	var count int
-	if err := database.Client.Raw(
-		`SELECT COUNT(DISTINCT ci.course_num)
-		 FROM time_infos ti 
-		 JOIN course_infos ci ON ci.id = ti.course_info_id
-		 WHERE ti.day_of_week = ? 
-           AND (ti.week_and_time & ? & (-1<<13)) != 0
-           AND (ti.week_and_time & ? & 0x1FFF) != 0`,
-		dayOfWeek, weekAndTime, weekAndTime,
-	).Scan(&count).Error; err != nil {
+	// 考试占用的教室同样计入
+	counted := make(map[string]bool, len(courseNums))
+	for _, num := range courseNums {
+		counted[num] = true
+	}
+	exams, err := ExamRoomsOn(context.Background(), 0, weekNum, dayOfWeek)
+	if err != nil {
 		fmt.Println(err)
 	}
+	for _, exam := range exams {
+		for _, l := range lessonNum {
+			if exam.Covers(l) {
+				counted[exam.CourseNum] = true
+				break
+			}
+		}
+	}
 
-	return count
+	return len(counted)
 }
 
 func GetSingleNumOfCourses(dayOfWeek, weekNum int, lessonNum int) int {
@@ -2,46 +2,14 @@ package course
 
 import (
 	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/coursedata"
 	"cengkeHelperBackGo/pkg/generator"
 	"fmt"
 	"regexp"
 	"strconv"
-	"unicode"
+	"strings"
 )
 
-var reg = regexp.MustCompile(`\d+`)
-
-// ExtractFloorNumber 从教室编号提取楼层号
-func ExtractFloorNumber(classroom string) int {
-	// 常见格式: A101, 201, 3-101 等
-	// 提取第一个或第二个数字作为楼层号
-	matches := reg.FindAllString(classroom, -1)
-	// 提取第一个不为0的数字序列
-	if len(matches) == 0 {
-		return 1 // 默认第一层
-	}
-
-	// 取第一个数字序列
-	numStr := matches[0]
-	for i := 0; i < len(numStr); i++ {
-		if numStr[i] != '0' && unicode.IsDigit(rune(numStr[i])) {
-			return int(numStr[i] - '0')
-		}
-	}
-	return 1
-}
-
-// ParseCredits 解析学分字符串为浮点数
-func ParseCredits(creditStr string) float32 {
-	credit, err := strconv.ParseFloat(creditStr, 32)
-	if err != nil {
-		fmt.Println(err)
-		return 0.0
-	}
-
-	return float32(credit)
-}
-
 func formatWeekRange(weeks []int) string {
 	if len(weeks) == 0 {
 		return ""
@@ -98,3 +66,66 @@ func ExtractBuildingCode(building string) string {
 	}
 	return building
 }
+
+// ParseWeekList 解析逗号分隔的周次列表（如 "3,5,7"），周次范围 1-19
+func ParseWeekList(s string) ([]int, error) {
+	weeks := make([]int, 0)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		week, err := strconv.Atoi(part)
+		if err != nil || week < 1 || week > 19 {
+			return nil, fmt.Errorf("无效的周次: %s", part)
+		}
+		weeks = append(weeks, week)
+	}
+	if len(weeks) == 0 {
+		return nil, fmt.Errorf("周次列表为空")
+	}
+	return weeks, nil
+}
+
+// ClearWeeks 从 week_and_time 编码中清除指定周次，节次保持不变
+func ClearWeeks(weekAndTime uint32, weeks []int) uint32 {
+	for _, week := range weeks {
+		weekAndTime &^= (1 << 31) >> (week - 1)
+	}
+	return weekAndTime
+}
+
+// BuildRoomID 生成结构化课程视图中的教室ID，格式 division_{学部}_{教学楼}_F{楼层}_{教室}
+func BuildRoomID(area int, building, classroom string) string {
+	return fmt.Sprintf("division_%d_%s_F%d_%s", area, building, coursedata.ExtractFloorNumber(classroom), classroom)
+}
+
+var roomIDReg = regexp.MustCompile(`^division_(\d+)_(.+?)_F\d+_(.+)$`)
+
+// ParseRoomID 解析 BuildRoomID 生成的教室ID，返回学部、教学楼与教室
+func ParseRoomID(roomID string) (area int, building, classroom string, ok bool) {
+	m := roomIDReg.FindStringSubmatch(roomID)
+	if m == nil {
+		return 0, "", "", false
+	}
+	area, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, "", "", false
+	}
+	return area, m[2], m[3], true
+}
+
+var buildingIDReg = regexp.MustCompile(`^division_(\d+)_(.+)$`)
+
+// ParseBuildingID 解析结构化课程视图中的教学楼ID，格式 division_{学部}_{教学楼}
+func ParseBuildingID(buildingID string) (area int, building string, ok bool) {
+	m := buildingIDReg.FindStringSubmatch(buildingID)
+	if m == nil {
+		return 0, "", false
+	}
+	area, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, "", false
+	}
+	return area, m[2], true
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services/course"
+	"cengkeHelperBackGo/pkg/auditscore"
+	"cengkeHelperBackGo/pkg/clock"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// auditorScoreWindowDays 计算平均打卡人数时回看的天数
+const auditorScoreWindowDays = 14
+
+// CourseAuditService 课程旁听态度、打卡与蹭课友好度相关服务
+type CourseAuditService struct{}
+
+// NewCourseAuditService 创建 CourseAuditService 实例
+func NewCourseAuditService() *CourseAuditService {
+	return &CourseAuditService{}
+}
+
+// ensureCourseExists 检查课程是否存在
+func ensureCourseExists(db *gorm.DB, courseID uint32) error {
+	var c dto.CourseInfo
+	if err := db.Select("id").First(&c, courseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(config.MsgCourseNotFound)
+		}
+		return fmt.Errorf("查询课程失败: %w", err)
+	}
+	return nil
+}
+
+// SubmitProposal 用户提议修改课程的旁听态度，进入审核队列
+func (s *CourseAuditService) SubmitProposal(courseID, userID uint32, payload dto.AuditPolicyProposalCreateDTO) (*vo.AuditPolicyProposalVO, error) {
+	if err := ensureCourseExists(database.Client, courseID); err != nil {
+		return nil, err
+	}
+
+	var pending int64
+	if err := database.Client.Model(&dto.CourseAuditPolicyProposal{}).
+		Where("course_id = ? AND user_id = ? AND status = ?", courseID, userID, dto.ProposalStatusPending).
+		Count(&pending).Error; err != nil {
+		return nil, fmt.Errorf("查询待审核提议失败: %w", err)
+	}
+	if pending > 0 {
+		return nil, errors.New(config.MsgProposalAlreadyExists)
+	}
+
+	proposal := dto.CourseAuditPolicyProposal{
+		CourseID: courseID,
+		UserID:   userID,
+		Policy:   payload.Policy,
+		Reason:   payload.Reason,
+		Status:   dto.ProposalStatusPending,
+	}
+	if err := database.Client.Create(&proposal).Error; err != nil {
+		return nil, fmt.Errorf("保存旁听态度提议失败: %w", err)
+	}
+
+	if err := database.Client.Preload("Course").Preload("User").First(&proposal, proposal.ID).Error; err != nil {
+		return nil, fmt.Errorf("获取提议信息失败: %w", err)
+	}
+	proposalVO := toAuditPolicyProposalVO(proposal)
+	return &proposalVO, nil
+}
+
+// ListProposals 管理员分页查看提议队列，status 为空时返回全部
+func (s *CourseAuditService) ListProposals(status string, page, limit int) (*vo.AuditPolicyProposalListVO, error) {
+	query := database.Client.Model(&dto.CourseAuditPolicyProposal{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计提议数量失败: %w", err)
+	}
+
+	var proposals []dto.CourseAuditPolicyProposal
+	if err := query.Preload("Course").Preload("User").
+		Order("created_at ASC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&proposals).Error; err != nil {
+		return nil, fmt.Errorf("获取提议列表失败: %w", err)
+	}
+
+	items := make([]vo.AuditPolicyProposalVO, 0, len(proposals))
+	for _, p := range proposals {
+		items = append(items, toAuditPolicyProposalVO(p))
+	}
+	return &vo.AuditPolicyProposalListVO{
+		Items:       items,
+		Total:       total,
+		CurrentPage: page,
+		PageSize:    limit,
+	}, nil
+}
+
+// ReviewProposal 管理员审核提议；通过时同步更新课程的旁听态度与友好度，并通知收藏了课程的用户
+func (s *CourseAuditService) ReviewProposal(ctx context.Context, proposalID, reviewerID uint32, approve bool) error {
+	now := clock.Now(ctx)
+	var proposal dto.CourseAuditPolicyProposal
+	err := database.Client.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&proposal, proposalID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(config.MsgProposalNotFound)
+			}
+			return fmt.Errorf("查询提议失败: %w", err)
+		}
+		if proposal.Status != dto.ProposalStatusPending {
+			return errors.New(config.MsgProposalReviewed)
+		}
+
+		status := dto.ProposalStatusRejected
+		if approve {
+			status = dto.ProposalStatusApproved
+		}
+		if err := tx.Model(&proposal).Updates(map[string]interface{}{
+			"status":      status,
+			"reviewer_id": reviewerID,
+			"reviewed_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("更新提议状态失败: %w", err)
+		}
+
+		if !approve {
+			return nil
+		}
+		return setAuditPolicy(tx, proposal.CourseID, proposal.Policy, now)
+	})
+	if err != nil {
+		return err
+	}
+	if approve {
+		notifyCourseUpdated([]uint32{proposal.CourseID}, proposal.UserID, "课程的旁听态度已更新")
+	}
+	return nil
+}
+
+// SetAuditPolicy 管理员直接设置课程的旁听态度
+func (s *CourseAuditService) SetAuditPolicy(ctx context.Context, courseID uint32, policy string) error {
+	if !dto.IsValidAuditPolicy(policy) {
+		return fmt.Errorf("无效的旁听态度: %s", policy)
+	}
+	if err := ensureCourseExists(database.Client, courseID); err != nil {
+		return err
+	}
+	now := clock.Now(ctx)
+	if err := database.Client.Transaction(func(tx *gorm.DB) error {
+		return setAuditPolicy(tx, courseID, policy, now)
+	}); err != nil {
+		return err
+	}
+	notifyCourseUpdated([]uint32{courseID}, 0, "课程的旁听态度已更新")
+	return nil
+}
+
+// setAuditPolicy 更新课程旁听态度并重算友好度，需在事务中调用
+func setAuditPolicy(tx *gorm.DB, courseID uint32, policy string, now time.Time) error {
+	if err := tx.Model(&dto.CourseInfo{}).Where("id = ?", courseID).
+		UpdateColumn("audit_policy", policy).Error; err != nil {
+		return fmt.Errorf("更新课程旁听态度失败: %w", err)
+	}
+	return recomputeAuditorScore(tx, courseID, now)
+}
+
+// CheckIn 用户在课程打卡（每人每课每天一次），并更新课程友好度
+func (s *CourseAuditService) CheckIn(ctx context.Context, courseID, userID uint32) (*vo.CourseCheckInVO, error) {
+	if err := ensureCourseExists(database.Client, courseID); err != nil {
+		return nil, err
+	}
+
+	now := clock.Now(ctx)
+	weekNum, weekday, lessonNum := NewCourseStructureService().TimeToNums(now, course.SemesterBeginDate)
+	checkIn := dto.CourseCheckIn{
+		CourseID:    courseID,
+		UserID:      userID,
+		CheckInDate: now.Format(time.DateOnly),
+		WeekNum:     weekNum,
+		DayOfWeek:   weekday,
+		LessonNum:   lessonNum,
+	}
+
+	var todayCount int64
+	var score float32
+	err := database.Client.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&dto.CourseCheckIn{}).
+			Where("course_id = ? AND user_id = ? AND check_in_date = ?", courseID, userID, checkIn.CheckInDate).
+			Count(&existing).Error; err != nil {
+			return fmt.Errorf("查询打卡记录失败: %w", err)
+		}
+		if existing > 0 {
+			return errors.New(config.MsgAlreadyCheckedIn)
+		}
+		if err := tx.Create(&checkIn).Error; err != nil {
+			return fmt.Errorf("保存打卡记录失败: %w", err)
+		}
+		if err := tx.Model(&dto.CourseCheckIn{}).
+			Where("course_id = ? AND check_in_date = ?", courseID, checkIn.CheckInDate).
+			Count(&todayCount).Error; err != nil {
+			return fmt.Errorf("统计今日打卡人数失败: %w", err)
+		}
+		if err := recomputeAuditorScore(tx, courseID, now); err != nil {
+			return err
+		}
+		var updated dto.CourseInfo
+		if err := tx.Select("auditor_score").First(&updated, courseID).Error; err != nil {
+			return fmt.Errorf("获取课程友好度失败: %w", err)
+		}
+		score = updated.AuditorScore
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vo.CourseCheckInVO{
+		CourseID:     courseID,
+		CheckInDate:  checkIn.CheckInDate,
+		TodayCount:   todayCount,
+		AuditorScore: score,
+	}, nil
+}
+
+// RecomputeAllScores 重新计算所有课程的蹭课友好度，返回处理的课程数。
+// 近期打卡人数按时间窗口统计，分数会随时间变化；同时为从未打卡、未改过态度的课程补齐初始分数
+func (s *CourseAuditService) RecomputeAllScores(ctx context.Context) (int, error) {
+	db := database.Client.WithContext(ctx)
+	now := clock.Now(ctx)
+	processed := 0
+	var lastID uint32
+	for {
+		var courseIDs []uint32
+		if err := db.Model(&dto.CourseInfo{}).Where("id > ?", lastID).Order("id").
+			Limit(500).Pluck("id", &courseIDs).Error; err != nil {
+			return processed, fmt.Errorf("查询课程失败: %w", err)
+		}
+		if len(courseIDs) == 0 {
+			return processed, nil
+		}
+		for _, courseID := range courseIDs {
+			if err := recomputeAuditorScore(db, courseID, now); err != nil {
+				return processed, err
+			}
+			processed++
+		}
+		lastID = courseIDs[len(courseIDs)-1]
+	}
+}
+
+// recomputeAuditorScore 结合旁听态度、教室容量与打卡数据重新计算课程的蹭课友好度
+func recomputeAuditorScore(db *gorm.DB, courseID uint32, now time.Time) error {
+	var info dto.CourseInfo
+	if err := db.Select("id", "audit_policy").First(&info, courseID).Error; err != nil {
+		return fmt.Errorf("查询课程失败: %w", err)
+	}
+
+	// 课程所有上课教室中最大的容量（rooms 表按教室编号匹配，可能缺失）
+	var capacity int
+	if err := db.Raw(`
+		SELECT COALESCE(MAX(r.capacity), 0)
+		FROM time_infos ti
+		JOIN rooms r ON r.room_number = ti.classroom
+		WHERE ti.course_info_id = ?`, courseID).Scan(&capacity).Error; err != nil {
+		log.Printf("Service: 查询课程 %d 教室容量失败，按未知容量处理: %v", courseID, err)
+		capacity = 0
+	}
+
+	type checkInStats struct {
+		Total    int64
+		Sessions int64
+	}
+	var recent checkInStats
+	since := now.AddDate(0, 0, -auditorScoreWindowDays).Format(time.DateOnly)
+	if err := db.Model(&dto.CourseCheckIn{}).
+		Select("COUNT(*) AS total, COUNT(DISTINCT check_in_date) AS sessions").
+		Where("course_id = ? AND check_in_date >= ?", courseID, since).
+		Scan(&recent).Error; err != nil {
+		return fmt.Errorf("统计近期打卡失败: %w", err)
+	}
+	avgHeadcount := 0.0
+	if recent.Sessions > 0 {
+		avgHeadcount = float64(recent.Total) / float64(recent.Sessions)
+	}
+
+	var distinctAuditors int64
+	if err := db.Model(&dto.CourseCheckIn{}).
+		Where("course_id = ?", courseID).
+		Distinct("user_id").
+		Count(&distinctAuditors).Error; err != nil {
+		return fmt.Errorf("统计打卡人数失败: %w", err)
+	}
+
+	score := auditscore.Score(info.AuditPolicy, capacity, avgHeadcount, int(distinctAuditors))
+	if err := db.Model(&dto.CourseInfo{}).Where("id = ?", courseID).
+		UpdateColumn("auditor_score", score).Error; err != nil {
+		return fmt.Errorf("更新课程友好度失败: %w", err)
+	}
+	return nil
+}
+
+// toAuditPolicyProposalVO 将提议模型转换为 VO（需预加载 Course 与 User）
+func toAuditPolicyProposalVO(p dto.CourseAuditPolicyProposal) vo.AuditPolicyProposalVO {
+	return vo.AuditPolicyProposalVO{
+		ID:           p.ID,
+		CourseID:     p.CourseID,
+		CourseName:   p.Course.CourseName,
+		TeacherName:  p.Course.Teacher,
+		UserID:       p.UserID,
+		Username:     p.User.Username,
+		Policy:       p.Policy,
+		Reason:       p.Reason,
+		Status:       p.Status,
+		CreatedAt:    p.CreatedAt,
+		ReviewedAt:   p.ReviewedAt,
+		CurrentValue: p.Course.AuditPolicy,
+	}
+}
@@ -0,0 +1,581 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services/course"
+	"cengkeHelperBackGo/pkg/clock"
+	"cengkeHelperBackGo/pkg/generator"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCorrection 勘误内容不合法（字段不允许修改、取值非法或与原值相同）
+var ErrInvalidCorrection = errors.New("勘误内容不合法")
+
+// CourseCorrectionService 课程勘误提交、审核与数据覆盖服务
+type CourseCorrectionService struct{}
+
+// NewCourseCorrectionService 创建 CourseCorrectionService 实例
+func NewCourseCorrectionService() *CourseCorrectionService {
+	return &CourseCorrectionService{}
+}
+
+// SubmitCorrection 用户提交课程勘误，记录每处修改的原值，进入审核队列
+func (s *CourseCorrectionService) SubmitCorrection(courseID, userID uint32, payload dto.CourseCorrectionCreateDTO) (*vo.CourseCorrectionVO, error) {
+	var courseInfo dto.CourseInfo
+	if err := database.Client.First(&courseInfo, courseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgCourseNotFound)
+		}
+		return nil, fmt.Errorf("查询课程失败: %w", err)
+	}
+
+	var timeInfos []dto.TimeInfo
+	if err := database.Client.Where("course_info_id = ?", courseID).Find(&timeInfos).Error; err != nil {
+		return nil, fmt.Errorf("查询课程时间信息失败: %w", err)
+	}
+	timeInfoByID := make(map[uint32]dto.TimeInfo, len(timeInfos))
+	for _, ti := range timeInfos {
+		timeInfoByID[ti.ID] = ti
+	}
+
+	items := make([]dto.CourseCorrectionItem, 0, len(payload.Items))
+	for _, item := range payload.Items {
+		newValue := strings.TrimSpace(item.NewValue)
+		var oldValue string
+		switch item.Target {
+		case dto.CorrectionTargetCourse:
+			if !dto.CorrectableCourseFields[item.Field] {
+				return nil, fmt.Errorf("%w: 字段 %s 不允许修改", ErrInvalidCorrection, item.Field)
+			}
+			if err := validateCourseFieldValue(item.Field, newValue); err != nil {
+				return nil, err
+			}
+			oldValue = readCourseField(courseInfo, item.Field)
+			item.TimeInfoID = nil
+		case dto.CorrectionTargetTime:
+			if !dto.CorrectableTimeFields[item.Field] {
+				return nil, fmt.Errorf("%w: 字段 %s 不允许修改", ErrInvalidCorrection, item.Field)
+			}
+			if item.TimeInfoID == nil {
+				return nil, fmt.Errorf("%w: 修改上课时间地点时必须指定 timeInfoId", ErrInvalidCorrection)
+			}
+			ti, ok := timeInfoByID[*item.TimeInfoID]
+			if !ok {
+				return nil, fmt.Errorf("%w: 时间段 %d 不属于该课程", ErrInvalidCorrection, *item.TimeInfoID)
+			}
+			if err := validateTimeFieldValue(ti, item.Field, newValue); err != nil {
+				return nil, err
+			}
+			oldValue = readTimeField(ti, item.Field)
+		}
+		if item.Field != dto.CorrectionFieldCancelledWeeks && oldValue == newValue {
+			return nil, fmt.Errorf("%w: 字段 %s 的新值与原值相同", ErrInvalidCorrection, item.Field)
+		}
+
+		items = append(items, dto.CourseCorrectionItem{
+			Target:     item.Target,
+			TimeInfoID: item.TimeInfoID,
+			Field:      item.Field,
+			OldValue:   oldValue,
+			NewValue:   newValue,
+		})
+	}
+
+	correction := dto.CourseCorrection{
+		CourseID: courseID,
+		UserID:   userID,
+		Reason:   payload.Reason,
+		Status:   dto.ProposalStatusPending,
+		Items:    items,
+	}
+	// Items 通过关联一并写入
+	if err := database.Client.Create(&correction).Error; err != nil {
+		return nil, fmt.Errorf("保存勘误失败: %w", err)
+	}
+
+	if err := preloadCorrection(database.Client).First(&correction, correction.ID).Error; err != nil {
+		return nil, fmt.Errorf("获取勘误信息失败: %w", err)
+	}
+	correctionVO := toCourseCorrectionVO(correction)
+	return &correctionVO, nil
+}
+
+// ListCorrections 管理员分页查看勘误队列，status 为空时返回全部
+func (s *CourseCorrectionService) ListCorrections(status string, page, limit int) (*vo.CourseCorrectionListVO, error) {
+	query := database.Client.Model(&dto.CourseCorrection{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return listCorrections(query, "created_at ASC", page, limit)
+}
+
+// ListUserCorrections 用户查看自己提交的勘误及审核结果
+func (s *CourseCorrectionService) ListUserCorrections(userID uint32, page, limit int) (*vo.CourseCorrectionListVO, error) {
+	query := database.Client.Model(&dto.CourseCorrection{}).Where("user_id = ?", userID)
+	return listCorrections(query, "created_at DESC", page, limit)
+}
+
+// listCorrections 勘误列表的公共分页逻辑
+func listCorrections(query *gorm.DB, order string, page, limit int) (*vo.CourseCorrectionListVO, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计勘误数量失败: %w", err)
+	}
+
+	var corrections []dto.CourseCorrection
+	if err := preloadCorrection(query).
+		Order(order).
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&corrections).Error; err != nil {
+		return nil, fmt.Errorf("获取勘误列表失败: %w", err)
+	}
+
+	items := make([]vo.CourseCorrectionVO, 0, len(corrections))
+	for _, c := range corrections {
+		items = append(items, toCourseCorrectionVO(c))
+	}
+	return &vo.CourseCorrectionListVO{
+		Items:       items,
+		Total:       total,
+		CurrentPage: page,
+		PageSize:    limit,
+	}, nil
+}
+
+// GetCourseContributors 返回为该课程贡献过被采纳勘误的用户，按采纳次数降序
+func (s *CourseCorrectionService) GetCourseContributors(courseID uint32) ([]vo.CorrectionContributorVO, error) {
+	contributors := make([]vo.CorrectionContributorVO, 0)
+	err := database.Client.Table("course_corrections cc").
+		Select("u.id AS user_id, u.username, u.avatar, COUNT(*) AS approved_count").
+		Joins("JOIN users u ON u.id = cc.user_id").
+		Where("cc.course_id = ? AND cc.status = ?", courseID, dto.ProposalStatusApproved).
+		Group("u.id, u.username, u.avatar").
+		Order("approved_count DESC").
+		Scan(&contributors).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取课程贡献者失败: %w", err)
+	}
+	return contributors, nil
+}
+
+// ReviewCorrection 管理员审核勘误；通过时写入数据覆盖并立即套用到当前数据，审核结果通知提交者
+func (s *CourseCorrectionService) ReviewCorrection(ctx context.Context, correctionID, reviewerID uint32, approve bool, note string) error {
+	now := clock.Now(ctx)
+	var correction dto.CourseCorrection
+	err := database.Client.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Items").Preload("Course").First(&correction, correctionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(config.MsgCorrectionNotFound)
+			}
+			return fmt.Errorf("查询勘误失败: %w", err)
+		}
+		if correction.Status != dto.ProposalStatusPending {
+			return errors.New(config.MsgCorrectionReviewed)
+		}
+
+		status := dto.ProposalStatusRejected
+		if approve {
+			status = dto.ProposalStatusApproved
+		}
+		if err := tx.Model(&correction).Updates(map[string]interface{}{
+			"status":      status,
+			"reviewer_id": reviewerID,
+			"review_note": note,
+			"reviewed_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("更新勘误状态失败: %w", err)
+		}
+
+		if !approve {
+			return nil
+		}
+
+		overrides := make([]dto.CourseDataOverride, 0, len(correction.Items))
+		for _, item := range correction.Items {
+			override, err := upsertOverride(tx, correction.Course, correction.ID, item)
+			if err != nil {
+				return err
+			}
+			overrides = append(overrides, *override)
+		}
+		if _, err := applyOverrides(tx, overrides); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 勘误生效后离线同步数据随之产生新版本，并通知收藏了该课程的用户
+	if approve {
+		NewCourseSyncService().RefreshAfter(ctx, dto.SyncReasonCorrection)
+		notifyCourseUpdated([]uint32{correction.CourseID}, correction.UserID, "课程信息已根据用户勘误更新")
+	}
+
+	message := fmt.Sprintf("你为「%s」提交的勘误已通过审核，课程信息已更新。感谢你的贡献！", correction.Course.CourseName)
+	if !approve {
+		message = fmt.Sprintf("你为「%s」提交的勘误未通过审核。", correction.Course.CourseName)
+	}
+	if note != "" {
+		message += "审核说明：" + note
+	}
+	notifyAsync(notificationEvent{
+		userID:     correction.UserID,
+		kind:       dto.NotificationModeration,
+		targetType: dto.NotificationTargetCourse,
+		targetID:   correction.CourseID,
+		message:    message,
+	})
+	return nil
+}
+
+// ApplyAllOverrides 将所有已通过的数据覆盖重新套用到当前数据，应在每次导入教务数据后调用
+func (s *CourseCorrectionService) ApplyAllOverrides(ctx context.Context) (*vo.OverrideApplyResultVO, error) {
+	var result *vo.OverrideApplyResultVO
+	err := database.Client.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var overrides []dto.CourseDataOverride
+		if err := tx.Find(&overrides).Error; err != nil {
+			return fmt.Errorf("查询数据覆盖失败: %w", err)
+		}
+		applied, err := applyOverrides(tx, overrides)
+		if err != nil {
+			return err
+		}
+		result = &vo.OverrideApplyResultVO{
+			Total:   len(overrides),
+			Applied: applied,
+			Missed:  len(overrides) - applied,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 该接口在导入教务数据后调用，同时检查导入带来的数据变化与数据质量
+	NewCourseSyncService().RefreshAfter(ctx, dto.SyncReasonImport)
+	NewDataLintService().RunAfterImport(ctx)
+	return result, nil
+}
+
+// upsertOverride 将勘误中的一处修改写入勘误所针对教学班的覆盖表；同一自然键已存在时覆盖其值（停课周次取并集）
+func upsertOverride(tx *gorm.DB, section dto.CourseInfo, correctionID uint32, item dto.CourseCorrectionItem) (*dto.CourseDataOverride, error) {
+	override := dto.CourseDataOverride{
+		CourseInfoID: section.ID,
+		CourseNum:    section.CourseNum,
+		Target:       item.Target,
+		Field:        item.Field,
+		Value:        item.NewValue,
+		TimeInfoID:   item.TimeInfoID,
+		CorrectionID: correctionID,
+	}
+
+	if item.Target == dto.CorrectionTargetTime {
+		timeKey, err := resolveTimeKey(tx, section.ID, *item.TimeInfoID)
+		if err != nil {
+			return nil, err
+		}
+		override.TimeKey = timeKey
+	}
+
+	if item.Field == dto.CorrectionFieldCancelledWeeks {
+		var existing dto.CourseDataOverride
+		err := tx.Where("course_info_id = ? AND target = ? AND time_key = ? AND field = ?",
+			override.CourseInfoID, override.Target, override.TimeKey, override.Field).First(&existing).Error
+		if err == nil {
+			override.Value = mergeWeekLists(existing.Value, override.Value)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("查询已有数据覆盖失败: %w", err)
+		}
+	}
+
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "course_info_id"}, {Name: "target"}, {Name: "time_key"}, {Name: "field"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "time_info_id", "correction_id", "updated_at"}),
+	}).Create(&override).Error; err != nil {
+		return nil, fmt.Errorf("保存数据覆盖失败: %w", err)
+	}
+	// 冲突更新时 MySQL 不回填已有记录的主键，按自然键重新读取
+	if err := tx.Where("course_info_id = ? AND target = ? AND time_key = ? AND field = ?",
+		override.CourseInfoID, override.Target, override.TimeKey, override.Field).First(&override).Error; err != nil {
+		return nil, fmt.Errorf("读取数据覆盖失败: %w", err)
+	}
+	return &override, nil
+}
+
+// resolveTimeKey 确定时间段的自然键：若该时间段已被覆盖过，沿用首次覆盖时记录的原始值
+func resolveTimeKey(tx *gorm.DB, courseInfoID uint32, timeInfoID uint32) (string, error) {
+	var existing dto.CourseDataOverride
+	err := tx.Where("course_info_id = ? AND target = ? AND time_info_id = ?", courseInfoID, dto.CorrectionTargetTime, timeInfoID).
+		First(&existing).Error
+	if err == nil {
+		return existing.TimeKey, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("查询已有数据覆盖失败: %w", err)
+	}
+
+	var ti dto.TimeInfo
+	if err := tx.First(&ti, timeInfoID).Error; err != nil {
+		return "", fmt.Errorf("查询时间段失败: %w", err)
+	}
+	return ti.OverrideKey(), nil
+}
+
+// overrideTimeGroup 同一教学班同一时间段的覆盖
+type overrideTimeGroup struct {
+	courseInfoID uint32
+	timeKey      string
+}
+
+// applyOverrides 将覆盖套用到所针对教学班的 course_infos / time_infos，返回成功套用的条数
+func applyOverrides(tx *gorm.DB, overrides []dto.CourseDataOverride) (int, error) {
+	applied := 0
+	timeGroups := make(map[overrideTimeGroup][]dto.CourseDataOverride)
+
+	for _, o := range overrides {
+		if o.Target == dto.CorrectionTargetCourse {
+			res := tx.Model(&dto.CourseInfo{}).Where("id = ?", o.CourseInfoID).UpdateColumn(o.Field, o.Value)
+			if res.Error != nil {
+				return applied, fmt.Errorf("套用课程覆盖失败: %w", res.Error)
+			}
+			if res.RowsAffected > 0 || courseExistsByID(tx, o.CourseInfoID) {
+				applied++
+			}
+			continue
+		}
+		key := overrideTimeGroup{courseInfoID: o.CourseInfoID, timeKey: o.TimeKey}
+		timeGroups[key] = append(timeGroups[key], o)
+	}
+
+	// 同一时间段的多个字段先统一定位再逐个套用，避免前一个字段改动后无法按原始值匹配
+	for key, group := range timeGroups {
+		var timeInfos []dto.TimeInfo
+		if err := tx.Where("course_info_id = ?", key.courseInfoID).Find(&timeInfos).Error; err != nil {
+			return applied, fmt.Errorf("查询课程时间段失败: %w", err)
+		}
+		ti := dto.LocateOverrideTimeInfo(timeInfos, key.courseInfoID, key.timeKey, group)
+		if ti == nil {
+			log.Printf("Service: 数据覆盖未找到对应时间段 course_info_id=%d time_key=%s", key.courseInfoID, key.timeKey)
+			continue
+		}
+
+		// 停课周次需要在 week_and_time 覆盖之后套用
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[j].Field == dto.CorrectionFieldCancelledWeeks && group[i].Field != dto.CorrectionFieldCancelledWeeks
+		})
+		for _, o := range group {
+			if err := applyTimeOverride(tx, ti, o); err != nil {
+				return applied, err
+			}
+			if err := tx.Model(&dto.CourseDataOverride{}).Where("id = ?", o.ID).
+				UpdateColumn("time_info_id", ti.ID).Error; err != nil {
+				return applied, fmt.Errorf("更新数据覆盖失败: %w", err)
+			}
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// applyTimeOverride 套用单个时间段字段覆盖，并同步更新内存中的 ti
+func applyTimeOverride(tx *gorm.DB, ti *dto.TimeInfo, o dto.CourseDataOverride) error {
+	var column string
+	var value interface{}
+	switch o.Field {
+	case "building":
+		ti.Building, column, value = o.Value, "building", o.Value
+	case "classroom":
+		ti.Classroom, column, value = o.Value, "classroom", o.Value
+	case "area":
+		area, _ := strconv.Atoi(o.Value)
+		ti.Area, column, value = uint8(area), "area", area
+	case "day_of_week":
+		day, _ := strconv.Atoi(o.Value)
+		ti.DayOfWeek, column, value = uint8(day), "day_of_week", day
+	case "week_and_time":
+		wat, _ := strconv.ParseUint(o.Value, 10, 32)
+		ti.WeekAndTime, column, value = uint32(wat), "week_and_time", uint32(wat)
+	case dto.CorrectionFieldCancelledWeeks:
+		weeks, err := course.ParseWeekList(o.Value)
+		if err != nil {
+			return fmt.Errorf("数据覆盖 %d 的停课周次无效: %w", o.ID, err)
+		}
+		ti.WeekAndTime = course.ClearWeeks(ti.WeekAndTime, weeks)
+		column, value = "week_and_time", ti.WeekAndTime
+	default:
+		return fmt.Errorf("数据覆盖 %d 的字段 %s 不支持", o.ID, o.Field)
+	}
+
+	if err := tx.Model(&dto.TimeInfo{}).Where("id = ?", ti.ID).UpdateColumn(column, value).Error; err != nil {
+		return fmt.Errorf("套用时间段覆盖失败: %w", err)
+	}
+	return nil
+}
+
+// courseExistsByID 判断教学班是否存在（UpdateColumn 值未变化时 RowsAffected 为 0）
+func courseExistsByID(tx *gorm.DB, courseInfoID uint32) bool {
+	var count int64
+	tx.Model(&dto.CourseInfo{}).Where("id = ?", courseInfoID).Count(&count)
+	return count > 0
+}
+
+// mergeWeekLists 合并两个逗号分隔的周次列表并去重排序
+func mergeWeekLists(a, b string) string {
+	set := make(map[int]bool)
+	for _, s := range []string{a, b} {
+		weeks, err := course.ParseWeekList(s)
+		if err != nil {
+			continue
+		}
+		for _, w := range weeks {
+			set[w] = true
+		}
+	}
+	weeks := make([]int, 0, len(set))
+	for w := range set {
+		weeks = append(weeks, w)
+	}
+	sort.Ints(weeks)
+	return joinInts(weeks)
+}
+
+// joinInts 将整数列表拼接为逗号分隔字符串
+func joinInts(nums []int) string {
+	parts := make([]string, 0, len(nums))
+	for _, n := range nums {
+		parts = append(parts, strconv.Itoa(n))
+	}
+	return strings.Join(parts, ",")
+}
+
+// readCourseField 读取课程字段的当前值
+func readCourseField(c dto.CourseInfo, field string) string {
+	switch field {
+	case "course_name":
+		return c.CourseName
+	case "teacher":
+		return c.Teacher
+	case "teacher_title":
+		return c.TeacherTitle
+	case "credit":
+		return c.Credit
+	case "course_type":
+		return c.CourseType
+	case "faculty":
+		return c.Faculty
+	}
+	return ""
+}
+
+// readTimeField 读取时间段字段的当前值；停课周次返回当前所有上课周次
+func readTimeField(ti dto.TimeInfo, field string) string {
+	switch field {
+	case "building":
+		return ti.Building
+	case "classroom":
+		return ti.Classroom
+	case "area":
+		return strconv.Itoa(int(ti.Area))
+	case "day_of_week":
+		return strconv.Itoa(int(ti.DayOfWeek))
+	case "week_and_time":
+		return strconv.FormatUint(uint64(ti.WeekAndTime), 10)
+	case dto.CorrectionFieldCancelledWeeks:
+		weeks, _ := generator.Bin2WeekLesson(ti.WeekAndTime)
+		return joinInts(weeks)
+	}
+	return ""
+}
+
+// validateCourseFieldValue 校验课程字段的新值
+func validateCourseFieldValue(field, value string) error {
+	if value == "" {
+		return fmt.Errorf("%w: 字段 %s 不能为空", ErrInvalidCorrection, field)
+	}
+	if field == "credit" {
+		if credit, err := strconv.ParseFloat(value, 32); err != nil || credit < 0 {
+			return fmt.Errorf("%w: 学分格式不正确", ErrInvalidCorrection)
+		}
+	}
+	return nil
+}
+
+// validateTimeFieldValue 校验时间段字段的新值
+func validateTimeFieldValue(ti dto.TimeInfo, field, value string) error {
+	switch field {
+	case "building", "classroom":
+		if value == "" {
+			return fmt.Errorf("%w: 字段 %s 不能为空", ErrInvalidCorrection, field)
+		}
+	case "area":
+		if area, err := strconv.Atoi(value); err != nil || area < 1 || area > 4 {
+			return fmt.Errorf("%w: 学部取值为 1-4", ErrInvalidCorrection)
+		}
+	case "day_of_week":
+		if day, err := strconv.Atoi(value); err != nil || day < 0 || day > 6 {
+			return fmt.Errorf("%w: 星期取值为 0-6", ErrInvalidCorrection)
+		}
+	case "week_and_time":
+		if wat, err := strconv.ParseUint(value, 10, 32); err != nil || wat == 0 {
+			return fmt.Errorf("%w: week_and_time 编码不正确", ErrInvalidCorrection)
+		}
+	case dto.CorrectionFieldCancelledWeeks:
+		weeks, err := course.ParseWeekList(value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCorrection, err)
+		}
+		for _, w := range weeks {
+			if !generator.IsWeekLessonMatch(w, -1, ti.WeekAndTime) {
+				return fmt.Errorf("%w: 第%d周本来就没有课", ErrInvalidCorrection, w)
+			}
+		}
+	}
+	return nil
+}
+
+// preloadCorrection 预加载勘误 VO 所需的关联
+func preloadCorrection(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items").Preload("Course").Preload("User")
+}
+
+// toCourseCorrectionVO 将勘误模型转换为 VO（需预加载 Items、Course 与 User）
+func toCourseCorrectionVO(c dto.CourseCorrection) vo.CourseCorrectionVO {
+	items := make([]vo.CourseCorrectionItemVO, 0, len(c.Items))
+	for _, item := range c.Items {
+		items = append(items, vo.CourseCorrectionItemVO{
+			Target:     item.Target,
+			TimeInfoID: item.TimeInfoID,
+			Field:      item.Field,
+			OldValue:   item.OldValue,
+			NewValue:   item.NewValue,
+		})
+	}
+	return vo.CourseCorrectionVO{
+		ID:         c.ID,
+		CourseID:   c.CourseID,
+		CourseName: c.Course.CourseName,
+		CourseCode: c.Course.CourseNum,
+		UserID:     c.UserID,
+		Username:   c.User.Username,
+		Reason:     c.Reason,
+		Status:     c.Status,
+		ReviewNote: c.ReviewNote,
+		CreatedAt:  c.CreatedAt,
+		ReviewedAt: c.ReviewedAt,
+		Items:      items,
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"errors"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// toCourseCardVO 将课程模型转换为课程卡片
+func toCourseCardVO(course dto.CourseInfo) vo.CourseCardVO {
+	return vo.CourseCardVO{
+		ID:            course.ID,
+		CourseName:    course.CourseName,
+		CourseCode:    course.CourseNum,
+		TeacherName:   course.Teacher,
+		TeacherTitle:  course.TeacherTitle,
+		Faculty:       course.Faculty,
+		CourseType:    course.CourseType,
+		AverageRating: course.AverageRating,
+		ReviewCount:   course.ReviewCount,
+		FavoriteCount: course.FavoriteCount,
+	}
+}
+
+// ToggleFavoriteCourse 收藏或取消收藏课程，并在同一事务中维护 course_infos.favorite_count。
+// 以删除和插入的影响行数判断状态，并发的重复收藏由 (user_id, course_id) 主键去重并视为已收藏，计数不会重复增加
+func (s *CourseService) ToggleFavoriteCourse(courseID uint32, userID uint32) (*vo.ToggleFavoriteCourseResponseDataVO, error) {
+	var course dto.CourseInfo
+	if err := database.Client.Select("id").First(&course, courseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgCourseNotFound)
+		}
+		return nil, fmt.Errorf("查询课程失败: %w", err)
+	}
+
+	isFavorited := false
+	err := database.Client.Transaction(func(tx *gorm.DB) error {
+		// 已收藏 -> 取消收藏
+		deleted := tx.Where("user_id = ? AND course_id = ?", userID, courseID).Delete(&dto.UserCourseFavorite{})
+		if deleted.Error != nil {
+			return fmt.Errorf("删除课程收藏记录失败: %w", deleted.Error)
+		}
+		if deleted.RowsAffected > 0 {
+			if err := tx.Model(&dto.CourseInfo{}).Where("id = ? AND favorite_count > 0", courseID).
+				UpdateColumn("favorite_count", gorm.Expr("favorite_count - 1")).Error; err != nil {
+				return fmt.Errorf("减少课程收藏数失败: %w", err)
+			}
+			isFavorited = false
+			return nil
+		}
+
+		// 未收藏 -> 收藏；主键冲突说明另一个请求刚刚收藏，视为已收藏
+		isFavorited = true
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dto.UserCourseFavorite{UserID: userID, CourseID: courseID})
+		if created.Error != nil {
+			return fmt.Errorf("创建课程收藏记录失败: %w", created.Error)
+		}
+		if created.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Model(&dto.CourseInfo{}).Where("id = ?", courseID).
+			UpdateColumn("favorite_count", gorm.Expr("favorite_count + 1")).Error; err != nil {
+			return fmt.Errorf("增加课程收藏数失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Service: 切换课程 (ID %d) 收藏状态失败 (用户 %d): %v", courseID, userID, err)
+		return nil, err
+	}
+
+	var updated dto.CourseInfo
+	favoriteCount := -1
+	if err := database.Client.Select("favorite_count").First(&updated, courseID).Error; err == nil {
+		favoriteCount = int(updated.FavoriteCount)
+	}
+
+	return &vo.ToggleFavoriteCourseResponseDataVO{
+		IsFavorited:   isFavorited,
+		FavoriteCount: favoriteCount,
+	}, nil
+}
+
+// GetFavoriteCourses 获取用户收藏的课程卡片，按收藏时间倒序
+func (s *CourseService) GetFavoriteCourses(userID uint32) ([]vo.CourseCardVO, error) {
+	var courses []dto.CourseInfo
+	if err := database.Client.Model(&dto.CourseInfo{}).
+		Joins("JOIN user_course_favorites ucf ON ucf.course_id = course_infos.id").
+		Where("ucf.user_id = ?", userID).
+		Order("ucf.created_at DESC").
+		Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("获取收藏课程失败: %w", err)
+	}
+
+	cards := make([]vo.CourseCardVO, 0, len(courses))
+	for _, course := range courses {
+		cards = append(cards, toCourseCardVO(course))
+	}
+	return cards, nil
+}
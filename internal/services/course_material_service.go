@@ -0,0 +1,498 @@
+package services
+
+import (
+	"bufio"
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/storage"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 课程资料的默认配置
+const (
+	defaultMaterialDir           = "./data/materials"
+	defaultMaterialMaxSizeMB     = 20
+	defaultTrustedMinCorrections = 3
+	materialStorageLocal         = "local"
+	materialSniffLen             = 512
+)
+
+// materialFileType 允许上传的文件类型：响应时使用的 Content-Type，以及内容嗅探结果应有的前缀
+type materialFileType struct {
+	contentType string
+	sniffPrefix string
+}
+
+// allowedMaterialTypes 按扩展名允许的文件类型；旧版 Office 文件嗅探结果为 octet-stream，新版为 zip
+var allowedMaterialTypes = map[string]materialFileType{
+	".pdf":  {"application/pdf", "application/pdf"},
+	".doc":  {"application/msword", "application/octet-stream"},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip"},
+	".ppt":  {"application/vnd.ms-powerpoint", "application/octet-stream"},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", "application/zip"},
+	".xls":  {"application/vnd.ms-excel", "application/octet-stream"},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "application/zip"},
+	".zip":  {"application/zip", "application/zip"},
+	".txt":  {"text/plain; charset=utf-8", "text/plain"},
+	".md":   {"text/markdown; charset=utf-8", "text/plain"},
+	".png":  {"image/png", "image/png"},
+	".jpg":  {"image/jpeg", "image/jpeg"},
+	".jpeg": {"image/jpeg", "image/jpeg"},
+}
+
+var (
+	materialStorageOnce sync.Once
+	materialStorageInst storage.Storage
+	materialStorageErr  error
+)
+
+// materialStorage 按配置创建存储后端，首次使用时初始化
+func materialStorage() (storage.Storage, error) {
+	materialStorageOnce.Do(func() {
+		switch backend := config.Conf.Materials.Storage; backend {
+		case "", materialStorageLocal:
+			dir := config.Conf.Materials.LocalDir
+			if dir == "" {
+				dir = defaultMaterialDir
+			}
+			materialStorageInst, materialStorageErr = storage.NewLocal(dir)
+		default:
+			materialStorageErr = fmt.Errorf("不支持的课程资料存储后端: %s", backend)
+		}
+	})
+	return materialStorageInst, materialStorageErr
+}
+
+// MaterialUpload 上传的文件
+type MaterialUpload struct {
+	FileName string
+	Size     int64
+	Reader   io.Reader
+}
+
+// MaterialDownload 下载的资料：文件类资料有 Reader，链接类资料只有 LinkURL
+type MaterialDownload struct {
+	Reader      io.ReadCloser
+	FileName    string
+	ContentType string
+	Size        int64
+	LinkURL     string
+}
+
+// CourseMaterialService 课程资料（教学大纲、课件、阅读书目）
+type CourseMaterialService struct{}
+
+// NewCourseMaterialService 创建 CourseMaterialService 实例
+func NewCourseMaterialService() *CourseMaterialService {
+	return &CourseMaterialService{}
+}
+
+// ensureCanUpload 管理员，或勘误被采纳次数达到阈值的用户可以上传资料
+func ensureCanUpload(db *gorm.DB, userID uint32, isAdmin bool) error {
+	if isAdmin {
+		return nil
+	}
+	threshold := config.Conf.Materials.TrustedMinCorrections
+	if threshold <= 0 {
+		threshold = defaultTrustedMinCorrections
+	}
+	var approved int64
+	if err := db.Model(&dto.CourseCorrection{}).
+		Where("user_id = ? AND status = ?", userID, dto.ProposalStatusApproved).
+		Count(&approved).Error; err != nil {
+		return fmt.Errorf("查询用户勘误记录失败: %w", err)
+	}
+	if approved < int64(threshold) {
+		return errors.New(config.MsgMaterialNotTrusted)
+	}
+	return nil
+}
+
+// CreateMaterial 为课程新建一份资料，上传文件或填写链接二选一
+func (s *CourseMaterialService) CreateMaterial(ctx context.Context, courseID, userID uint32, isAdmin bool, payload dto.CourseMaterialCreateDTO, upload *MaterialUpload) (*vo.CourseMaterialVO, error) {
+	db := database.Client.WithContext(ctx)
+	var courseInfo dto.CourseInfo
+	if err := db.Select("id", "course_num").First(&courseInfo, courseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgCourseNotFound)
+		}
+		return nil, fmt.Errorf("查询课程失败: %w", err)
+	}
+	if err := ensureCanUpload(db, userID, isAdmin); err != nil {
+		return nil, err
+	}
+	if upload == nil && payload.LinkURL == "" {
+		return nil, errors.New(config.MsgMaterialFileRequired)
+	}
+	if upload != nil && payload.LinkURL != "" {
+		return nil, errors.New(config.MsgMaterialFileAndLink)
+	}
+	if payload.LinkURL != "" && !isWebLink(payload.LinkURL) {
+		return nil, errors.New(config.MsgMaterialLinkInvalid)
+	}
+
+	material := dto.CourseMaterial{
+		CourseNum:   courseInfo.CourseNum,
+		Title:       strings.TrimSpace(payload.Title),
+		Kind:        payload.Kind,
+		Description: strings.TrimSpace(payload.Description),
+		UploaderID:  userID,
+	}
+	if upload == nil {
+		material.LinkURL = payload.LinkURL
+		if err := db.Create(&material).Error; err != nil {
+			return nil, fmt.Errorf("保存课程资料失败: %w", err)
+		}
+	} else {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&material).Error; err != nil {
+				return fmt.Errorf("保存课程资料失败: %w", err)
+			}
+			return saveMaterialVersion(ctx, tx, &material, userID, payload.Note, upload)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	notifyCourseUpdated([]uint32{courseID}, userID, fmt.Sprintf("新增课程资料《%s》", material.Title))
+	return s.getMaterialVO(ctx, material.ID)
+}
+
+// isWebLink 链接类资料下载时会直接重定向过去，只允许带主机名的 http/https 地址，
+// 避免 javascript:、data: 等协议被用来构造恶意跳转
+func isWebLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return scheme == "http" || scheme == "https"
+}
+
+// AddVersion 为文件类资料上传新版本，旧版本保留可下载
+func (s *CourseMaterialService) AddVersion(ctx context.Context, materialID, userID uint32, isAdmin bool, note string, upload *MaterialUpload) (*vo.CourseMaterialVO, error) {
+	db := database.Client.WithContext(ctx)
+	if err := ensureCanUpload(db, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var material dto.CourseMaterial
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&material, materialID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(config.MsgMaterialNotFound)
+			}
+			return fmt.Errorf("查询课程资料失败: %w", err)
+		}
+		if material.LinkURL != "" {
+			return errors.New(config.MsgMaterialIsLink)
+		}
+		return saveMaterialVersion(ctx, tx, &material, userID, note, upload)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.getMaterialVO(ctx, materialID)
+}
+
+// saveMaterialVersion 校验并写入文件，记录新版本并更新资料的最新版本号；数据库写入失败时删除已写入的文件
+func saveMaterialVersion(ctx context.Context, tx *gorm.DB, material *dto.CourseMaterial, userID uint32, note string, upload *MaterialUpload) error {
+	maxSize := int64(config.Conf.Materials.MaxSizeMB)
+	if maxSize <= 0 {
+		maxSize = defaultMaterialMaxSizeMB
+	}
+	maxSize <<= 20
+	if upload.Size > maxSize {
+		return errors.New(config.MsgMaterialTooLarge)
+	}
+
+	ext := strings.ToLower(filepath.Ext(upload.FileName))
+	fileType, ok := allowedMaterialTypes[ext]
+	if !ok {
+		return errors.New(config.MsgMaterialTypeNotAllowed)
+	}
+	reader := bufio.NewReaderSize(upload.Reader, materialSniffLen)
+	head, err := reader.Peek(materialSniffLen)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return fmt.Errorf("读取上传文件失败: %w", err)
+	}
+	if !strings.HasPrefix(http.DetectContentType(head), fileType.sniffPrefix) {
+		return errors.New(config.MsgMaterialTypeNotAllowed)
+	}
+
+	store, err := materialStorage()
+	if err != nil {
+		return err
+	}
+	version := material.CurrentVersion + 1
+	key := fmt.Sprintf("materials/%d/v%d%s", material.ID, version, ext)
+
+	// 边写边计算大小与哈希，多读 1 字节用于发现超出上限的文件
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	limited := io.LimitReader(reader, maxSize+1)
+	if err := store.Put(ctx, key, io.TeeReader(limited, io.MultiWriter(hasher, counter))); err != nil {
+		return fmt.Errorf("保存文件失败: %w", err)
+	}
+	cleanup := func() {
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("Service: 清理课程资料文件 %s 失败: %v", key, err)
+		}
+	}
+	if counter.n > maxSize {
+		cleanup()
+		return errors.New(config.MsgMaterialTooLarge)
+	}
+
+	record := dto.CourseMaterialVersion{
+		MaterialID:  material.ID,
+		Version:     version,
+		StorageKey:  key,
+		FileName:    filepath.Base(upload.FileName),
+		ContentType: fileType.contentType,
+		Size:        counter.n,
+		SHA256:      hex.EncodeToString(hasher.Sum(nil)),
+		Note:        strings.TrimSpace(note),
+		UploaderID:  userID,
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		cleanup()
+		return fmt.Errorf("保存资料版本失败: %w", err)
+	}
+	if err := tx.Model(material).Update("current_version", version).Error; err != nil {
+		cleanup()
+		return fmt.Errorf("更新资料版本失败: %w", err)
+	}
+	material.CurrentVersion = version
+	return nil
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// ListCourseMaterials 列出课程（按课程编号，所有教学班共享）的资料，教学大纲排在最前
+func (s *CourseMaterialService) ListCourseMaterials(ctx context.Context, courseID uint32) ([]vo.CourseMaterialVO, error) {
+	db := database.Client.WithContext(ctx)
+	var courseInfo dto.CourseInfo
+	if err := db.Select("id", "course_num").First(&courseInfo, courseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgCourseNotFound)
+		}
+		return nil, fmt.Errorf("查询课程失败: %w", err)
+	}
+
+	var materials []dto.CourseMaterial
+	if err := db.Preload("Uploader").
+		Where("course_num = ?", courseInfo.CourseNum).
+		Order(clause.Expr{SQL: "kind = ? DESC, updated_at DESC, id DESC", Vars: []interface{}{dto.MaterialKindSyllabus}}).
+		Find(&materials).Error; err != nil {
+		return nil, fmt.Errorf("查询课程资料失败: %w", err)
+	}
+	return s.toMaterialVOs(db, materials)
+}
+
+// ListVersions 列出文件类资料的所有版本，新版本在前
+func (s *CourseMaterialService) ListVersions(ctx context.Context, materialID uint32) ([]vo.CourseMaterialVersionVO, error) {
+	db := database.Client.WithContext(ctx)
+	if err := db.Select("id").First(&dto.CourseMaterial{}, materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgMaterialNotFound)
+		}
+		return nil, fmt.Errorf("查询课程资料失败: %w", err)
+	}
+
+	var versions []dto.CourseMaterialVersion
+	if err := db.Preload("Uploader").
+		Where("material_id = ?", materialID).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("查询资料版本失败: %w", err)
+	}
+	result := make([]vo.CourseMaterialVersionVO, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, toMaterialVersionVO(v))
+	}
+	return result, nil
+}
+
+// Download 打开资料用于下载并累加下载次数；version 为 0 时取最新版本
+func (s *CourseMaterialService) Download(ctx context.Context, materialID uint32, version int) (*MaterialDownload, error) {
+	db := database.Client.WithContext(ctx)
+	var material dto.CourseMaterial
+	if err := db.First(&material, materialID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgMaterialNotFound)
+		}
+		return nil, fmt.Errorf("查询课程资料失败: %w", err)
+	}
+
+	// 早于协议校验保存的链接同样不允许跳转到非 http/https 地址
+	if material.LinkURL != "" && !isWebLink(material.LinkURL) {
+		return nil, errors.New(config.MsgMaterialLinkInvalid)
+	}
+	download := &MaterialDownload{LinkURL: material.LinkURL}
+	if material.LinkURL == "" {
+		if version == 0 {
+			version = material.CurrentVersion
+		}
+		var record dto.CourseMaterialVersion
+		if err := db.Where("material_id = ? AND version = ?", materialID, version).First(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New(config.MsgMaterialNotFound)
+			}
+			return nil, fmt.Errorf("查询资料版本失败: %w", err)
+		}
+		store, err := materialStorage()
+		if err != nil {
+			return nil, err
+		}
+		reader, err := store.Open(ctx, record.StorageKey)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, errors.New(config.MsgMaterialNotFound)
+			}
+			return nil, fmt.Errorf("读取资料文件失败: %w", err)
+		}
+		download.Reader = reader
+		download.FileName = record.FileName
+		download.ContentType = record.ContentType
+		download.Size = record.Size
+	}
+
+	if err := db.Model(&dto.CourseMaterial{}).Where("id = ?", materialID).
+		UpdateColumn("download_count", gorm.Expr("download_count + ?", 1)).Error; err != nil {
+		log.Printf("Service: 更新资料 %d 下载次数失败: %v", materialID, err)
+	}
+	return download, nil
+}
+
+// DeleteMaterial 删除资料及其所有版本的文件
+func (s *CourseMaterialService) DeleteMaterial(ctx context.Context, materialID uint32) error {
+	db := database.Client.WithContext(ctx)
+	var versions []dto.CourseMaterialVersion
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&dto.CourseMaterial{}, materialID)
+		if result.Error != nil {
+			return fmt.Errorf("删除课程资料失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(config.MsgMaterialNotFound)
+		}
+		if err := tx.Where("material_id = ?", materialID).Find(&versions).Error; err != nil {
+			return fmt.Errorf("查询资料版本失败: %w", err)
+		}
+		if err := tx.Where("material_id = ?", materialID).Delete(&dto.CourseMaterialVersion{}).Error; err != nil {
+			return fmt.Errorf("删除资料版本失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 数据库记录删除成功后再清理文件，清理失败只留下孤立文件
+	if store, err := materialStorage(); err == nil {
+		for _, v := range versions {
+			if err := store.Delete(ctx, v.StorageKey); err != nil {
+				log.Printf("Service: 删除资料文件 %s 失败: %v", v.StorageKey, err)
+			}
+		}
+	}
+	return nil
+}
+
+// getMaterialVO 查询单个资料并转换为 VO
+func (s *CourseMaterialService) getMaterialVO(ctx context.Context, materialID uint32) (*vo.CourseMaterialVO, error) {
+	db := database.Client.WithContext(ctx)
+	var material dto.CourseMaterial
+	if err := db.Preload("Uploader").First(&material, materialID).Error; err != nil {
+		return nil, fmt.Errorf("查询课程资料失败: %w", err)
+	}
+	vos, err := s.toMaterialVOs(db, []dto.CourseMaterial{material})
+	if err != nil {
+		return nil, err
+	}
+	return &vos[0], nil
+}
+
+// toMaterialVOs 批量转换资料，并附上各自的最新版本
+func (s *CourseMaterialService) toMaterialVOs(db *gorm.DB, materials []dto.CourseMaterial) ([]vo.CourseMaterialVO, error) {
+	latestByMaterial := make(map[uint32]dto.CourseMaterialVersion)
+	conditions := make([][]interface{}, 0, len(materials))
+	for _, m := range materials {
+		if m.CurrentVersion > 0 {
+			conditions = append(conditions, []interface{}{m.ID, m.CurrentVersion})
+		}
+	}
+	if len(conditions) > 0 {
+		var versions []dto.CourseMaterialVersion
+		if err := db.Preload("Uploader").
+			Where("(material_id, version) IN ?", conditions).
+			Find(&versions).Error; err != nil {
+			return nil, fmt.Errorf("查询资料版本失败: %w", err)
+		}
+		for _, v := range versions {
+			latestByMaterial[v.MaterialID] = v
+		}
+	}
+
+	result := make([]vo.CourseMaterialVO, 0, len(materials))
+	for _, m := range materials {
+		item := vo.CourseMaterialVO{
+			ID:             m.ID,
+			CourseNum:      m.CourseNum,
+			Title:          m.Title,
+			Kind:           m.Kind,
+			Description:    m.Description,
+			LinkURL:        m.LinkURL,
+			CurrentVersion: m.CurrentVersion,
+			DownloadCount:  m.DownloadCount,
+			UploaderName:   m.Uploader.Username,
+			CreatedAt:      m.CreatedAt,
+			UpdatedAt:      m.UpdatedAt,
+		}
+		if latest, ok := latestByMaterial[m.ID]; ok {
+			versionVO := toMaterialVersionVO(latest)
+			item.Latest = &versionVO
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// toMaterialVersionVO 转换资料版本
+func toMaterialVersionVO(v dto.CourseMaterialVersion) vo.CourseMaterialVersionVO {
+	return vo.CourseMaterialVersionVO{
+		Version:      v.Version,
+		FileName:     v.FileName,
+		ContentType:  v.ContentType,
+		Size:         v.Size,
+		SHA256:       v.SHA256,
+		Note:         v.Note,
+		UploaderName: v.Uploader.Username,
+		CreatedAt:    v.CreatedAt,
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services/course"
+	"cengkeHelperBackGo/pkg/coursedata"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// toCourseSectionVO 将课程及其时间段转换为教学班 VO
+func toCourseSectionVO(c dto.CourseInfo, slots []dto.TimeInfo) vo.CourseSectionVO {
+	timeSlots := make([]vo.TimeSlotVO, 0, len(slots))
+	rooms := make([]string, 0, len(slots))
+	seenRooms := make(map[string]bool)
+	for _, slot := range slots {
+		timeSlots = append(timeSlots, course.ParseTimeSlots(slot.WeekAndTime, int(slot.DayOfWeek))...)
+		room := strings.TrimSpace(slot.Building + " " + slot.Classroom)
+		if room != "" && !seenRooms[room] {
+			seenRooms[room] = true
+			rooms = append(rooms, room)
+		}
+	}
+	sort.SliceStable(timeSlots, func(i, j int) bool {
+		if timeSlots[i].DayOfWeek != timeSlots[j].DayOfWeek {
+			return timeSlots[i].DayOfWeek < timeSlots[j].DayOfWeek
+		}
+		return timeSlots[i].StartPeriod < timeSlots[j].StartPeriod
+	})
+
+	return vo.CourseSectionVO{
+		CourseCardVO: toCourseCardVO(c),
+		Credits:      coursedata.ParseCredits(c.Credit),
+		TimeSlots:    timeSlots,
+		Rooms:        rooms,
+		AuditPolicy:  c.AuditPolicy,
+		AuditorScore: c.AuditorScore,
+	}
+}
+
+// loadTimeInfosByCourseIDs 批量加载课程的时间段，按课程ID分组
+func loadTimeInfosByCourseIDs(courseIDs []uint32) (map[uint32][]dto.TimeInfo, error) {
+	var timeInfos []dto.TimeInfo
+	if err := database.Client.Where("course_info_id IN ?", courseIDs).
+		Order("day_of_week ASC, id ASC").
+		Find(&timeInfos).Error; err != nil {
+		return nil, fmt.Errorf("查询课程时间信息失败: %w", err)
+	}
+	result := make(map[uint32][]dto.TimeInfo, len(courseIDs))
+	for _, ti := range timeInfos {
+		result[ti.CourseInfoId] = append(result[ti.CourseInfoId], ti)
+	}
+	return result, nil
+}
+
+// GetSectionsByCourseNum 列出同一课程编号下的所有教学班，按评分降序
+func (s *CourseService) GetSectionsByCourseNum(courseNum string) (*vo.CourseSectionsVO, error) {
+	var courses []dto.CourseInfo
+	if err := database.Client.Where("course_num = ?", courseNum).
+		Order("average_rating DESC, review_count DESC, id ASC").
+		Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("查询教学班失败: %w", err)
+	}
+	if len(courses) == 0 {
+		return nil, errors.New(config.MsgCourseNotFound)
+	}
+
+	courseIDs := make([]uint32, 0, len(courses))
+	for _, c := range courses {
+		courseIDs = append(courseIDs, c.ID)
+	}
+	timeInfos, err := loadTimeInfosByCourseIDs(courseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := make([]vo.CourseSectionVO, 0, len(courses))
+	for _, c := range courses {
+		sections = append(sections, toCourseSectionVO(c, timeInfos[c.ID]))
+	}
+	return &vo.CourseSectionsVO{
+		CourseNum:    courseNum,
+		CourseName:   courses[0].CourseName,
+		SectionCount: len(sections),
+		Sections:     sections,
+	}, nil
+}
+
+// CompareCourses 对若干教学班做并排对比，结果顺序与传入的 ID 顺序一致
+func (s *CourseService) CompareCourses(courseIDs []uint32) (*vo.CourseComparisonVO, error) {
+	var courses []dto.CourseInfo
+	if err := database.Client.Where("id IN ?", courseIDs).Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("查询课程失败: %w", err)
+	}
+	courseByID := make(map[uint32]dto.CourseInfo, len(courses))
+	for _, c := range courses {
+		courseByID[c.ID] = c
+	}
+	for _, id := range courseIDs {
+		if _, ok := courseByID[id]; !ok {
+			return nil, errors.New(config.MsgCourseNotFound)
+		}
+	}
+
+	timeInfos, err := loadTimeInfosByCourseIDs(courseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 评分分布：每门课 1-5 分各有多少条公开展示的评价
+	type ratingBucket struct {
+		CourseID uint32
+		Rating   int
+		Count    int
+	}
+	var buckets []ratingBucket
+	if err := database.Client.Model(&dto.CourseReviewModel{}).
+		Select("course_id, rating, COUNT(*) AS count").
+		Where("course_id IN ? AND moderation_status = ?", courseIDs, dto.ModerationVisible).
+		Group("course_id, rating").
+		Scan(&buckets).Error; err != nil {
+		return nil, fmt.Errorf("统计评分分布失败: %w", err)
+	}
+	distributions := make(map[uint32][5]int, len(courseIDs))
+	for _, b := range buckets {
+		if b.Rating < 1 || b.Rating > 5 {
+			continue
+		}
+		d := distributions[b.CourseID]
+		d[b.Rating-1] += b.Count
+		distributions[b.CourseID] = d
+	}
+
+	items := make([]vo.CourseComparisonItemVO, 0, len(courseIDs))
+	for _, id := range courseIDs {
+		dist := distributions[id]
+		total := 0
+		for _, n := range dist {
+			total += n
+		}
+		share := make([]float32, 5)
+		if total > 0 {
+			for i, n := range dist {
+				share[i] = float32(n) / float32(total)
+			}
+		}
+		items = append(items, vo.CourseComparisonItemVO{
+			CourseSectionVO:    toCourseSectionVO(courseByID[id], timeInfos[id]),
+			RatingDistribution: dist[:],
+			RatingShare:        share,
+		})
+	}
+
+	return &vo.CourseComparisonVO{
+		Items:           items,
+		DifferingFields: differingComparisonFields(items),
+	}, nil
+}
+
+// differingComparisonFields 找出各教学班之间取值不同的字段，便于前端高亮
+func differingComparisonFields(items []vo.CourseComparisonItemVO) []string {
+	fields := []struct {
+		name  string
+		value func(vo.CourseComparisonItemVO) string
+	}{
+		{"courseName", func(i vo.CourseComparisonItemVO) string { return i.CourseName }},
+		{"teacherName", func(i vo.CourseComparisonItemVO) string { return i.TeacherName }},
+		{"teacherTitle", func(i vo.CourseComparisonItemVO) string { return i.TeacherTitle }},
+		{"faculty", func(i vo.CourseComparisonItemVO) string { return i.Faculty }},
+		{"courseType", func(i vo.CourseComparisonItemVO) string { return i.CourseType }},
+		{"credits", func(i vo.CourseComparisonItemVO) string { return fmt.Sprintf("%.1f", i.Credits) }},
+		{"timeSlots", func(i vo.CourseComparisonItemVO) string { return fmt.Sprint(i.TimeSlots) }},
+		{"rooms", func(i vo.CourseComparisonItemVO) string { return strings.Join(i.Rooms, ",") }},
+		{"auditPolicy", func(i vo.CourseComparisonItemVO) string { return i.AuditPolicy }},
+	}
+
+	differing := make([]string, 0)
+	for _, f := range fields {
+		for _, item := range items[1:] {
+			if f.value(item) != f.value(items[0]) {
+				differing = append(differing, f.name)
+				break
+			}
+		}
+	}
+	return differing
+}
@@ -5,6 +5,7 @@ import (
 	database "cengkeHelperBackGo/internal/db" // 确保这是您项目中统一的数据库客户端包
 	"cengkeHelperBackGo/internal/models/dto"  // 使用您提供的dto包
 	"cengkeHelperBackGo/internal/models/vo"   // 使用您提供的vo包
+	"context"
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
@@ -12,6 +13,9 @@ import (
 	"math"
 	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // CourseService 结构体用于组织课程相关的服务方法
@@ -61,6 +65,7 @@ type courseQueryRow struct {
 
 // queryStrAllByArea 是一个新的查询字符串，它只按 'area' 过滤
 // 它基于 const.go 中的 queryStr，但移除了 ti.day_of_week = ?
+// 结果按 教学楼/教室/课程号 排序，以便稳定分页
 const queryStrAllByArea = `
         SELECT 
             MAX(ci.id) AS id,
@@ -81,108 +86,204 @@ const queryStrAllByArea = `
             ti.building, 
             ti.classroom,
             ci.course_num
+        ORDER BY
+            ti.building,
+            ti.classroom,
+            ci.course_num
+        LIMIT ? OFFSET ?
     `
 
-// GetAllCourses 获取数据库中所有的课程信息，并按学部和教学楼分组
-// (返回与 course.GetTeachInfos 相同的结构)
-func (s *CourseService) GetAllCourses() ([][]vo.BuildingInfoVO, error) {
-
-	// 模仿 building.go，我们假设有4个学部(area 1-4)
-	allFacultiesData := make([][]vo.BuildingInfoVO, 4)
+// countStrAllByArea 统计 queryStrAllByArea 在某个学部下的分组总数
+const countStrAllByArea = `
+        SELECT COUNT(*) FROM (
+            SELECT 1
+            FROM time_infos ti
+            JOIN course_infos ci ON ci.id = ti.course_info_id
+            WHERE ti.area = ?
+            GROUP BY ti.building, ti.classroom, ci.course_num
+        ) grouped
+    `
 
-	// 循环遍历4个学部 (Area 1 到 4)
-	for areaNum := 1; areaNum <= 4; areaNum++ {
+// allCoursesQueryTimeout GetAllCourses 整体（四个学部并发）的最长耗时
+const allCoursesQueryTimeout = 10 * time.Second
 
-		var results []courseQueryRow
+// AllCoursesQueryParams 全部课程分页查询参数
+type AllCoursesQueryParams struct {
+	DivisionID *int // 学部ID (1-4)，nil 表示全部学部
+	Page       int  // 页码，从 1 开始，对每个学部分别生效
+	Limit      int  // 每个学部每页的课程数
+}
 
-		// 1. 执行 Raw SQL 查询，获取该学部的所有课程
-		if err := database.Client.Raw(queryStrAllByArea, areaNum).Scan(&results).Error; err != nil {
-			log.Printf("Service: GetAllCourses (Area %d) 查询失败: %v", areaNum, err)
-			return nil, fmt.Errorf("获取所有课程 (Area %d) 的数据库操作失败: %w", areaNum, err)
-		}
+// GetAllCourses 获取数据库中所有的课程信息，并按学部和教学楼分组
+// 各学部并发查询并各自分页；ctx 取消或超时会中断 SQL，任一学部失败则整体返回错误
+func (s *CourseService) GetAllCourses(ctx context.Context, params *AllCoursesQueryParams) ([]vo.AllCoursesPageVO, error) {
+	ctx, cancel := context.WithTimeout(ctx, allCoursesQueryTimeout)
+	defer cancel()
+
+	areas := []int{1, 2, 3, 4}
+	if params.DivisionID != nil {
+		areas = []int{*params.DivisionID}
+	}
 
-		// 2. 将结果按教学楼分组 (模仿 building.go)
-		buildingMap := make(map[string][]vo.CourseInfoVO)
-
-		for _, row := range results {
-			// 关键区别：我们不再调用 generator.IsWeekLessonMatch
-			// 我们接受所有查询到的课程
-
-			// 转换课程时间
-			// TODO: 你应该使用你的 generator 包中的函数来将 row.WeekAndTime 转换为可读字符串
-			// 暂时我们使用一个占位符
-			dayStr := strconv.Itoa(int(row.DayOfWeek))
-			courseTimeStr := fmt.Sprintf("周%s (Raw: %d)", dayStr, row.WeekAndTime)
-
-			// 确保 vo.CourseInfoVO 的字段被正确填充
-			res := vo.CourseInfoVO{
-				ID:           row.ID,
-				Room:         row.Classroom,
-				Faculty:      row.Faculty,
-				CourseName:   row.CourseName,
-				TeacherName:  row.Teacher,
-				TeacherTitle: row.TeacherTitle,
-				CourseTime:   courseTimeStr,
-				CourseType:   row.CourseType,
+	pages := make([]vo.AllCoursesPageVO, len(areas))
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i, areaNum := range areas {
+		wg.Add(1)
+		go func(i, areaNum int) {
+			defer wg.Done()
+			page, err := s.getCoursesPageByArea(ctx, areaNum, params.Page, params.Limit)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
 			}
+			pages[i] = *page
+		}(i, areaNum)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return pages, nil
+}
 
-			buildingMap[row.Building] = append(buildingMap[row.Building], res)
+// GetAllCoursesGrouped 不分页地获取所有课程，按学部和教学楼分组
+// (返回与 course.GetTeachInfos 相同的结构，供 /all 的旧版调用方使用)
+func (s *CourseService) GetAllCoursesGrouped(ctx context.Context) ([][]vo.BuildingInfoVO, error) {
+	pages, err := s.GetAllCourses(ctx, &AllCoursesQueryParams{Page: 1, Limit: math.MaxInt32})
+	if err != nil {
+		return nil, err
+	}
+	allFacultiesData := make([][]vo.BuildingInfoVO, len(pages))
+	for i, page := range pages {
+		allFacultiesData[i] = page.Buildings
+		if allFacultiesData[i] == nil {
+			allFacultiesData[i] = []vo.BuildingInfoVO{}
 		}
+	}
+	return allFacultiesData, nil
+}
+
+// getCoursesPageByArea 查询单个学部的一页课程，并按教学楼分组
+func (s *CourseService) getCoursesPageByArea(ctx context.Context, areaNum, page, limit int) (*vo.AllCoursesPageVO, error) {
+	db := database.Client.WithContext(ctx)
 
-		// 3. 将 map 转换为 []vo.BuildingInfoVO
-		buildingInfos := make([]vo.BuildingInfoVO, 0, len(buildingMap))
-		for key, infos := range buildingMap {
-			// 填充 vo.BuildingInfoVO
-			buildingInfos = append(buildingInfos, vo.BuildingInfoVO{
-				Building: key,
-				Label:    key, // Label 和 Value 也填充一下
-				Value:    0,   //
-				Infos:    infos,
-			})
+	var total int64
+	if err := db.Raw(countStrAllByArea, areaNum).Scan(&total).Error; err != nil {
+		log.Printf("Service: GetAllCourses (Area %d) 统计失败: %v", areaNum, err)
+		return nil, fmt.Errorf("统计所有课程 (Area %d) 的数据库操作失败: %w", areaNum, err)
+	}
+
+	var results []courseQueryRow
+
+	// 1. 执行 Raw SQL 查询，获取该学部当前页的课程
+	if err := db.Raw(queryStrAllByArea, areaNum, limit, (page-1)*limit).Scan(&results).Error; err != nil {
+		log.Printf("Service: GetAllCourses (Area %d) 查询失败: %v", areaNum, err)
+		return nil, fmt.Errorf("获取所有课程 (Area %d) 的数据库操作失败: %w", areaNum, err)
+	}
+
+	// 2. 将结果按教学楼分组 (模仿 building.go)
+	buildingMap := make(map[string][]vo.CourseInfoVO)
+
+	for _, row := range results {
+		// 关键区别：我们不再调用 generator.IsWeekLessonMatch
+		// 我们接受所有查询到的课程
+
+		// 转换课程时间
+		// TODO: 你应该使用你的 generator 包中的函数来将 row.WeekAndTime 转换为可读字符串
+		// 暂时我们使用一个占位符
+		dayStr := strconv.Itoa(int(row.DayOfWeek))
+		courseTimeStr := fmt.Sprintf("周%s (Raw: %d)", dayStr, row.WeekAndTime)
+
+		// 确保 vo.CourseInfoVO 的字段被正确填充
+		res := vo.CourseInfoVO{
+			ID:           row.ID,
+			Room:         row.Classroom,
+			Faculty:      row.Faculty,
+			CourseName:   row.CourseName,
+			TeacherName:  row.Teacher,
+			TeacherTitle: row.TeacherTitle,
+			CourseTime:   courseTimeStr,
+			CourseType:   row.CourseType,
 		}
 
-		// 4. 按课程数量对教学楼进行排序 (模仿 building.go)
-		slices.SortFunc(buildingInfos, func(a, b vo.BuildingInfoVO) int {
-			return len(b.Infos) - len(a.Infos)
-		})
+		buildingMap[row.Building] = append(buildingMap[row.Building], res)
+	}
 
-		// 5. 将这个学部的教学楼列表存入最终结果
-		allFacultiesData[areaNum-1] = buildingInfos
+	// 3. 将 map 转换为 []vo.BuildingInfoVO
+	buildingInfos := make([]vo.BuildingInfoVO, 0, len(buildingMap))
+	for key, infos := range buildingMap {
+		// 填充 vo.BuildingInfoVO
+		buildingInfos = append(buildingInfos, vo.BuildingInfoVO{
+			Building: key,
+			Label:    key, // Label 和 Value 也填充一下
+			Value:    0,   //
+			Infos:    infos,
+		})
 	}
 
-	return allFacultiesData, nil
+	// 4. 按课程数量对教学楼进行排序 (模仿 building.go)
+	slices.SortFunc(buildingInfos, func(a, b vo.BuildingInfoVO) int {
+		return len(b.Infos) - len(a.Infos)
+	})
+
+	return &vo.AllCoursesPageVO{
+		DivisionID:  areaNum,
+		Buildings:   buildingInfos,
+		Total:       total,
+		CurrentPage: page,
+		PageSize:    limit,
+		HasMore:     int64(page*limit) < total,
+	}, nil
 }
 
 // GetCourseDetailByID 根据课程 ID 获取课程详细信息
 func (s *CourseService) GetCourseDetailByID(courseID uint) (*vo.CourseDetailVO, error) {
-	//var courseModel dto.CourseInfo // 使用 dto.CourseInfo 作为课程模型
-	//
-	//// 使用 database.Client
-	//if err := database.Client.First(&courseModel, courseID).Error; err != nil {
-	//	if errors.Is(err, gorm.ErrRecordNotFound) {
-	//		return nil, errors.New(config.MsgCourseNotFound) // 使用统一定义的错误消息
-	//	}
-	//	log.Printf("Service: 获取课程详情 (ID %d) 失败: %v", courseID, err)
-	//	return nil, fmt.Errorf("获取课程详情数据库操作失败: %w", err)
-	//}
-	//
-	//// 将模型转换为 VO
-	//courseDetailVO := &vo.CourseDetailVO{
-	//	ID:            courseModel.ID,
-	//	Room:          courseModel.Room,
-	//	Faculty:       courseModel.Faculty,
-	//	CourseName:    courseModel.CourseName,
-	//	TeacherName:   courseModel.TeacherName,
-	//	TeacherTitle:  courseModel.TeacherTitle,
-	//	CourseTime:    courseModel.CourseTime,
-	//	CourseType:    courseModel.CourseType,
-	//	Description:   courseModel.Description,
-	//	Credit:       courseModel.Credit,
-	//	AverageRating: courseModel.AverageRating,
-	//	ReviewCount:   courseModel.ReviewCount,
-	//}
-
-	return nil, nil
+	var courseModel dto.CourseInfo
+	if err := database.Client.First(&courseModel, courseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgCourseNotFound)
+		}
+		log.Printf("Service: 获取课程详情 (ID %d) 失败: %v", courseID, err)
+		return nil, fmt.Errorf("获取课程详情数据库操作失败: %w", err)
+	}
+
+	timeInfos, err := loadTimeInfosByCourseIDs([]uint32{courseModel.ID})
+	if err != nil {
+		return nil, err
+	}
+	section := toCourseSectionVO(courseModel, timeInfos[courseModel.ID])
+
+	exams, err := loadExams(database.Client.Where("course_exams.course_id = ?", courseModel.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &vo.CourseDetailVO{
+		ID:            uint(courseModel.ID),
+		CourseName:    courseModel.CourseName,
+		CourseCode:    courseModel.CourseNum,
+		TeacherName:   courseModel.Teacher,
+		TeacherTitle:  courseModel.TeacherTitle,
+		Faculty:       courseModel.Faculty,
+		Credits:       section.Credits,
+		CourseType:    courseModel.CourseType,
+		Room:          strings.Join(section.Rooms, "、"),
+		TimeSlots:     section.TimeSlots,
+		Description:   courseModel.Description,
+		AverageRating: courseModel.AverageRating,
+		ReviewCount:   uint(courseModel.ReviewCount),
+		AuditPolicy:   courseModel.AuditPolicy,
+		AuditorScore:  courseModel.AuditorScore,
+		Exams:         exams,
+	}, nil
 }
 
 // GetCourseReviewsByCourseID 根据课程 ID 获取课程评价列表
@@ -203,7 +304,7 @@ func (s *CourseService) GetCourseReviewsByCourseID(courseID uint) ([]vo.CourseRe
 	// dto.CourseReviewModel 有 User   dto.User `gorm:"foreignKey:UserID"`
 	// 假设 dto.User 有 Username 字段
 	// 使用 database.Client
-	if err := database.Client.Preload("User").Where("course_id = ?", courseID).Order("created_at desc").Find(&reviews).Error; err != nil {
+	if err := database.Client.Preload("User").Where("course_id = ? AND moderation_status = ?", courseID, dto.ModerationVisible).Order("created_at desc").Find(&reviews).Error; err != nil {
 		log.Printf("Service: 获取课程 (ID %d) 的评价列表失败: %v", courseID, err)
 		return nil, fmt.Errorf("获取课程评价列表数据库操作失败: %w", err)
 	}
@@ -229,6 +330,47 @@ func (s *CourseService) GetCourseReviewsByCourseID(courseID uint) ([]vo.CourseRe
 	return reviewVOs, nil
 }
 
+// refreshCourseRating 从评价表重新统计课程的平均评分和评价数，在新增或删除评价的事务中调用
+func refreshCourseRating(tx *gorm.DB, courseID uint32) error {
+	// 使用 GORM 的聚合查询计算，避免竞态条件
+	type RatingStats struct {
+		Average float32
+		Count   uint
+	}
+	var stats RatingStats
+	if err := tx.Model(&dto.CourseReviewModel{}).
+		Where("course_id = ?", courseID).
+		Select("AVG(rating) as average, COUNT(*) as count").
+		Scan(&stats).Error; err != nil {
+		log.Printf("Service: 计算课程 (ID %d) 新的平均分和评价数失败: %v", courseID, err)
+		return fmt.Errorf("更新课程评分信息时计算失败: %w", err)
+	}
+
+	if err := tx.Model(&dto.CourseInfo{}).Where("id = ?", courseID).Updates(map[string]interface{}{
+		"average_rating": stats.Average,
+		"review_count":   stats.Count,
+	}).Error; err != nil {
+		log.Printf("Service: 更新课程 (ID %d) 的评分和评价数失败: %v", courseID, err)
+		return fmt.Errorf("保存课程评分信息失败: %w", err)
+	}
+	return nil
+}
+
+// deleteCourseReview 删除课程评价并更新课程评分，评价不存在时不做任何事
+func deleteCourseReview(tx *gorm.DB, reviewID uint32) error {
+	var review dto.CourseReviewModel
+	if err := tx.Select("id", "course_id").First(&review, reviewID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := tx.Delete(&review).Error; err != nil {
+		return fmt.Errorf("删除课程评价失败: %w", err)
+	}
+	return refreshCourseRating(tx, review.CourseID)
+}
+
 // SubmitCourseReview 提交课程评价
 // userID 从认证中间件中获取
 // 返回创建的评价VO和错误
@@ -255,18 +397,26 @@ func (s *CourseService) SubmitCourseReview(userID uint32, payload dto.CourseRevi
 		return nil, fmt.Errorf("为评价查询用户数据库操作失败: %w", err)
 	}
 
+	// 评价内容命中审核词时评分照常计入，文字待审核通过后才展示
+	check := newContentCheck()
+	comment, err := check.filter(payload.Comment)
+	if err != nil {
+		return nil, err
+	}
+
 	var createdReviewModel dto.CourseReviewModel
 
 	// 3. 使用事务创建评价记录并更新课程统计信息
 	// 使用 database.Client
-	err := database.Client.Transaction(func(tx *gorm.DB) error {
+	err = database.Client.Transaction(func(tx *gorm.DB) error {
 		// 将前端可能传来的小数评分（如 4.5）四舍五入为最近的整数以兼容后端存储（int）
 		roundedRating := int(math.Round(float64(payload.Rating)))
 		reviewToCreate := dto.CourseReviewModel{ // 使用 dto.CourseReviewModel
-			CourseID: payload.CourseID,
-			UserID:   userID,
-			Rating:   roundedRating,
-			Comment:  payload.Comment,
+			CourseID:         payload.CourseID,
+			UserID:           userID,
+			Rating:           roundedRating,
+			Comment:          comment,
+			ModerationStatus: check.status(),
 			// User 和 Course 关联字段通常由 GORM 自动处理或在查询时 Preload，创建时不需要手动赋值模型实例
 		}
 		if err := tx.Create(&reviewToCreate).Error; err != nil {
@@ -274,37 +424,14 @@ func (s *CourseService) SubmitCourseReview(userID uint32, payload dto.CourseRevi
 			return fmt.Errorf("提交评价数据库操作失败: %w", err)
 		}
 		createdReviewModel = reviewToCreate // 保存刚创建的记录
-
-		// 更新课程的平均评分和评论数 (更健壮的方式)
-		var newAvgRating float32
-		var newReviewCount uint
-
-		// 使用原生 SQL 或 GORM 的聚合函数来计算，避免竞态条件
-		// 这里使用 GORM 的聚合查询，更安全
-		type RatingStats struct {
-			Average float32
-			Count   uint
-		}
-		var stats RatingStats
-		if err := tx.Model(&dto.CourseReviewModel{}).
-			Where("course_id = ?", payload.CourseID).
-			Select("AVG(rating) as average, COUNT(*) as count").
-			Scan(&stats).Error; err != nil {
-			log.Printf("Service: 计算课程 (ID %d) 新的平均分和评价数失败: %v", payload.CourseID, err)
-			return fmt.Errorf("更新课程评分信息时计算失败: %w", err)
-		}
-		newAvgRating = stats.Average
-		newReviewCount = stats.Count
-
-		if err := tx.Model(&dto.CourseInfo{}).Where("id = ?", payload.CourseID).Updates(map[string]interface{}{
-			"average_rating": newAvgRating,
-			"review_count":   newReviewCount,
-		}).Error; err != nil {
-			log.Printf("Service: 更新课程 (ID %d) 的评分和评价数失败: %v", payload.CourseID, err)
-			return fmt.Errorf("保存课程评分信息失败: %w", err)
+		if check.held() {
+			if err := holdForReview(tx, dto.ContentTargetReview, reviewToCreate.ID, userID, comment, check); err != nil {
+				return err
+			}
 		}
 
-		return nil
+		// 更新课程的平均评分和评论数
+		return refreshCourseRating(tx, payload.CourseID)
 	})
 
 	if err != nil {
@@ -315,12 +442,13 @@ func (s *CourseService) SubmitCourseReview(userID uint32, payload dto.CourseRevi
 	// createdReviewModel 此时有 ID, CourseID, UserID, Rating, Comment, CreatedAt
 	// User 关联需要从之前查询到的 user 变量获取 username
 	reviewVO := &vo.CourseReviewInfoVO{
-		ID:           createdReviewModel.ID,
-		CourseID:     createdReviewModel.CourseID,
-		Rating:       createdReviewModel.Rating,
-		Comment:      createdReviewModel.Comment,
-		ReviewerName: user.Username, // 从之前查询到的 user 中获取
-		CreatedAt:    createdReviewModel.CreatedAt,
+		ID:               createdReviewModel.ID,
+		CourseID:         createdReviewModel.CourseID,
+		Rating:           createdReviewModel.Rating,
+		Comment:          createdReviewModel.Comment,
+		ReviewerName:     user.Username, // 从之前查询到的 user 中获取
+		CreatedAt:        createdReviewModel.CreatedAt,
+		ModerationStatus: createdReviewModel.ModerationStatus,
 	}
 
 	return reviewVO, nil
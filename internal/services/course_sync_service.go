@@ -0,0 +1,340 @@
+package services
+
+import (
+	"bytes"
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"cengkeHelperBackGo/pkg/coursedata"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// syncSnapshotCacheTTL 快照按版本号缓存，版本不变内容就不变
+const syncSnapshotCacheTTL = 24 * time.Hour
+
+// syncRefreshMu 串行化同步检查，避免并发检查产生重复版本
+var syncRefreshMu sync.Mutex
+
+// CourseSyncService 教学班数据的离线快照与增量同步
+type CourseSyncService struct{}
+
+// NewCourseSyncService 创建 CourseSyncService 实例
+func NewCourseSyncService() *CourseSyncService {
+	return &CourseSyncService{}
+}
+
+// Refresh 对比当前教学班数据与上次同步时的内容哈希，有变化时生成新版本。
+// 教务数据直接导入数据库，因此在导入后、勘误生效后以及定时任务中调用
+func (s *CourseSyncService) Refresh(ctx context.Context, reason string) (*vo.SyncRefreshResultVO, error) {
+	syncRefreshMu.Lock()
+	defer syncRefreshMu.Unlock()
+
+	db := database.Client.WithContext(ctx)
+	_, _, sections, err := loadActiveSyncSections(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var states []dto.CourseSyncState
+	if err := db.Select("course_id", "hash", "deleted").Find(&states).Error; err != nil {
+		return nil, fmt.Errorf("查询同步状态失败: %w", err)
+	}
+	known := make(map[uint32]dto.CourseSyncState, len(states))
+	for _, st := range states {
+		known[st.CourseID] = st
+	}
+
+	upserts := make([]dto.CourseSyncState, 0)
+	current := make(map[uint32]bool, len(sections))
+	for _, section := range sections {
+		current[section.ID] = true
+		hash := hashSyncSection(section)
+		if st, ok := known[section.ID]; ok && !st.Deleted && st.Hash == hash {
+			continue
+		}
+		upserts = append(upserts, dto.CourseSyncState{CourseID: section.ID, Hash: hash})
+	}
+	deletes := make([]uint32, 0)
+	for id, st := range known {
+		if !st.Deleted && !current[id] {
+			deletes = append(deletes, id)
+		}
+	}
+
+	if len(upserts) == 0 && len(deletes) == 0 {
+		version, err := currentSyncVersion(db)
+		if err != nil {
+			return nil, err
+		}
+		return &vo.SyncRefreshResultVO{Version: version}, nil
+	}
+
+	var version dto.CourseSyncVersion
+	err = db.Transaction(func(tx *gorm.DB) error {
+		version = dto.CourseSyncVersion{Reason: reason, Upserted: len(upserts), Deleted: len(deletes)}
+		if err := tx.Create(&version).Error; err != nil {
+			return fmt.Errorf("创建同步版本失败: %w", err)
+		}
+
+		for i := range upserts {
+			upserts[i].Version = version.ID
+		}
+		if len(upserts) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "course_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"hash", "version", "deleted", "updated_at"}),
+			}).CreateInBatches(upserts, 500).Error; err != nil {
+				return fmt.Errorf("保存同步状态失败: %w", err)
+			}
+		}
+
+		for start := 0; start < len(deletes); start += 500 {
+			end := min(start+500, len(deletes))
+			if err := tx.Model(&dto.CourseSyncState{}).
+				Where("course_id IN ?", deletes[start:end]).
+				Updates(map[string]interface{}{"deleted": true, "version": version.ID}).Error; err != nil {
+				return fmt.Errorf("标记已删除教学班失败: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Service: 同步版本 %d（%s）：%d 个教学班新增或修改，%d 个删除", version.ID, reason, len(upserts), len(deletes))
+	return &vo.SyncRefreshResultVO{
+		Version:  version.ID,
+		Changed:  true,
+		Upserted: len(upserts),
+		Deleted:  len(deletes),
+	}, nil
+}
+
+// RefreshAfter 在导入或勘误生效后检查数据变化，失败只记录日志
+func (s *CourseSyncService) RefreshAfter(ctx context.Context, reason string) {
+	if _, err := s.Refresh(ctx, reason); err != nil {
+		log.Printf("Service: 同步检查失败（%s）: %v", reason, err)
+	}
+}
+
+// Snapshot 返回当前版本的 gzip 压缩全量快照，结果按版本号缓存在 Redis 中
+func (s *CourseSyncService) Snapshot(ctx context.Context) ([]byte, uint64, error) {
+	db := database.Client.WithContext(ctx)
+	version, err := currentSyncVersion(db)
+	if err != nil {
+		return nil, 0, err
+	}
+	// 从未同步过时先生成第一个版本
+	if version == 0 {
+		result, err := s.Refresh(ctx, dto.SyncReasonImport)
+		if err != nil {
+			return nil, 0, err
+		}
+		version = result.Version
+	}
+
+	cacheKey := fmt.Sprintf("sync:courses:snapshot:%d", version)
+	if database.RedisClient != nil {
+		data, err := database.RedisClient.Get(ctx, cacheKey).Bytes()
+		if err == nil {
+			return data, version, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("Service: 读取同步快照缓存失败: %v", err)
+		}
+	}
+
+	// 快照内容可能比版本号略新（检查之后又有导入），客户端之后拉取增量时重复的修改是幂等的
+	years, semester, sections, err := loadActiveSyncSections(db)
+	if err != nil {
+		return nil, 0, err
+	}
+	snapshot := vo.SyncSnapshotVO{
+		Version:     version,
+		Years:       years,
+		Semester:    semester,
+		GeneratedAt: clock.Now(ctx),
+		Sections:    sections,
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(snapshot); err != nil {
+		return nil, 0, fmt.Errorf("生成同步快照失败: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, 0, fmt.Errorf("压缩同步快照失败: %w", err)
+	}
+
+	if database.RedisClient != nil {
+		if err := database.RedisClient.Set(ctx, cacheKey, buf.Bytes(), syncSnapshotCacheTTL).Err(); err != nil {
+			log.Printf("Service: 写入同步快照缓存失败: %v", err)
+		}
+	}
+	return buf.Bytes(), version, nil
+}
+
+// Changes 返回自 since 版本以来新增、修改和删除的教学班
+func (s *CourseSyncService) Changes(ctx context.Context, since uint64) (*vo.SyncChangesVO, error) {
+	db := database.Client.WithContext(ctx)
+	version, err := currentSyncVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if since > version {
+		return nil, errors.New(config.MsgSyncVersionInvalid)
+	}
+
+	result := &vo.SyncChangesVO{
+		Since:    since,
+		Version:  version,
+		Upserted: make([]vo.SyncSectionVO, 0),
+		Deleted:  make([]uint32, 0),
+	}
+	if since == version {
+		return result, nil
+	}
+
+	var states []dto.CourseSyncState
+	if err := db.Select("course_id", "deleted").
+		Where("version > ?", since).
+		Order("course_id ASC").
+		Find(&states).Error; err != nil {
+		return nil, fmt.Errorf("查询同步状态失败: %w", err)
+	}
+	changedIDs := make([]uint32, 0, len(states))
+	for _, st := range states {
+		if st.Deleted {
+			result.Deleted = append(result.Deleted, st.CourseID)
+		} else {
+			changedIDs = append(changedIDs, st.CourseID)
+		}
+	}
+	if len(changedIDs) == 0 {
+		return result, nil
+	}
+
+	var courses []dto.CourseInfo
+	if err := db.Where("id IN ?", changedIDs).Order("id ASC").Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("查询教学班失败: %w", err)
+	}
+	sections, err := buildSyncSections(courses)
+	if err != nil {
+		return nil, err
+	}
+	result.Upserted = sections
+	return result, nil
+}
+
+// currentSyncVersion 当前最新的同步版本，从未同步过时为 0
+func currentSyncVersion(db *gorm.DB) (uint64, error) {
+	var version uint64
+	if err := db.Model(&dto.CourseSyncVersion{}).Select("COALESCE(MAX(id), 0)").Scan(&version).Error; err != nil {
+		return 0, fmt.Errorf("查询同步版本失败: %w", err)
+	}
+	return version, nil
+}
+
+// currentSemester 当前学期，取最近一次导入的课程所在学期；没有任何课程时返回空串
+func currentSemester(db *gorm.DB) (years, semester string, err error) {
+	var latest dto.CourseInfo
+	err = db.Select("years", "semester").Order("id DESC").Take(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("查询当前学期失败: %w", err)
+	}
+	return latest.Years, latest.Semester, nil
+}
+
+// loadActiveSyncSections 加载当前学期的全部教学班
+func loadActiveSyncSections(db *gorm.DB) (string, string, []vo.SyncSectionVO, error) {
+	years, semester, err := currentSemester(db)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if years == "" && semester == "" {
+		return "", "", make([]vo.SyncSectionVO, 0), nil
+	}
+
+	var courses []dto.CourseInfo
+	if err := db.Where("years = ? AND semester = ?", years, semester).
+		Order("id ASC").
+		Find(&courses).Error; err != nil {
+		return "", "", nil, fmt.Errorf("查询教学班失败: %w", err)
+	}
+	sections, err := buildSyncSections(courses)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return years, semester, sections, nil
+}
+
+// buildSyncSections 组装教学班及其上课时间段
+func buildSyncSections(courses []dto.CourseInfo) ([]vo.SyncSectionVO, error) {
+	sections := make([]vo.SyncSectionVO, 0, len(courses))
+	if len(courses) == 0 {
+		return sections, nil
+	}
+
+	courseIDs := make([]uint32, 0, len(courses))
+	for _, c := range courses {
+		courseIDs = append(courseIDs, c.ID)
+	}
+	timeInfos, err := loadTimeInfosByCourseIDs(courseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range courses {
+		slots := make([]vo.SyncSlotVO, 0, len(timeInfos[c.ID]))
+		for _, ti := range timeInfos[c.ID] {
+			slots = append(slots, vo.SyncSlotVO{
+				DayOfWeek:   ti.DayOfWeek,
+				WeekAndTime: ti.WeekAndTime,
+				Area:        ti.Area,
+				Building:    ti.Building,
+				Classroom:   ti.Classroom,
+			})
+		}
+		sections = append(sections, vo.SyncSectionVO{
+			ID:           c.ID,
+			CourseNum:    c.CourseNum,
+			CourseName:   c.CourseName,
+			TeacherName:  c.Teacher,
+			TeacherTitle: c.TeacherTitle,
+			Faculty:      c.Faculty,
+			Major:        c.Major,
+			Grade:        c.Grade,
+			Credits:      coursedata.ParseCredits(c.Credit),
+			CourseType:   c.CourseType,
+			AuditPolicy:  c.AuditPolicy,
+			Slots:        slots,
+		})
+	}
+	return sections, nil
+}
+
+// hashSyncSection 教学班内容哈希，只包含离线同步下发的字段，评分等统计变化不产生新版本
+func hashSyncSection(section vo.SyncSectionVO) string {
+	data, _ := json.Marshal(section)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"cengkeHelperBackGo/pkg/coursedata"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 数据异常类别
+const (
+	LintUnparseableFloor = "unparseable_floor"
+	LintInvalidCredit    = "invalid_credit"
+	LintEmptyLessons     = "empty_lessons"
+	LintEmptyWeeks       = "empty_weeks"
+	LintOrphanTimeInfo   = "orphan_time_info"
+	LintUnknownArea      = "unknown_area"
+	LintInvalidWeekday   = "invalid_weekday"
+	LintCourseNoTime     = "course_without_time"
+)
+
+// 异常严重程度
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+)
+
+// lintMaxIssuesPerCategory 每类异常最多列出的条目数，总数仍完整统计
+const lintMaxIssuesPerCategory = 200
+
+// lintLatestReportKey 最近一次报告在 Redis 中的键
+const lintLatestReportKey = "datalint:courses:latest"
+
+// lintCategoryMeta 各类异常的说明与修复建议，顺序即报告中的顺序
+var lintCategoryMeta = []struct {
+	code, title, severity, suggestion string
+}{
+	{LintOrphanTimeInfo, "时间段引用了不存在的课程", LintSeverityError, "删除这些时间段，或补导对应的课程记录"},
+	{LintEmptyLessons, "时间段没有任何节次", LintSeverityError, "检查导入时的节次解析，这些时间段不会出现在任何时刻的课表中"},
+	{LintEmptyWeeks, "时间段没有任何周次", LintSeverityError, "检查导入时的周次解析，这些时间段不会出现在任何一周的课表中"},
+	{LintUnknownArea, "未知的学部编号", LintSeverityError, "学部编号应为 1-4（文理/信息/工/医），请核对教学楼所属学部"},
+	{LintInvalidWeekday, "无效的星期", LintSeverityError, "星期应为 0-6（0 表示周日）"},
+	{LintInvalidCredit, "学分无法解析", LintSeverityWarning, "将学分修正为非负数字，如 2.0；目前这些课程的学分按 0 显示"},
+	{LintUnparseableFloor, "教室编号无法推断楼层", LintSeverityWarning, "教室编号中缺少数字，楼层默认为 1 层；可通过勘误修正教室编号"},
+	{LintCourseNoTime, "课程没有任何时间段", LintSeverityWarning, "确认是否为无固定时间的课程（如实践课），否则补导时间信息"},
+}
+
+// DataLintService 课程数据质量检查
+type DataLintService struct{}
+
+// NewDataLintService 创建 DataLintService 实例
+func NewDataLintService() *DataLintService {
+	return &DataLintService{}
+}
+
+// lintTimeRow 检查时间段需要的字段，CourseExists 来自 LEFT JOIN
+type lintTimeRow struct {
+	ID           uint32
+	CourseInfoID uint32
+	WeekAndTime  uint32
+	DayOfWeek    uint8
+	Area         uint8
+	Building     string
+	Classroom    string
+	CourseExists bool
+}
+
+// Run 扫描 course_infos 与 time_infos，生成分类的数据异常报告，并保存为最近一次报告
+func (s *DataLintService) Run(ctx context.Context) (*vo.DataLintReportVO, error) {
+	db := database.Client.WithContext(ctx)
+
+	var courses []dto.CourseInfo
+	if err := db.Select("id", "credit").Order("id ASC").Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("查询课程失败: %w", err)
+	}
+	var timeRows []lintTimeRow
+	if err := db.Table("time_infos ti").
+		Select("ti.id, ti.course_info_id, ti.week_and_time, ti.day_of_week, ti.area, ti.building, ti.classroom, ci.id IS NOT NULL AS course_exists").
+		Joins("LEFT JOIN course_infos ci ON ci.id = ti.course_info_id").
+		Order("ti.id ASC").
+		Scan(&timeRows).Error; err != nil {
+		return nil, fmt.Errorf("查询时间段失败: %w", err)
+	}
+
+	issues := make(map[string][]vo.DataLintIssueVO)
+	add := func(code, table string, rowID uint32, value, detail string) {
+		issues[code] = append(issues[code], vo.DataLintIssueVO{Table: table, RowID: rowID, Value: value, Detail: detail})
+	}
+
+	hasTime := make(map[uint32]bool, len(courses))
+	for _, row := range timeRows {
+		hasTime[row.CourseInfoID] = true
+		if !row.CourseExists {
+			add(LintOrphanTimeInfo, "time_infos", row.ID, strconv.FormatUint(uint64(row.CourseInfoID), 10), "course_info_id 不存在")
+		}
+		if row.WeekAndTime&0x1FFF == 0 {
+			add(LintEmptyLessons, "time_infos", row.ID, fmt.Sprintf("%#08x", row.WeekAndTime), "")
+		}
+		if row.WeekAndTime&^0x1FFF == 0 {
+			add(LintEmptyWeeks, "time_infos", row.ID, fmt.Sprintf("%#08x", row.WeekAndTime), "")
+		}
+		if row.Area < 1 || row.Area > 4 {
+			add(LintUnknownArea, "time_infos", row.ID, strconv.Itoa(int(row.Area)), row.Building)
+		}
+		if row.DayOfWeek > 6 {
+			add(LintInvalidWeekday, "time_infos", row.ID, strconv.Itoa(int(row.DayOfWeek)), "")
+		}
+		if !coursedata.HasFloorNumber(row.Classroom) {
+			add(LintUnparseableFloor, "time_infos", row.ID, row.Classroom, row.Building)
+		}
+	}
+	for _, c := range courses {
+		if _, err := coursedata.ParseCreditsStrict(c.Credit); err != nil {
+			add(LintInvalidCredit, "course_infos", c.ID, c.Credit, "")
+		}
+		if !hasTime[c.ID] {
+			add(LintCourseNoTime, "course_infos", c.ID, "", "")
+		}
+	}
+
+	report := &vo.DataLintReportVO{
+		GeneratedAt:   clock.Now(ctx),
+		CourseCount:   int64(len(courses)),
+		TimeInfoCount: int64(len(timeRows)),
+		Categories:    make([]vo.DataLintCategoryVO, 0),
+	}
+	for _, meta := range lintCategoryMeta {
+		found := issues[meta.code]
+		if len(found) == 0 {
+			continue
+		}
+		category := vo.DataLintCategoryVO{
+			Code:       meta.code,
+			Title:      meta.title,
+			Severity:   meta.severity,
+			Suggestion: meta.suggestion,
+			Count:      len(found),
+			Issues:     found,
+		}
+		if len(found) > lintMaxIssuesPerCategory {
+			category.Issues = found[:lintMaxIssuesPerCategory]
+			category.Truncated = true
+		}
+		report.IssueCount += len(found)
+		report.Categories = append(report.Categories, category)
+	}
+
+	if database.RedisClient != nil {
+		if data, err := json.Marshal(report); err == nil {
+			if err := database.RedisClient.Set(ctx, lintLatestReportKey, data, 0).Err(); err != nil {
+				log.Printf("Service: 保存数据质量报告失败: %v", err)
+			}
+		}
+	}
+	return report, nil
+}
+
+// RunAfterImport 导入教务数据后检查数据质量，结果只记录日志并保存为最近一次报告
+func (s *DataLintService) RunAfterImport(ctx context.Context) {
+	report, err := s.Run(ctx)
+	if err != nil {
+		log.Printf("Service: 导入后数据质量检查失败: %v", err)
+		return
+	}
+	log.Printf("Service: 导入后数据质量检查完成，发现 %d 处异常", report.IssueCount)
+	for _, category := range report.Categories {
+		log.Printf("Service:   [%s] %s：%d 条", category.Severity, category.Title, category.Count)
+	}
+}
+
+// LatestReport 返回最近一次的数据质量报告
+func (s *DataLintService) LatestReport(ctx context.Context) (*vo.DataLintReportVO, error) {
+	if database.RedisClient == nil {
+		return nil, errors.New(config.MsgLintReportNotFound)
+	}
+	data, err := database.RedisClient.Get(ctx, lintLatestReportKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, errors.New(config.MsgLintReportNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取数据质量报告失败: %w", err)
+	}
+	var report vo.DataLintReportVO
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("解析数据质量报告失败: %w", err)
+	}
+	return &report, nil
+}
+
+// FormatDataLintReport 将报告格式化为便于阅读的文本
+func FormatDataLintReport(report *vo.DataLintReportVO) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "课程数据质量报告 %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "共检查 %d 门课程、%d 个时间段，发现 %d 处异常\n", report.CourseCount, report.TimeInfoCount, report.IssueCount)
+	for _, category := range report.Categories {
+		fmt.Fprintf(&b, "\n[%s] %s（%s）：%d 条\n", category.Severity, category.Title, category.Code, category.Count)
+		fmt.Fprintf(&b, "  建议：%s\n", category.Suggestion)
+		for _, issue := range category.Issues {
+			fmt.Fprintf(&b, "  - %s#%d", issue.Table, issue.RowID)
+			if issue.Value != "" {
+				fmt.Fprintf(&b, " 值=%q", issue.Value)
+			}
+			if issue.Detail != "" {
+				fmt.Fprintf(&b, " %s", issue.Detail)
+			}
+			b.WriteString("\n")
+		}
+		if category.Truncated {
+			fmt.Fprintf(&b, "  ……仅列出前 %d 条\n", len(category.Issues))
+		}
+	}
+	return b.String()
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/rand"
 	"crypto/tls"
 	"fmt"
+	"html"
 	"log"
 	"math/big"
 
@@ -60,15 +61,7 @@ func (e *EmailService) SendVerificationCode(email string) (string, error) {
 	m.SetBody("text/html", body)
 
 	// 配置SMTP
-	d := gomail.NewDialer(
-		config.Conf.Email.SmtpHost,
-		config.Conf.Email.SmtpPort,
-		config.Conf.Email.Username,
-		config.Conf.Email.Password,
-	)
-
-	// 启用TLS加密
-	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	d := newMailDialer()
 
 	// 添加调试日志
 	log.Printf("尝试连接SMTP服务器: %s:%d, 用户: %s",
@@ -97,6 +90,72 @@ func (e *EmailService) SendVerificationCode(email string) (string, error) {
 	return code, nil
 }
 
+// newMailDialer 按配置创建 SMTP 连接
+func newMailDialer() *gomail.Dialer {
+	d := gomail.NewDialer(
+		config.Conf.Email.SmtpHost,
+		config.Conf.Email.SmtpPort,
+		config.Conf.Email.Username,
+		config.Conf.Email.Password,
+	)
+
+	// 启用TLS加密
+	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	return d
+}
+
+// SendPostPublished 通知作者定时发布的帖子已经发布
+func (e *EmailService) SendPostPublished(email, title string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", m.FormatAddress(config.Conf.Email.Username, config.Conf.Email.FromName))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", "【蹭课小助手】你的定时帖子已发布")
+
+	body := fmt.Sprintf(`
+<html>
+<body>
+    <p>您好！</p>
+    <p>您设置了定时发布的帖子《%s》已按时发布。</p>
+    <br>
+    <p>此邮件由系统自动发送，请勿回复。</p>
+    <p>蹭课小助手团队</p>
+</body>
+</html>
+	`, html.EscapeString(title))
+	m.SetBody("text/html", body)
+
+	if err := newMailDialer().DialAndSend(m); err != nil {
+		return fmt.Errorf("发送邮件失败: %v", err)
+	}
+	return nil
+}
+
+// SendModerationNotice 发送举报处理结果、警告或封禁等管理通知，message 为纯文本
+func (e *EmailService) SendModerationNotice(email, subject, message string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", m.FormatAddress(config.Conf.Email.Username, config.Conf.Email.FromName))
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", "【蹭课小助手】"+subject)
+
+	body := fmt.Sprintf(`
+<html>
+<body>
+    <p>您好！</p>
+    <p>%s</p>
+    <br>
+    <p>此邮件由系统自动发送，请勿回复。</p>
+    <p>蹭课小助手团队</p>
+</body>
+</html>
+	`, html.EscapeString(message))
+	m.SetBody("text/html", body)
+
+	if err := newMailDialer().DialAndSend(m); err != nil {
+		return fmt.Errorf("发送邮件失败: %v", err)
+	}
+	return nil
+}
+
 // VerifyEmailCode 验证邮箱验证码
 func (e *EmailService) VerifyEmailCode(email, inputCode string) bool {
 	// 从Redis获取验证码
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services/course"
+	"cengkeHelperBackGo/pkg/clock"
+	"cengkeHelperBackGo/pkg/ics"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExamService 考试安排
+type ExamService struct{}
+
+// NewExamService 创建 ExamService 实例
+func NewExamService() *ExamService {
+	return &ExamService{}
+}
+
+// ImportExams 批量导入考试安排，同一教学班同一开考时间的记录会被覆盖
+func (s *ExamService) ImportExams(ctx context.Context, payload dto.ExamImportDTO) (*vo.ExamImportResultVO, error) {
+	result := &vo.ExamImportResultVO{Skipped: make([]vo.ExamImportSkipVO, 0)}
+	db := database.Client.WithContext(ctx)
+
+	// 只给 courseNum 时只匹配当前学期的教学班，避免往届同号课程也被写入考试安排
+	years, semester, err := currentSemester(db)
+	if err != nil {
+		return nil, err
+	}
+
+	exams := make([]dto.CourseExam, 0, len(payload.Exams))
+	for i, item := range payload.Exams {
+		if reason := validateExamItem(item); reason != "" {
+			result.Skipped = append(result.Skipped, vo.ExamImportSkipVO{Index: i, Reason: reason})
+			continue
+		}
+
+		var courseIDs []uint32
+		query := db.Model(&dto.CourseInfo{})
+		if item.CourseID != 0 {
+			query = query.Where("id = ?", item.CourseID)
+		} else {
+			query = query.Where("course_num = ? AND years = ? AND semester = ?", item.CourseNum, years, semester)
+		}
+		if err := query.Pluck("id", &courseIDs).Error; err != nil {
+			return nil, fmt.Errorf("查询教学班失败: %w", err)
+		}
+		if len(courseIDs) == 0 {
+			result.Skipped = append(result.Skipped, vo.ExamImportSkipVO{Index: i, Reason: config.MsgCourseNotFound})
+			continue
+		}
+
+		for _, courseID := range courseIDs {
+			exams = append(exams, dto.CourseExam{
+				CourseID:  courseID,
+				ExamType:  strings.TrimSpace(item.ExamType),
+				ExamDate:  item.ExamDate,
+				StartTime: item.StartTime,
+				EndTime:   item.EndTime,
+				Area:      item.Area,
+				Building:  strings.TrimSpace(item.Building),
+				Classroom: strings.TrimSpace(item.Classroom),
+				SeatNote:  strings.TrimSpace(item.SeatNote),
+			})
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if payload.Replace {
+			if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&dto.CourseExam{}).Error; err != nil {
+				return fmt.Errorf("清空考试安排失败: %w", err)
+			}
+		}
+		if len(exams) == 0 {
+			return nil
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "course_id"}, {Name: "exam_date"}, {Name: "start_time"}},
+			DoUpdates: clause.AssignmentColumns([]string{"exam_type", "end_time", "area", "building", "classroom", "seat_note", "updated_at"}),
+		}).CreateInBatches(exams, 500).Error; err != nil {
+			return fmt.Errorf("保存考试安排失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Imported = len(exams)
+	courseIDs := make([]uint32, 0, len(exams))
+	seen := make(map[uint32]bool, len(exams))
+	for _, exam := range exams {
+		if !seen[exam.CourseID] {
+			seen[exam.CourseID] = true
+			courseIDs = append(courseIDs, exam.CourseID)
+		}
+	}
+	notifyCourseUpdated(courseIDs, 0, "考试安排已更新")
+	return result, nil
+}
+
+// validateExamItem 校验一条考试安排，返回跳过原因，合法时返回空串
+func validateExamItem(item dto.ExamImportItemDTO) string {
+	if item.CourseID == 0 && strings.TrimSpace(item.CourseNum) == "" {
+		return "courseId 与 courseNum 至少填写一个"
+	}
+	start, err := time.ParseInLocation(course.ExamTimeLayout, item.ExamDate+" "+item.StartTime, time.Local)
+	if err != nil {
+		return "考试日期或开始时间格式错误，应为 YYYY-MM-DD 与 HH:MM"
+	}
+	end, err := time.ParseInLocation(course.ExamTimeLayout, item.ExamDate+" "+item.EndTime, time.Local)
+	if err != nil {
+		return "结束时间格式错误，应为 HH:MM"
+	}
+	if !end.After(start) {
+		return "结束时间必须晚于开始时间"
+	}
+	return ""
+}
+
+// ListCourseExams 列出教学班的考试安排
+func (s *ExamService) ListCourseExams(ctx context.Context, courseID uint32) ([]vo.ExamVO, error) {
+	if err := ensureCourseExists(database.Client.WithContext(ctx), courseID); err != nil {
+		return nil, err
+	}
+	return loadExams(database.Client.WithContext(ctx).Where("course_exams.course_id = ?", courseID))
+}
+
+// ListUserExams 列出用户个人课表（收藏的课程）中的考试安排
+func (s *ExamService) ListUserExams(ctx context.Context, userID uint32) ([]vo.ExamVO, error) {
+	return loadExams(database.Client.WithContext(ctx).
+		Joins("JOIN user_course_favorites f ON f.course_id = course_exams.course_id").
+		Where("f.user_id = ?", userID))
+}
+
+// Calendar 将考试安排导出为 .ics 日历
+func (s *ExamService) Calendar(ctx context.Context, name string, exams []vo.ExamVO) []byte {
+	cal := &ics.Calendar{
+		Name:   name,
+		ProdID: "-//cengkeHelper//Exam Schedule//CN",
+		Events: make([]ics.Event, 0, len(exams)),
+	}
+	for _, exam := range exams {
+		start, errStart := time.ParseInLocation(course.ExamTimeLayout, exam.ExamDate+" "+exam.StartTime, time.Local)
+		end, errEnd := time.ParseInLocation(course.ExamTimeLayout, exam.ExamDate+" "+exam.EndTime, time.Local)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+		summary := exam.CourseName
+		if exam.ExamType != "" {
+			summary += " " + exam.ExamType
+		}
+		cal.Events = append(cal.Events, ics.Event{
+			UID:         "exam-" + strconv.FormatUint(uint64(exam.ID), 10) + "@cengkeHelper",
+			Summary:     summary + "考试",
+			Location:    strings.TrimSpace(exam.Building + " " + exam.Classroom),
+			Description: exam.SeatNote,
+			Start:       start,
+			End:         end,
+		})
+	}
+	return cal.Bytes(clock.Now(ctx))
+}
+
+// loadExams 按考试时间顺序加载考试安排及课程名
+func loadExams(query *gorm.DB) ([]vo.ExamVO, error) {
+	var exams []dto.CourseExam
+	if err := query.Preload("Course", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "course_name")
+	}).Order("course_exams.exam_date ASC, course_exams.start_time ASC, course_exams.id ASC").
+		Find(&exams).Error; err != nil {
+		return nil, fmt.Errorf("查询考试安排失败: %w", err)
+	}
+	result := make([]vo.ExamVO, 0, len(exams))
+	for _, exam := range exams {
+		result = append(result, toExamVO(exam))
+	}
+	return result, nil
+}
+
+// toExamVO 转换考试安排
+func toExamVO(exam dto.CourseExam) vo.ExamVO {
+	return vo.ExamVO{
+		ID:         exam.ID,
+		CourseID:   exam.CourseID,
+		CourseName: exam.Course.CourseName,
+		ExamType:   exam.ExamType,
+		ExamDate:   exam.ExamDate,
+		StartTime:  exam.StartTime,
+		EndTime:    exam.EndTime,
+		Area:       exam.Area,
+		Building:   exam.Building,
+		Classroom:  exam.Classroom,
+		RoomID:     course.BuildRoomID(int(exam.Area), exam.Building, exam.Classroom),
+		SeatNote:   exam.SeatNote,
+	}
+}
+
+// roomExamsOn 某教室某天的考试安排，空闲教室计算时这些时段视为占用
+func roomExamsOn(db *gorm.DB, area int, building, classroom, date string) ([]vo.ExamVO, error) {
+	return loadExams(db.Where("course_exams.area = ? AND course_exams.building = ? AND course_exams.classroom = ? AND course_exams.exam_date = ?",
+		area, building, classroom, date))
+}
@@ -0,0 +1,627 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"cengkeHelperBackGo/pkg/mention"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxMentionsPerComment 一条评论最多通知的 @ 用户数
+const maxMentionsPerComment = 10
+
+// notificationActorsShown 每条通知展示的最近触发用户数
+const notificationActorsShown = 3
+
+type NotificationService struct{}
+
+func NewNotificationService() *NotificationService {
+	return &NotificationService{}
+}
+
+// notificationEvent 一次待投递的通知
+type notificationEvent struct {
+	userID     uint32
+	kind       string
+	targetType string
+	targetID   uint32
+	postID     uint32
+	actorID    uint32 // 触发通知的用户，系统通知为 0
+	excerpt    string
+	message    string
+	// mentions 不为空时按用户名查找接收人，跳过 exclude 中已收到回复通知的用户
+	mentions []string
+	exclude  []uint32
+	// courseIDs 不为空时投递给收藏了这些课程的用户
+	courseIDs []uint32
+	// email 为 true 时同时以邮件发送 message，用于警告、封禁等处理结果
+	email bool
+}
+
+// 通知邮件由独立的协程池发送，SMTP 变慢或无响应时不影响站内通知的写入
+const (
+	notificationEmailWorkers   = 2
+	notificationEmailQueueSize = 256
+	notificationEmailTimeout   = 30 * time.Second
+)
+
+var (
+	notificationQueue      chan notificationEvent
+	notificationEmailQueue chan notificationEvent
+	notificationWorkerOnce sync.Once
+	// emailSending 正在发送的邮件数上限；超时的发送会在后台继续占用名额直到返回，避免协程无限增长
+	emailSending chan struct{}
+
+	droppedNotifications atomic.Int64
+	droppedEmails        atomic.Int64
+)
+
+// startNotificationWorker 启动投递通知的后台协程，通知按入队顺序逐条写入；邮件交给独立的发送协程
+func startNotificationWorker() {
+	size := config.Conf.Notifications.QueueSize
+	if size <= 0 {
+		size = 1024
+	}
+	notificationQueue = make(chan notificationEvent, size)
+	notificationEmailQueue = make(chan notificationEvent, notificationEmailQueueSize)
+	emailSending = make(chan struct{}, 2*notificationEmailWorkers)
+	go func() {
+		for ev := range notificationQueue {
+			if err := deliverNotification(context.Background(), ev); err != nil {
+				log.Printf("Service: 投递 %s 通知失败: %v", ev.kind, err)
+			}
+		}
+	}()
+	for i := 0; i < notificationEmailWorkers; i++ {
+		go func() {
+			for ev := range notificationEmailQueue {
+				sendNotificationEmail(ev)
+			}
+		}()
+	}
+}
+
+// notifyAsync 将通知放入队列后立即返回，不拖慢点赞、评论等请求；队列已满时丢弃并记录累计丢弃数
+func notifyAsync(ev notificationEvent) {
+	if ev.userID != 0 && ev.userID == ev.actorID {
+		return
+	}
+	notificationWorkerOnce.Do(startNotificationWorker)
+	select {
+	case notificationQueue <- ev:
+	default:
+		n := droppedNotifications.Add(1)
+		log.Printf("Service: 通知队列已满，丢弃发给用户 %d 的 %s 通知（累计丢弃 %d 条）", ev.userID, ev.kind, n)
+	}
+}
+
+// emailAsync 将邮件放入发送队列，队列已满时丢弃并记录累计丢弃数，站内通知已经写入
+func emailAsync(ev notificationEvent) {
+	select {
+	case notificationEmailQueue <- ev:
+	default:
+		n := droppedEmails.Add(1)
+		log.Printf("Service: 邮件队列已满，丢弃发给用户 %d 的 %s 邮件（累计丢弃 %d 封）", ev.userID, ev.kind, n)
+	}
+}
+
+// sendNotificationEmail 发送一封通知邮件，超过 notificationEmailTimeout 仍未返回时放弃等待
+func sendNotificationEmail(ev notificationEvent) {
+	emailSending <- struct{}{}
+	done := make(chan error, 1)
+	go func() {
+		defer func() { <-emailSending }()
+		done <- emailNotification(database.Client, ev)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			log.Printf("Service: %v", err)
+		}
+	case <-time.After(notificationEmailTimeout):
+		log.Printf("Service: 发送邮件通知给用户 %d 超时", ev.userID)
+	}
+}
+
+// deliverNotification 写入一次通知，跳过自己触发的和用户关闭了的通知
+func deliverNotification(ctx context.Context, ev notificationEvent) error {
+	db := database.Client.WithContext(ctx)
+	switch {
+	case len(ev.courseIDs) > 0:
+		return deliverCourseUpdate(db, ev)
+	case len(ev.mentions) > 0:
+		return deliverMentions(db, ev)
+	}
+	if ev.userID == 0 || ev.userID == ev.actorID {
+		return nil
+	}
+	if enabled, err := notificationEnabled(db, ev.userID, ev.kind); err != nil || !enabled {
+		return err
+	}
+	if err := saveNotification(db, ev); err != nil {
+		return err
+	}
+	if ev.email {
+		emailAsync(ev)
+	}
+	return nil
+}
+
+// emailNotification 将通知内容以邮件发送给接收人
+func emailNotification(db *gorm.DB, ev notificationEvent) error {
+	var user dto.User
+	if err := db.Select("id", "email").First(&user, ev.userID).Error; err != nil {
+		return fmt.Errorf("查询用户 %d 失败: %w", ev.userID, err)
+	}
+	if err := NewEmailService().SendModerationNotice(user.Email, "社区管理通知", ev.message); err != nil {
+		return fmt.Errorf("发送邮件通知给用户 %d 失败: %w", ev.userID, err)
+	}
+	return nil
+}
+
+func notificationEnabled(db *gorm.DB, userID uint32, kind string) (bool, error) {
+	if !dto.IsConfigurableNotificationType(kind) {
+		return true, nil
+	}
+	var disabled int64
+	if err := db.Model(&dto.NotificationPreference{}).
+		Where("user_id = ? AND type = ? AND enabled = ?", userID, kind, false).
+		Count(&disabled).Error; err != nil {
+		return false, fmt.Errorf("查询通知偏好失败: %w", err)
+	}
+	return disabled == 0, nil
+}
+
+// disabledNotificationUsers 关闭了某类通知的用户，用于批量投递时排除
+func disabledNotificationUsers(db *gorm.DB, kind string) *gorm.DB {
+	return db.Model(&dto.NotificationPreference{}).Select("user_id").Where("type = ? AND enabled = ?", kind, false)
+}
+
+// deliverMentions 按用户名找到评论中 @ 的用户逐个通知，不存在的用户名忽略
+func deliverMentions(db *gorm.DB, ev notificationEvent) error {
+	skip := make(map[uint32]bool, len(ev.exclude)+1)
+	skip[ev.actorID] = true
+	for _, id := range ev.exclude {
+		skip[id] = true
+	}
+	var userIDs []uint32
+	if err := db.Model(&dto.User{}).
+		Where("username IN ?", ev.mentions).
+		Where("id NOT IN (?)", disabledNotificationUsers(db, dto.NotificationMention)).
+		Pluck("id", &userIDs).Error; err != nil {
+		return fmt.Errorf("查询被提及的用户失败: %w", err)
+	}
+	for _, userID := range userIDs {
+		if skip[userID] {
+			continue
+		}
+		e := ev
+		e.userID, e.mentions = userID, nil
+		if err := saveNotification(db, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliverCourseUpdate 通知收藏了课程的用户，同一课程未读期间的更新聚合为一条
+func deliverCourseUpdate(db *gorm.DB, ev notificationEvent) error {
+	var courses []dto.CourseInfo
+	if err := db.Select("id", "course_name").Where("id IN ?", ev.courseIDs).Find(&courses).Error; err != nil {
+		return fmt.Errorf("查询课程失败: %w", err)
+	}
+	for _, course := range courses {
+		var userIDs []uint32
+		if err := db.Model(&dto.UserCourseFavorite{}).
+			Where("course_id = ?", course.ID).
+			Where("user_id NOT IN (?)", disabledNotificationUsers(db, dto.NotificationCourseUpdate)).
+			Pluck("user_id", &userIDs).Error; err != nil {
+			return fmt.Errorf("查询课程 %d 的收藏用户失败: %w", course.ID, err)
+		}
+		for _, userID := range userIDs {
+			if userID == ev.actorID {
+				continue
+			}
+			e := notificationEvent{
+				userID:     userID,
+				kind:       dto.NotificationCourseUpdate,
+				targetType: dto.NotificationTargetCourse,
+				targetID:   course.ID,
+				excerpt:    course.CourseName,
+				message:    ev.message,
+			}
+			if err := saveNotification(db, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// notificationAggregated 可以聚合的通知类型，提及和处理结果每次单独一条
+func notificationAggregated(kind string) bool {
+	switch kind {
+	case dto.NotificationReply, dto.NotificationLike, dto.NotificationCollect, dto.NotificationCourseUpdate:
+		return true
+	}
+	return false
+}
+
+// saveNotification 写入通知。可聚合的通知先按聚合键找到未读的那一条，新的触发用户才增加人数；
+// 同一用户重复回复只更新回复摘要，取消点赞后重新点赞不产生新通知
+func saveNotification(db *gorm.DB, ev notificationEvent) error {
+	var actorID *uint32
+	if ev.actorID != 0 {
+		id := ev.actorID
+		actorID = &id
+	}
+	notification := dto.Notification{
+		UserID:     ev.userID,
+		Type:       ev.kind,
+		TargetType: ev.targetType,
+		TargetID:   ev.targetID,
+		PostID:     ev.postID,
+		ActorID:    actorID,
+		Excerpt:    truncateRunes(ev.excerpt, 200),
+		Message:    truncateRunes(ev.message, 200),
+	}
+
+	if !notificationAggregated(ev.kind) {
+		return db.Transaction(func(tx *gorm.DB) error {
+			notification.Count = 1
+			if err := tx.Create(&notification).Error; err != nil {
+				return fmt.Errorf("保存通知失败: %w", err)
+			}
+			if actorID == nil {
+				return nil
+			}
+			return tx.Create(&dto.NotificationActor{NotificationID: notification.ID, ActorID: *actorID}).Error
+		})
+	}
+
+	key := fmt.Sprintf("%d:%s:%s:%d", ev.userID, ev.kind, ev.targetType, ev.targetID)
+	notification.GroupKey = &key
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&notification).Error; err != nil {
+			return fmt.Errorf("保存通知失败: %w", err)
+		}
+		var group dto.Notification
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("group_key = ?", key).First(&group).Error; err != nil {
+			return fmt.Errorf("查询聚合通知失败: %w", err)
+		}
+
+		updates := map[string]interface{}{
+			"actor_id": actorID,
+			"excerpt":  notification.Excerpt,
+			"message":  notification.Message,
+		}
+		if actorID != nil {
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&dto.NotificationActor{NotificationID: group.ID, ActorID: *actorID})
+			if res.Error != nil {
+				return fmt.Errorf("记录通知触发用户失败: %w", res.Error)
+			}
+			if res.RowsAffected == 0 {
+				if ev.kind != dto.NotificationReply {
+					return nil
+				}
+				return tx.Model(&group).Updates(updates).Error
+			}
+		}
+		updates["count"] = gorm.Expr("`count` + 1")
+		return tx.Model(&group).Updates(updates).Error
+	})
+}
+
+// notifyCommentCreated 通知被回复的评论作者、回复对象和帖子作者，以及评论中 @ 到的用户
+func notifyCommentCreated(post dto.Post, comment dto.Comment, parent *dto.Comment) {
+	message := truncateRunes(comment.Content, 100)
+	notified := map[uint32]bool{comment.AuthorID: true}
+	if parent != nil {
+		recipients := []uint32{parent.AuthorID}
+		if comment.ReplyToUserID != nil {
+			recipients = append(recipients, *comment.ReplyToUserID)
+		}
+		for _, userID := range recipients {
+			if userID == 0 || notified[userID] {
+				continue
+			}
+			notified[userID] = true
+			notifyAsync(notificationEvent{
+				userID:     userID,
+				kind:       dto.NotificationReply,
+				targetType: dto.ContentTargetComment,
+				targetID:   parent.ID,
+				postID:     post.ID,
+				actorID:    comment.AuthorID,
+				excerpt:    parent.Content,
+				message:    message,
+			})
+		}
+	}
+	if !notified[post.AuthorID] {
+		notified[post.AuthorID] = true
+		notifyAsync(notificationEvent{
+			userID:     post.AuthorID,
+			kind:       dto.NotificationReply,
+			targetType: dto.ContentTargetPost,
+			targetID:   post.ID,
+			postID:     post.ID,
+			actorID:    comment.AuthorID,
+			excerpt:    post.Title,
+			message:    message,
+		})
+	}
+
+	names := mention.Extract(comment.Content, maxMentionsPerComment)
+	if len(names) == 0 {
+		return
+	}
+	exclude := make([]uint32, 0, len(notified))
+	for userID := range notified {
+		exclude = append(exclude, userID)
+	}
+	notifyAsync(notificationEvent{
+		kind:       dto.NotificationMention,
+		targetType: dto.ContentTargetComment,
+		targetID:   comment.ID,
+		postID:     post.ID,
+		actorID:    comment.AuthorID,
+		excerpt:    post.Title,
+		message:    message,
+		mentions:   names,
+		exclude:    exclude,
+	})
+}
+
+// notifyCourseUpdated 通知收藏了这些课程的用户课程有更新，actorID 为做出修改的用户，不通知其本人
+func notifyCourseUpdated(courseIDs []uint32, actorID uint32, message string) {
+	if len(courseIDs) == 0 {
+		return
+	}
+	notifyAsync(notificationEvent{kind: dto.NotificationCourseUpdate, courseIDs: courseIDs, actorID: actorID, message: message})
+}
+
+// List 分页查询当前用户的通知，最近有新动态的在前
+func (s *NotificationService) List(ctx context.Context, userID uint32, params dto.GetNotificationsParamsDTO) (*vo.NotificationListVO, error) {
+	db := database.Client.WithContext(ctx)
+	query := db.Model(&dto.Notification{}).Where("user_id = ?", userID)
+	if params.UnreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	if params.Type != "" {
+		query = query.Where("type = ?", params.Type)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计通知失败: %w", err)
+	}
+	var unread int64
+	if err := db.Model(&dto.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&unread).Error; err != nil {
+		return nil, fmt.Errorf("统计未读通知失败: %w", err)
+	}
+	var notifications []dto.Notification
+	if err := query.Order("updated_at DESC, id DESC").Offset((params.Page - 1) * params.Limit).Limit(params.Limit).
+		Find(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("查询通知失败: %w", err)
+	}
+
+	actorIDs := make(map[uint32][]uint32, len(notifications))
+	userIDs := make([]uint32, 0, len(notifications)*notificationActorsShown)
+	for _, n := range notifications {
+		var ids []uint32
+		if err := db.Model(&dto.NotificationActor{}).Where("notification_id = ?", n.ID).
+			Order("created_at DESC").Limit(notificationActorsShown).Pluck("actor_id", &ids).Error; err != nil {
+			return nil, fmt.Errorf("查询通知触发用户失败: %w", err)
+		}
+		actorIDs[n.ID] = ids
+		userIDs = append(userIDs, ids...)
+	}
+	users := make(map[uint32]vo.AuthorInfoVO, len(userIDs))
+	if len(userIDs) > 0 {
+		var rows []dto.User
+		if err := db.Select("id", "username", "avatar").Where("id IN ?", userIDs).Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("查询通知触发用户失败: %w", err)
+		}
+		for _, u := range rows {
+			users[u.Id] = vo.AuthorInfoVO{ID: u.Id, Username: u.Username, Avatar: u.Avatar}
+		}
+	}
+
+	items := make([]vo.NotificationVO, 0, len(notifications))
+	for _, n := range notifications {
+		actors := make([]vo.AuthorInfoVO, 0, len(actorIDs[n.ID]))
+		for _, id := range actorIDs[n.ID] {
+			if u, ok := users[id]; ok {
+				actors = append(actors, u)
+			}
+		}
+		items = append(items, vo.NotificationVO{
+			ID:         n.ID,
+			Type:       n.Type,
+			TargetType: n.TargetType,
+			TargetID:   n.TargetID,
+			PostID:     n.PostID,
+			Summary:    notificationSummary(n, actors),
+			Excerpt:    n.Excerpt,
+			Message:    n.Message,
+			Count:      n.Count,
+			Actors:     actors,
+			IsRead:     n.IsRead,
+			ReadAt:     n.ReadAt,
+			CreatedAt:  n.CreatedAt,
+			UpdatedAt:  n.UpdatedAt,
+		})
+	}
+	return &vo.NotificationListVO{Items: items, Total: total, Unread: unread, CurrentPage: params.Page, PageSize: params.Limit}, nil
+}
+
+// notificationSummary 生成通知标题，如"张三等12人赞了你的帖子《标题》"
+func notificationSummary(n dto.Notification, actors []vo.AuthorInfoVO) string {
+	who := "有人"
+	if len(actors) > 0 {
+		who = actors[0].Username
+	}
+	if n.Count > 1 {
+		who = fmt.Sprintf("%s等%d人", who, n.Count)
+	}
+	var object string
+	switch n.TargetType {
+	case dto.ContentTargetPost:
+		object = fmt.Sprintf("你的帖子《%s》", n.Excerpt)
+	case dto.ContentTargetComment:
+		object = fmt.Sprintf("你的评论「%s」", truncateRunes(n.Excerpt, 30))
+	}
+
+	switch n.Type {
+	case dto.NotificationReply:
+		if n.TargetType == dto.ContentTargetComment {
+			return who + "回复了" + object
+		}
+		return who + "评论了" + object
+	case dto.NotificationLike:
+		return who + "赞了" + object
+	case dto.NotificationCollect:
+		return who + "收藏了" + object
+	case dto.NotificationMention:
+		return fmt.Sprintf("%s在帖子《%s》的评论中提到了你", who, n.Excerpt)
+	case dto.NotificationCourseUpdate:
+		if n.Count > 1 {
+			return fmt.Sprintf("你收藏的课程《%s》有%d条更新", n.Excerpt, n.Count)
+		}
+		return fmt.Sprintf("你收藏的课程《%s》有更新", n.Excerpt)
+	}
+	return n.Message
+}
+
+// UnreadCount 统计当前用户的未读通知数，聚合通知计为一条
+func (s *NotificationService) UnreadCount(ctx context.Context, userID uint32) (*vo.NotificationUnreadVO, error) {
+	var rows []struct {
+		Type  string
+		Count int64
+	}
+	if err := database.Client.WithContext(ctx).Model(&dto.Notification{}).
+		Select("type, COUNT(*) AS count").
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Group("type").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("统计未读通知失败: %w", err)
+	}
+	result := &vo.NotificationUnreadVO{ByType: make(map[string]int64, len(rows))}
+	for _, row := range rows {
+		result.ByType[row.Type] = row.Count
+		result.Total += row.Count
+	}
+	return result, nil
+}
+
+// readUpdates 标记已读时的更新；聚合键置空后，同一对象的新动态会另起一条未读通知
+func readUpdates(now time.Time) map[string]interface{} {
+	return map[string]interface{}{"is_read": true, "read_at": now, "group_key": nil}
+}
+
+// MarkRead 将一条通知标记为已读，已读的通知重复标记视为成功
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID uint32) error {
+	db := database.Client.WithContext(ctx)
+	var notification dto.Notification
+	if err := db.Select("id", "is_read").Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(config.MsgNotificationNotFound)
+		}
+		return fmt.Errorf("查询通知失败: %w", err)
+	}
+	if notification.IsRead {
+		return nil
+	}
+	if err := db.Model(&dto.Notification{}).Where("id = ?", notificationID).Updates(readUpdates(clock.Now(ctx))).Error; err != nil {
+		return fmt.Errorf("标记通知已读失败: %w", err)
+	}
+	return nil
+}
+
+// MarkAllRead 将当前用户的未读通知全部标记为已读，notificationType 不为空时只处理该类型，返回标记的条数
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uint32, notificationType string) (*vo.NotificationReadAllVO, error) {
+	query := database.Client.WithContext(ctx).Model(&dto.Notification{}).Where("user_id = ? AND is_read = ?", userID, false)
+	if notificationType != "" {
+		query = query.Where("type = ?", notificationType)
+	}
+	res := query.Updates(readUpdates(clock.Now(ctx)))
+	if res.Error != nil {
+		return nil, fmt.Errorf("标记通知已读失败: %w", res.Error)
+	}
+	return &vo.NotificationReadAllVO{Updated: res.RowsAffected}, nil
+}
+
+// GetPreferences 返回可设置的通知类型及其开关，未设置过的类型默认开启
+func (s *NotificationService) GetPreferences(ctx context.Context, userID uint32) ([]vo.NotificationPreferenceVO, error) {
+	var prefs []dto.NotificationPreference
+	if err := database.Client.WithContext(ctx).Where("user_id = ?", userID).Find(&prefs).Error; err != nil {
+		return nil, fmt.Errorf("查询通知偏好失败: %w", err)
+	}
+	enabled := make(map[string]bool, len(prefs))
+	for _, p := range prefs {
+		enabled[p.Type] = p.Enabled
+	}
+	result := make([]vo.NotificationPreferenceVO, 0, len(dto.NotificationConfigurableTypes))
+	for _, t := range dto.NotificationConfigurableTypes {
+		on, ok := enabled[t]
+		result = append(result, vo.NotificationPreferenceVO{Type: t, Enabled: !ok || on})
+	}
+	return result, nil
+}
+
+// UpdatePreferences 修改通知开关，只影响之后产生的通知
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uint32, payload dto.UpdateNotificationPreferencesDTO) ([]vo.NotificationPreferenceVO, error) {
+	prefs := make([]dto.NotificationPreference, 0, len(payload.Preferences))
+	for t, on := range payload.Preferences {
+		if !dto.IsConfigurableNotificationType(t) {
+			return nil, errors.New(config.MsgNotificationTypeInvalid)
+		}
+		prefs = append(prefs, dto.NotificationPreference{UserID: userID, Type: t, Enabled: on})
+	}
+	if err := database.Client.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&prefs).Error; err != nil {
+		return nil, fmt.Errorf("保存通知偏好失败: %w", err)
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+// PurgeRead 删除已读超过保留天数的通知，返回删除的条数
+func (s *NotificationService) PurgeRead(ctx context.Context, retentionDays int) (int, error) {
+	db := database.Client.WithContext(ctx)
+	cutoff := clock.Now(ctx).AddDate(0, 0, -retentionDays)
+	purged := 0
+	for {
+		var ids []uint32
+		if err := db.Model(&dto.Notification{}).Where("is_read = ? AND read_at < ?", true, cutoff).
+			Limit(1000).Pluck("id", &ids).Error; err != nil {
+			return purged, fmt.Errorf("查询过期通知失败: %w", err)
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("notification_id IN ?", ids).Delete(&dto.NotificationActor{}).Error; err != nil {
+				return err
+			}
+			return tx.Where("id IN ?", ids).Delete(&dto.Notification{}).Error
+		})
+		if err != nil {
+			return purged, fmt.Errorf("删除过期通知失败: %w", err)
+		}
+		purged += len(ids)
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	"cengkeHelperBackGo/pkg/cursor"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// keysetKind 排序键的类型，决定游标中的值如何编码
+type keysetKind int
+
+const (
+	keysetTime keysetKind = iota
+	keysetInt
+	keysetFloat
+)
+
+// keysetColumn 列表支持的一个排序字段
+type keysetColumn struct {
+	expr string // 排序键的 SQL 表达式
+	kind keysetKind
+}
+
+// keysetOrder 解析后的排序方式：按排序键排序，排序键相同时按 id 同方向排序，保证顺序稳定、可以用游标续读
+type keysetOrder struct {
+	sort  string        // 规范化后的 sortBy，写入游标
+	field string        // 前端字段名，用于从结果中取排序键
+	expr  string        // 排序键的 SQL 表达式
+	args  []interface{} // expr 中占位符的参数
+	kind  keysetKind
+	desc  bool
+}
+
+// parseKeysetOrder 解析 "field_asc/field_desc" 形式的 sortBy，不支持的字段使用 fallback，方向缺省为降序
+func parseKeysetOrder(sortBy string, columns map[string]keysetColumn, fallback string) keysetOrder {
+	field, dir, _ := strings.Cut(sortBy, "_")
+	col, ok := columns[field]
+	if !ok {
+		field, dir = fallback, "desc"
+		col = columns[fallback]
+	}
+	desc := !strings.EqualFold(dir, "asc")
+	direction := "desc"
+	if !desc {
+		direction = "asc"
+	}
+	return keysetOrder{
+		sort:  field + "_" + direction,
+		field: field,
+		expr:  col.expr,
+		kind:  col.kind,
+		desc:  desc,
+	}
+}
+
+// apply 为查询加上排序；c 非空时只取游标之后的数据
+func (o keysetOrder) apply(query *gorm.DB, c *cursor.Cursor) (*gorm.DB, error) {
+	op, dir := ">", "ASC"
+	if o.desc {
+		op, dir = "<", "DESC"
+	}
+	if c != nil {
+		value, err := o.parseValue(c.Value)
+		if err != nil {
+			return nil, errors.New(config.MsgCursorInvalid)
+		}
+		args := make([]interface{}, 0, 2*len(o.args)+3)
+		args = append(args, o.args...)
+		args = append(args, value)
+		args = append(args, o.args...)
+		args = append(args, value, c.ID)
+		query = query.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", o.expr, op, o.expr, op), args...)
+	}
+	return query.Order(clause.Expr{SQL: o.expr + " " + dir + ", id " + dir, Vars: o.args, WithoutParentheses: true}), nil
+}
+
+// decode 解析请求中的游标，为空时返回 nil
+func (o keysetOrder) decode(s string) (*cursor.Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	c, err := cursor.Decode(s, o.sort)
+	if err != nil {
+		return nil, errors.New(config.MsgCursorInvalid)
+	}
+	return &c, nil
+}
+
+// encode 根据最后一条数据的排序键和ID生成下一页的游标
+func (o keysetOrder) encode(value interface{}, id uint32) string {
+	var s string
+	switch v := value.(type) {
+	case time.Time:
+		s = v.Format(time.RFC3339Nano)
+	case float64:
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		s = fmt.Sprint(v)
+	}
+	return cursor.Cursor{Sort: o.sort, Value: s, ID: id}.Encode()
+}
+
+func (o keysetOrder) parseValue(s string) (interface{}, error) {
+	switch o.kind {
+	case keysetTime:
+		return time.Parse(time.RFC3339Nano, s)
+	case keysetFloat:
+		return strconv.ParseFloat(s, 64)
+	default:
+		return strconv.ParseInt(s, 10, 64)
+	}
+}
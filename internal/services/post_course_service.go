@@ -0,0 +1,108 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// replacePostCourses 用 courseIDs 整体替换帖子关联的课程，需在事务中调用
+func replacePostCourses(tx *gorm.DB, postID uint32, courseIDs []uint32) error {
+	courseIDs = uniqueUint32(courseIDs)
+
+	if len(courseIDs) > 0 {
+		var count int64
+		if err := tx.Model(&dto.CourseInfo{}).Where("id IN ?", courseIDs).Count(&count).Error; err != nil {
+			return fmt.Errorf("验证关联课程失败: %w", err)
+		}
+		if int(count) != len(courseIDs) {
+			return errors.New(config.MsgCourseNotFound)
+		}
+	}
+
+	if err := tx.Where("post_id = ?", postID).Delete(&dto.PostCourse{}).Error; err != nil {
+		return fmt.Errorf("清除帖子关联课程失败: %w", err)
+	}
+	if len(courseIDs) == 0 {
+		return nil
+	}
+
+	links := make([]dto.PostCourse, 0, len(courseIDs))
+	for _, courseID := range courseIDs {
+		links = append(links, dto.PostCourse{PostID: postID, CourseID: courseID})
+	}
+	if err := tx.Create(&links).Error; err != nil {
+		return fmt.Errorf("保存帖子关联课程失败: %w", err)
+	}
+	return nil
+}
+
+// loadCourseCardsByPostIDs 批量加载一组帖子关联的课程卡片，返回 postID -> 课程卡片
+func loadCourseCardsByPostIDs(db *gorm.DB, postIDs []uint32) (map[uint32][]vo.CourseCardVO, error) {
+	result := make(map[uint32][]vo.CourseCardVO)
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+
+	var links []dto.PostCourse
+	if err := db.Where("post_id IN ?", postIDs).Order("created_at ASC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("查询帖子关联课程失败: %w", err)
+	}
+	if len(links) == 0 {
+		return result, nil
+	}
+
+	courseIDs := make([]uint32, 0, len(links))
+	for _, link := range links {
+		courseIDs = append(courseIDs, link.CourseID)
+	}
+	var courses []dto.CourseInfo
+	if err := db.Where("id IN ?", uniqueUint32(courseIDs)).Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("查询关联课程信息失败: %w", err)
+	}
+	courseMap := make(map[uint32]dto.CourseInfo, len(courses))
+	for _, course := range courses {
+		courseMap[course.ID] = course
+	}
+
+	for _, link := range links {
+		if course, ok := courseMap[link.CourseID]; ok {
+			result[link.PostID] = append(result[link.PostID], toCourseCardVO(course))
+		}
+	}
+	return result, nil
+}
+
+// uniqueUint32 去重并保持原有顺序
+func uniqueUint32(ids []uint32) []uint32 {
+	seen := make(map[uint32]struct{}, len(ids))
+	result := make([]uint32, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
+
+// GetPostsByCourse 获取关联了指定课程的帖子（课程讨论区），课程不存在时返回 config.MsgCourseNotFound
+func (s *PostService) GetPostsByCourse(ctx context.Context, courseID uint32, params *dto.GetPostsParamsDTO, currentUserID *uint32) (*vo.GetPostsResponseDataVO, error) {
+	var course dto.CourseInfo
+	if err := database.Client.Select("id").First(&course, courseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgCourseNotFound)
+		}
+		return nil, fmt.Errorf("查询课程失败: %w", err)
+	}
+
+	params.CourseID = courseID
+	return s.GetPosts(ctx, params, currentUserID)
+}
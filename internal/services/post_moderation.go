@@ -0,0 +1,113 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// postPinActive 帖子当前是否处于置顶状态，置顶到期后自动失效，无需定时任务清理
+func postPinActive(post dto.Post, now time.Time) bool {
+	return post.IsPinned && (post.PinnedUntil == nil || post.PinnedUntil.After(now))
+}
+
+// activePinCondition 列表中生效的置顶条件：全站置顶总是生效，分类置顶只在按分类筛选时生效
+func activePinCondition(now time.Time, byCategory bool) (string, []interface{}) {
+	cond := "is_pinned = TRUE AND (pinned_until IS NULL OR pinned_until > ?) AND "
+	if byCategory {
+		cond += "pin_scope IN ?"
+		return "(" + cond + ")", []interface{}{now, []string{dto.PinScopeGlobal, dto.PinScopeCategory}}
+	}
+	cond += "pin_scope = ?"
+	return "(" + cond + ")", []interface{}{now, dto.PinScopeGlobal}
+}
+
+// moderatePost 对已发布的帖子执行管理操作，返回操作后的帖子
+func (s *PostService) moderatePost(ctx context.Context, postID uint32, fn func(db *gorm.DB, post *dto.Post) error) (*vo.PostVO, error) {
+	db := database.Client.WithContext(ctx)
+	var post dto.Post
+	if err := db.First(&post, postID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgPostNotFound)
+		}
+		return nil, err
+	}
+	if !post.IsPublished {
+		return nil, errors.New(config.MsgPostNotFound)
+	}
+	if err := fn(db, &post); err != nil {
+		return nil, err
+	}
+	if err := db.Preload("Author").First(&post, postID).Error; err != nil {
+		return nil, fmt.Errorf("获取帖子信息失败: %w", err)
+	}
+	return convertPostToVO(ctx, post, nil, db)
+}
+
+// PinPost 置顶帖子，分类置顶要求帖子有分类；重复置顶会更新范围、到期时间并排到最前
+func (s *PostService) PinPost(ctx context.Context, postID uint32, payload dto.PinPostDTO) (*vo.PostVO, error) {
+	now := clock.Now(ctx)
+	if payload.Until != nil && !payload.Until.After(now) {
+		return nil, errors.New(config.MsgPinExpiryInvalid)
+	}
+	return s.moderatePost(ctx, postID, func(db *gorm.DB, post *dto.Post) error {
+		if payload.Scope == dto.PinScopeCategory && (post.Category == nil || *post.Category == "") {
+			return errors.New(config.MsgPinCategoryMissing)
+		}
+		return db.Model(post).UpdateColumns(map[string]interface{}{
+			"is_pinned":    true,
+			"pin_scope":    payload.Scope,
+			"pinned_at":    now,
+			"pinned_until": payload.Until,
+		}).Error
+	})
+}
+
+// UnpinPost 取消置顶
+func (s *PostService) UnpinPost(ctx context.Context, postID uint32) (*vo.PostVO, error) {
+	return s.moderatePost(ctx, postID, func(db *gorm.DB, post *dto.Post) error {
+		return db.Model(post).UpdateColumns(map[string]interface{}{
+			"is_pinned":    false,
+			"pin_scope":    "",
+			"pinned_at":    nil,
+			"pinned_until": nil,
+		}).Error
+	})
+}
+
+// SetPostLocked 锁定或解锁帖子评论，锁定后 AddComment 拒绝新评论
+func (s *PostService) SetPostLocked(ctx context.Context, postID uint32, locked bool) (*vo.PostVO, error) {
+	return s.moderatePost(ctx, postID, func(db *gorm.DB, post *dto.Post) error {
+		return db.Model(post).UpdateColumn("is_locked", locked).Error
+	})
+}
+
+// SetPostFeatured 设置或取消精华帖
+func (s *PostService) SetPostFeatured(ctx context.Context, postID uint32, featured bool) (*vo.PostVO, error) {
+	now := clock.Now(ctx)
+	return s.moderatePost(ctx, postID, func(db *gorm.DB, post *dto.Post) error {
+		var featuredAt *time.Time
+		if featured {
+			featuredAt = &now
+		}
+		return db.Model(post).UpdateColumns(map[string]interface{}{
+			"is_featured": featured,
+			"featured_at": featuredAt,
+		}).Error
+	})
+}
+
+// MovePost 将帖子移动到另一个分类，分类置顶随帖子一起移动
+func (s *PostService) MovePost(ctx context.Context, postID uint32, category string) (*vo.PostVO, error) {
+	return s.moderatePost(ctx, postID, func(db *gorm.DB, post *dto.Post) error {
+		return db.Model(post).UpdateColumn("category", category).Error
+	})
+}
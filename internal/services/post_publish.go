@@ -0,0 +1,176 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// postPublishBatch 每轮最多发布的到期定时帖子数
+const postPublishBatch = 100
+
+// publishPost 在 tx 中发布草稿：created_at 更新为发布时间，使列表排序、今日新帖和热门标签都以发布时间为准，
+// 并在此时更新标签使用次数与社区计数。草稿已被发布（例如多个实例同时处理）时返回 false
+func publishPost(tx *gorm.DB, post dto.Post, now time.Time) (bool, error) {
+	result := tx.Model(&dto.Post{}).
+		Where("id = ? AND is_published = ?", post.ID, false).
+		UpdateColumns(map[string]interface{}{"is_published": true, "publish_at": nil, "created_at": now})
+	if result.Error != nil {
+		return false, fmt.Errorf("发布帖子失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	if err := tx.Model(&dto.PostTag{}).Where("post_id = ?", post.ID).UpdateColumn("created_at", now).Error; err != nil {
+		return false, fmt.Errorf("更新帖子标签时间失败: %w", err)
+	}
+	tagIDs, err := postTagIDs(tx, post.ID)
+	if err != nil {
+		return false, err
+	}
+	if err := recountTagUsage(tx, tagIDs); err != nil {
+		return false, err
+	}
+	if err := applyCounterEvent(tx, counterEvent{kind: counterPostPublished, postID: post.ID, ownerID: post.AuthorID}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PublishPost 发布作者自己的草稿；payload.PublishAt 晚于当前时间时改为定时发布，可重复调用修改发布时间
+func (s *PostService) PublishPost(ctx context.Context, postID, userID uint32, payload dto.PublishPostDTO) (*vo.PostVO, error) {
+	db := database.Client.WithContext(ctx)
+	var post dto.Post
+	if err := db.First(&post, postID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgPostNotFound)
+		}
+		return nil, err
+	}
+	if post.AuthorID != userID {
+		return nil, errors.New(config.MsgPostNotAuthor)
+	}
+	if post.IsPublished {
+		return nil, errors.New(config.MsgPostAlreadyPublished)
+	}
+
+	now := clock.Now(ctx)
+	if payload.PublishAt != nil && payload.PublishAt.After(now) {
+		if err := db.Model(&post).UpdateColumn("publish_at", *payload.PublishAt).Error; err != nil {
+			return nil, fmt.Errorf("设置定时发布失败: %w", err)
+		}
+	} else {
+		var published bool
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			published, err = publishPost(tx, post, now)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		if !published {
+			return nil, errors.New(config.MsgPostAlreadyPublished)
+		}
+	}
+
+	if err := db.Preload("Author").First(&post, postID).Error; err != nil {
+		return nil, fmt.Errorf("获取发布后的帖子信息失败: %w", err)
+	}
+	return convertPostToVO(ctx, post, &userID, db)
+}
+
+// PublishDue 发布所有已到发布时间的定时帖子并通知作者，返回本次发布的帖子数。
+// 定时状态保存在数据库中，服务重启后由下一轮任务继续发布
+func (s *PostService) PublishDue(ctx context.Context) (int, error) {
+	db := database.Client.WithContext(ctx)
+	now := clock.Now(ctx)
+	total := 0
+	for {
+		var due []dto.Post
+		if err := db.Preload("Author").
+			Where("is_published = ? AND publish_at <= ?", false, now).
+			Order("publish_at, id").
+			Limit(postPublishBatch).
+			Find(&due).Error; err != nil {
+			return total, fmt.Errorf("查询到期的定时帖子失败: %w", err)
+		}
+		if len(due) == 0 {
+			return total, nil
+		}
+
+		for _, post := range due {
+			var published bool
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				var err error
+				published, err = publishPost(tx, post, now)
+				return err
+			}); err != nil {
+				return total, fmt.Errorf("发布定时帖子 %d 失败: %w", post.ID, err)
+			}
+			if !published {
+				continue
+			}
+			total++
+			notifyAsync(notificationEvent{
+				userID:     post.AuthorID,
+				kind:       dto.NotificationPostPublish,
+				targetType: dto.ContentTargetPost,
+				targetID:   post.ID,
+				postID:     post.ID,
+				excerpt:    post.Title,
+				message:    fmt.Sprintf("你定时发布的帖子《%s》已发布", post.Title),
+			})
+			if post.Author.Email != "" {
+				if err := NewEmailService().SendPostPublished(post.Author.Email, post.Title); err != nil {
+					log.Printf("Service: 通知作者帖子 %d 已发布失败: %v", post.ID, err)
+				}
+			}
+		}
+	}
+}
+
+// ListDrafts 获取作者自己的草稿（含定时发布的帖子），按最近修改排序
+func (s *PostService) ListDrafts(ctx context.Context, userID uint32, params dto.GetDraftsParamsDTO) (*vo.GetPostsResponseDataVO, error) {
+	db := database.Client.WithContext(ctx)
+	query := db.Model(&dto.Post{}).Where("author_id = ? AND is_published = ?", userID, false)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计草稿失败: %w", err)
+	}
+	var posts []dto.Post
+	if err := query.Preload("Author").
+		Order("updated_at DESC, id DESC").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("查询草稿失败: %w", err)
+	}
+
+	items := make([]vo.PostVO, 0, len(posts))
+	for _, post := range posts {
+		item, err := convertPostToVO(ctx, post, nil, db)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	page, pageSize := params.Page, params.Limit
+	return &vo.GetPostsResponseDataVO{
+		Items:       items,
+		Total:       &total,
+		CurrentPage: &page,
+		PageSize:    &pageSize,
+		HasMore:     int64(params.Page*params.Limit) < total,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/fulltext"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// postSearchMinTokenLen 与 MySQL ngram_token_size 默认值一致，更短的词无法走全文索引
+	postSearchMinTokenLen = 2
+	// postMatchExpr 与 dto.Post 上 idx_posts_fulltext 索引的列顺序保持一致
+	postMatchExpr = "MATCH(title, content, search_tags) AGAINST (? IN BOOLEAN MODE)"
+	// postSnippetRunes 搜索结果内容摘要的长度
+	postSnippetRunes = 120
+	// postSearchBackfillBatch 回填标签文本时每批处理的帖子数
+	postSearchBackfillBatch = 500
+)
+
+// applyPostTextFilter 按搜索语法过滤帖子：能走全文索引的词用 MATCH AGAINST，过短的词和只有排除词时退回 LIKE。
+// 返回的 against 非空时可用于按相关度排序。
+func applyPostTextFilter(query *gorm.DB, q fulltext.Query) (*gorm.DB, string) {
+	against, shortInclude, shortExclude := q.Against(postSearchMinTokenLen)
+	if against != "" {
+		query = query.Where(postMatchExpr, against)
+	}
+	for _, text := range shortInclude {
+		like := "%" + escapeLike(text) + "%"
+		query = query.Where("(title LIKE ? OR content LIKE ? OR search_tags LIKE ?)", like, like, like)
+	}
+	for _, text := range shortExclude {
+		like := "%" + escapeLike(text) + "%"
+		query = query.Where("NOT (title LIKE ? OR content LIKE ? OR IFNULL(search_tags, '') LIKE ?)", like, like, like)
+	}
+	return query, against
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// highlightPost 生成搜索结果的标题高亮与内容摘要
+func highlightPost(post dto.Post, q fulltext.Query) *vo.PostHighlightVO {
+	return &vo.PostHighlightVO{
+		Title:   fulltext.Highlight(post.Title, q.Include, 0),
+		Snippet: fulltext.Highlight(fulltext.PlainText(post.Content), q.Include, postSnippetRunes),
+	}
+}
+
+// postSearchTags 生成供全文索引使用的标签文本
+func postSearchTags(tags []string) *string {
+	text := strings.Join(tags, " ")
+	return &text
+}
+
+// BackfillSearchTags 为建立全文索引前发布的帖子补齐标签文本，已回填的帖子不会重复处理
+func (s *PostService) BackfillSearchTags(ctx context.Context) (int, error) {
+	db := database.Client.WithContext(ctx)
+	total := 0
+	for {
+		var posts []dto.Post
+		if err := db.Unscoped().Select("id", "tags").
+			Where("search_tags IS NULL").
+			Limit(postSearchBackfillBatch).
+			Find(&posts).Error; err != nil {
+			return total, fmt.Errorf("查询待回填的帖子失败: %w", err)
+		}
+		if len(posts) == 0 {
+			return total, nil
+		}
+		for _, post := range posts {
+			var tags []string
+			if len(post.Tags) > 0 {
+				if err := json.Unmarshal(post.Tags, &tags); err != nil {
+					log.Printf("Service: 帖子 %d 的标签无法解析: %v", post.ID, err)
+				}
+			}
+			if err := db.Unscoped().Model(&dto.Post{}).Where("id = ?", post.ID).
+				UpdateColumn("search_tags", postSearchTags(tags)).Error; err != nil {
+				return total, fmt.Errorf("回填帖子 %d 的标签文本失败: %w", post.ID, err)
+			}
+		}
+		total += len(posts)
+	}
+}
@@ -4,12 +4,14 @@ import (
 	database "cengkeHelperBackGo/internal/db"
 	"cengkeHelperBackGo/internal/models/dto"
 	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"cengkeHelperBackGo/pkg/fulltext"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"time"
 
-	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -21,14 +23,50 @@ func NewPostService() *PostService {
 	return &PostService{}
 }
 
-// GetPosts 获取帖子列表并处理分页、排序和过滤
-func (s *PostService) GetPosts(params *dto.GetPostsParamsDTO) (*vo.GetPostsResponseDataVO, error) {
+// ErrPostNotFound 帖子不存在或已被删除
+var ErrPostNotFound = errors.New("帖子未找到")
+
+// postSortRelevance 按全文检索相关度排序
+const postSortRelevance = "relevance"
+
+// postSortColumns 帖子列表支持的排序字段
+var postSortColumns = map[string]keysetColumn{
+	"createdAt":     {"created_at", keysetTime},
+	"updatedAt":     {"updated_at", keysetTime},
+	"viewCount":     {"view_count", keysetInt},
+	"likesCount":    {"likes_count", keysetInt},
+	"commentsCount": {"comments_count", keysetInt},
+}
+
+// postSortValue 取帖子在指定排序字段上的值，用于生成游标
+func postSortValue(p dto.Post, field string) interface{} {
+	switch field {
+	case "updatedAt":
+		return p.UpdatedAt
+	case "viewCount":
+		return p.ViewCount
+	case "likesCount":
+		return p.LikesCount
+	case "commentsCount":
+		return p.CommentsCount
+	case postSortRelevance:
+		return p.Relevance
+	default:
+		return p.CreatedAt
+	}
+}
+
+// GetPosts 获取帖子列表并处理分页、排序和过滤；currentUserID 非空时填充当前用户的点赞、收藏状态
+func (s *PostService) GetPosts(ctx context.Context, params *dto.GetPostsParamsDTO, currentUserID *uint32) (*vo.GetPostsResponseDataVO, error) {
 	var posts []dto.Post
 	var total int64
 
-	query := database.Client.Model(&dto.Post{})
-	if params.FilterText != "" {
-		query = query.Where("title LIKE ? OR content LIKE ?", "%"+params.FilterText+"%", "%"+params.FilterText+"%")
+	// 草稿、未到发布时间的定时帖子以及待审核、已隐藏的帖子不出现在列表和搜索结果中
+	query := database.Client.Model(&dto.Post{}).Where("is_published = ? AND moderation_status = ?", true, dto.ModerationVisible)
+	searchQuery := fulltext.Parse(params.FilterText)
+	against := ""
+	if !searchQuery.IsEmpty() {
+		query, against = applyPostTextFilter(query, searchQuery)
 	}
 	if params.Category != "" {
 		query = query.Where("category = ?", params.Category)
@@ -36,51 +74,95 @@ func (s *PostService) GetPosts(params *dto.GetPostsParamsDTO) (*vo.GetPostsRespo
 	if params.AuthorID != 0 {
 		query = query.Where("author_id = ?", params.AuthorID)
 	}
-	if params.Tag != "" {
-		query = query.Where("CAST(tags AS CHAR) LIKE ?", "%"+params.Tag+"%")
+	if params.Tag != "" || params.Tags != "" {
+		tagNames := splitTagParam(params.Tags)
+		if params.Tag != "" {
+			tagNames = append(tagNames, params.Tag)
+		}
+		var err error
+		if query, err = applyPostTagFilter(database.Client, query, tagNames, params.TagMatch); err != nil {
+			return nil, err
+		}
+	}
+	if params.CourseID != 0 {
+		query = query.Where("id IN (?)", database.Client.Model(&dto.PostCourse{}).Select("post_id").Where("course_id = ?", params.CourseID))
 	}
-
-	// 计算总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, err
+	if params.Featured {
+		query = query.Where("is_featured = ?", true)
 	}
 
-	// 应用排序
-	if params.SortBy != "" {
-		parts := strings.Split(params.SortBy, "_")
-		if len(parts) == 2 {
-			column := parts[0]
-			order := strings.ToUpper(parts[1])
-
-			// Map frontend column names to actual database column names
-			columnMapping := map[string]string{
-				"createdAt":     "created_at",
-				"updatedAt":     "updated_at",
-				"viewCount":     "view_count",
-				"likesCount":    "likes_count",
-				"commentsCount": "comments_count",
+	// 置顶帖：不搜索时，生效的置顶帖在第一页单独排在最前，并从常规分页中排除，避免翻页时重复出现
+	now := clock.Now(ctx)
+	var pinned []dto.Post
+	var pinnedTotal int64
+	if searchQuery.IsEmpty() {
+		cond, args := activePinCondition(now, params.Category != "")
+		if params.Cursor == "" && params.Page <= 1 {
+			if err := query.Session(&gorm.Session{}).Where(cond, args...).
+				Order("pinned_at DESC, id DESC").Preload("Author").Find(&pinned).Error; err != nil {
+				return nil, err
 			}
-
-			if dbColumn, exists := columnMapping[column]; exists {
-				query = query.Order(dbColumn + " " + order)
-			} else {
-				query = query.Order("created_at DESC")
+			pinnedTotal = int64(len(pinned))
+		} else if !params.SkipTotal {
+			if err := query.Session(&gorm.Session{}).Where(cond, args...).Count(&pinnedTotal).Error; err != nil {
+				return nil, err
 			}
-		} else {
-			query = query.Order("created_at DESC")
 		}
-	} else {
-		query = query.Order("created_at DESC")
+		query = query.Where("NOT "+cond, args...)
 	}
 
-	// 应用分页
-	offset := (params.Page - 1) * params.Limit
-	query = query.Offset(offset).Limit(params.Limit)
+	// 计算总数，滚动加载时可通过 skipTotal 跳过
+	if !params.SkipTotal {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		total += pinnedTotal
+	}
+
+	// 应用排序：有全文检索条件时默认按相关度排序
+	order := parseKeysetOrder(params.SortBy, postSortColumns, "createdAt")
+	if against != "" && (params.SortBy == "" || params.SortBy == postSortRelevance) {
+		order = keysetOrder{sort: postSortRelevance, field: postSortRelevance, expr: postMatchExpr, args: []interface{}{against}, kind: keysetFloat, desc: true}
+		query = query.Select("posts.*, "+postMatchExpr+" AS relevance", against)
+	}
+	after, err := order.decode(params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if query, err = order.apply(query, after); err != nil {
+		return nil, err
+	}
+
+	// 应用分页：传入游标时从游标处续读，否则按页码偏移；多取一条用于判断是否还有下一页
+	if after == nil {
+		query = query.Offset((params.Page - 1) * params.Limit)
+	}
+	query = query.Limit(params.Limit + 1)
 
 	// 预加载 Author 信息
 	if err := query.Preload("Author").Find(&posts).Error; err != nil {
 		return nil, err
 	}
+	hasMore := len(posts) > params.Limit
+	if hasMore {
+		posts = posts[:params.Limit]
+	}
+	var nextCursor string
+	if hasMore {
+		last := posts[len(posts)-1]
+		nextCursor = order.encode(postSortValue(last, order.field), last.ID)
+	}
+	posts = append(pinned, posts...)
+
+	postIDs := make([]uint32, 0, len(posts))
+	for _, p := range posts {
+		postIDs = append(postIDs, p.ID)
+	}
+	// 整页帖子一次性查询当前用户的点赞、收藏状态
+	viewer, err := loadPostViewerState(database.Client, currentUserID, postIDs)
+	if err != nil {
+		return nil, err
+	}
 
 	// --- 转换为 VO (关键修改) ---
 	// itemsVO := make([]vo.PostSimpleVO, len(posts)) // 旧代码
@@ -113,11 +195,10 @@ func (s *PostService) GetPosts(params *dto.GetPostsParamsDTO) (*vo.GetPostsRespo
 		collectCountPtr := int(p.CollectCount)
 		commentsCountPtr := int(p.CommentsCount)
 		isPublishedPtr := p.IsPublished
-		isPinnedPtr := p.IsPinned
+		isPinnedPtr := postPinActive(p, now)
 		isLockedPtr := p.IsLocked
-		isLikedByCurrentUserPtr := p.IsLikedByCurrentUser
-		isCollectedByCurrentUserPtr := p.IsCollectedByCurrentUser
-		// isLikedByCurrentUserPtr := false // 这个需要额外逻辑判断当前用户是否点赞
+		isLikedByCurrentUserPtr := viewer.liked[p.ID]
+		isCollectedByCurrentUserPtr := viewer.collected[p.ID]
 
 		// *** 修改点 3: 填充 PostVO 而不是 PostSimpleVO ***
 		itemsVO[i] = vo.PostVO{
@@ -135,25 +216,47 @@ func (s *PostService) GetPosts(params *dto.GetPostsParamsDTO) (*vo.GetPostsRespo
 			CommentsCount:            &commentsCountPtr,
 			IsPublished:              &isPublishedPtr,
 			IsPinned:                 &isPinnedPtr,
+			PinScope:                 p.PinScope,
+			PinnedUntil:              p.PinnedUntil,
 			IsLocked:                 &isLockedPtr,
+			IsFeatured:               p.IsFeatured,
+			ModerationStatus:         p.ModerationStatus,
 			CollectCount:             &collectCountPtr,
-			IsLikedByCurrentUser:     &isLikedByCurrentUserPtr,     // 需要额外逻辑
-			IsCollectedByCurrentUser: &isCollectedByCurrentUserPtr, // 需要额外逻辑
-			// IsLikedByCurrentUser: &isLikedByCurrentUserPtr, // 需要额外逻辑
+			IsLikedByCurrentUser:     &isLikedByCurrentUserPtr,
+			IsCollectedByCurrentUser: &isCollectedByCurrentUserPtr,
+		}
+		if len(searchQuery.Include) > 0 {
+			itemsVO[i].Highlight = highlightPost(p, searchQuery)
 		}
 	}
 
-	currentPage := params.Page // 获取 int 值
-	pageSize := params.Limit   // 获取 int 值
-	return &vo.GetPostsResponseDataVO{
-		Items:       itemsVO, // *** 现在是 []PostVO ***
-		Total:       total,
-		CurrentPage: &currentPage, // *** 修改点 4: 取地址变成 *int ***
-		PageSize:    &pageSize,    // *** 修改点 5: 取地址变成 *int ***
-	}, nil
+	// 批量加载关联课程卡片
+	courseCards, err := loadCourseCardsByPostIDs(database.Client, postIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range itemsVO {
+		itemsVO[i].Courses = courseCards[itemsVO[i].ID]
+	}
+
+	pageSize := params.Limit // 获取 int 值
+	result := &vo.GetPostsResponseDataVO{
+		Items:    itemsVO, // *** 现在是 []PostVO ***
+		PageSize: &pageSize,
+		HasMore:  hasMore,
+	}
+	if !params.SkipTotal {
+		result.Total = &total
+	}
+	if after == nil {
+		currentPage := params.Page
+		result.CurrentPage = &currentPage
+	}
+	result.NextCursor = nextCursor
+	return result, nil
 }
 
-func (s *PostService) GetPostByID(postID uint32, currentUserID *uint32) (*vo.PostVO, error) {
+func (s *PostService) GetPostByID(ctx context.Context, postID uint32, currentUserID *uint32) (*vo.PostVO, error) {
 	// 开启事务
 	tx := database.Client.Begin()
 	defer func() {
@@ -175,11 +278,17 @@ func (s *PostService) GetPostByID(postID uint32, currentUserID *uint32) (*vo.Pos
 		return nil, err
 	}
 
+	// 草稿、待审核和已隐藏的帖子只有作者本人可以查看，不计浏览量
+	if !post.IsPublished || post.ModerationStatus != dto.ModerationVisible {
+		tx.Rollback()
+		if currentUserID == nil || *currentUserID != post.AuthorID {
+			return nil, errors.New("帖子未找到")
+		}
+		return convertPostToVO(ctx, post, currentUserID, database.Client)
+	}
+
 	// 2. 浏览量+1（使用原子操作避免并发问题）
-	if err := tx.Model(&dto.Post{}).
-		Where("id = ?", postID).
-		UpdateColumn("view_count", gorm.Expr("view_count + 1")).
-		Error; err != nil {
+	if err := applyCounterEvent(tx, counterEvent{kind: counterPostViewed, postID: postID}); err != nil {
 		tx.Rollback()
 		return nil, fmt.Errorf("更新浏览量失败: %w", err)
 	}
@@ -196,12 +305,12 @@ func (s *PostService) GetPostByID(postID uint32, currentUserID *uint32) (*vo.Pos
 	}
 
 	// 4. 转换为VO对象并返回
-	return convertPostToVO(post, currentUserID, database.Client)
+	return convertPostToVO(ctx, post, currentUserID, database.Client)
 }
 
 // --- Helper function to convert dto.Post to vo.PostVO ---
 // (这个辅助函数可以抽取出来，或者每个需要的地方单独实现转换逻辑)
-func convertPostToVO(post dto.Post, currentUserID *uint32, db *gorm.DB) (*vo.PostVO, error) {
+func convertPostToVO(ctx context.Context, post dto.Post, currentUserID *uint32, db *gorm.DB) (*vo.PostVO, error) {
 	var tagsInPost []string
 	if post.Tags != nil && len(post.Tags) > 0 {
 		if err := json.Unmarshal(post.Tags, &tagsInPost); err != nil {
@@ -222,24 +331,25 @@ func convertPostToVO(post dto.Post, currentUserID *uint32, db *gorm.DB) (*vo.Pos
 	likesCount := int(post.LikesCount)
 	commentsCount := int(post.CommentsCount)
 	isPublished := post.IsPublished
-	isPinned := post.IsPinned
+	isPinned := postPinActive(post, clock.Now(ctx))
 	isLocked := post.IsLocked
 	collectCount := int(post.CollectCount)
 
-	var isLikedByCurrentUser bool = post.IsLikedByCurrentUser
-	var isCollectedByCurrentUser bool = post.IsCollectedByCurrentUser
-
-	if currentUserID != nil && *currentUserID > 0 && db != nil {
-		var likeCount int64
-		db.Model(&dto.UserPostLike{}).Where("user_id = ? AND post_id = ?", *currentUserID, post.ID).Count(&likeCount)
-		if likeCount > 0 {
-			isLikedByCurrentUser = true
+	var isLikedByCurrentUser, isCollectedByCurrentUser bool
+	var courses []vo.CourseCardVO
+	if db != nil {
+		viewer, err := loadPostViewerState(db, currentUserID, []uint32{post.ID})
+		if err != nil {
+			return nil, err
 		}
-		var collectCount int64
-		db.Model(&dto.UserPostCollect{}).Where("user_id = ? AND post_id = ?", *currentUserID, post.ID).Count(&collectCount)
-		if collectCount > 0 {
-			isCollectedByCurrentUser = true
+		isLikedByCurrentUser = viewer.liked[post.ID]
+		isCollectedByCurrentUser = viewer.collected[post.ID]
+
+		courseCards, err := loadCourseCardsByPostIDs(db, []uint32{post.ID})
+		if err != nil {
+			return nil, err
 		}
+		courses = courseCards[post.ID]
 	}
 
 	return &vo.PostVO{
@@ -255,37 +365,83 @@ func convertPostToVO(post dto.Post, currentUserID *uint32, db *gorm.DB) (*vo.Pos
 		LikesCount:               &likesCount,
 		CommentsCount:            &commentsCount,
 		IsPublished:              &isPublished,
+		PublishAt:                post.PublishAt,
 		IsPinned:                 &isPinned,
+		PinScope:                 post.PinScope,
+		PinnedUntil:              post.PinnedUntil,
 		IsLocked:                 &isLocked,
+		IsFeatured:               post.IsFeatured,
+		ModerationStatus:         post.ModerationStatus,
 		CollectCount:             &collectCount,
 		IsLikedByCurrentUser:     &isLikedByCurrentUser,
 		IsCollectedByCurrentUser: &isCollectedByCurrentUser,
+		Courses:                  courses,
 	}, nil
 }
 
 // --- 新增 CreatePost 方法 ---
-func (s *PostService) CreatePost(postData *dto.CreatePostDTO, authorID uint32) (*vo.PostVO, error) {
-	var tagsJSON datatypes.JSON
-	if len(postData.Tags) > 0 {
-		tagsBytes, err := json.Marshal(postData.Tags)
-		if err != nil {
-			return nil, fmt.Errorf("序列化标签失败: %w", err)
-		}
-		tagsJSON = datatypes.JSON(tagsBytes)
+func (s *PostService) CreatePost(ctx context.Context, postData *dto.CreatePostDTO, authorID uint32) (*vo.PostVO, error) {
+	// 标题与内容命中拦截词时拒绝，命中打码词时打码，命中审核词时保存但只有作者可见
+	check := newContentCheck()
+	title, err := check.filter(postData.Title)
+	if err != nil {
+		return nil, err
+	}
+	content, err := check.filter(postData.Content)
+	if err != nil {
+		return nil, err
 	}
 
 	newPost := dto.Post{
-		Title:    postData.Title,
-		Content:  postData.Content,
-		AuthorID: authorID, // 从认证信息中获取的作者ID
-		Category: postData.Category,
-		Tags:     tagsJSON,
+		Title:            title,
+		Content:          content,
+		AuthorID:         authorID, // 从认证信息中获取的作者ID
+		Category:         postData.Category,
+		SearchTags:       postSearchTags(nil), // 标签在事务中归一化后写入
+		ModerationStatus: check.status(),
 		// IsPublished 默认应为 true (在 GORM 模型中定义)
 	}
 
-	result := database.Client.Create(&newPost)
-	if result.Error != nil {
-		return nil, result.Error
+	// 保存为草稿，或定时到将来某个时间发布
+	var publishAt *time.Time
+	if postData.PublishAt != nil && postData.PublishAt.After(clock.Now(ctx)) {
+		publishAt = postData.PublishAt
+	}
+	draft := postData.IsDraft || publishAt != nil
+
+	// 帖子、标签与关联课程在同一事务中写入
+	if err := database.Client.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newPost).Error; err != nil {
+			return err
+		}
+		if draft {
+			// is_published 列默认值为 true，Create 不会写入零值，草稿需要单独更新
+			if err := tx.Model(&newPost).UpdateColumns(map[string]interface{}{"is_published": false, "publish_at": publishAt}).Error; err != nil {
+				return err
+			}
+		}
+		if check.held() {
+			if err := holdForReview(tx, dto.ContentTargetPost, newPost.ID, authorID, title+"\n"+content, check); err != nil {
+				return err
+			}
+		}
+		if len(postData.Tags) > 0 {
+			if err := applyPostTags(tx, newPost.ID, postData.Tags); err != nil {
+				return err
+			}
+		}
+		if len(postData.CourseIDs) > 0 {
+			if err := replacePostCourses(tx, newPost.ID, postData.CourseIDs); err != nil {
+				return err
+			}
+		}
+		if draft {
+			return nil
+		}
+		// 社区计数在发布时更新，草稿发布时由 publishPost 更新
+		return applyCounterEvent(tx, counterEvent{kind: counterPostPublished, postID: newPost.ID, ownerID: authorID})
+	}); err != nil {
+		return nil, err
 	}
 
 	// 创建成功后，需要重新查询一次以预加载 Author 信息并转换为 VO
@@ -300,11 +456,11 @@ func (s *PostService) CreatePost(postData *dto.CreatePostDTO, authorID uint32) (
 		return nil, fmt.Errorf("获取创建后的帖子信息失败: %w", err)
 	}
 
-	return convertPostToVO(createdPostWithAuthor, &authorID, database.Client)
+	return convertPostToVO(ctx, createdPostWithAuthor, &authorID, database.Client)
 }
 
 // --- 新增 UpdatePost 方法 ---
-func (s *PostService) UpdatePost(postID uint32, userID uint32, postData *dto.UpdatePostDTO) (*vo.PostVO, error) {
+func (s *PostService) UpdatePost(ctx context.Context, postID uint32, userID uint32, postData *dto.UpdatePostDTO) (*vo.PostVO, error) {
 	var existingPost dto.Post
 	// 1. 查找帖子并验证作者
 	if err := database.Client.Where("id = ?", postID).First(&existingPost).Error; err != nil {
@@ -314,72 +470,117 @@ func (s *PostService) UpdatePost(postID uint32, userID uint32, postData *dto.Upd
 		return nil, err
 	}
 
-	// 3. 准备更新的数据
+	// 3. 准备更新的数据，修改后的标题与内容同样经过敏感词检查
 	updates := make(map[string]interface{})
+	check := newContentCheck()
+	title, content := existingPost.Title, existingPost.Content
+	var err error
 	if postData.Title != nil {
-		updates["title"] = *postData.Title
+		if title, err = check.filter(*postData.Title); err != nil {
+			return nil, err
+		}
+		updates["title"] = title
 	}
 	if postData.Content != nil {
-		updates["content"] = *postData.Content
+		if content, err = check.filter(*postData.Content); err != nil {
+			return nil, err
+		}
+		updates["content"] = content
+	}
+	// 命中审核词时重新进入审核；已被隐藏的帖子保持隐藏
+	if check.held() && existingPost.ModerationStatus == dto.ModerationVisible {
+		updates["moderation_status"] = dto.ModerationPending
 	}
 	if postData.Category != nil {
 		updates["category"] = *postData.Category
 	}
-	// 对于 Tags，如果 postData.Tags 非 nil (即使是空数组)，都表示要更新
-	if postData.Tags != nil {
-		tagsBytes, err := json.Marshal(postData.Tags)
-		if err != nil {
-			return nil, fmt.Errorf("序列化标签失败: %w", err)
-		}
-		updates["tags"] = datatypes.JSON(tagsBytes)
-	}
+	// 对于 Tags，如果 postData.Tags 非 nil (即使是空数组)，都表示要更新，在事务中归一化后写入
 	// 如果允许更新 isPublished, isPinned, isLocked 等，也在这里添加
 	// if postData.IsPublished != nil { updates["is_published"] = *postData.IsPublished }
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && postData.Tags == nil && postData.CourseIDs == nil {
 		// 如果没有要更新的字段，可以直接返回当前帖子信息 (需要重新查询以包含用户信息)
-		return s.GetPostByID(postID, &userID)
+		return s.GetPostByID(ctx, postID, &userID)
 	}
 
-	// 4. 执行更新
-	if err := database.Client.Model(&existingPost).Updates(updates).Error; err != nil {
+	// 4. 执行更新（字段、标签与关联课程在同一事务中）
+	if err := database.Client.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&existingPost).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if check.held() {
+			if err := holdForReview(tx, dto.ContentTargetPost, postID, existingPost.AuthorID, title+"\n"+content, check); err != nil {
+				return err
+			}
+		}
+		if postData.Tags != nil {
+			if err := applyPostTags(tx, postID, postData.Tags); err != nil {
+				return err
+			}
+		}
+		if postData.CourseIDs != nil {
+			return replacePostCourses(tx, postID, postData.CourseIDs)
+		}
+		return nil
+	}); err != nil {
 		return nil, err
 	}
 
 	// 5. 返回更新后的帖子详情 (重新查询以获取最新数据和关联数据)
-	return s.GetPostByID(postID, &userID)
+	return s.GetPostByID(ctx, postID, &userID)
 }
 
 // --- 新增 DeletePost 方法 ---
 func (s *PostService) DeletePost(postID uint32, userID uint32, userRole string) error {
+	return database.Client.Transaction(func(tx *gorm.DB) error {
+		return deletePost(tx, postID)
+	})
+}
+
+// deletePost 在 tx 中删除帖子及连带的评论与点赞，并更新标签使用次数和受影响用户的计数；帖子不存在时返回 ErrPostNotFound
+func deletePost(tx *gorm.DB, postID uint32) error {
 	var post dto.Post
-	// 1. 查找帖子并验证作者
-	if err := database.Client.Where("id = ?", postID).First(&post).Error; err != nil {
+	// 1. 查找帖子
+	if err := tx.Where("id = ?", postID).First(&post).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("帖子未找到")
+			return ErrPostNotFound
 		}
 		return err
 	}
 
-	// 3. 执行删除 (硬删除)
-	// 如果需要软删除，GORM 模型中应包含 gorm.DeletedAt，并且这里使用 db.Delete(&post)
-	// 硬删除：
-	if err := database.Client.Select("Comments", "UserPostLikes", "UserPostCollects").Delete(&post).Error; err != nil {
+	// 2. 帖子作者、评论作者以及点赞过帖子或其评论的用户，删除后需要重新统计计数
+	var affectedUsers []uint32
+	if err := tx.Raw(`SELECT ? UNION SELECT author_id FROM comments WHERE post_id = ?
+		UNION SELECT user_id FROM user_post_likes WHERE post_id = ?
+		UNION SELECT user_comment_likes.user_id FROM user_comment_likes
+			JOIN comments ON comments.id = user_comment_likes.comment_id WHERE comments.post_id = ?`,
+		post.AuthorID, postID, postID, postID).Scan(&affectedUsers).Error; err != nil {
+		return fmt.Errorf("查询帖子关联用户失败: %w", err)
+	}
+
+	if err := tx.Select("Comments", "UserPostLikes", "UserPostCollects").Delete(&post).Error; err != nil {
 		// GORM 的 Delete 如果设置了 Select，会尝试删除关联数据（如果关联已定义且支持级联或通过回调处理）
 		// 如果没有 Select 或者关联未正确设置，可能需要手动删除关联表中的记录
 		// 或者依赖数据库的级联删除约束 (ON DELETE CASCADE)
-		// 为了确保，可以显式删除关联：
-		// database.Client.Where("post_id = ?", postID).Delete(&dto.UserPostLike{})
-		// database.Client.Where("post_id = ?", postID).Delete(&dto.UserPostCollect{})
-		// database.Client.Where("post_id = ?", postID).Delete(&dto.Comment{}) // 假设 Comment 有 PostID
 		return err
 	}
-	// 对于硬删除，如果上面 Select 方式不工作，直接删除帖子即可，依赖数据库外键约束
-	// if err := database.Client.Delete(&dto.Post{}, postID).Error; err != nil {
-	// return err
-	// }
 
-	return nil
+	// 已删除的帖子不再出现在课程讨论区
+	if err := tx.Where("post_id = ?", postID).Delete(&dto.PostCourse{}).Error; err != nil {
+		return fmt.Errorf("删除帖子关联的课程失败: %w", err)
+	}
+
+	// 已删除的帖子不再计入标签使用次数
+	tagIDs, err := postTagIDs(tx, postID)
+	if err != nil {
+		return err
+	}
+	if err := recountTagUsage(tx, tagIDs); err != nil {
+		return err
+	}
+	return applyCounterEvent(tx, counterEvent{kind: counterPostDeleted, postID: postID, ownerID: post.AuthorID, users: affectedUsers})
 }
 
 // --- ToggleLikePost 方法 ---
@@ -392,6 +593,9 @@ func (s *PostService) ToggleLikePost(postID uint32, userID uint32) (*vo.ToggleLi
 		}
 		return nil, err // Other database error
 	}
+	if !post.IsPublished || post.ModerationStatus != dto.ModerationVisible { // 草稿、待审核和已隐藏的帖子不能点赞、收藏
+		return nil, errors.New("帖子未找到")
+	}
 
 	like := dto.UserPostLike{UserID: userID, PostID: postID}
 	var currentLikesCount int64 // To store the final likes count
@@ -417,12 +621,8 @@ func (s *PostService) ToggleLikePost(postID uint32, userID uint32) (*vo.ToggleLi
 			tx.Rollback()
 			return nil, err
 		}
-		// Decrement likes_count on the post
-		if err := tx.Model(&dto.Post{}).Where("id = ?", postID).UpdateColumn("likes_count", gorm.Expr("likes_count - 1")).Error; err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-		if err := tx.Model(&dto.Post{}).Where("id = ?", postID).UpdateColumn("is_liked_by_current_user", gorm.Expr("false")).Error; err != nil {
+		// Decrement likes_count on the post and the related user counters
+		if err := applyCounterEvent(tx, counterEvent{kind: counterPostUnliked, postID: postID, actorID: userID, ownerID: post.AuthorID}); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
@@ -432,12 +632,8 @@ func (s *PostService) ToggleLikePost(postID uint32, userID uint32) (*vo.ToggleLi
 			tx.Rollback()
 			return nil, err
 		}
-		// Increment likes_count on the post
-		if err := tx.Model(&dto.Post{}).Where("id = ?", postID).UpdateColumn("likes_count", gorm.Expr("likes_count + 1")).Error; err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-		if err := tx.Model(&dto.Post{}).Where("id = ?", postID).UpdateColumn("is_liked_by_current_user", gorm.Expr("true")).Error; err != nil {
+		// Increment likes_count on the post and the related user counters
+		if err := applyCounterEvent(tx, counterEvent{kind: counterPostLiked, postID: postID, actorID: userID, ownerID: post.AuthorID}); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
@@ -451,6 +647,17 @@ func (s *PostService) ToggleLikePost(postID uint32, userID uint32) (*vo.ToggleLi
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
+	if isLiked {
+		notifyAsync(notificationEvent{
+			userID:     post.AuthorID,
+			kind:       dto.NotificationLike,
+			targetType: dto.ContentTargetPost,
+			targetID:   postID,
+			postID:     postID,
+			actorID:    userID,
+			excerpt:    post.Title,
+		})
+	}
 
 	// Get the latest likes_count from the Post table after the transaction
 	var updatedPostForLikeCount dto.Post
@@ -480,6 +687,9 @@ func (s *PostService) ToggleCollectPost(postID uint32, userID uint32) (*vo.Toggl
 		}
 		return nil, err
 	}
+	if !post.IsPublished || post.ModerationStatus != dto.ModerationVisible { // 草稿、待审核和已隐藏的帖子不能点赞、收藏
+		return nil, errors.New("帖子未找到")
+	}
 
 	collect := dto.UserPostCollect{UserID: userID, PostID: postID}
 	var currentCollectCount int64
@@ -505,11 +715,7 @@ func (s *PostService) ToggleCollectPost(postID uint32, userID uint32) (*vo.Toggl
 			return nil, err
 		}
 		// Update collect_count on the Post table
-		if err := tx.Model(&dto.Post{}).Where("id = ?", postID).UpdateColumn("collect_count", gorm.Expr("collect_count - 1")).Error; err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-		if err := tx.Model(&dto.Post{}).Where("id = ?", postID).UpdateColumn("is_collected_by_current_user", gorm.Expr("false")).Error; err != nil {
+		if err := applyCounterEvent(tx, counterEvent{kind: counterPostUncollected, postID: postID, actorID: userID, ownerID: post.AuthorID}); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
@@ -520,11 +726,7 @@ func (s *PostService) ToggleCollectPost(postID uint32, userID uint32) (*vo.Toggl
 			return nil, err
 		}
 		// Update collect_count on the Post table
-		if err := tx.Model(&dto.Post{}).Where("id = ?", postID).UpdateColumn("collect_count", gorm.Expr("collect_count + 1")).Error; err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-		if err := tx.Model(&dto.Post{}).Where("id = ?", postID).UpdateColumn("is_collected_by_current_user", gorm.Expr("true")).Error; err != nil {
+		if err := applyCounterEvent(tx, counterEvent{kind: counterPostCollected, postID: postID, actorID: userID, ownerID: post.AuthorID}); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
@@ -537,6 +739,17 @@ func (s *PostService) ToggleCollectPost(postID uint32, userID uint32) (*vo.Toggl
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
+	if isCollected {
+		notifyAsync(notificationEvent{
+			userID:     post.AuthorID,
+			kind:       dto.NotificationCollect,
+			targetType: dto.ContentTargetPost,
+			targetID:   postID,
+			postID:     postID,
+			actorID:    userID,
+			excerpt:    post.Title,
+		})
+	}
 
 	// Get the latest collect_count from the Post table after the transaction
 	var updatedPostForCollectCount dto.Post
@@ -553,7 +766,8 @@ func (s *PostService) ToggleCollectPost(postID uint32, userID uint32) (*vo.Toggl
 }
 
 // GetActiveUsers 获取最近 N 天中发帖最多的用户（默认3天），返回用户基本信息与帖子数
-func (s *PostService) GetActiveUsers(days int, limit int) ([]vo.ActiveUserVO, error) {
+// "最近 N 天"以 ctx 中的时钟为准
+func (s *PostService) GetActiveUsers(ctx context.Context, days int, limit int) ([]vo.ActiveUserVO, error) {
 	if days <= 0 {
 		days = 3
 	}
@@ -561,13 +775,14 @@ func (s *PostService) GetActiveUsers(days int, limit int) ([]vo.ActiveUserVO, er
 		limit = 10
 	}
 
-	// raw SQL: select author_id, count(*) as cnt from posts where created_at >= NOW() - INTERVAL ? DAY group by author_id order by cnt desc limit ?
+	// raw SQL: select author_id, count(*) as cnt from posts where created_at >= ? group by author_id order by cnt desc limit ?
 	type row struct {
 		AuthorID uint32 `gorm:"column:author_id"`
 		Cnt      int64  `gorm:"column:cnt"`
 	}
+	since := clock.Now(ctx).AddDate(0, 0, -days)
 	var rows []row
-	if err := database.Client.Raw("SELECT author_id, COUNT(*) as cnt FROM posts WHERE created_at >= DATE_SUB(NOW(), INTERVAL ? DAY) GROUP BY author_id ORDER BY cnt DESC LIMIT ?", days, limit).Scan(&rows).Error; err != nil {
+	if err := database.Client.WithContext(ctx).Raw("SELECT author_id, COUNT(*) as cnt FROM posts WHERE created_at >= ? AND is_published = TRUE AND deleted_at IS NULL GROUP BY author_id ORDER BY cnt DESC LIMIT ?", since, limit).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
 
@@ -610,12 +825,14 @@ func (s *PostService) GetActiveUsers(days int, limit int) ([]vo.ActiveUserVO, er
 }
 
 // GetCommunityStats 返回社区统计：总帖子、注册用户数、今日新帖
-func (s *PostService) GetCommunityStats() (vo.CommunityStatsVO, error) {
+// "今日"以 ctx 中的时钟为准，而不是数据库的 NOW()
+func (s *PostService) GetCommunityStats(ctx context.Context) (vo.CommunityStatsVO, error) {
 	var stats vo.CommunityStatsVO
 
 	// total posts
 	var totalPosts int64
-	if err := database.Client.Model(&dto.Post{}).Count(&totalPosts).Error; err != nil {
+	// 草稿不计入，定时帖子发布时 created_at 更新为发布时间，计入发布当天的新帖
+	if err := database.Client.Model(&dto.Post{}).Where("is_published = ?", true).Count(&totalPosts).Error; err != nil {
 		return stats, err
 	}
 
@@ -626,12 +843,11 @@ func (s *PostService) GetCommunityStats() (vo.CommunityStatsVO, error) {
 	}
 
 	// today new posts: created_at >= today's midnight
+	now := clock.Now(ctx)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	var todayNew int64
-	if err := database.Client.Raw("SELECT COUNT(*) as cnt FROM posts WHERE created_at >= DATE_FORMAT(NOW(), '%Y-%m-%d 00:00:00')").Scan(&todayNew).Error; err != nil {
-		// fallback to using GORM count with condition
-		if err2 := database.Client.Model(&dto.Post{}).Where("created_at >= DATE_FORMAT(NOW(), '%Y-%m-%d 00:00:00')").Count(&todayNew).Error; err2 != nil {
-			return stats, err2
-		}
+	if err := database.Client.Model(&dto.Post{}).Where("is_published = ? AND created_at >= ? AND created_at <= ?", true, midnight, now).Count(&todayNew).Error; err != nil {
+		return stats, err
 	}
 
 	stats.TotalPosts = totalPosts
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultRecommendationTopN 未配置时每个用户保留的推荐条数
+const defaultRecommendationTopN = 50
+
+// rebuildingUsers 正在后台计算推荐的用户，避免重复触发
+var rebuildingUsers sync.Map
+
+// RecommendationService 课程推荐服务：离线计算并按用户存储，接口只读结果
+type RecommendationService struct{}
+
+// NewRecommendationService 创建 RecommendationService 实例
+func NewRecommendationService() *RecommendationService {
+	return &RecommendationService{}
+}
+
+// recCandidate 参与推荐的课程及其时间段
+type recCandidate struct {
+	dto.CourseInfo
+	Slots []dto.TimeInfo `gorm:"-"`
+}
+
+// recCandidateSet 一次计算中所有用户共享的候选课程与老师评分
+type recCandidateSet struct {
+	courses       []recCandidate
+	teacherRating map[string]float64 // 老师所有课程按评价数加权的平均分
+	teacherCount  map[string]uint32  // 老师所有课程的评价总数
+}
+
+// interestProfile 根据收藏、评价、打卡推断的用户兴趣与课表
+type interestProfile struct {
+	faculties     map[string]float64
+	courseTypes   map[string]float64
+	teachers      map[string]float64
+	ratedTeachers map[string]bool   // 用户给过好评（4 分及以上）的老师，只有这些老师才解释为“好评过”
+	facultySource map[string]string // 学院 -> 用于解释的代表课程名
+	excluded      map[string]bool   // 已经收藏/评价/打卡过的课程编号
+	ownFaculties  map[string]bool   // 用户资料中学院的原始写法
+	ownMajors     map[string]bool   // 用户资料中专业的原始写法（学院|专业）
+	busy          busySlots
+	hasSignals    bool
+}
+
+// GetRecommendations 读取用户已计算好的推荐；尚未计算时在后台触发一次计算
+func (s *RecommendationService) GetRecommendations(ctx context.Context, userID uint32, limit int) (*vo.CourseRecommendationListVO, error) {
+	var recs []dto.UserCourseRecommendation
+	if err := database.Client.WithContext(ctx).
+		Preload("Course").
+		Where("user_id = ?", userID).
+		Order("`rank` ASC").
+		Limit(limit).
+		Find(&recs).Error; err != nil {
+		return nil, fmt.Errorf("获取推荐结果失败: %w", err)
+	}
+
+	result := &vo.CourseRecommendationListVO{Items: make([]vo.RecommendedCourseVO, 0, len(recs))}
+	if len(recs) == 0 {
+		s.rebuildForUserAsync(userID)
+		result.Pending = true
+		return result, nil
+	}
+
+	result.ComputedAt = &recs[0].ComputedAt
+	for _, r := range recs {
+		// 计算之后课程被删除或老师改为不欢迎旁听的，读取时跳过
+		if r.Course.ID == 0 || r.Course.AuditPolicy == dto.AuditPolicyClosed {
+			continue
+		}
+		var reasons []string
+		if err := json.Unmarshal(r.Reasons, &reasons); err != nil {
+			reasons = []string{}
+		}
+		result.Items = append(result.Items, vo.RecommendedCourseVO{
+			Course:  toCourseCardVO(r.Course),
+			Score:   r.Score,
+			Reasons: reasons,
+		})
+	}
+	return result, nil
+}
+
+// rebuildForUserAsync 在后台为单个用户计算推荐
+func (s *RecommendationService) rebuildForUserAsync(userID uint32) {
+	if _, loaded := rebuildingUsers.LoadOrStore(userID, true); loaded {
+		return
+	}
+	go func() {
+		defer rebuildingUsers.Delete(userID)
+		if err := s.RebuildForUser(context.Background(), userID); err != nil {
+			log.Printf("Service: 为用户 %d 计算推荐失败: %v", userID, err)
+		}
+	}()
+}
+
+// RebuildForUser 重新计算单个用户的推荐
+func (s *RecommendationService) RebuildForUser(ctx context.Context, userID uint32) error {
+	db := database.Client.WithContext(ctx)
+	candidates, err := loadRecCandidates(db)
+	if err != nil {
+		return err
+	}
+	return rebuildUserRecommendations(db, candidates, userID, clock.Now(ctx))
+}
+
+// RebuildAll 重新计算所有用户的推荐，返回处理的用户数。候选课程只加载一次。
+func (s *RecommendationService) RebuildAll(ctx context.Context) (int, error) {
+	db := database.Client.WithContext(ctx)
+	candidates, err := loadRecCandidates(db)
+	if err != nil {
+		return 0, err
+	}
+
+	var userIDs []uint32
+	if err := db.Model(&dto.User{}).Pluck("id", &userIDs).Error; err != nil {
+		return 0, fmt.Errorf("查询用户列表失败: %w", err)
+	}
+
+	now := clock.Now(ctx)
+	processed := 0
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+		if err := rebuildUserRecommendations(db, candidates, userID, now); err != nil {
+			log.Printf("Service: 为用户 %d 计算推荐失败: %v", userID, err)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// loadRecCandidates 加载所有未拒绝旁听的课程及其时间段，并统计老师评分
+func loadRecCandidates(db *gorm.DB) (*recCandidateSet, error) {
+	var courses []recCandidate
+	if err := db.Model(&dto.CourseInfo{}).
+		Where("audit_policy <> ?", dto.AuditPolicyClosed).
+		Find(&courses).Error; err != nil {
+		return nil, fmt.Errorf("加载候选课程失败: %w", err)
+	}
+
+	var slots []dto.TimeInfo
+	if err := db.Select("course_info_id", "day_of_week", "week_and_time").Find(&slots).Error; err != nil {
+		return nil, fmt.Errorf("加载课程时间段失败: %w", err)
+	}
+	slotsByCourse := make(map[uint32][]dto.TimeInfo)
+	for _, slot := range slots {
+		slotsByCourse[slot.CourseInfoId] = append(slotsByCourse[slot.CourseInfoId], slot)
+	}
+
+	set := &recCandidateSet{
+		courses:       courses,
+		teacherRating: make(map[string]float64),
+		teacherCount:  make(map[string]uint32),
+	}
+	ratingSum := make(map[string]float64)
+	for i := range set.courses {
+		c := &set.courses[i]
+		c.Slots = slotsByCourse[c.ID]
+		ratingSum[c.Teacher] += float64(c.AverageRating) * float64(c.ReviewCount)
+		set.teacherCount[c.Teacher] += c.ReviewCount
+	}
+	for teacher, count := range set.teacherCount {
+		if count > 0 {
+			set.teacherRating[teacher] = ratingSum[teacher] / float64(count)
+		}
+	}
+	return set, nil
+}
+
+// buildInterestProfile 汇总用户的收藏、评价与打卡记录
+func buildInterestProfile(db *gorm.DB, userID uint32) (*interestProfile, error) {
+	p := &interestProfile{
+		faculties:     make(map[string]float64),
+		courseTypes:   make(map[string]float64),
+		teachers:      make(map[string]float64),
+		ratedTeachers: make(map[string]bool),
+		facultySource: make(map[string]string),
+		excluded:      make(map[string]bool),
+		ownFaculties:  make(map[string]bool),
+		ownMajors:     make(map[string]bool),
+	}
+
+	add := func(c dto.CourseInfo, weight float64) {
+		p.excluded[c.CourseNum] = true
+		p.hasSignals = true
+		p.teachers[c.Teacher] += weight
+		if weight <= 0 {
+			return
+		}
+		p.faculties[c.Faculty] += weight
+		p.courseTypes[c.CourseType] += weight
+		if _, ok := p.facultySource[c.Faculty]; !ok {
+			p.facultySource[c.Faculty] = c.CourseName
+		}
+	}
+
+	// 收藏：最强的兴趣信号，同时构成个人课表
+	var favorites []dto.CourseInfo
+	if err := db.Model(&dto.CourseInfo{}).
+		Joins("JOIN user_course_favorites f ON f.course_id = course_infos.id").
+		Where("f.user_id = ?", userID).
+		Find(&favorites).Error; err != nil {
+		return nil, fmt.Errorf("查询收藏课程失败: %w", err)
+	}
+	for _, c := range favorites {
+		add(c, 3)
+	}
+
+	// 评价：好评加分，差评对老师减分
+	type reviewed struct {
+		dto.CourseInfo
+		Rating int
+	}
+	var reviews []reviewed
+	if err := db.Model(&dto.CourseInfo{}).
+		Select("course_infos.*, r.rating").
+		Joins("JOIN course_reviews r ON r.course_id = course_infos.id").
+		Where("r.user_id = ?", userID).
+		Scan(&reviews).Error; err != nil {
+		return nil, fmt.Errorf("查询评价课程失败: %w", err)
+	}
+	for _, r := range reviews {
+		switch {
+		case r.Rating >= 4:
+			add(r.CourseInfo, float64(r.Rating-2))
+			p.ratedTeachers[r.Teacher] = true
+		case r.Rating == 3:
+			add(r.CourseInfo, 0.5)
+		default:
+			add(r.CourseInfo, -2)
+		}
+	}
+
+	// 打卡：去过的课说明对该方向有兴趣
+	var checkedIn []dto.CourseInfo
+	if err := db.Model(&dto.CourseInfo{}).
+		Where("id IN (?)", db.Model(&dto.CourseCheckIn{}).Select("DISTINCT course_id").Where("user_id = ?", userID)).
+		Find(&checkedIn).Error; err != nil {
+		return nil, fmt.Errorf("查询打卡课程失败: %w", err)
+	}
+	for _, c := range checkedIn {
+		add(c, 1)
+	}
+
+	// 用户资料中的学院与专业
+	var user dto.User
+	if err := db.Select("id", "faculty_id", "major_id").First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("查询用户资料失败: %w", err)
+	}
+	if user.FacultyID != nil {
+		raws, err := catalogRawFaculties(db, *user.FacultyID)
+		if err != nil {
+			return nil, err
+		}
+		for _, raw := range raws {
+			p.faculties[raw] += 2
+			p.ownFaculties[raw] = true
+		}
+	}
+	if user.MajorID != nil {
+		var aliases []dto.CatalogAlias
+		if err := db.Where("kind = ? AND target_id = ?", dto.CatalogKindMajor, *user.MajorID).Find(&aliases).Error; err != nil {
+			return nil, fmt.Errorf("查询专业别名失败: %w", err)
+		}
+		for _, a := range aliases {
+			p.ownMajors[majorAliasKey(a.RawFaculty, a.RawValue)] = true
+		}
+	}
+
+	busy, err := loadUserBusySlots(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	p.busy = busy
+	return p, nil
+}
+
+// scoredCourse 单门课程的推荐分与理由
+type scoredCourse struct {
+	course  *recCandidate
+	score   float64
+	reasons []string
+}
+
+// scoreCandidate 计算推荐分：兴趣匹配(60) + 老师口碑(25) + 蹭课友好度(15) + 热度(10)
+func scoreCandidate(p *interestProfile, set *recCandidateSet, c *recCandidate) scoredCourse {
+	sc := scoredCourse{course: c, reasons: make([]string, 0, 4)}
+
+	if w := p.faculties[c.Faculty]; w > 0 {
+		sc.score += 30 * w / maxWeight(p.faculties)
+		if source, ok := p.facultySource[c.Faculty]; ok {
+			sc.reasons = append(sc.reasons, fmt.Sprintf("与你关注的「%s」同属%s", source, c.Faculty))
+		} else if p.ownFaculties[c.Faculty] {
+			sc.reasons = append(sc.reasons, fmt.Sprintf("属于你所在的%s", c.Faculty))
+		}
+	}
+	if p.ownMajors[majorAliasKey(c.Faculty, c.Major)] {
+		sc.score += 10
+		sc.reasons = append(sc.reasons, fmt.Sprintf("是你的专业（%s）开设的课程", c.Major))
+	}
+	if w := p.courseTypes[c.CourseType]; w > 0 && c.CourseType != "" {
+		sc.score += 10 * w / maxWeight(p.courseTypes)
+	}
+	switch w := p.teachers[c.Teacher]; {
+	case w > 0:
+		sc.score += 10 * min(1, w/3)
+		if p.ratedTeachers[c.Teacher] {
+			sc.reasons = append(sc.reasons, fmt.Sprintf("你好评过%s老师的课", c.Teacher))
+		}
+	case w < 0:
+		sc.score -= 20
+	}
+
+	if count := set.teacherCount[c.Teacher]; count > 0 {
+		rating := set.teacherRating[c.Teacher]
+		sc.score += 25 * rating / 5 * min(1, float64(count)/5)
+		if rating >= 4 && count >= 3 {
+			sc.reasons = append(sc.reasons, fmt.Sprintf("%s老师评分 %.1f（%d 条评价）", c.Teacher, rating, count))
+		}
+	}
+
+	sc.score += 15 * float64(c.AuditorScore) / 100
+	if c.AuditPolicy == dto.AuditPolicyWelcome {
+		sc.reasons = append(sc.reasons, "老师欢迎旁听")
+	} else if c.AuditorScore >= 70 {
+		sc.reasons = append(sc.reasons, fmt.Sprintf("蹭课友好度 %.0f", c.AuditorScore))
+	}
+
+	sc.score += 10 * min(1, float64(c.FavoriteCount)/20)
+	if !p.hasSignals && c.FavoriteCount > 0 {
+		sc.reasons = append(sc.reasons, fmt.Sprintf("已有 %d 位同学收藏", c.FavoriteCount))
+	}
+
+	if len(p.busy) > 0 && p.busy.fits(c.Slots) {
+		sc.reasons = append(sc.reasons, "与你收藏课程的时间不冲突")
+	}
+	return sc
+}
+
+// maxWeight 返回权重表中的最大值（至少为 1，避免除零）
+func maxWeight(weights map[string]float64) float64 {
+	m := 1.0
+	for _, w := range weights {
+		m = max(m, w)
+	}
+	return m
+}
+
+// rebuildUserRecommendations 为单个用户打分排序，并替换其已存储的推荐
+func rebuildUserRecommendations(db *gorm.DB, set *recCandidateSet, userID uint32, now time.Time) error {
+	profile, err := buildInterestProfile(db, userID)
+	if err != nil {
+		return err
+	}
+
+	// 同一课程编号只保留得分最高的教学班
+	best := make(map[string]scoredCourse)
+	for i := range set.courses {
+		c := &set.courses[i]
+		if len(c.Slots) == 0 || profile.excluded[c.CourseNum] || !profile.busy.fits(c.Slots) {
+			continue
+		}
+		sc := scoreCandidate(profile, set, c)
+		if sc.score <= 0 {
+			continue
+		}
+		if prev, ok := best[c.CourseNum]; !ok || sc.score > prev.score {
+			best[c.CourseNum] = sc
+		}
+	}
+
+	ranked := make([]scoredCourse, 0, len(best))
+	for _, sc := range best {
+		ranked = append(ranked, sc)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].course.ID < ranked[j].course.ID
+	})
+
+	topN := config.Conf.Recommendation.TopN
+	if topN <= 0 {
+		topN = defaultRecommendationTopN
+	}
+	if len(ranked) > topN {
+		ranked = ranked[:topN]
+	}
+
+	rows := make([]dto.UserCourseRecommendation, 0, len(ranked))
+	for i, sc := range ranked {
+		reasons, _ := json.Marshal(sc.reasons)
+		rows = append(rows, dto.UserCourseRecommendation{
+			UserID:     userID,
+			CourseID:   sc.course.ID,
+			Rank:       i + 1,
+			Score:      float32(sc.score),
+			Reasons:    reasons,
+			ComputedAt: now,
+		})
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&dto.UserCourseRecommendation{}).Error; err != nil {
+			return fmt.Errorf("清理旧推荐失败: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(rows, 100).Error; err != nil {
+			return fmt.Errorf("保存推荐结果失败: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReportService struct{}
+
+func NewReportService() *ReportService {
+	return &ReportService{}
+}
+
+// reportTargetLabels 通知中被举报对象的称呼
+var reportTargetLabels = map[string]string{
+	dto.ContentTargetPost:    "帖子",
+	dto.ContentTargetComment: "评论",
+	dto.ContentTargetReview:  "课程评价",
+	dto.ContentTargetUser:    "用户",
+}
+
+// reportActionLabels 通知中处理方式的描述
+var reportActionLabels = map[string]string{
+	dto.ReportActionDismiss: "经核实未发现违规，举报未被采纳",
+	dto.ReportActionHide:    "相关内容已被隐藏",
+	dto.ReportActionDelete:  "相关内容已被删除",
+	dto.ReportActionWarn:    "已对相关用户作出警告",
+	dto.ReportActionBan:     "已对相关用户作出封禁",
+}
+
+// reportTarget 被举报对象的作者与内容摘要
+type reportTarget struct {
+	authorID uint32
+	excerpt  string
+}
+
+// loadReportTarget 查询被举报对象，已删除的内容和草稿视为不存在
+func loadReportTarget(db *gorm.DB, targetType string, targetID uint32) (*reportTarget, error) {
+	var target reportTarget
+	var err error
+	switch targetType {
+	case dto.ContentTargetPost:
+		var post dto.Post
+		err = db.Select("id", "author_id", "title", "is_published").First(&post, targetID).Error
+		if err == nil && !post.IsPublished {
+			err = gorm.ErrRecordNotFound
+		}
+		target = reportTarget{authorID: post.AuthorID, excerpt: post.Title}
+	case dto.ContentTargetComment:
+		var comment dto.Comment
+		err = db.Select("id", "author_id", "content").First(&comment, targetID).Error
+		target = reportTarget{authorID: comment.AuthorID, excerpt: comment.Content}
+	case dto.ContentTargetReview:
+		var review dto.CourseReviewModel
+		err = db.Select("id", "user_id", "comment").First(&review, targetID).Error
+		target = reportTarget{authorID: review.UserID, excerpt: review.Comment}
+	case dto.ContentTargetUser:
+		var user dto.User
+		err = db.Select("id", "username").First(&user, targetID).Error
+		target = reportTarget{authorID: user.Id, excerpt: user.Username}
+	default:
+		return nil, errors.New(config.MsgReportTargetNotFound)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgReportTargetNotFound)
+		}
+		return nil, fmt.Errorf("查询被举报内容失败: %w", err)
+	}
+	target.excerpt = truncateRunes(target.excerpt, 200)
+	return &target, nil
+}
+
+func reportOpenKey(targetType string, targetID uint32) string {
+	return fmt.Sprintf("%s:%d", targetType, targetID)
+}
+
+// Report 提交举报。同一对象的举报汇总到同一张未处理的举报单，同一用户重复举报只更新理由；
+// 举报人数达到阈值的内容自动隐藏，直到版主处理
+func (s *ReportService) Report(ctx context.Context, reporterID uint32, payload dto.CreateReportDTO) (*vo.ReportVO, error) {
+	var report dto.ContentReport
+	err := database.Client.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		target, err := loadReportTarget(tx, payload.TargetType, payload.TargetID)
+		if err != nil {
+			return err
+		}
+		if target.authorID == reporterID {
+			return errors.New(config.MsgReportSelf)
+		}
+
+		// 开单与加锁：并发举报同一对象时由 open_key 唯一索引保证只有一张未处理的举报单
+		openKey := reportOpenKey(payload.TargetType, payload.TargetID)
+		reportCase := dto.ReportCase{
+			TargetType:     payload.TargetType,
+			TargetID:       payload.TargetID,
+			TargetAuthorID: target.authorID,
+			OpenKey:        &openKey,
+			Status:         dto.ReportCaseOpen,
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&reportCase).Error; err != nil {
+			return fmt.Errorf("创建举报单失败: %w", err)
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("open_key = ?", openKey).First(&reportCase).Error; err != nil {
+			return fmt.Errorf("查询举报单失败: %w", err)
+		}
+
+		err = tx.Where("case_id = ? AND reporter_id = ?", reportCase.ID, reporterID).First(&report).Error
+		switch {
+		case err == nil:
+			if err := tx.Model(&report).Updates(map[string]interface{}{"reason": payload.Reason, "detail": payload.Detail}).Error; err != nil {
+				return fmt.Errorf("更新举报失败: %w", err)
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			report = dto.ContentReport{CaseID: reportCase.ID, ReporterID: reporterID, Reason: payload.Reason, Detail: payload.Detail}
+			if err := tx.Create(&report).Error; err != nil {
+				return fmt.Errorf("保存举报失败: %w", err)
+			}
+		default:
+			return fmt.Errorf("查询举报失败: %w", err)
+		}
+
+		// 举报人数与严重程度从举报明细重新统计，重复举报修改理由时同样准确
+		var reasons []string
+		if err := tx.Model(&dto.ContentReport{}).Where("case_id = ?", reportCase.ID).Pluck("reason", &reasons).Error; err != nil {
+			return fmt.Errorf("统计举报失败: %w", err)
+		}
+		updates := map[string]interface{}{"report_count": len(reasons), "severity": maxReportSeverity(reasons)}
+
+		threshold := config.Conf.Reports.AutoHideThreshold
+		if _, ok := moderationTables[payload.TargetType]; ok && threshold > 0 && len(reasons) >= threshold && !reportCase.AutoHidden {
+			hidden, err := setModerationStatus(tx, payload.TargetType, payload.TargetID, dto.ModerationReported, dto.ModerationVisible)
+			if err != nil {
+				return fmt.Errorf("自动隐藏被举报内容失败: %w", err)
+			}
+			if hidden {
+				updates["auto_hidden"] = true
+			}
+		}
+		return tx.Model(&reportCase).UpdateColumns(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &vo.ReportVO{
+		ID:         report.ID,
+		TargetType: payload.TargetType,
+		TargetID:   payload.TargetID,
+		Reason:     payload.Reason,
+		CreatedAt:  report.CreatedAt,
+		UpdatedAt:  report.UpdatedAt,
+	}, nil
+}
+
+func maxReportSeverity(reasons []string) uint8 {
+	var severity uint8
+	for _, reason := range reasons {
+		if s := dto.ReportReasonSeverity[reason]; s > severity {
+			severity = s
+		}
+	}
+	return severity
+}
+
+// List 举报处理队列：未处理的按严重程度、举报人数从高到低排列，已处理的按处理时间倒序
+func (s *ReportService) List(ctx context.Context, params dto.GetReportCasesParamsDTO) (*vo.ReportCaseListVO, error) {
+	db := database.Client.WithContext(ctx)
+	query := db.Model(&dto.ReportCase{}).Where("status = ?", params.Status)
+	if params.TargetType != "" {
+		query = query.Where("target_type = ?", params.TargetType)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("统计举报单失败: %w", err)
+	}
+	order := "severity DESC, report_count DESC, created_at ASC, id ASC"
+	if params.Status == dto.ReportCaseResolved {
+		order = "resolved_at DESC, id DESC"
+	}
+	var cases []dto.ReportCase
+	if err := query.Order(order).Offset((params.Page - 1) * params.Limit).Limit(params.Limit).Find(&cases).Error; err != nil {
+		return nil, fmt.Errorf("查询举报单失败: %w", err)
+	}
+
+	caseIDs := make([]uint32, 0, len(cases))
+	authorIDs := make([]uint32, 0, len(cases))
+	for _, c := range cases {
+		caseIDs = append(caseIDs, c.ID)
+		authorIDs = append(authorIDs, c.TargetAuthorID)
+	}
+
+	reasons := make(map[uint32]map[string]int, len(cases))
+	authors := make(map[uint32]vo.AuthorInfoVO, len(authorIDs))
+	if len(cases) > 0 {
+		var rows []struct {
+			CaseID uint32
+			Reason string
+			Count  int
+		}
+		if err := db.Model(&dto.ContentReport{}).
+			Select("case_id, reason, COUNT(*) AS count").
+			Where("case_id IN ?", caseIDs).
+			Group("case_id, reason").
+			Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("统计举报理由失败: %w", err)
+		}
+		for _, row := range rows {
+			if reasons[row.CaseID] == nil {
+				reasons[row.CaseID] = make(map[string]int)
+			}
+			reasons[row.CaseID][row.Reason] = row.Count
+		}
+
+		var users []dto.User
+		if err := db.Select("id", "username", "avatar").Where("id IN ?", authorIDs).Find(&users).Error; err != nil {
+			return nil, fmt.Errorf("查询被举报用户失败: %w", err)
+		}
+		for _, u := range users {
+			authors[u.Id] = vo.AuthorInfoVO{ID: u.Id, Username: u.Username, Avatar: u.Avatar}
+		}
+	}
+
+	items := make([]vo.ReportCaseVO, 0, len(cases))
+	for _, c := range cases {
+		item := vo.ReportCaseVO{
+			ID:             c.ID,
+			TargetType:     c.TargetType,
+			TargetID:       c.TargetID,
+			Severity:       c.Severity,
+			ReportCount:    c.ReportCount,
+			Reasons:        reasons[c.ID],
+			AutoHidden:     c.AutoHidden,
+			Status:         c.Status,
+			Action:         c.Action,
+			ResolutionNote: c.ResolutionNote,
+			ResolverID:     c.ResolverID,
+			ResolvedAt:     c.ResolvedAt,
+			CreatedAt:      c.CreatedAt,
+			UpdatedAt:      c.UpdatedAt,
+		}
+		if author, ok := authors[c.TargetAuthorID]; ok {
+			item.TargetAuthor = &author
+		}
+		// 内容可能已被删除，此时没有摘要
+		if target, err := loadReportTarget(db, c.TargetType, c.TargetID); err == nil {
+			item.Excerpt = target.excerpt
+		}
+		items = append(items, item)
+	}
+	return &vo.ReportCaseListVO{Items: items, Total: total, CurrentPage: params.Page, PageSize: params.Limit}, nil
+}
+
+// Resolve 处理举报单并记录处理结果，完成后通知所有举报人和被处理内容的作者。
+// 除驳回外，因举报自动隐藏的内容保持隐藏
+func (s *ReportService) Resolve(ctx context.Context, caseID, moderatorID uint32, payload dto.ResolveReportDTO) error {
+	db := database.Client.WithContext(ctx)
+	var reportCase dto.ReportCase
+	if err := db.First(&reportCase, caseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(config.MsgReportCaseNotFound)
+		}
+		return err
+	}
+	if reportCase.Status != dto.ReportCaseOpen {
+		return errors.New(config.MsgReportCaseResolved)
+	}
+	_, isContent := moderationTables[reportCase.TargetType]
+	if !isContent && (payload.Action == dto.ReportActionHide || payload.Action == dto.ReportActionDelete) {
+		return errors.New(config.MsgReportActionInvalid)
+	}
+
+	now := clock.Now(ctx)
+	var banUntil time.Time
+	var reporterIDs []uint32
+	err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&dto.ReportCase{}).
+			Where("id = ? AND status = ?", caseID, dto.ReportCaseOpen).
+			Updates(map[string]interface{}{
+				"status":          dto.ReportCaseResolved,
+				"open_key":        nil,
+				"action":          payload.Action,
+				"resolution_note": payload.Note,
+				"resolver_id":     moderatorID,
+				"resolved_at":     now,
+			})
+		if res.Error != nil {
+			return fmt.Errorf("记录处理结果失败: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return errors.New(config.MsgReportCaseResolved)
+		}
+		if err := tx.Model(&dto.ContentReport{}).Where("case_id = ?", caseID).Pluck("reporter_id", &reporterIDs).Error; err != nil {
+			return fmt.Errorf("查询举报人失败: %w", err)
+		}
+
+		switch payload.Action {
+		case dto.ReportActionDismiss:
+			if reportCase.AutoHidden {
+				_, err := setModerationStatus(tx, reportCase.TargetType, reportCase.TargetID, dto.ModerationVisible, dto.ModerationReported)
+				return err
+			}
+			return nil
+		case dto.ReportActionHide:
+			_, err := setModerationStatus(tx, reportCase.TargetType, reportCase.TargetID, dto.ModerationHidden)
+			return err
+		case dto.ReportActionDelete:
+			// 删除与处理结果在同一事务中，任何一步失败都不会留下已删除但举报单仍未处理的状态；内容已被删除时视为成功
+			var err error
+			switch reportCase.TargetType {
+			case dto.ContentTargetPost:
+				err = deletePost(tx, reportCase.TargetID)
+			case dto.ContentTargetComment:
+				err = deleteComment(tx, reportCase.TargetID)
+			case dto.ContentTargetReview:
+				err = deleteCourseReview(tx, reportCase.TargetID)
+			}
+			if errors.Is(err, ErrPostNotFound) || errors.Is(err, ErrCommentNotFound) {
+				err = nil
+			}
+			return err
+		case dto.ReportActionWarn:
+			if err := tx.Model(&dto.User{}).Where("id = ?", reportCase.TargetAuthorID).
+				UpdateColumn("warnings_count", gorm.Expr("warnings_count + 1")).Error; err != nil {
+				return fmt.Errorf("记录警告失败: %w", err)
+			}
+		case dto.ReportActionBan:
+			banUntil = dto.PermanentBanUntil()
+			if payload.BanDays > 0 {
+				banUntil = now.AddDate(0, 0, payload.BanDays)
+			}
+			if err := tx.Model(&dto.User{}).Where("id = ?", reportCase.TargetAuthorID).
+				UpdateColumn("banned_until", banUntil).Error; err != nil {
+				return fmt.Errorf("封禁用户失败: %w", err)
+			}
+		}
+		if reportCase.AutoHidden && isContent {
+			_, err := setModerationStatus(tx, reportCase.TargetType, reportCase.TargetID, dto.ModerationHidden, dto.ModerationReported)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	reportCase.Action = payload.Action
+	reportCase.ResolutionNote = payload.Note
+	notifyReportResolved(reportCase, reporterIDs, banUntil)
+	return nil
+}
+
+// notifyReportResolved 通过通知队列告知举报人处理结果，并通知被处理内容的作者；警告和封禁同时发送邮件
+func notifyReportResolved(reportCase dto.ReportCase, reporterIDs []uint32, banUntil time.Time) {
+	label := reportTargetLabels[reportCase.TargetType]
+	message := fmt.Sprintf("感谢您的举报。您举报的%s已处理：%s。", label, reportActionLabels[reportCase.Action])
+	for _, reporterID := range reporterIDs {
+		notifyAsync(notificationEvent{
+			userID:     reporterID,
+			kind:       dto.NotificationModeration,
+			targetType: reportCase.TargetType,
+			targetID:   reportCase.TargetID,
+			message:    message,
+		})
+	}
+
+	var notice string
+	switch reportCase.Action {
+	case dto.ReportActionHide:
+		notice = fmt.Sprintf("您发布的%s被多名用户举报，经版主核实存在违规，已被隐藏。", label)
+	case dto.ReportActionDelete:
+		notice = fmt.Sprintf("您发布的%s被多名用户举报，经版主核实存在违规，已被删除。", label)
+	case dto.ReportActionWarn:
+		notice = fmt.Sprintf("您发布的%s被多名用户举报，经版主核实存在违规，现对您作出警告。请遵守社区规范。", label)
+	case dto.ReportActionBan:
+		notice = fmt.Sprintf("您发布的%s被多名用户举报，经版主核实存在违规，账号已被封禁", label)
+		if banUntil.Equal(dto.PermanentBanUntil()) {
+			notice += "。"
+		} else {
+			notice += fmt.Sprintf("至 %s。", banUntil.Format("2006-01-02 15:04"))
+		}
+	default:
+		return
+	}
+	if reportCase.ResolutionNote != "" {
+		notice += "处理说明：" + reportCase.ResolutionNote
+	}
+	notifyAsync(notificationEvent{
+		userID:     reportCase.TargetAuthorID,
+		kind:       dto.NotificationModeration,
+		targetType: reportCase.TargetType,
+		targetID:   reportCase.TargetID,
+		message:    notice,
+		email:      reportCase.Action == dto.ReportActionWarn || reportCase.Action == dto.ReportActionBan,
+	})
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services/course"
+	"cengkeHelperBackGo/pkg/clock"
+	"cengkeHelperBackGo/pkg/generator"
+	"cengkeHelperBackGo/pkg/qrcode"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 教室状态
+const (
+	RoomStatusFree     = "free"
+	RoomStatusOccupied = "occupied"
+)
+
+// 教室二维码格式
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+)
+
+// qrModuleSize 二维码每个模块的像素边长，打印 A5 门牌足够清晰
+const qrModuleSize = 10
+
+// RoomService 教室实时状态与门牌二维码
+type RoomService struct {
+	structureService *CourseStructureService
+}
+
+// NewRoomService 创建 RoomService 实例
+func NewRoomService() *RoomService {
+	return &RoomService{structureService: NewCourseStructureService()}
+}
+
+// roomLessonRow 教室中某个时间段及其课程
+type roomLessonRow struct {
+	CourseID    uint32
+	CourseName  string
+	Teacher     string
+	AuditPolicy string
+	DayOfWeek   uint8
+	WeekAndTime uint32
+}
+
+// GetRoomStatus 返回教室当前的课、下一节课、是否空闲以及当前课程今天的打卡人数
+func (s *RoomService) GetRoomStatus(ctx context.Context, roomID string) (*vo.RoomStatusVO, error) {
+	area, building, classroom, ok := course.ParseRoomID(roomID)
+	if !ok {
+		return nil, errors.New(config.MsgRoomNotFound)
+	}
+
+	var rows []roomLessonRow
+	if err := database.Client.WithContext(ctx).Table("time_infos ti").
+		Select("ci.id AS course_id, ci.course_name, ci.teacher, ci.audit_policy, ti.day_of_week, ti.week_and_time").
+		Joins("JOIN course_infos ci ON ci.id = ti.course_info_id").
+		Where("ti.area = ? AND ti.building = ? AND ti.classroom = ?", area, building, classroom).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询教室课程失败: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New(config.MsgRoomNotFound)
+	}
+
+	now := clock.Now(ctx)
+	weekNum, weekday, lessonNum := s.structureService.TimeToNums(now, course.SemesterBeginDate)
+	status := &vo.RoomStatusVO{
+		RoomID:    roomID,
+		Area:      area,
+		Building:  building,
+		Classroom: classroom,
+		WeekNum:   weekNum,
+		DayOfWeek: weekday,
+		LessonNum: lessonNum,
+		Status:    RoomStatusFree,
+	}
+
+	// 学期外（不在第 1-19 周）教室没有课，只看考试
+	slots := make([]generator.DaySlot, len(rows))
+	for i, row := range rows {
+		slots[i] = generator.DaySlot{DayOfWeek: int(row.DayOfWeek), WeekAndTime: row.WeekAndTime}
+	}
+	if i := generator.CurrentSlot(slots, weekNum, weekday, lessonNum); i >= 0 {
+		status.Current = toRoomLessonVO(rows[i], weekNum, lessonNum)
+		status.Status = RoomStatusOccupied
+	}
+	if i, week, lesson := generator.NextSlotStart(slots, weekNum, weekday, lessonNum); i >= 0 {
+		status.Next = toRoomLessonVO(rows[i], week, lesson)
+	}
+
+	// 考试期间教室同样视为占用
+	exams, err := roomExamsOn(database.Client.WithContext(ctx), area, building, classroom, now.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	status.TodayExams = exams
+	clockTime := now.Format("15:04")
+	for i := range exams {
+		if exams[i].StartTime <= clockTime && clockTime < exams[i].EndTime {
+			status.CurrentExam = &exams[i]
+			status.Status = RoomStatusOccupied
+			break
+		}
+	}
+
+	if status.Current != nil {
+		var headcount int64
+		if err := database.Client.WithContext(ctx).Model(&dto.CourseCheckIn{}).
+			Where("course_id = ? AND check_in_date = ?", status.Current.CourseID, now.Format(time.DateOnly)).
+			Count(&headcount).Error; err != nil {
+			return nil, fmt.Errorf("统计打卡人数失败: %w", err)
+		}
+		status.Headcount = headcount
+	}
+	return status, nil
+}
+
+// toRoomLessonVO 以 lesson 所在的连续节次作为这节课的起止
+func toRoomLessonVO(row roomLessonRow, weekNum, lesson int) *vo.RoomLessonVO {
+	start, end := lesson, lesson
+	for start > 1 && generator.IsWeekLessonMatch(-1, start-1, row.WeekAndTime) {
+		start--
+	}
+	for end < 13 && generator.IsWeekLessonMatch(-1, end+1, row.WeekAndTime) {
+		end++
+	}
+	return &vo.RoomLessonVO{
+		CourseID:    row.CourseID,
+		CourseName:  row.CourseName,
+		TeacherName: row.Teacher,
+		AuditPolicy: row.AuditPolicy,
+		WeekNum:     weekNum,
+		DayOfWeek:   int(row.DayOfWeek),
+		StartLesson: start,
+		EndLesson:   end,
+	}
+}
+
+// RoomDeepLink 教室二维码指向的链接；未配置模板时使用配置的外部访问地址拼出本服务的教室状态接口。
+// 二维码会被打印张贴，地址只从配置读取，不根据请求头推断
+func (s *RoomService) RoomDeepLink(roomID string) (string, error) {
+	template := config.Conf.RoomQR.DeepLinkTemplate
+	if template == "" {
+		base := strings.TrimRight(config.Conf.RoomQR.PublicBaseURL, "/")
+		if base == "" {
+			return "", errors.New(config.MsgRoomQRNotConfigured)
+		}
+		return base + "/api/v1/rooms/" + url.PathEscape(roomID) + "/now", nil
+	}
+	escaped := url.PathEscape(roomID)
+	if idx := strings.Index(template, "{roomId}"); idx >= 0 && strings.Contains(template[:idx], "?") {
+		escaped = url.QueryEscape(roomID)
+	}
+	return strings.ReplaceAll(template, "{roomId}", escaped), nil
+}
+
+// RoomQRCode 生成单个教室的门牌二维码
+func (s *RoomService) RoomQRCode(ctx context.Context, roomID, format string) ([]byte, error) {
+	area, building, classroom, ok := course.ParseRoomID(roomID)
+	if !ok {
+		return nil, errors.New(config.MsgRoomNotFound)
+	}
+	var count int64
+	if err := database.Client.WithContext(ctx).Model(&dto.TimeInfo{}).
+		Where("area = ? AND building = ? AND classroom = ?", area, building, classroom).
+		Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("查询教室失败: %w", err)
+	}
+	if count == 0 {
+		return nil, errors.New(config.MsgRoomNotFound)
+	}
+	link, err := s.RoomDeepLink(roomID)
+	if err != nil {
+		return nil, err
+	}
+	return renderRoomQRCode(link, format)
+}
+
+// BuildingQRCodesZip 打包教学楼内所有教室的门牌二维码，buildingID 格式 division_{学部}_{教学楼}
+func (s *RoomService) BuildingQRCodesZip(ctx context.Context, buildingID, format string) ([]byte, error) {
+	area, building, ok := course.ParseBuildingID(buildingID)
+	if !ok {
+		return nil, errors.New(config.MsgBuildingNotFound)
+	}
+	// 提前检查链接配置，避免查询完教室才失败
+	if _, err := s.RoomDeepLink(""); err != nil {
+		return nil, err
+	}
+	var classrooms []string
+	if err := database.Client.WithContext(ctx).Model(&dto.TimeInfo{}).
+		Distinct("classroom").
+		Where("area = ? AND building = ?", area, building).
+		Order("classroom ASC").
+		Pluck("classroom", &classrooms).Error; err != nil {
+		return nil, fmt.Errorf("查询教学楼教室失败: %w", err)
+	}
+	if len(classrooms) == 0 {
+		return nil, errors.New(config.MsgBuildingNotFound)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, classroom := range classrooms {
+		if strings.TrimSpace(classroom) == "" {
+			continue
+		}
+		roomID := course.BuildRoomID(area, building, classroom)
+		link, err := s.RoomDeepLink(roomID)
+		if err != nil {
+			return nil, err
+		}
+		data, err := renderRoomQRCode(link, format)
+		if err != nil {
+			return nil, fmt.Errorf("生成教室 %s 的二维码失败: %w", classroom, err)
+		}
+		name := strings.NewReplacer("/", "_", "\\", "_").Replace(building+"_"+classroom) + "." + format
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("写入压缩包失败: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("写入压缩包失败: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("写入压缩包失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderRoomQRCode 将链接编码为指定格式的二维码图片
+func renderRoomQRCode(link, format string) ([]byte, error) {
+	code, err := qrcode.Encode(link, qrcode.LevelM)
+	if err != nil {
+		return nil, fmt.Errorf("编码二维码失败: %w", err)
+	}
+	if format == QRFormatSVG {
+		return code.SVG(qrModuleSize), nil
+	}
+	return code.PNG(qrModuleSize)
+}
@@ -0,0 +1,455 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"cengkeHelperBackGo/pkg/textnorm"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	tagMaxLen           = 50  // 与 tags.name 列宽一致
+	tagMigrateBatchSize = 200 // 迁移旧 JSON 标签时每批处理的帖子数
+)
+
+// TagService 帖子标签：热门标签、自动补全、标签页与同义标签合并
+type TagService struct{}
+
+// NewTagService 创建 TagService 实例
+func NewTagService() *TagService {
+	return &TagService{}
+}
+
+// normalizeTagNames 清理用户输入的标签：合并空白、截断过长的标签，归一化后相同的只保留第一个
+func normalizeTagNames(names []string) []string {
+	result := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = textnorm.Display(name)
+		if utf8.RuneCountInString(name) > tagMaxLen {
+			name = string([]rune(name)[:tagMaxLen])
+		}
+		key := textnorm.Key(name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+// findCanonicalTags 按名称查找已有标签，被合并的标签替换为合并后的标签；返回归一化键到标签的映射
+func findCanonicalTags(db *gorm.DB, names []string) (map[string]dto.Tag, error) {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, textnorm.Key(name))
+	}
+	var tags []dto.Tag
+	if err := db.Where("norm_key IN ?", keys).Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+
+	targetIDs := make([]uint32, 0)
+	for _, t := range tags {
+		if t.MergedIntoID != nil {
+			targetIDs = append(targetIDs, *t.MergedIntoID)
+		}
+	}
+	targets := make(map[uint32]dto.Tag)
+	if len(targetIDs) > 0 {
+		var merged []dto.Tag
+		if err := db.Where("id IN ?", targetIDs).Find(&merged).Error; err != nil {
+			return nil, fmt.Errorf("查询合并后的标签失败: %w", err)
+		}
+		for _, t := range merged {
+			targets[t.ID] = t
+		}
+	}
+
+	result := make(map[string]dto.Tag, len(tags))
+	for _, t := range tags {
+		if t.MergedIntoID != nil {
+			if target, ok := targets[*t.MergedIntoID]; ok {
+				result[t.NormKey] = target
+				continue
+			}
+		}
+		result[t.NormKey] = t
+	}
+	return result, nil
+}
+
+// resolveTags 将标签名解析为有效标签，不存在的标签自动创建；合并后重复的标签只保留一个
+func resolveTags(tx *gorm.DB, names []string) ([]dto.Tag, error) {
+	names = normalizeTagNames(names)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	missing := make([]dto.Tag, 0)
+	existing, err := findCanonicalTags(tx, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if _, ok := existing[textnorm.Key(name)]; !ok {
+			missing = append(missing, dto.Tag{Name: name, NormKey: textnorm.Key(name)})
+		}
+	}
+	if len(missing) > 0 {
+		// 并发创建同一标签时以先写入的为准
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+			return nil, fmt.Errorf("创建标签失败: %w", err)
+		}
+		if existing, err = findCanonicalTags(tx, names); err != nil {
+			return nil, err
+		}
+	}
+
+	tags := make([]dto.Tag, 0, len(names))
+	seen := make(map[uint32]bool, len(names))
+	for _, name := range names {
+		t, ok := existing[textnorm.Key(name)]
+		if !ok || seen[t.ID] {
+			continue
+		}
+		seen[t.ID] = true
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+// applyPostTags 整体替换帖子的标签：保留未变化的关联（及其关联时间），更新使用次数，
+// 并把规范后的标签名写回帖子的 tags/search_tags 列
+func applyPostTags(tx *gorm.DB, postID uint32, names []string) error {
+	tags, err := resolveTags(tx, names)
+	if err != nil {
+		return err
+	}
+	oldTagIDs, err := postTagIDs(tx, postID)
+	if err != nil {
+		return err
+	}
+
+	tagNames := make([]string, 0, len(tags))
+	newTagIDs := make([]uint32, 0, len(tags))
+	added := make([]dto.PostTag, 0, len(tags))
+	for _, t := range tags {
+		tagNames = append(tagNames, t.Name)
+		newTagIDs = append(newTagIDs, t.ID)
+		if !slices.Contains(oldTagIDs, t.ID) {
+			added = append(added, dto.PostTag{PostID: postID, TagID: t.ID})
+		}
+	}
+	removed := make([]uint32, 0, len(oldTagIDs))
+	for _, id := range oldTagIDs {
+		if !slices.Contains(newTagIDs, id) {
+			removed = append(removed, id)
+		}
+	}
+	if len(removed) > 0 {
+		if err := tx.Where("post_id = ? AND tag_id IN ?", postID, removed).Delete(&dto.PostTag{}).Error; err != nil {
+			return fmt.Errorf("删除帖子标签失败: %w", err)
+		}
+	}
+	if len(added) > 0 {
+		if err := tx.Create(&added).Error; err != nil {
+			return fmt.Errorf("保存帖子标签失败: %w", err)
+		}
+	}
+
+	tagsJSON, err := json.Marshal(tagNames)
+	if err != nil {
+		return fmt.Errorf("序列化标签失败: %w", err)
+	}
+	if err := tx.Model(&dto.Post{}).Unscoped().Where("id = ?", postID).UpdateColumns(map[string]interface{}{
+		"tags":        datatypes.JSON(tagsJSON),
+		"search_tags": postSearchTags(tagNames),
+	}).Error; err != nil {
+		return fmt.Errorf("更新帖子标签失败: %w", err)
+	}
+	affected := removed
+	for _, row := range added {
+		affected = append(affected, row.TagID)
+	}
+	return recountTagUsage(tx, affected)
+}
+
+// recountTagUsage 重新统计标签的使用次数，已删除的帖子和草稿不计入
+func recountTagUsage(tx *gorm.DB, tagIDs []uint32) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	if err := tx.Exec(`UPDATE tags SET usage_count = (
+		SELECT COUNT(*) FROM post_tags JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL AND posts.is_published = TRUE
+		WHERE post_tags.tag_id = tags.id
+	) WHERE id IN ?`, tagIDs).Error; err != nil {
+		return fmt.Errorf("更新标签使用次数失败: %w", err)
+	}
+	return nil
+}
+
+// postTagIDs 查询帖子当前的标签ID
+func postTagIDs(db *gorm.DB, postID uint32) ([]uint32, error) {
+	var tagIDs []uint32
+	if err := db.Model(&dto.PostTag{}).Where("post_id = ?", postID).Pluck("tag_id", &tagIDs).Error; err != nil {
+		return nil, fmt.Errorf("查询帖子标签失败: %w", err)
+	}
+	return tagIDs, nil
+}
+
+// applyPostTagFilter 按标签精确筛选帖子，match 为 dto.TagMatchAny 时包含任一标签即可，否则须包含全部标签
+func applyPostTagFilter(db, query *gorm.DB, names []string, match string) (*gorm.DB, error) {
+	names = normalizeTagNames(names)
+	if len(names) == 0 {
+		return query, nil
+	}
+	found, err := findCanonicalTags(db, names)
+	if err != nil {
+		return nil, err
+	}
+	tagIDs := make([]uint32, 0, len(found))
+	missing := false
+	for _, name := range names {
+		t, ok := found[textnorm.Key(name)]
+		if !ok {
+			missing = true
+			continue
+		}
+		if !slices.Contains(tagIDs, t.ID) {
+			tagIDs = append(tagIDs, t.ID)
+		}
+	}
+	if len(tagIDs) == 0 || (missing && match != dto.TagMatchAny) {
+		return query.Where("1 = 0"), nil
+	}
+
+	sub := db.Model(&dto.PostTag{}).Select("post_id").Where("tag_id IN ?", tagIDs)
+	if match != dto.TagMatchAny {
+		sub = sub.Group("post_id").Having("COUNT(*) = ?", len(tagIDs))
+	}
+	return query.Where("id IN (?)", sub), nil
+}
+
+// splitTagParam 拆分逗号分隔的标签参数，同时支持中文逗号
+func splitTagParam(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '，' })
+}
+
+func toTagVO(t dto.Tag) vo.TagVO {
+	return vo.TagVO{ID: t.ID, Name: t.Name, UsageCount: t.UsageCount}
+}
+
+// Trending 返回最近一段时间内新增使用最多的标签
+func (s *TagService) Trending(ctx context.Context, window time.Duration, limit int) ([]vo.TrendingTagVO, error) {
+	since := clock.Now(ctx).Add(-window)
+	var rows []struct {
+		dto.Tag
+		RecentCount int64
+	}
+	if err := database.Client.WithContext(ctx).Table("post_tags").
+		Select("tags.*, COUNT(*) AS recent_count").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id AND tags.merged_into_id IS NULL").
+		Joins("JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL AND posts.is_published = TRUE").
+		Where("post_tags.created_at >= ?", since).
+		Group("tags.id").
+		Order("recent_count DESC, tags.usage_count DESC, tags.id").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询热门标签失败: %w", err)
+	}
+
+	result := make([]vo.TrendingTagVO, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, vo.TrendingTagVO{TagVO: toTagVO(row.Tag), RecentCount: row.RecentCount})
+	}
+	return result, nil
+}
+
+// Suggest 标签自动补全：前缀匹配的排在前面，其余按包含匹配，同组内按使用次数排序
+func (s *TagService) Suggest(ctx context.Context, q string, limit int) ([]vo.TagVO, error) {
+	key := textnorm.Key(q)
+	result := make([]vo.TagVO, 0, limit)
+	if key == "" {
+		return result, nil
+	}
+	like := "%" + escapeLike(key) + "%"
+	prefix := escapeLike(key) + "%"
+
+	var tags []dto.Tag
+	if err := database.Client.WithContext(ctx).
+		Where("merged_into_id IS NULL AND norm_key LIKE ?", like).
+		Order(clause.Expr{SQL: "norm_key LIKE ? DESC, usage_count DESC, CHAR_LENGTH(name), id", Vars: []interface{}{prefix}}).
+		Limit(limit).
+		Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	for _, t := range tags {
+		result = append(result, toTagVO(t))
+	}
+	return result, nil
+}
+
+// GetTagDetail 返回标签页信息，使用被合并的旧写法访问时返回合并后的标签
+func (s *TagService) GetTagDetail(ctx context.Context, name string, relatedLimit int) (*vo.TagDetailVO, error) {
+	db := database.Client.WithContext(ctx)
+	names := normalizeTagNames([]string{name})
+	if len(names) == 0 {
+		return nil, errors.New(config.MsgTagNotFound)
+	}
+	found, err := findCanonicalTags(db, names)
+	if err != nil {
+		return nil, err
+	}
+	tag, ok := found[textnorm.Key(names[0])]
+	if !ok {
+		return nil, errors.New(config.MsgTagNotFound)
+	}
+
+	var related []dto.Tag
+	if err := db.Table("post_tags AS a").
+		Select("tags.*").
+		Joins("JOIN post_tags AS b ON b.post_id = a.post_id AND b.tag_id <> a.tag_id").
+		Joins("JOIN tags ON tags.id = b.tag_id").
+		Joins("JOIN posts ON posts.id = a.post_id AND posts.deleted_at IS NULL AND posts.is_published = TRUE").
+		Where("a.tag_id = ?", tag.ID).
+		Group("tags.id").
+		Order("COUNT(*) DESC, tags.usage_count DESC, tags.id").
+		Limit(relatedLimit).
+		Scan(&related).Error; err != nil {
+		return nil, fmt.Errorf("查询相关标签失败: %w", err)
+	}
+
+	detail := &vo.TagDetailVO{TagVO: toTagVO(tag), RelatedTags: make([]vo.TagVO, 0, len(related))}
+	for _, t := range related {
+		detail.RelatedTags = append(detail.RelatedTags, toTagVO(t))
+	}
+	return detail, nil
+}
+
+// Merge 将同义标签合并到目标标签：帖子上的旧标签替换为目标标签，旧标签保留并指向目标标签
+func (s *TagService) Merge(ctx context.Context, payload dto.TagMergeDTO) (*vo.TagMergeResultVO, error) {
+	sourceIDs := slices.Compact(slices.Sorted(slices.Values(payload.SourceIDs)))
+	if slices.Contains(sourceIDs, payload.TargetID) {
+		return nil, errors.New(config.MsgTagMergeInvalid)
+	}
+
+	var target dto.Tag
+	var affectedPosts []uint32
+	err := database.Client.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&target, payload.TargetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(config.MsgTagNotFound)
+			}
+			return fmt.Errorf("查询目标标签失败: %w", err)
+		}
+		if target.MergedIntoID != nil {
+			return errors.New(config.MsgTagMergeInvalid)
+		}
+		var sourceCount int64
+		if err := tx.Model(&dto.Tag{}).Where("id IN ?", sourceIDs).Count(&sourceCount).Error; err != nil {
+			return fmt.Errorf("查询被合并标签失败: %w", err)
+		}
+		if int(sourceCount) != len(sourceIDs) {
+			return errors.New(config.MsgTagNotFound)
+		}
+
+		if err := tx.Model(&dto.PostTag{}).Distinct("post_id").
+			Where("tag_id IN ?", sourceIDs).Pluck("post_id", &affectedPosts).Error; err != nil {
+			return fmt.Errorf("查询受影响的帖子失败: %w", err)
+		}
+		// 保留最早的关联时间，避免合并本身让目标标签变成热门
+		if err := tx.Exec(`INSERT IGNORE INTO post_tags (post_id, tag_id, created_at)
+			SELECT post_id, ?, MIN(created_at) FROM post_tags WHERE tag_id IN ? GROUP BY post_id`,
+			target.ID, sourceIDs).Error; err != nil {
+			return fmt.Errorf("迁移帖子标签失败: %w", err)
+		}
+		if err := tx.Where("tag_id IN ?", sourceIDs).Delete(&dto.PostTag{}).Error; err != nil {
+			return fmt.Errorf("删除旧标签关联失败: %w", err)
+		}
+		// 之前合并到旧标签的写法也改为指向目标标签
+		if err := tx.Model(&dto.Tag{}).
+			Where("id IN ? OR merged_into_id IN ?", sourceIDs, sourceIDs).
+			Updates(map[string]interface{}{"merged_into_id": target.ID, "usage_count": 0}).Error; err != nil {
+			return fmt.Errorf("更新被合并标签失败: %w", err)
+		}
+		if err := recountTagUsage(tx, []uint32{target.ID}); err != nil {
+			return err
+		}
+
+		// 帖子上冗余保存的标签名同步改写，关联表已指向目标标签，这里不会再改动关联
+		for _, postID := range affectedPosts {
+			var post dto.Post
+			if err := tx.Unscoped().Select("id", "tags").First(&post, postID).Error; err != nil {
+				return fmt.Errorf("查询帖子 %d 失败: %w", postID, err)
+			}
+			var names []string
+			if len(post.Tags) > 0 {
+				_ = json.Unmarshal(post.Tags, &names)
+			}
+			if err := applyPostTags(tx, postID, names); err != nil {
+				return err
+			}
+		}
+		return tx.First(&target, target.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vo.TagMergeResultVO{
+		Target:        toTagVO(target),
+		MergedCount:   len(sourceIDs),
+		AffectedPosts: len(affectedPosts),
+	}, nil
+}
+
+// MigrateJSONTags 将建立标签表之前保存在帖子 tags JSON 列中的标签迁移到标签表，已迁移的帖子不会重复处理
+func (s *TagService) MigrateJSONTags(ctx context.Context) (int, error) {
+	db := database.Client.WithContext(ctx)
+	migrated := 0
+	var lastID uint32
+	for {
+		var posts []dto.Post
+		if err := db.Unscoped().Select("id", "tags").
+			Where("id > ? AND tags IS NOT NULL AND JSON_LENGTH(tags) > 0", lastID).
+			Where("NOT EXISTS (SELECT 1 FROM post_tags WHERE post_tags.post_id = posts.id)").
+			Order("id").Limit(tagMigrateBatchSize).
+			Find(&posts).Error; err != nil {
+			return migrated, fmt.Errorf("查询待迁移标签的帖子失败: %w", err)
+		}
+		if len(posts) == 0 {
+			return migrated, nil
+		}
+		for _, post := range posts {
+			lastID = post.ID
+			var names []string
+			if err := json.Unmarshal(post.Tags, &names); err != nil {
+				log.Printf("Service: 帖子 %d 的标签无法解析，跳过: %v", post.ID, err)
+				continue
+			}
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				return applyPostTags(tx, post.ID, names)
+			}); err != nil {
+				return migrated, err
+			}
+			migrated++
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/internal/services/course"
+	"cengkeHelperBackGo/pkg/generator"
+	"cengkeHelperBackGo/pkg/timetable"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// 课表图片格式。只提供 SVG：课程名需要中文字体，SVG 交给查看端渲染文字
+const TimetableFormatSVG = "svg"
+
+// timetableCacheTTL 渲染结果按内容哈希缓存，内容不变则键不变，过期只为回收空间
+const timetableCacheTTL = 24 * time.Hour
+
+// RenderedTimetable 渲染好的课表图片
+type RenderedTimetable struct {
+	Data        []byte
+	ContentType string
+	Hash        string // 课表内容哈希，可作为 ETag
+}
+
+// TimetableService 课表图片服务
+type TimetableService struct{}
+
+// NewTimetableService 创建课表图片服务
+func NewTimetableService() *TimetableService {
+	return &TimetableService{}
+}
+
+// timetableRow 课表查询结果：时间段及其课程
+type timetableRow struct {
+	CourseID    uint32
+	CourseNum   string
+	CourseName  string
+	Teacher     string
+	DayOfWeek   uint8
+	WeekAndTime uint32
+	Building    string
+	Classroom   string
+}
+
+// UserSchedule 构建用户个人课表（收藏的课程），weekNum 大于 0 时只包含该周有课的时间段
+func (s *TimetableService) UserSchedule(ctx context.Context, userID uint32, weekNum int) (*timetable.Timetable, error) {
+	var user dto.User
+	if err := database.Client.WithContext(ctx).Select("id", "username").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(config.MsgUserNotFound)
+		}
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	var rows []timetableRow
+	if err := database.Client.WithContext(ctx).Table("time_infos ti").
+		Select("ci.id AS course_id, ci.course_num, ci.course_name, ci.teacher, ti.day_of_week, ti.week_and_time, ti.building, ti.classroom").
+		Joins("JOIN course_infos ci ON ci.id = ti.course_info_id").
+		Joins("JOIN user_course_favorites f ON f.course_id = ci.id").
+		Where("f.user_id = ?", userID).
+		Order("ti.day_of_week ASC, ti.id ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询个人课表失败: %w", err)
+	}
+
+	t := &timetable.Timetable{
+		Title:    fmt.Sprintf("%s 的课表", user.Username),
+		Subtitle: weekSubtitle(weekNum),
+	}
+	for _, row := range rows {
+		t.Entries = append(t.Entries, toTimetableEntries(row, weekNum, strings.TrimSpace(row.Building+" "+row.Classroom))...)
+	}
+
+	// 指定周次时把该周的考试也画进课表
+	if weekNum > 0 {
+		exams, err := NewExamService().ListUserExams(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, exam := range exams {
+			if entry, ok := examTimetableEntry(exam, weekNum); ok {
+				t.Entries = append(t.Entries, entry)
+			}
+		}
+	}
+	return t, nil
+}
+
+// examTimetableEntry 将考试换算到节次，不在 weekNum 这一周时返回 false
+func examTimetableEntry(exam vo.ExamVO, weekNum int) (timetable.Entry, bool) {
+	start, errStart := time.ParseInLocation(course.ExamTimeLayout, exam.ExamDate+" "+exam.StartTime, time.Local)
+	end, errEnd := time.ParseInLocation(course.ExamTimeLayout, exam.ExamDate+" "+exam.EndTime, time.Local)
+	if errStart != nil || errEnd != nil {
+		return timetable.Entry{}, false
+	}
+	structure := NewCourseStructureService()
+	week, day, startLesson := structure.TimeToNums(start, course.SemesterBeginDate)
+	_, _, endLesson := structure.TimeToNums(end.Add(-time.Minute), course.SemesterBeginDate)
+	if week != weekNum {
+		return timetable.Entry{}, false
+	}
+	return timetable.Entry{
+		Title:       "考试 " + exam.CourseName,
+		Location:    strings.TrimSpace(exam.Building + " " + exam.Classroom),
+		Detail:      exam.StartTime + "-" + exam.EndTime,
+		Day:         day,
+		StartLesson: startLesson,
+		EndLesson:   max(startLesson, endLesson),
+		ColorKey:    "exam",
+	}, true
+}
+
+// RoomSchedule 构建教室的周课表，roomID 为结构化课程视图中的教室ID
+func (s *TimetableService) RoomSchedule(ctx context.Context, roomID string, weekNum int) (*timetable.Timetable, error) {
+	area, building, classroom, ok := course.ParseRoomID(roomID)
+	if !ok {
+		return nil, errors.New(config.MsgRoomNotFound)
+	}
+
+	var rows []timetableRow
+	if err := database.Client.WithContext(ctx).Table("time_infos ti").
+		Select("ci.id AS course_id, ci.course_num, ci.course_name, ci.teacher, ti.day_of_week, ti.week_and_time, ti.building, ti.classroom").
+		Joins("JOIN course_infos ci ON ci.id = ti.course_info_id").
+		Where("ti.area = ? AND ti.building = ? AND ti.classroom = ?", area, building, classroom).
+		Order("ti.day_of_week ASC, ti.id ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询教室课表失败: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New(config.MsgRoomNotFound)
+	}
+
+	t := &timetable.Timetable{
+		Title:    fmt.Sprintf("%s %s 教室课表", building, classroom),
+		Subtitle: weekSubtitle(weekNum),
+	}
+	for _, row := range rows {
+		// 教室课表中地点都相同，格子里改为显示任课老师
+		t.Entries = append(t.Entries, toTimetableEntries(row, weekNum, row.Teacher)...)
+	}
+	return t, nil
+}
+
+// Render 渲染课表图片，结果按课表内容哈希缓存在 Redis 中
+func (s *TimetableService) Render(ctx context.Context, t *timetable.Timetable, format string) (*RenderedTimetable, error) {
+	hash := t.Hash()
+	rendered := &RenderedTimetable{Hash: hash, ContentType: "image/svg+xml; charset=utf-8"}
+
+	cacheKey := fmt.Sprintf("timetable:%s:%s", format, hash)
+	if database.RedisClient != nil {
+		data, err := database.RedisClient.Get(ctx, cacheKey).Bytes()
+		if err == nil {
+			rendered.Data = data
+			return rendered, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("Service: 读取课表图片缓存失败: %v", err)
+		}
+	}
+
+	rendered.Data = timetable.RenderSVG(t)
+
+	// 缓存写入失败不影响本次返回
+	if database.RedisClient != nil {
+		if err := database.RedisClient.Set(ctx, cacheKey, rendered.Data, timetableCacheTTL).Err(); err != nil {
+			log.Printf("Service: 写入课表图片缓存失败: %v", err)
+		}
+	}
+	return rendered, nil
+}
+
+// toTimetableEntries 将一个时间段转换为课表格子，节次不连续时（如第 1-2 节和第 5-6 节）每段连续的节次各占一格；
+// 指定周次且该周无课时返回空
+func toTimetableEntries(row timetableRow, weekNum int, location string) []timetable.Entry {
+	if weekNum > 0 && !generator.IsWeekLessonMatch(weekNum, -1, row.WeekAndTime) {
+		return nil
+	}
+	slots := course.ParseTimeSlots(row.WeekAndTime, int(row.DayOfWeek))
+	if len(slots) == 0 {
+		return nil
+	}
+	slot := slots[0]
+	runs := generator.LessonRuns(row.WeekAndTime)
+	entries := make([]timetable.Entry, 0, len(runs))
+	for _, run := range runs {
+		entries = append(entries, timetable.Entry{
+			Title:       row.CourseName,
+			Location:    location,
+			Detail:      slot.Weeks,
+			Day:         slot.DayOfWeek,
+			StartLesson: run[0],
+			EndLesson:   run[1],
+			ColorKey:    row.CourseNum,
+		})
+	}
+	return entries
+}
+
+// weekSubtitle 课表副标题
+func weekSubtitle(weekNum int) string {
+	if weekNum > 0 {
+		return fmt.Sprintf("第 %d 周", weekNum)
+	}
+	return "全部周次"
+}
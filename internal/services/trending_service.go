@@ -0,0 +1,359 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/internal/models/vo"
+	"cengkeHelperBackGo/pkg/clock"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// TrendingEvent 计入课程热度的用户行为
+type TrendingEvent string
+
+const (
+	TrendingEventView     TrendingEvent = "view"     // 浏览课程详情
+	TrendingEventFavorite TrendingEvent = "favorite" // 收藏
+	TrendingEventCheckIn  TrendingEvent = "check_in" // 蹭课打卡
+	TrendingEventReview   TrendingEvent = "review"   // 发表评价
+)
+
+// trendingEventWeights 各行为的热度权重：收藏、评价、打卡比一次浏览更能说明兴趣
+var trendingEventWeights = map[TrendingEvent]float64{
+	TrendingEventView:     1,
+	TrendingEventFavorite: 5,
+	TrendingEventCheckIn:  3,
+	TrendingEventReview:   4,
+}
+
+// 热门窗口
+const (
+	TrendingWindow24h = "24h"
+	TrendingWindow7d  = "7d"
+)
+
+const (
+	trendingHourKeyPrefix = "trending:h:" // 按小时聚合，trending:h:2006010215
+	trendingDayKeyPrefix  = "trending:d:" // 按天聚合，trending:d:20060102
+	trendingResultPrefix  = "trending:result:"
+	trendingSeenPrefix    = "trending:seen:"    // 去重标记，trending:seen:24h:view:u:1:42，值为计入的桶
+	trendingHourKeyTTL    = 50 * time.Hour      // 24h 窗口需要本窗口和上一窗口共 48 个小时桶
+	trendingDayKeyTTL     = 15 * 24 * time.Hour // 7d 窗口需要 14 个天桶
+	trendingResultTTL     = 5 * time.Minute
+)
+
+// TrendingService 基于 Redis 时间桶统计课程热度
+type TrendingService struct{}
+
+// NewTrendingService 创建 TrendingService 实例
+func NewTrendingService() *TrendingService {
+	return &TrendingService{}
+}
+
+// trendingBucket 一个热门窗口使用的时间桶及同一用户的去重时长
+type trendingBucket struct {
+	window string
+	key    string
+	ttl    time.Duration
+	dedupe time.Duration
+}
+
+// trendingBuckets 某一时刻的行为计入的小时桶（24h 窗口）和天桶（7d 窗口）
+func trendingBuckets(now time.Time) []trendingBucket {
+	return []trendingBucket{
+		{TrendingWindow24h, trendingHourKeyPrefix + now.Format("2006010215"), trendingHourKeyTTL, 24 * time.Hour},
+		{TrendingWindow7d, trendingDayKeyPrefix + now.Format("20060102"), trendingDayKeyTTL, 7 * 24 * time.Hour},
+	}
+}
+
+// trendingSeenKey 同一用户对同一课程的同一种行为在一个窗口内只计一次
+func trendingSeenKey(window string, event TrendingEvent, actor string, courseID uint32) string {
+	return fmt.Sprintf("%s%s:%s:%s:%d", trendingSeenPrefix, window, event, actor, courseID)
+}
+
+// TrendingActor 热度去重使用的行为主体：登录用户按用户ID，未登录按IP
+func TrendingActor(userID *uint32, clientIP string) string {
+	if userID != nil {
+		return "u:" + strconv.FormatUint(uint64(*userID), 10)
+	}
+	return "ip:" + clientIP
+}
+
+// Record 记录一次用户行为，累加到所在小时和当天的桶。同一用户对同一课程的同一种行为在每个窗口内只计一次，
+// 反复刷新或反复收藏不会刷高热度；Redis 不可用时忽略，不影响主流程
+func (s *TrendingService) Record(ctx context.Context, courseID uint32, actor string, event TrendingEvent) {
+	if database.RedisClient == nil {
+		return
+	}
+	weight, ok := trendingEventWeights[event]
+	if !ok {
+		return
+	}
+	buckets := trendingBuckets(clock.Now(ctx))
+
+	// 去重标记的值为计入的桶，撤销行为时从同一个桶中扣除
+	seen := database.RedisClient.Pipeline()
+	firsts := make([]*redis.BoolCmd, len(buckets))
+	for i, b := range buckets {
+		firsts[i] = seen.SetNX(ctx, trendingSeenKey(b.window, event, actor, courseID), b.key, b.dedupe)
+	}
+	if _, err := seen.Exec(ctx); err != nil {
+		log.Printf("Service: 记录课程 %d 的 %s 行为失败: %v", courseID, event, err)
+		return
+	}
+
+	member := strconv.FormatUint(uint64(courseID), 10)
+	pipe := database.RedisClient.Pipeline()
+	counted := false
+	for i, b := range buckets {
+		if !firsts[i].Val() {
+			continue
+		}
+		pipe.ZIncrBy(ctx, b.key, weight, member)
+		pipe.Expire(ctx, b.key, b.ttl)
+		counted = true
+	}
+	if !counted {
+		return
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Service: 记录课程 %d 的 %s 行为失败: %v", courseID, event, err)
+	}
+}
+
+// Revoke 撤销一次已计入的行为（如取消收藏），从当时计入的桶中扣除权重并清除去重标记
+func (s *TrendingService) Revoke(ctx context.Context, courseID uint32, actor string, event TrendingEvent) {
+	if database.RedisClient == nil {
+		return
+	}
+	weight, ok := trendingEventWeights[event]
+	if !ok {
+		return
+	}
+	windows := []string{TrendingWindow24h, TrendingWindow7d}
+
+	// 读取并删除去重标记放在同一个事务中，重复撤销只扣除一次
+	seen := database.RedisClient.TxPipeline()
+	bucketKeys := make([]*redis.StringCmd, len(windows))
+	for i, window := range windows {
+		key := trendingSeenKey(window, event, actor, courseID)
+		bucketKeys[i] = seen.Get(ctx, key)
+		seen.Del(ctx, key)
+	}
+	if _, err := seen.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("Service: 撤销课程 %d 的 %s 行为失败: %v", courseID, event, err)
+		return
+	}
+
+	member := strconv.FormatUint(uint64(courseID), 10)
+	pipe := database.RedisClient.Pipeline()
+	revoked := false
+	for _, cmd := range bucketKeys {
+		bucketKey := cmd.Val()
+		if bucketKey == "" {
+			continue
+		}
+		// 去重标记的有效期短于桶，标记还在时桶一定还在
+		pipe.ZIncrBy(ctx, bucketKey, -weight, member)
+		revoked = true
+	}
+	if !revoked {
+		return
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Service: 撤销课程 %d 的 %s 行为失败: %v", courseID, event, err)
+	}
+}
+
+// trendingBucketKeys 返回本窗口和上一窗口各自包含的桶
+func trendingBucketKeys(window string, now time.Time) (current, previous []string) {
+	switch window {
+	case TrendingWindow7d:
+		day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		for i := 0; i < 14; i++ {
+			key := trendingDayKeyPrefix + day.AddDate(0, 0, -i).Format("20060102")
+			if i < 7 {
+				current = append(current, key)
+			} else {
+				previous = append(previous, key)
+			}
+		}
+	default:
+		hour := now.Truncate(time.Hour)
+		for i := 0; i < 48; i++ {
+			key := trendingHourKeyPrefix + hour.Add(-time.Duration(i)*time.Hour).Format("2006010215")
+			if i < 24 {
+				current = append(current, key)
+			} else {
+				previous = append(previous, key)
+			}
+		}
+	}
+	return current, previous
+}
+
+// sumTrendingBuckets 读取并累加多个桶的热度
+func sumTrendingBuckets(ctx context.Context, keys []string) (map[uint32]float64, error) {
+	pipe := database.RedisClient.Pipeline()
+	cmds := make([]*redis.ZSliceCmd, 0, len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, pipe.ZRangeWithScores(ctx, key, 0, -1))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("读取热度统计失败: %w", err)
+	}
+
+	scores := make(map[uint32]float64)
+	for _, cmd := range cmds {
+		for _, z := range cmd.Val() {
+			member, _ := z.Member.(string)
+			courseID, err := strconv.ParseUint(member, 10, 32)
+			if err != nil {
+				continue
+			}
+			scores[uint32(courseID)] += z.Score
+		}
+	}
+	return scores, nil
+}
+
+// Trending 返回窗口内热度上升最快的课程，按热度增量排序；divisionID 非空时只看该学部上课的课程
+func (s *TrendingService) Trending(ctx context.Context, window string, divisionID *int, limit int) (*vo.TrendingCoursesVO, error) {
+	if database.RedisClient == nil {
+		return nil, errors.New(config.MsgTrendingUnavailable)
+	}
+
+	divisionStr := "all"
+	if divisionID != nil {
+		divisionStr = strconv.Itoa(*divisionID)
+	}
+	cacheKey := fmt.Sprintf("%s%s:%s:%d", trendingResultPrefix, window, divisionStr, limit)
+	if data, err := database.RedisClient.Get(ctx, cacheKey).Bytes(); err == nil {
+		var cached vo.TrendingCoursesVO
+		if err := json.Unmarshal(data, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	now := clock.Now(ctx)
+	currentKeys, previousKeys := trendingBucketKeys(window, now)
+	current, err := sumTrendingBuckets(ctx, currentKeys)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := sumTrendingBuckets(ctx, previousKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]vo.TrendingCourseVO, 0, len(current))
+	for courseID, score := range current {
+		prev := previous[courseID]
+		if score <= prev {
+			continue
+		}
+		items = append(items, vo.TrendingCourseVO{
+			CourseID:      courseID,
+			Score:         score,
+			PreviousScore: prev,
+			Delta:         score - prev,
+			GrowthRate:    (score - prev) / max(prev, 1),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Delta != items[j].Delta {
+			return items[i].Delta > items[j].Delta
+		}
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].CourseID < items[j].CourseID
+	})
+
+	courses, err := s.fillTrendingCourses(ctx, items, divisionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := &vo.TrendingCoursesVO{
+		Window:      window,
+		DivisionID:  divisionID,
+		GeneratedAt: now,
+		Courses:     courses,
+	}
+	if data, err := json.Marshal(result); err == nil {
+		_ = database.RedisClient.Set(ctx, cacheKey, data, trendingResultTTL).Err()
+	}
+	return result, nil
+}
+
+// fillTrendingCourses 补充课程信息并按学部过滤，已删除的课程直接跳过
+func (s *TrendingService) fillTrendingCourses(ctx context.Context, items []vo.TrendingCourseVO, divisionID *int, limit int) ([]vo.TrendingCourseVO, error) {
+	result := make([]vo.TrendingCourseVO, 0, limit)
+	if len(items) == 0 {
+		return result, nil
+	}
+	courseIDs := make([]uint32, 0, len(items))
+	for _, item := range items {
+		courseIDs = append(courseIDs, item.CourseID)
+	}
+
+	db := database.Client.WithContext(ctx)
+	var infos []dto.CourseInfo
+	if err := db.Select("id", "course_num", "course_name", "teacher", "faculty").
+		Where("id IN ?", courseIDs).Find(&infos).Error; err != nil {
+		return nil, fmt.Errorf("查询课程信息失败: %w", err)
+	}
+	infoByID := make(map[uint32]dto.CourseInfo, len(infos))
+	for _, info := range infos {
+		infoByID[info.ID] = info
+	}
+
+	var areaRows []struct {
+		CourseInfoId uint32
+		Area         uint8
+	}
+	if err := db.Model(&dto.TimeInfo{}).Distinct("course_info_id", "area").
+		Where("course_info_id IN ?", courseIDs).Scan(&areaRows).Error; err != nil {
+		return nil, fmt.Errorf("查询课程学部失败: %w", err)
+	}
+	areasByCourse := make(map[uint32][]int)
+	for _, row := range areaRows {
+		areasByCourse[row.CourseInfoId] = append(areasByCourse[row.CourseInfoId], int(row.Area))
+	}
+
+	for _, item := range items {
+		info, ok := infoByID[item.CourseID]
+		if !ok {
+			continue
+		}
+		areas := areasByCourse[item.CourseID]
+		sort.Ints(areas)
+		if divisionID != nil && !slices.Contains(areas, *divisionID) {
+			continue
+		}
+		item.CourseName = info.CourseName
+		item.CourseCode = info.CourseNum
+		item.TeacherName = info.Teacher
+		item.Faculty = info.Faculty
+		item.Divisions = areas
+		if item.Divisions == nil {
+			item.Divisions = []int{}
+		}
+		result = append(result, item)
+		if len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
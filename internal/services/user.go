@@ -1 +1,35 @@
 package services
+
+import (
+	"cengkeHelperBackGo/internal/config"
+	database "cengkeHelperBackGo/internal/db"
+	"cengkeHelperBackGo/internal/models/dto"
+	"context"
+	"errors"
+	"fmt"
+)
+
+type UserService struct{}
+
+func NewUserService() *UserService {
+	return &UserService{}
+}
+
+// SetRole 设置用户角色，用户重新登录后新角色生效
+func (s *UserService) SetRole(ctx context.Context, userID uint32, role uint8) error {
+	result := database.Client.WithContext(ctx).Model(&dto.User{}).Where("id = ?", userID).UpdateColumn("user_role", role)
+	if result.Error != nil {
+		return fmt.Errorf("更新用户角色失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// 角色未变化时 RowsAffected 也为 0，需要区分用户是否存在
+		var count int64
+		if err := database.Client.WithContext(ctx).Model(&dto.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+			return fmt.Errorf("查询用户失败: %w", err)
+		}
+		if count == 0 {
+			return errors.New(config.MsgUserNotFound)
+		}
+	}
+	return nil
+}
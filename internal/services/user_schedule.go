@@ -0,0 +1,42 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/models/dto"
+	"cengkeHelperBackGo/pkg/generator"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// busySlots 个人课表占用的时间：星期 -> 该天所有时间段的 week_and_time 编码
+type busySlots map[uint8][]uint32
+
+// loadUserBusySlots 加载用户个人课表（即收藏的课程）占用的时间段
+func loadUserBusySlots(db *gorm.DB, userID uint32) (busySlots, error) {
+	var slots []dto.TimeInfo
+	if err := db.Model(&dto.TimeInfo{}).
+		Select("time_infos.day_of_week", "time_infos.week_and_time").
+		Joins("JOIN user_course_favorites f ON f.course_id = time_infos.course_info_id").
+		Where("f.user_id = ?", userID).
+		Find(&slots).Error; err != nil {
+		return nil, fmt.Errorf("查询个人课表失败: %w", err)
+	}
+
+	busy := make(busySlots)
+	for _, slot := range slots {
+		busy[slot.DayOfWeek] = append(busy[slot.DayOfWeek], slot.WeekAndTime)
+	}
+	return busy, nil
+}
+
+// fits 课程的所有时间段都不与个人课表冲突
+func (b busySlots) fits(slots []dto.TimeInfo) bool {
+	for _, slot := range slots {
+		for _, busy := range b[slot.DayOfWeek] {
+			if generator.IsTimeConflict(slot.WeekAndTime, busy) {
+				return false
+			}
+		}
+	}
+	return true
+}
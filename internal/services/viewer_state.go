@@ -0,0 +1,62 @@
+package services
+
+import (
+	"cengkeHelperBackGo/internal/models/dto"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// postViewerState 当前用户对一批帖子的点赞、收藏状态
+type postViewerState struct {
+	liked     map[uint32]bool
+	collected map[uint32]bool
+}
+
+// loadPostViewerState 批量查询当前用户对一页帖子的点赞与收藏状态，未登录时返回空状态
+func loadPostViewerState(db *gorm.DB, currentUserID *uint32, postIDs []uint32) (postViewerState, error) {
+	state := postViewerState{liked: map[uint32]bool{}, collected: map[uint32]bool{}}
+	if currentUserID == nil || *currentUserID == 0 || len(postIDs) == 0 {
+		return state, nil
+	}
+
+	var likedIDs []uint32
+	if err := db.Model(&dto.UserPostLike{}).
+		Where("user_id = ? AND post_id IN ?", *currentUserID, postIDs).
+		Pluck("post_id", &likedIDs).Error; err != nil {
+		return state, fmt.Errorf("查询帖子点赞状态失败: %w", err)
+	}
+	for _, id := range likedIDs {
+		state.liked[id] = true
+	}
+
+	var collectedIDs []uint32
+	if err := db.Model(&dto.UserPostCollect{}).
+		Where("user_id = ? AND post_id IN ?", *currentUserID, postIDs).
+		Pluck("post_id", &collectedIDs).Error; err != nil {
+		return state, fmt.Errorf("查询帖子收藏状态失败: %w", err)
+	}
+	for _, id := range collectedIDs {
+		state.collected[id] = true
+	}
+	return state, nil
+}
+
+// loadCommentLikedSet 批量查询当前用户点赞过的评论，未登录时返回空集合
+func loadCommentLikedSet(db *gorm.DB, currentUserID *uint32, commentIDs []uint32) (map[uint32]bool, error) {
+	liked := map[uint32]bool{}
+	if currentUserID == nil || *currentUserID == 0 || len(commentIDs) == 0 {
+		return liked, nil
+	}
+
+	var ids []uint32
+	if err := db.Model(&dto.UserCommentLike{}).
+		Where("user_id = ? AND comment_id IN ?", *currentUserID, commentIDs).
+		Pluck("comment_id", &ids).Error; err != nil {
+		return liked, fmt.Errorf("查询评论点赞状态失败: %w", err)
+	}
+	for _, id := range ids {
+		liked[id] = true
+	}
+	return liked, nil
+}
@@ -0,0 +1,29 @@
+// Package auditscore 计算课程的蹭课友好度
+package auditscore
+
+// Score 计算课程的蹭课友好度（0-100）
+// policy: 旁听态度；capacity: 教室容量（<=0 表示未知）；
+// avgHeadcount: 近期每次课平均打卡人数；distinctAuditors: 历史打卡过的不同用户数
+func Score(policy string, capacity int, avgHeadcount float64, distinctAuditors int) float32 {
+	// 老师明确不欢迎旁听时直接为 0
+	var policyScore float64
+	switch policy {
+	case "welcome":
+		policyScore = 50
+	case "closed":
+		return 0
+	default:
+		policyScore = 30
+	}
+
+	// 教室余量：打卡人数越接近容量越拥挤；容量未知时取中间值
+	roomScore := 15.0
+	if capacity > 0 {
+		roomScore = 30 * (1 - min(1, avgHeadcount/float64(capacity)))
+	}
+
+	// 已有同学成功蹭过课说明可行，最多 10 人封顶
+	socialScore := 20 * min(1, float64(distinctAuditors)/10)
+
+	return float32(policyScore + roomScore + socialScore)
+}
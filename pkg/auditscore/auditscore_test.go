@@ -0,0 +1,36 @@
+package auditscore
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name             string
+		policy           string
+		capacity         int
+		avgHeadcount     float64
+		distinctAuditors int
+		want             float32
+	}{
+		{"不欢迎旁听直接为0", "closed", 100, 0, 50, 0},
+		{"欢迎旁听且教室空", "welcome", 100, 0, 0, 80},
+		{"未表态且容量未知取中间值", "neutral", 0, 30, 0, 45},
+		{"未知态度按未表态处理", "", -1, 0, 0, 45},
+		{"教室坐满时没有余量分", "welcome", 40, 40, 0, 50},
+		{"打卡人数超过容量不为负", "welcome", 40, 80, 0, 50},
+		{"打卡过的人数达到10人封顶", "welcome", 0, 0, 10, 85},
+		{"超过10人不再加分", "welcome", 0, 0, 200, 85},
+		{"未满10人按比例", "neutral", 100, 50, 5, 30 + 15 + 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.policy, tt.capacity, tt.avgHeadcount, tt.distinctAuditors)
+			if math.Abs(float64(got-tt.want)) > 1e-4 {
+				t.Errorf("Score(%q, %d, %v, %d) = %v, want %v",
+					tt.policy, tt.capacity, tt.avgHeadcount, tt.distinctAuditors, got, tt.want)
+			}
+		})
+	}
+}
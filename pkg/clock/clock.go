@@ -0,0 +1,76 @@
+package clock
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// OverrideHeader 管理员或测试环境可通过该请求头指定"当前时间"（ISO8601）
+const OverrideHeader = "X-Clock-Now"
+
+// Clock 时间来源抽象，所有与"当前时间"相关的业务逻辑都应通过它获取时间
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// Real 使用系统时间的默认实现
+var Real Clock = realClock{}
+
+type fixedClock struct {
+	t time.Time
+}
+
+func (f fixedClock) Now() time.Time { return f.t }
+
+// Fixed 返回一个始终停在 t 的时钟，用于测试与时间覆盖
+func Fixed(t time.Time) Clock {
+	return fixedClock{t: t}
+}
+
+type ctxKey struct{}
+
+// WithClock 将时钟放入 context，后续通过 Now(ctx) 获取时间
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, ctxKey{}, c)
+}
+
+// FromContext 取出 context 中的时钟，没有则返回 Real
+func FromContext(ctx context.Context) Clock {
+	if ctx != nil {
+		if c, ok := ctx.Value(ctxKey{}).(Clock); ok && c != nil {
+			return c
+		}
+	}
+	return Real
+}
+
+// Now 获取 ctx 对应时钟的当前时间
+func Now(ctx context.Context) time.Time {
+	return FromContext(ctx).Now()
+}
+
+// 支持的时间格式：带时区的 RFC3339，以及按本地时区解释的不带时区格式
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// Parse 解析 ISO8601 时间字符串，未带时区时按本地时区处理
+func Parse(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(time.Local), nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间 %q，请使用 ISO8601 格式（如 2025-09-08T10:00:00+08:00）", s)
+}
@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNowDefaultsToReal(t *testing.T) {
+	before := time.Now()
+	got := Now(context.Background())
+	if got.Before(before) || time.Since(got) > time.Second {
+		t.Fatalf("expected real time, got %v", got)
+	}
+}
+
+func TestWithClockOverridesNow(t *testing.T) {
+	fixed := time.Date(2025, time.September, 8, 10, 0, 0, 0, time.Local)
+	ctx := WithClock(context.Background(), Fixed(fixed))
+	if got := Now(ctx); !got.Equal(fixed) {
+		t.Fatalf("Now = %v, want %v", got, fixed)
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2025-09-08T10:00:00+08:00", time.Date(2025, 9, 8, 2, 0, 0, 0, time.UTC)},
+		{"2025-09-08T10:00:00", time.Date(2025, 9, 8, 10, 0, 0, 0, time.Local)},
+		{"2025-09-08 19:30", time.Date(2025, 9, 8, 19, 30, 0, 0, time.Local)},
+	}
+	for _, tc := range cases {
+		got, err := Parse(tc.in)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tc.in, err)
+		}
+		if !got.Equal(tc.want) {
+			t.Fatalf("Parse(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+
+	if _, err := Parse("next monday"); err == nil {
+		t.Fatalf("expected error for invalid input")
+	}
+}
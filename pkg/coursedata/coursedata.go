@@ -0,0 +1,58 @@
+// Package coursedata 解析教务数据中的教室编号、学分等字段，展示与数据质量检查共用同一套解析逻辑
+package coursedata
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var reg = regexp.MustCompile(`\d+`)
+
+// ExtractFloorNumber 从教室编号提取楼层号，无法推断时默认第一层
+func ExtractFloorNumber(classroom string) int {
+	if floor, ok := parseFloorNumber(classroom); ok {
+		return floor
+	}
+	return 1 // 默认第一层
+}
+
+// HasFloorNumber 教室编号能否推断出楼层，与 ExtractFloorNumber 使用同一解析逻辑
+func HasFloorNumber(classroom string) bool {
+	_, ok := parseFloorNumber(classroom)
+	return ok
+}
+
+// parseFloorNumber 常见格式: A101, 201, 3-101 等，取第一个数字序列中第一个不为0的数字作为楼层号
+func parseFloorNumber(classroom string) (int, bool) {
+	numStr := reg.FindString(classroom)
+	for i := 0; i < len(numStr); i++ {
+		if numStr[i] != '0' && unicode.IsDigit(rune(numStr[i])) {
+			return int(numStr[i] - '0'), true
+		}
+	}
+	return 0, false
+}
+
+// ParseCreditsStrict 解析学分，无法解析或为负数时返回错误
+func ParseCreditsStrict(creditStr string) (float32, error) {
+	credit, err := strconv.ParseFloat(strings.TrimSpace(creditStr), 32)
+	if err != nil {
+		return 0, err
+	}
+	if credit < 0 {
+		return 0, fmt.Errorf("学分为负数: %s", creditStr)
+	}
+	return float32(credit), nil
+}
+
+// ParseCredits 解析学分字符串为浮点数，与数据检查使用同一解析逻辑，无法解析或为负数时返回 0
+func ParseCredits(creditStr string) float32 {
+	credit, err := ParseCreditsStrict(creditStr)
+	if err != nil {
+		return 0.0
+	}
+	return credit
+}
@@ -0,0 +1,50 @@
+package coursedata
+
+import "testing"
+
+func TestFloorNumberParsersAgree(t *testing.T) {
+	tests := []struct {
+		classroom string
+		floor     int
+		ok        bool
+	}{
+		{"A101", 1, true},
+		{"305", 3, true},
+		{"3-101", 3, true},
+		{"B0204", 2, true},
+		{"000", 1, false},
+		{"报告厅", 1, false},
+		{"", 1, false},
+	}
+	for _, tt := range tests {
+		if got := ExtractFloorNumber(tt.classroom); got != tt.floor {
+			t.Errorf("ExtractFloorNumber(%q) = %d, want %d", tt.classroom, got, tt.floor)
+		}
+		if got := HasFloorNumber(tt.classroom); got != tt.ok {
+			t.Errorf("HasFloorNumber(%q) = %v, want %v", tt.classroom, got, tt.ok)
+		}
+	}
+}
+
+func TestCreditParsersAgree(t *testing.T) {
+	tests := []struct {
+		credit string
+		want   float32
+		ok     bool
+	}{
+		{"2", 2, true},
+		{" 3.5 ", 3.5, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"两学分", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		if got := ParseCredits(tt.credit); got != tt.want {
+			t.Errorf("ParseCredits(%q) = %v, want %v", tt.credit, got, tt.want)
+		}
+		if _, err := ParseCreditsStrict(tt.credit); (err == nil) != tt.ok {
+			t.Errorf("ParseCreditsStrict(%q) error = %v, want ok %v", tt.credit, err, tt.ok)
+		}
+	}
+}
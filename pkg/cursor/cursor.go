@@ -0,0 +1,37 @@
+// Package cursor 实现列表接口的不透明游标：记录上一页最后一条数据的排序键与ID，用于 keyset 分页
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalid 游标无法解析，或与当前排序方式不一致
+var ErrInvalid = errors.New("cursor: 无效的游标")
+
+// Cursor 上一页最后一条数据的位置
+type Cursor struct {
+	Sort  string `json:"s"` // 生成游标时的排序方式，换了排序方式的游标不能继续使用
+	Value string `json:"v"` // 排序键的值
+	ID    uint32 `json:"i"` // 排序键相同时以ID区分先后
+}
+
+// Encode 编码为可放在 URL 中的字符串
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode 解码游标，并校验其排序方式与 sort 一致
+func Decode(s, sort string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalid
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort || c.ID == 0 {
+		return Cursor{}, ErrInvalid
+	}
+	return c, nil
+}
@@ -0,0 +1,33 @@
+package cursor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	c := Cursor{Sort: "createdAt_desc", Value: "2025-09-08T08:00:00.123+08:00", ID: 42}
+	encoded := c.Encode()
+	got, err := Decode(encoded, "createdAt_desc")
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got != c {
+		t.Errorf("Decode = %+v, want %+v", got, c)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	valid := Cursor{Sort: "likesCount_desc", Value: "3", ID: 7}.Encode()
+	cases := map[string]struct{ in, sort string }{
+		"排序方式不同":   {valid, "createdAt_desc"},
+		"非 base64": {"***", "likesCount_desc"},
+		"非 JSON":   {"bm90LWpzb24", "likesCount_desc"},
+		"缺少ID":     {Cursor{Sort: "likesCount_desc", Value: "3"}.Encode(), "likesCount_desc"},
+	}
+	for name, c := range cases {
+		if _, err := Decode(c.in, c.sort); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: err = %v, want ErrInvalid", name, err)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package fulltext 解析用户输入的搜索语法，生成 MySQL BOOLEAN MODE 全文检索表达式，并生成带高亮的摘要
+//
+// 支持的语法：
+//
+//	数据结构 算法      多个词须同时出现
+//	"操作 系统"       引号内作为整体短语匹配
+//	-期末 -"往年 试卷" 排除包含该词或短语的结果
+package fulltext
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query 解析后的搜索条件
+type Query struct {
+	Include []string // 必须出现的词或短语
+	Exclude []string // 不能出现的词或短语
+}
+
+// booleanOperators MySQL BOOLEAN MODE 中有特殊含义的字符，出现在用户输入中时按分隔符处理
+const booleanOperators = `+-<>()~*"@`
+
+// Parse 解析搜索语法，重复的词只保留一次
+func Parse(s string) Query {
+	var q Query
+	seen := make(map[string]bool)
+	add := func(text string, exclude bool) {
+		text = normalizeSpace(strings.Map(func(r rune) rune {
+			if strings.ContainsRune(booleanOperators, r) {
+				return ' '
+			}
+			return r
+		}, text))
+		if text == "" {
+			return
+		}
+		key := strings.ToLower(text)
+		if exclude {
+			key = "-" + key
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		if exclude {
+			q.Exclude = append(q.Exclude, text)
+		} else {
+			q.Include = append(q.Include, text)
+		}
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		exclude := false
+		if runes[i] == '-' {
+			exclude = true
+			i++
+			if i >= len(runes) {
+				break
+			}
+		}
+		if runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			add(string(runes[i+1:min(end, len(runes))]), exclude)
+			i = end + 1
+			continue
+		}
+		end := i
+		for end < len(runes) && !unicode.IsSpace(runes[end]) {
+			end++
+		}
+		add(string(runes[i:end]), exclude)
+		i = end
+	}
+	return q
+}
+
+// IsEmpty 没有任何搜索条件
+func (q Query) IsEmpty() bool {
+	return len(q.Include) == 0 && len(q.Exclude) == 0
+}
+
+// Against 生成 BOOLEAN MODE 的检索表达式。ngram 解析器无法检索少于 minTokenLen 个字符的词，
+// 这些词与只有排除条件的情况一样，通过 shortInclude/shortExclude 返回，由调用方改用 LIKE 匹配。
+// 没有可用于全文检索的必选词时 against 为空：BOOLEAN MODE 下只有排除词的表达式不会匹配任何行。
+func (q Query) Against(minTokenLen int) (against string, shortInclude, shortExclude []string) {
+	var parts []string
+	for _, text := range q.Include {
+		if utf8.RuneCountInString(text) < minTokenLen {
+			shortInclude = append(shortInclude, text)
+			continue
+		}
+		parts = append(parts, `+"`+text+`"`)
+	}
+	if len(parts) == 0 {
+		return "", shortInclude, q.Exclude
+	}
+	for _, text := range q.Exclude {
+		if utf8.RuneCountInString(text) < minTokenLen {
+			shortExclude = append(shortExclude, text)
+			continue
+		}
+		parts = append(parts, `-"`+text+`"`)
+	}
+	return strings.Join(parts, " "), shortInclude, shortExclude
+}
+
+var tagReg = regexp.MustCompile(`<[^>]*>`)
+
+// PlainText 去掉 HTML 标签并合并空白，用于生成摘要
+func PlainText(s string) string {
+	return normalizeSpace(html.UnescapeString(tagReg.ReplaceAllString(s, " ")))
+}
+
+// Highlight 在纯文本中为所有命中的词加上 <mark> 标签，其余内容做 HTML 转义。
+// maxRunes > 0 时截取以第一个命中位置为中心、约 maxRunes 个字符的片段，被截断的一侧补省略号。
+func Highlight(text string, words []string, maxRunes int) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// 极少数字符转小写后长度变化，此时退回按原文匹配
+		lower = runes
+	}
+
+	type span struct{ start, end int }
+	var spans []span
+	for _, word := range words {
+		w := []rune(strings.ToLower(word))
+		if len(w) == 0 {
+			continue
+		}
+		for i := 0; i+len(w) <= len(lower); i++ {
+			if runesEqual(lower[i:i+len(w)], w) {
+				spans = append(spans, span{i, i + len(w)})
+				i += len(w) - 1
+			}
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	// 合并重叠的命中区间
+	merged := spans[:0]
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start <= merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	from, to := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		center := 0
+		if len(merged) > 0 {
+			center = merged[0].start
+		}
+		from = max(0, center-maxRunes/4)
+		to = min(len(runes), from+maxRunes)
+		from = max(0, to-maxRunes)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, s := range merged {
+		if s.end <= from || s.start >= to {
+			continue
+		}
+		start, end := max(s.start, from), min(s.end, to)
+		b.WriteString(html.EscapeString(string(runes[pos:start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[start:end])))
+		b.WriteString("</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
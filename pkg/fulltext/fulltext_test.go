@@ -0,0 +1,74 @@
+package fulltext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in      string
+		include []string
+		exclude []string
+	}{
+		{"数据结构 算法", []string{"数据结构", "算法"}, nil},
+		{`"操作  系统" -期末`, []string{"操作 系统"}, []string{"期末"}},
+		{`-"往年 试卷" 高数`, []string{"高数"}, []string{"往年 试卷"}},
+		{"a+b (c) ~d*", []string{"a b", "c", "d"}, nil},
+		{"高数 高数 -", []string{"高数"}, nil},
+		{`"未闭合 短语`, []string{"未闭合 短语"}, nil},
+		{"   ", nil, nil},
+	}
+	for _, c := range cases {
+		q := Parse(c.in)
+		if !reflect.DeepEqual(q.Include, c.include) || !reflect.DeepEqual(q.Exclude, c.exclude) {
+			t.Errorf("Parse(%q) = %+v, want include=%v exclude=%v", c.in, q, c.include, c.exclude)
+		}
+	}
+}
+
+func TestAgainst(t *testing.T) {
+	against, shortInc, shortExc := Parse(`数据结构 "操作 系统" 树 -期末 -考`).Against(2)
+	if want := `+"数据结构" +"操作 系统" -"期末"`; against != want {
+		t.Errorf("against = %q, want %q", against, want)
+	}
+	if !reflect.DeepEqual(shortInc, []string{"树"}) || !reflect.DeepEqual(shortExc, []string{"考"}) {
+		t.Errorf("short = %v %v", shortInc, shortExc)
+	}
+
+	// 只有排除条件时不能生成 BOOLEAN MODE 表达式
+	against, shortInc, shortExc = Parse("-期末").Against(2)
+	if against != "" || shortInc != nil || !reflect.DeepEqual(shortExc, []string{"期末"}) {
+		t.Errorf("exclude only: %q %v %v", against, shortInc, shortExc)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	got := Highlight("期末复习：数据结构与算法 <重点>", []string{"数据结构", "算法"}, 0)
+	want := "期末复习：<mark>数据结构</mark>与<mark>算法</mark> &lt;重点&gt;"
+	if got != want {
+		t.Errorf("Highlight = %q, want %q", got, want)
+	}
+
+	if got := Highlight("Go and GO", []string{"go"}, 0); got != "<mark>Go</mark> and <mark>GO</mark>" {
+		t.Errorf("case-insensitive Highlight = %q", got)
+	}
+
+	// 重叠的命中合并为一个区间
+	if got := Highlight("数据结构", []string{"数据", "据结"}, 0); got != "<mark>数据结</mark>构" {
+		t.Errorf("overlap Highlight = %q", got)
+	}
+
+	// 截取命中位置附近的片段
+	text := "一二三四五六七八九十关键词一二三四五六七八九十"
+	got = Highlight(text, []string{"关键词"}, 8)
+	if want := "…九十<mark>关键词</mark>一二三…"; got != want {
+		t.Errorf("snippet Highlight = %q, want %q", got, want)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	if got := PlainText("<p>数据&amp;结构</p>\n<b>复习</b>"); got != "数据&结构 复习" {
+		t.Errorf("PlainText = %q", got)
+	}
+}
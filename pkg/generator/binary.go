@@ -29,9 +29,23 @@ func NearestToDisplay(lessonNum int, binNum uint32) string {
 	return fmt.Sprintf("第 %d-%d 节", begin+1, end-1)
 }
 
+// MaxWeekNum 和 MaxLessonNum 是 week_and_time 能表示的最大周次和节次
+const (
+	MaxWeekNum   = 19
+	MaxLessonNum = 13
+)
+
+// IsTermWeek 判断周次是否落在学期内（第 1-19 周）
+func IsTermWeek(weekNum int) bool {
+	return weekNum >= 1 && weekNum <= MaxWeekNum
+}
+
 // IsWeekLessonMatch 判断周次和节次是否在所给的二进制数中
 func IsWeekLessonMatch(weekNum, lessonNum int, binNum uint32) bool {
-	if lessonNum < -1 {
+	if lessonNum < -1 || lessonNum == 0 || lessonNum > MaxLessonNum {
+		return false
+	}
+	if weekNum != -1 && !IsTermWeek(weekNum) {
 		return false
 	}
 
@@ -78,11 +92,29 @@ func Bin2WeekLesson(binNum uint32) ([]int, []int) {
 
 }
 
+// LessonRuns 将节次拆分为连续的区间，如第 1-2 节和第 5-6 节返回 [[1 2] [5 6]]
+func LessonRuns(binNum uint32) [][2]int {
+	runs := make([][2]int, 0)
+	for i := 1; i <= 13; i++ {
+		if (1<<(i-1))&binNum == 0 {
+			continue
+		}
+		if n := len(runs); n > 0 && runs[n-1][1] == i-1 {
+			runs[n-1][1] = i
+			continue
+		}
+		runs = append(runs, [2]int{i, i})
+	}
+	return runs
+}
+
+// WeekLesson2Bin 将周次和节次编码为 week_and_time，超出范围的值会被忽略
 func WeekLesson2Bin(weekNums, lessonNums []int) uint32 {
 	var res uint32 = 0
 	for _, num := range weekNums {
-		if num > 19 {
-			log.Fatal("weekNum不能超过19", num)
+		if !IsTermWeek(num) {
+			log.Println("weekNum超出范围，已忽略", num)
+			continue
 		}
 		if res&((1<<31)>>(num-1)) != 0 {
 			log.Println("weekNum重复覆盖！", weekNums)
@@ -91,8 +123,9 @@ func WeekLesson2Bin(weekNums, lessonNums []int) uint32 {
 	}
 
 	for _, num := range lessonNums {
-		if num > 13 {
-			log.Fatal("lessonNums不能超过13", num)
+		if num < 1 || num > MaxLessonNum {
+			log.Println("lessonNum超出范围，已忽略", num)
+			continue
 		}
 		if res&(1<<(num-1)) != 0 {
 			log.Println("lessonNum重复覆盖！", lessonNums)
@@ -102,3 +135,13 @@ func WeekLesson2Bin(weekNums, lessonNums []int) uint32 {
 
 	return res
 }
+
+const (
+	weekMask   uint32 = 0xFFFFE000 // 高 19 位：周次
+	lessonMask uint32 = 0x00001FFF // 低 13 位：节次
+)
+
+// IsTimeConflict 判断同一天的两个时间段是否冲突：周次与节次同时有交集
+func IsTimeConflict(a, b uint32) bool {
+	return a&b&weekMask != 0 && a&b&lessonMask != 0
+}
@@ -73,3 +73,64 @@ func TestNearestToDisplayRanges(t *testing.T) {
 		t.Fatalf("NearestToDisplay all-day unexpected: %s", s3)
 	}
 }
+
+func TestIsTimeConflict(t *testing.T) {
+	base := WeekLesson2Bin([]int{1, 2, 3}, []int{3, 4})
+
+	cases := []struct {
+		name  string
+		other uint32
+		want  bool
+	}{
+		{"same slot", base, true},
+		{"overlapping week and lesson", WeekLesson2Bin([]int{3, 4}, []int{4, 5}), true},
+		{"same weeks, different lessons", WeekLesson2Bin([]int{1, 2, 3}, []int{5, 6}), false},
+		{"same lessons, different weeks", WeekLesson2Bin([]int{10, 11}, []int{3, 4}), false},
+		{"empty", 0, false},
+	}
+	for _, c := range cases {
+		if got := IsTimeConflict(base, c.other); got != c.want {
+			t.Fatalf("%s: got %v want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestLessonRuns(t *testing.T) {
+	tests := []struct {
+		name    string
+		lessons []int
+		want    [][2]int
+	}{
+		{"无节次", nil, [][2]int{}},
+		{"单个区间", []int{3, 4, 5}, [][2]int{{3, 5}}},
+		{"两个区间", []int{1, 2, 5, 6}, [][2]int{{1, 2}, {5, 6}}},
+		{"单节与末节", []int{1, 7, 12, 13}, [][2]int{{1, 1}, {7, 7}, {12, 13}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LessonRuns(WeekLesson2Bin([]int{1, 2}, tt.lessons))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("LessonRuns(%v) = %v, want %v", tt.lessons, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutOfRangeWeekLesson(t *testing.T) {
+	bin := WeekLesson2Bin([]int{1, 2}, []int{3, 4})
+	for _, w := range []int{0, -3, 20, 32, 59} {
+		if IsWeekLessonMatch(w, 3, bin) || IsWeekLessonMatch(w, -1, bin) {
+			t.Fatalf("week %d should never match", w)
+		}
+	}
+	for _, l := range []int{0, 14} {
+		if IsWeekLessonMatch(1, l, bin) {
+			t.Fatalf("lesson %d should never match", l)
+		}
+	}
+
+	got := WeekLesson2Bin([]int{0, 1, 20, 59}, []int{-1, 3, 14})
+	if got != WeekLesson2Bin([]int{1}, []int{3}) {
+		t.Fatalf("out-of-range values should be ignored, got %032b", got)
+	}
+}
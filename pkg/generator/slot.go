@@ -0,0 +1,63 @@
+package generator
+
+// DaySlot 一个上课时间段：星期几（0=周日）及其 week_and_time
+type DaySlot struct {
+	DayOfWeek   int
+	WeekAndTime uint32
+}
+
+// CurrentSlot 返回第 weekNum 周星期 weekday 第 lessonNum 节正在上课的时间段下标，学期外或没有课时返回 -1
+func CurrentSlot(slots []DaySlot, weekNum, weekday, lessonNum int) int {
+	if !IsTermWeek(weekNum) {
+		return -1
+	}
+	for i, slot := range slots {
+		if slot.DayOfWeek == weekday && IsWeekLessonMatch(weekNum, lessonNum, slot.WeekAndTime) {
+			return i
+		}
+	}
+	return -1
+}
+
+// NextSlotStart 从当前节次之后往后找最近的一节课，最多向后看 7 天，且不越过第 19 周。
+// 返回时间段下标、所在周次和开始节次，学期外或找不到时下标为 -1
+func NextSlotStart(slots []DaySlot, weekNum, weekday, lessonNum int) (idx, week, lesson int) {
+	if !IsTermWeek(weekNum) {
+		return -1, 0, 0
+	}
+	for offset := 0; offset <= 7; offset++ {
+		// 周次以周一为起点，周日（0）是一周的最后一天
+		dayIndex := (weekday+6)%7 + offset
+		week := weekNum + dayIndex/7
+		if week > MaxWeekNum {
+			break
+		}
+		day := (dayIndex%7 + 1) % 7
+
+		best, bestLesson := -1, 0
+		for i, slot := range slots {
+			if slot.DayOfWeek != day || !IsWeekLessonMatch(week, -1, slot.WeekAndTime) {
+				continue
+			}
+			_, lessons := Bin2WeekLesson(slot.WeekAndTime)
+			for _, l := range lessons {
+				// 当天只找当前节次之后开始的课
+				if offset == 0 && l <= lessonNum {
+					continue
+				}
+				// 跳过连续时段中间的节次，只取每节课的开始
+				if l > 1 && IsWeekLessonMatch(-1, l-1, slot.WeekAndTime) {
+					continue
+				}
+				if best == -1 || l < bestLesson {
+					best, bestLesson = i, l
+				}
+				break
+			}
+		}
+		if best != -1 {
+			return best, week, bestLesson
+		}
+	}
+	return -1, 0, 0
+}
@@ -0,0 +1,45 @@
+package generator
+
+import "testing"
+
+func TestRoomSlots(t *testing.T) {
+	slots := []DaySlot{
+		{DayOfWeek: 1, WeekAndTime: WeekLesson2Bin([]int{1, 2, 19}, []int{3, 4})},
+		{DayOfWeek: 3, WeekAndTime: WeekLesson2Bin([]int{1, 2}, []int{1, 2, 7, 8})},
+	}
+
+	if got := CurrentSlot(slots, 1, 1, 4); got != 0 {
+		t.Fatalf("CurrentSlot in class = %d, want 0", got)
+	}
+	if got := CurrentSlot(slots, 1, 1, 5); got != -1 {
+		t.Fatalf("CurrentSlot after class = %d, want -1", got)
+	}
+
+	// 周一第 4 节之后，下一节是周三第 1 节
+	if idx, week, lesson := NextSlotStart(slots, 1, 1, 4); idx != 1 || week != 1 || lesson != 1 {
+		t.Fatalf("NextSlotStart = (%d, %d, %d), want (1, 1, 1)", idx, week, lesson)
+	}
+	// 周三第 2 节之后，同一天还有第 7 节
+	if idx, week, lesson := NextSlotStart(slots, 2, 3, 2); idx != 1 || week != 2 || lesson != 7 {
+		t.Fatalf("NextSlotStart = (%d, %d, %d), want (1, 2, 7)", idx, week, lesson)
+	}
+	// 第 19 周周一之后不再越过学期末
+	if idx, _, _ := NextSlotStart(slots, 19, 1, 4); idx != -1 {
+		t.Fatalf("NextSlotStart past week 19 = %d, want -1", idx)
+	}
+}
+
+func TestRoomSlotsOutsideTerm(t *testing.T) {
+	slots := []DaySlot{
+		// 与第 13 节共用位的周次不能被误判为有课
+		{DayOfWeek: 1, WeekAndTime: WeekLesson2Bin([]int{1}, []int{1, 13})},
+	}
+	for _, week := range []int{-2, 0, 20, 32, 59} {
+		if got := CurrentSlot(slots, week, 1, 13); got != -1 {
+			t.Errorf("CurrentSlot(week %d) = %d, want -1", week, got)
+		}
+		if idx, _, _ := NextSlotStart(slots, week, 0, 1); idx != -1 {
+			t.Errorf("NextSlotStart(week %d) = %d, want -1", week, idx)
+		}
+	}
+}
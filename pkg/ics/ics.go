@@ -0,0 +1,79 @@
+// Package ics 生成 iCalendar（RFC 5545）日历文件
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// Event 日历中的一个事件
+type Event struct {
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+// Calendar 日历
+type Calendar struct {
+	Name   string
+	ProdID string
+	Events []Event
+}
+
+const timeLayout = "20060102T150405Z"
+
+// Bytes 序列化为 .ics 文件内容，时间统一转换为 UTC
+func (c *Calendar) Bytes(now time.Time) []byte {
+	var b bytes.Buffer
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+escape(c.ProdID))
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escape(c.Name))
+	}
+	stamp := now.UTC().Format(timeLayout)
+	for _, e := range c.Events {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+escape(e.UID))
+		writeLine(&b, "DTSTAMP:"+stamp)
+		writeLine(&b, "DTSTART:"+e.Start.UTC().Format(timeLayout))
+		writeLine(&b, "DTEND:"+e.End.UTC().Format(timeLayout))
+		writeLine(&b, "SUMMARY:"+escape(e.Summary))
+		if e.Location != "" {
+			writeLine(&b, "LOCATION:"+escape(e.Location))
+		}
+		if e.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+escape(e.Description))
+		}
+		writeLine(&b, "END:VEVENT")
+	}
+	writeLine(&b, "END:VCALENDAR")
+	return b.Bytes()
+}
+
+// escape 转义文本值中的特殊字符
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeLine 写入一行，超过 75 字节时按规范折行，且不截断 UTF-8 字符
+func writeLine(b *bytes.Buffer, line string) {
+	const limit = 75
+	width := 0
+	for _, r := range line {
+		n := len(string(r))
+		if width+n > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	b.WriteString("\r\n")
+}
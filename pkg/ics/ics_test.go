@@ -0,0 +1,50 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestCalendarBytes(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	cal := &Calendar{
+		Name:   "我的考试",
+		ProdID: "-//test//CN",
+		Events: []Event{{
+			UID:         "exam-1@test",
+			Summary:     "高等数学, 期末考试",
+			Location:    "教一楼 3-101",
+			Description: "座位号见准考证;\n请带学生证",
+			Start:       time.Date(2026, 1, 10, 9, 0, 0, 0, loc),
+			End:         time.Date(2026, 1, 10, 11, 0, 0, 0, loc),
+		}},
+	}
+	out := string(cal.Bytes(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART:20260110T010000Z\r\n",
+		"DTEND:20260110T030000Z\r\n",
+		`SUMMARY:高等数学\, 期末考试`,
+		`DESCRIPTION:座位号见准考证\;\n请带学生证`,
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Bytes() missing %q", want)
+		}
+	}
+}
+
+func TestWriteLineFolding(t *testing.T) {
+	cal := &Calendar{Events: []Event{{Summary: strings.Repeat("考", 60)}}}
+	for _, line := range strings.Split(string(cal.Bytes(time.Now())), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 bytes: %d", len(line))
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("folding split a UTF-8 character: %q", line)
+		}
+	}
+}
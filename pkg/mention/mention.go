@@ -0,0 +1,43 @@
+// Package mention 从帖子、评论正文中提取 @用户名
+package mention
+
+import (
+	"strings"
+	"unicode"
+)
+
+// isNameRune 用户名中允许出现的字符：字母（含中文）、数字以及 _ - .
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+// Extract 按出现顺序返回去重后的被提及用户名，最多 limit 个（<=0 表示不限制）。
+// @ 前紧跟英文字母或数字时（如邮箱地址）不视为提及，用户名末尾的 . 视为句号
+func Extract(text string, limit int) []string {
+	var names []string
+	seen := make(map[string]bool)
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' && runes[i] != '＠' {
+			continue
+		}
+		if i > 0 && runes[i-1] < unicode.MaxASCII && (unicode.IsLetter(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && isNameRune(runes[j]) {
+			j++
+		}
+		name := strings.TrimRight(string(runes[i+1:j]), ".")
+		i = j - 1
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		if limit > 0 && len(names) >= limit {
+			break
+		}
+	}
+	return names
+}
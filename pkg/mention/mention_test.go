@@ -0,0 +1,26 @@
+package mention
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	cases := []struct {
+		text  string
+		limit int
+		want  []string
+	}{
+		{"@alice 你看这个", 0, []string{"alice"}},
+		{"感谢@张三 和 @bob_1，还有@张三", 0, []string{"张三", "bob_1"}},
+		{"联系 me@example.com 或 @carol.", 0, []string{"carol"}},
+		{"＠dave：收到", 0, []string{"dave"}},
+		{"@a @b @c", 2, []string{"a", "b"}},
+		{"单独的 @ 不算", 0, nil},
+	}
+	for _, c := range cases {
+		if got := Extract(c.text, c.limit); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Extract(%q, %d) = %v, want %v", c.text, c.limit, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package qrcode 纯 Go 实现的二维码编码器（字节模式，版本 1-10），
+// 用于生成教室门牌二维码，不依赖外部服务
+package qrcode
+
+import (
+	"errors"
+)
+
+// Level 纠错等级
+type Level int
+
+const (
+	LevelL Level = iota // 约 7% 纠错
+	LevelM              // 约 15% 纠错
+	LevelQ              // 约 25% 纠错
+	LevelH              // 约 30% 纠错
+)
+
+// MaxVersion 支持的最大版本
+const MaxVersion = 10
+
+// ErrTooLong 内容超出支持的最大容量
+var ErrTooLong = errors.New("qrcode: 内容过长")
+
+// formatBits 纠错等级在格式信息中的编码
+var formatBits = [4]int{LevelL: 1, LevelM: 0, LevelQ: 3, LevelH: 2}
+
+// blockSpec 某版本某纠错等级的分块方式：每块纠错码字数，以及两组数据块的块数与每块数据码字数
+type blockSpec struct {
+	ecPerBlock int
+	g1Blocks   int
+	g1Data     int
+	g2Blocks   int
+	g2Data     int
+}
+
+func (b blockSpec) dataCodewords() int {
+	return b.g1Blocks*b.g1Data + b.g2Blocks*b.g2Data
+}
+
+// blockSpecs[version-1][level]
+var blockSpecs = [MaxVersion][4]blockSpec{
+	{{7, 1, 19, 0, 0}, {10, 1, 16, 0, 0}, {13, 1, 13, 0, 0}, {17, 1, 9, 0, 0}},
+	{{10, 1, 34, 0, 0}, {16, 1, 28, 0, 0}, {22, 1, 22, 0, 0}, {28, 1, 16, 0, 0}},
+	{{15, 1, 55, 0, 0}, {26, 1, 44, 0, 0}, {18, 2, 17, 0, 0}, {22, 2, 13, 0, 0}},
+	{{20, 1, 80, 0, 0}, {18, 2, 32, 0, 0}, {26, 2, 24, 0, 0}, {16, 4, 9, 0, 0}},
+	{{26, 1, 108, 0, 0}, {24, 2, 43, 0, 0}, {18, 2, 15, 2, 16}, {22, 2, 11, 2, 12}},
+	{{18, 2, 68, 0, 0}, {16, 4, 27, 0, 0}, {24, 4, 19, 0, 0}, {28, 4, 15, 0, 0}},
+	{{20, 2, 78, 0, 0}, {18, 4, 31, 0, 0}, {18, 2, 14, 4, 15}, {26, 4, 13, 1, 14}},
+	{{24, 2, 97, 0, 0}, {22, 2, 38, 2, 39}, {22, 4, 18, 2, 19}, {26, 4, 14, 2, 15}},
+	{{30, 2, 116, 0, 0}, {22, 3, 36, 2, 37}, {20, 4, 16, 4, 17}, {24, 4, 12, 4, 13}},
+	{{18, 2, 68, 2, 69}, {26, 4, 43, 1, 44}, {24, 6, 19, 2, 20}, {28, 6, 15, 2, 16}},
+}
+
+// alignmentPositions[version-1] 校正图形中心坐标
+var alignmentPositions = [MaxVersion][]int{
+	{},
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+}
+
+// Code 编码后的二维码矩阵
+type Code struct {
+	Version int
+	Size    int
+	modules [][]bool
+}
+
+// Dark 返回 (x, y) 处模块是否为深色，x 为列，y 为行
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y][x]
+}
+
+// Encode 以字节模式编码内容，自动选择能容纳内容的最小版本
+func Encode(content string, level Level) (*Code, error) {
+	data := []byte(content)
+	version := 0
+	for v := 1; v <= MaxVersion; v++ {
+		if 4+countBits(v)+8*len(data) <= blockSpecs[v-1][level].dataCodewords()*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(encodeData(data, version, level), version, level)
+	m := newMatrix(version)
+	m.drawFunctionPatterns()
+	m.drawCodewords(codewords)
+
+	// 选择惩罚分最低的掩模
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		m.applyMask(mask)
+		m.drawFormatBits(level, mask)
+		if p := m.penalty(); bestPenalty < 0 || p < bestPenalty {
+			bestMask, bestPenalty = mask, p
+		}
+		m.applyMask(mask) // 异或两次即撤销
+	}
+	m.applyMask(bestMask)
+	m.drawFormatBits(level, bestMask)
+
+	return &Code{Version: version, Size: m.size, modules: m.modules}, nil
+}
+
+// countBits 字节模式下长度字段的位数
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// bitBuffer 按位追加的缓冲区
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 == 1)
+	}
+}
+
+// encodeData 生成数据码字：模式指示、长度、数据、终止符与填充
+func encodeData(data []byte, version int, level Level) []byte {
+	capacity := blockSpecs[version-1][level].dataCodewords()
+
+	var bits bitBuffer
+	bits.append(0b0100, 4) // 字节模式
+	bits.append(len(data), countBits(version))
+	for _, d := range data {
+		bits.append(int(d), 8)
+	}
+	bits.append(0, min(4, capacity*8-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+
+	result := make([]byte, 0, capacity)
+	for i := 0; i < len(bits); i += 8 {
+		var v byte
+		for j := 0; j < 8; j++ {
+			if bits[i+j] {
+				v |= 1 << (7 - j)
+			}
+		}
+		result = append(result, v)
+	}
+	for pad := byte(0xEC); len(result) < capacity; pad ^= 0xEC ^ 0x11 {
+		result = append(result, pad)
+	}
+	return result
+}
+
+// addErrorCorrection 分块计算纠错码字，并按列交错数据码字与纠错码字
+func addErrorCorrection(data []byte, version int, level Level) []byte {
+	spec := blockSpecs[version-1][level]
+	divisor := rsDivisor(spec.ecPerBlock)
+
+	blocks := make([][]byte, 0, spec.g1Blocks+spec.g2Blocks)
+	ecBlocks := make([][]byte, 0, cap(blocks))
+	offset := 0
+	for i := 0; i < spec.g1Blocks+spec.g2Blocks; i++ {
+		n := spec.g1Data
+		if i >= spec.g1Blocks {
+			n = spec.g2Data
+		}
+		block := data[offset : offset+n]
+		offset += n
+		blocks = append(blocks, block)
+		ecBlocks = append(ecBlocks, rsRemainder(block, divisor))
+	}
+
+	result := make([]byte, 0, len(data)+spec.ecPerBlock*len(blocks))
+	for i := 0; i < max(spec.g1Data, spec.g2Data); i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < spec.ecPerBlock; i++ {
+		for _, ec := range ecBlocks {
+			result = append(result, ec[i])
+		}
+	}
+	return result
+}
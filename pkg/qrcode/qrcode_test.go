@@ -0,0 +1,192 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// "HELLO WORLD" 1-M 的数据码字与纠错码字
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	got := rsRemainder(data, rsDivisor(10))
+	if !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatInfo(t *testing.T) {
+	cases := []struct {
+		level Level
+		mask  int
+		want  int
+	}{
+		{LevelL, 0, 0b111011111000100},
+		{LevelM, 0, 0b101010000010010},
+		{LevelQ, 0, 0b011010101011111},
+		{LevelH, 0, 0b001011010001001},
+		{LevelL, 4, 0b110011000101111},
+		{LevelM, 5, 0b100000011001110},
+		{LevelM, 7, 0b100101010100000},
+	}
+	for _, c := range cases {
+		if got := formatInfo(c.level, c.mask); got != c.want {
+			t.Errorf("formatInfo(%d, %d) = %015b, want %015b", c.level, c.mask, got, c.want)
+		}
+	}
+}
+
+func TestVersionInfo(t *testing.T) {
+	if got, want := versionInfo(7), 0b000111110010010100; got != want {
+		t.Errorf("versionInfo(7) = %018b, want %018b", got, want)
+	}
+	if got, want := versionInfo(10), 0b001010010011010011; got != want {
+		t.Errorf("versionInfo(10) = %018b, want %018b", got, want)
+	}
+}
+
+func TestEncodeDataPadding(t *testing.T) {
+	got := encodeData([]byte("A"), 1, LevelM)
+	// 0100 00000001 01000001 0000 -> 0x40 0x14 0x10，之后以 0xEC 0x11 交替填充
+	want := []byte{0x40, 0x14, 0x10, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeData() = % X, want % X", got, want)
+	}
+}
+
+func TestEncodeVersionSelection(t *testing.T) {
+	cases := []struct {
+		n    int
+		want int
+	}{
+		{14, 1}, {15, 2}, {213, 10},
+	}
+	for _, c := range cases {
+		code, err := Encode(strings.Repeat("a", c.n), LevelM)
+		if err != nil {
+			t.Fatalf("Encode(%d bytes) error = %v", c.n, err)
+		}
+		if code.Version != c.want || code.Size != c.want*4+17 {
+			t.Errorf("Encode(%d bytes) version = %d size = %d, want version %d", c.n, code.Version, code.Size, c.want)
+		}
+	}
+	if _, err := Encode(strings.Repeat("a", 214), LevelM); err != ErrTooLong {
+		t.Errorf("Encode(214 bytes) error = %v, want ErrTooLong", err)
+	}
+}
+
+// TestEncodeRoundTrip 从矩阵中读回格式信息与码字，校验与编码时一致
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, content := range []string{"https://example.com/rooms/division_1_教一楼_F3_3-101/now", strings.Repeat("x", 150)} {
+		code, err := Encode(content, LevelM)
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+
+		// 两份格式信息一致，且能解出纠错等级 M
+		first, second := 0, 0
+		for i := 0; i <= 5; i++ {
+			first |= b2i(code.Dark(8, i)) << i
+		}
+		first |= b2i(code.Dark(8, 7))<<6 | b2i(code.Dark(8, 8))<<7 | b2i(code.Dark(7, 8))<<8
+		for i := 9; i < 15; i++ {
+			first |= b2i(code.Dark(14-i, 8)) << i
+		}
+		for i := 0; i < 8; i++ {
+			second |= b2i(code.Dark(code.Size-1-i, 8)) << i
+		}
+		for i := 8; i < 15; i++ {
+			second |= b2i(code.Dark(8, code.Size-15+i)) << i
+		}
+		if first != second {
+			t.Fatalf("format copies differ: %015b vs %015b", first, second)
+		}
+		mask := -1
+		for m := 0; m < 8; m++ {
+			if formatInfo(LevelM, m) == first {
+				mask = m
+			}
+		}
+		if mask < 0 {
+			t.Fatalf("format info %015b does not decode to level M", first)
+		}
+
+		// 去掉掩模后按填充顺序读回码字
+		m := newMatrix(code.Version)
+		m.drawFunctionPatterns()
+		m.modules = code.modules
+		m.applyMask(mask)
+		want := addErrorCorrection(encodeData([]byte(content), code.Version, LevelM), code.Version, LevelM)
+		got := make([]byte, len(want))
+		i := 0
+		for right := m.size - 1; right >= 1; right -= 2 {
+			if right == 6 {
+				right = 5
+			}
+			for vert := 0; vert < m.size; vert++ {
+				y := vert
+				if (right+1)&2 == 0 {
+					y = m.size - 1 - vert
+				}
+				for j := 0; j < 2; j++ {
+					x := right - j
+					if !m.isFunction[y][x] && i < len(got)*8 {
+						got[i>>3] |= byte(b2i(m.modules[y][x])) << (7 - i&7)
+						i++
+					}
+				}
+			}
+		}
+		m.applyMask(mask)
+		if !bytes.Equal(got, want) {
+			t.Errorf("codewords read back differ for %q", content)
+		}
+	}
+}
+
+func TestFinderPatterns(t *testing.T) {
+	code, err := Encode("hello", LevelM)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	for _, corner := range [][2]int{{0, 0}, {code.Size - 7, 0}, {0, code.Size - 7}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := max(abs(dx-3), abs(dy-3))
+				if want := ring != 2; code.Dark(corner[0]+dx, corner[1]+dy) != want {
+					t.Fatalf("finder at %v wrong at (%d,%d)", corner, dx, dy)
+				}
+			}
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	code, err := Encode("hello", LevelM)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if svg := string(code.SVG(8)); !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, "M4 4h1v1h-1z") {
+		t.Errorf("SVG() output unexpected")
+	}
+	data, err := code.PNG(4)
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("PNG() produced invalid png: %v", err)
+	}
+	if want := (code.Size + quietZone*2) * 4; img.Bounds().Dx() != want {
+		t.Errorf("PNG() width = %d, want %d", img.Bounds().Dx(), want)
+	}
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
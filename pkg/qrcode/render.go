@@ -0,0 +1,56 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// quietZone 四周留白的模块数，规范要求至少 4
+const quietZone = 4
+
+// SVG 渲染为 SVG，moduleSize 为每个模块的边长（像素）
+func (c *Code) SVG(moduleSize int) []byte {
+	moduleSize = max(1, moduleSize)
+	dim := (c.Size + quietZone*2) * moduleSize
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		dim, dim, c.Size+quietZone*2, c.Size+quietZone*2)
+	b.WriteString("\n")
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", c.Size+quietZone*2, c.Size+quietZone*2)
+	b.WriteString(`<path fill="#000000" d="`)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x+quietZone, y+quietZone)
+			}
+		}
+	}
+	b.WriteString(`"/>` + "\n</svg>\n")
+	return b.Bytes()
+}
+
+// PNG 渲染为黑白 PNG，moduleSize 为每个模块的边长（像素）
+func (c *Code) PNG(moduleSize int) ([]byte, error) {
+	moduleSize = max(1, moduleSize)
+	dim := (c.Size + quietZone*2) * moduleSize
+	img := image.NewGray(image.Rect(0, 0, dim, dim))
+	for py := 0; py < dim; py++ {
+		for px := 0; px < dim; px++ {
+			v := color.Gray{Y: 0xFF}
+			if c.Dark(px/moduleSize-quietZone, py/moduleSize-quietZone) {
+				v = color.Gray{Y: 0x00}
+			}
+			img.SetGray(px, py, v)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
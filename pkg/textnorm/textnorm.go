@@ -0,0 +1,51 @@
+// Package textnorm 对教务数据中的自由文本（学院、专业等）做归一化，便于把写法不同的同一取值合并
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Key 返回用于比较的归一化键：全角转半角、去掉所有空白、统一括号、ASCII 转小写
+func Key(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		r = toHalfWidth(r)
+		if unicode.IsSpace(r) {
+			continue
+		}
+		switch r {
+		case '【', '〔', '［':
+			r = '('
+		case '】', '〕', '］':
+			r = ')'
+		}
+		if r < unicode.MaxASCII {
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Display 返回用于展示的清理后文本：全角转半角、合并连续空白并去掉首尾空白
+func Display(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		b.WriteRune(toHalfWidth(r))
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// toHalfWidth 将全角 ASCII 字符与全角空格转换为半角
+func toHalfWidth(r rune) rune {
+	switch {
+	case r == '　':
+		return ' '
+	case r >= '！' && r <= '～':
+		return r - 0xFEE0
+	}
+	return r
+}
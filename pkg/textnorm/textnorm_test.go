@@ -0,0 +1,38 @@
+package textnorm
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"计算机学院", "计算机学院"},
+		{" 计算机 学院 ", "计算机学院"},
+		{"计算机学院　", "计算机学院"},
+		{"软件工程（中外合作）", "软件工程(中外合作)"},
+		{"软件工程(中外合作)", "软件工程(中外合作)"},
+		{"软件工程【中外合作】", "软件工程(中外合作)"},
+		{"ＡＩ学院", "ai学院"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := Key(c.in); got != c.want {
+			t.Fatalf("Key(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"  计算机学院  ", "计算机学院"},
+		{"软件工程（中外合作）", "软件工程(中外合作)"},
+		{"数学  与　统计学院", "数学 与 统计学院"},
+	}
+	for _, c := range cases {
+		if got := Display(c.in); got != c.want {
+			t.Fatalf("Display(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package timetable
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// SVG 布局参数
+const (
+	svgMarginLeft = 56
+	svgHeaderTop  = 64
+	svgDayHeight  = 32
+	svgColWidth   = 140
+	svgRowHeight  = 48
+	svgFontFamily = "PingFang SC, Microsoft YaHei, Noto Sans CJK SC, sans-serif"
+)
+
+var svgDayNames = map[int]string{1: "周一", 2: "周二", 3: "周三", 4: "周四", 5: "周五", 6: "周六", 0: "周日"}
+
+// RenderSVG 渲染为 SVG 文档
+func RenderSVG(t *Timetable) []byte {
+	width := svgMarginLeft + svgColWidth*len(DayOrder) + 16
+	gridTop := svgHeaderTop + svgDayHeight
+	height := gridTop + svgRowHeight*MaxLesson + 16
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="%s">`,
+		width, height, width, height, svgFontFamily)
+	b.WriteString("\n")
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="30" font-size="20" font-weight="bold" fill="#222">%s</text>`+"\n", svgMarginLeft, escape(t.Title))
+	if t.Subtitle != "" {
+		fmt.Fprintf(&b, `<text x="%d" y="52" font-size="13" fill="#666">%s</text>`+"\n", svgMarginLeft, escape(t.Subtitle))
+	}
+
+	// 星期表头与竖线
+	for i, day := range DayOrder {
+		x := svgMarginLeft + i*svgColWidth
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="14" text-anchor="middle" fill="#333">%s</text>`+"\n",
+			x+svgColWidth/2, svgHeaderTop+21, svgDayNames[day])
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#e5e5e5"/>`+"\n", x, svgHeaderTop, x, height-16)
+	}
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#e5e5e5"/>`+"\n",
+		width-16, svgHeaderTop, width-16, height-16)
+
+	// 节次与横线
+	for lesson := 1; lesson <= MaxLesson; lesson++ {
+		y := gridTop + (lesson-1)*svgRowHeight
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#e5e5e5"/>`+"\n", svgMarginLeft, y, width-16, y)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="13" text-anchor="middle" fill="#888">%d</text>`+"\n",
+			svgMarginLeft/2, y+svgRowHeight/2+5, lesson)
+	}
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#e5e5e5"/>`+"\n",
+		svgMarginLeft, height-16, width-16, height-16)
+
+	// 课程格子
+	for _, p := range layout(t.Entries) {
+		laneWidth := svgColWidth / p.lanes
+		x := svgMarginLeft + p.col*svgColWidth + p.lane*laneWidth + 2
+		y := gridTop + (p.StartLesson-1)*svgRowHeight + 2
+		w := laneWidth - 4
+		h := (p.EndLesson-p.StartLesson+1)*svgRowHeight - 4
+		c := colorFor(p.ColorKey)
+
+		fmt.Fprintf(&b, `<g><rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="#%02x%02x%02x"/>`,
+			x, y, w, h, c.R, c.G, c.B)
+		// 每个汉字约 12px，按宽度截断
+		maxChars := max(2, w/12)
+		lines := []struct {
+			text, size, weight, fill string
+		}{
+			{truncate(p.Title, maxChars), "12", "bold", "#222"},
+			{truncate(p.Location, maxChars), "11", "normal", "#444"},
+			{truncate(p.Detail, maxChars), "10", "normal", "#666"},
+		}
+		lineY := y + 16
+		for _, line := range lines {
+			if line.text == "" || lineY > y+h-4 {
+				continue
+			}
+			fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="%s" font-weight="%s" fill="%s">%s</text>`,
+				x+5, lineY, line.size, line.weight, line.fill, escape(line.text))
+			lineY += 15
+		}
+		b.WriteString("</g>\n")
+	}
+
+	b.WriteString("</svg>\n")
+	return b.Bytes()
+}
+
+// escape 转义 XML 特殊字符
+func escape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// truncate 按字符数截断，超出时以省略号结尾
+func truncate(s string, maxChars int) string {
+	runes := []rune(s)
+	if len(runes) <= maxChars {
+		return s
+	}
+	return string(runes[:maxChars-1]) + "…"
+}
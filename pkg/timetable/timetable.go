@@ -0,0 +1,125 @@
+// Package timetable 将课表渲染为 SVG 图片，纯 Go 实现，不依赖外部服务
+package timetable
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash/fnv"
+	"image/color"
+	"sort"
+)
+
+// 课表网格的尺寸
+const (
+	MaxLesson = 13 // 每天最多节次
+)
+
+// DayOrder 课表列的顺序：周一到周日（DayOfWeek 0 表示周日）
+var DayOrder = []int{1, 2, 3, 4, 5, 6, 0}
+
+// Entry 课表中的一个格子
+type Entry struct {
+	Title       string `json:"title"`    // 课程名
+	Location    string `json:"location"` // 教学楼 + 教室
+	Detail      string `json:"detail"`   // 周次、老师等附加信息
+	Day         int    `json:"day"`      // 0-6，0 表示周日
+	StartLesson int    `json:"startLesson"`
+	EndLesson   int    `json:"endLesson"`
+	ColorKey    string `json:"colorKey"` // 相同 ColorKey 使用相同颜色，一般为课程编号
+}
+
+// Timetable 一张课表
+type Timetable struct {
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle"`
+	Entries  []Entry `json:"entries"`
+}
+
+// Hash 返回课表内容的哈希，作为渲染结果的缓存键与 ETag
+func (t *Timetable) Hash() string {
+	data, _ := json.Marshal(t)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// palette 浅色调色板，保证深色文字可读
+var palette = []color.RGBA{
+	{0xFD, 0xE2, 0xE4, 0xFF},
+	{0xE2, 0xEC, 0xE9, 0xFF},
+	{0xBE, 0xE1, 0xE6, 0xFF},
+	{0xF0, 0xEF, 0xEB, 0xFF},
+	{0xDF, 0xE7, 0xFD, 0xFF},
+	{0xFF, 0xF1, 0xE6, 0xFF},
+	{0xE8, 0xDF, 0xF5, 0xFF},
+	{0xFC, 0xE1, 0xA4, 0xFF},
+	{0xD3, 0xF8, 0xE2, 0xFF},
+	{0xCD, 0xDA, 0xFD, 0xFF},
+}
+
+// colorFor 根据 key 选取稳定的颜色
+func colorFor(key string) color.RGBA {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
+// placedEntry 计算好位置的格子
+type placedEntry struct {
+	Entry
+	col   int // 列下标（DayOrder 中的位置）
+	lane  int // 同一天内重叠课程的并列序号
+	lanes int // 同一天的并列总数
+}
+
+// layout 计算每个格子所在的列与并列位置；同一天时间重叠的课程并排显示
+func layout(entries []Entry) []placedEntry {
+	colOf := make(map[int]int, len(DayOrder))
+	for i, d := range DayOrder {
+		colOf[d] = i
+	}
+
+	byDay := make(map[int][]placedEntry)
+	for _, e := range entries {
+		col, ok := colOf[e.Day]
+		if !ok || e.StartLesson < 1 || e.EndLesson < e.StartLesson {
+			continue
+		}
+		e.EndLesson = min(e.EndLesson, MaxLesson)
+		byDay[col] = append(byDay[col], placedEntry{Entry: e, col: col})
+	}
+
+	placed := make([]placedEntry, 0, len(entries))
+	for col := range DayOrder {
+		dayEntries := byDay[col]
+		sort.SliceStable(dayEntries, func(i, j int) bool {
+			if dayEntries[i].StartLesson != dayEntries[j].StartLesson {
+				return dayEntries[i].StartLesson < dayEntries[j].StartLesson
+			}
+			return dayEntries[i].Title < dayEntries[j].Title
+		})
+
+		// 贪心分配并列序号：占用到的最后节次小于开始节次的序号可复用
+		laneEnds := make([]int, 0)
+		for i := range dayEntries {
+			lane := -1
+			for l, end := range laneEnds {
+				if end < dayEntries[i].StartLesson {
+					lane = l
+					break
+				}
+			}
+			if lane == -1 {
+				lane = len(laneEnds)
+				laneEnds = append(laneEnds, 0)
+			}
+			laneEnds[lane] = dayEntries[i].EndLesson
+			dayEntries[i].lane = lane
+		}
+		for i := range dayEntries {
+			dayEntries[i].lanes = max(1, len(laneEnds))
+		}
+		placed = append(placed, dayEntries...)
+	}
+	return placed
+}
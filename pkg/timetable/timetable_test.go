@@ -0,0 +1,59 @@
+package timetable
+
+import (
+	"strings"
+	"testing"
+)
+
+func sampleTimetable() *Timetable {
+	return &Timetable{
+		Title: "我的课表 <测试>",
+		Entries: []Entry{
+			{Title: "高等数学", Location: "教一 3-101", Day: 1, StartLesson: 1, EndLesson: 2, ColorKey: "MATH101"},
+			{Title: "大学物理", Location: "教二 B201", Day: 1, StartLesson: 2, EndLesson: 3, ColorKey: "PHY101"},
+			{Title: "体育", Location: "操场", Day: 0, StartLesson: 5, EndLesson: 6, ColorKey: "PE"},
+			{Title: "无效", Day: 9, StartLesson: 1, EndLesson: 2},
+		},
+	}
+}
+
+func TestLayout(t *testing.T) {
+	placed := layout(sampleTimetable().Entries)
+	if len(placed) != 3 {
+		t.Fatalf("layout() placed %d entries, want 3", len(placed))
+	}
+	// 周一的两门课时间重叠，应并排显示
+	if placed[0].lanes != 2 || placed[1].lanes != 2 || placed[0].lane == placed[1].lane {
+		t.Errorf("overlapping entries not split into lanes: %+v %+v", placed[0], placed[1])
+	}
+	// 周日排在最后一列
+	if placed[2].col != len(DayOrder)-1 {
+		t.Errorf("sunday column = %d, want %d", placed[2].col, len(DayOrder)-1)
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	svg := string(RenderSVG(sampleTimetable()))
+	if !strings.HasPrefix(svg, "<svg") {
+		t.Fatalf("RenderSVG() does not start with <svg")
+	}
+	for _, want := range []string{"高等数学", "操场", "&lt;测试&gt;", "周日"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("RenderSVG() missing %q", want)
+		}
+	}
+	if strings.Contains(svg, "无效") {
+		t.Errorf("RenderSVG() rendered entry with invalid day")
+	}
+}
+
+func TestHashStable(t *testing.T) {
+	a, b := sampleTimetable(), sampleTimetable()
+	if a.Hash() != b.Hash() {
+		t.Errorf("Hash() differs for identical timetables")
+	}
+	b.Entries[0].Location = "教一 3-102"
+	if a.Hash() == b.Hash() {
+		t.Errorf("Hash() unchanged after content change")
+	}
+}
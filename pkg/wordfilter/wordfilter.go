@@ -0,0 +1,249 @@
+// Package wordfilter 基于 Aho-Corasick 自动机的敏感词匹配。
+// 匹配前对文本归一化：全角转半角、字母转小写，并跳过空白、标点、符号和零宽字符，
+// 因此在敏感词中插入分隔符或改用全角字符也能命中
+package wordfilter
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Action 命中敏感词后的处理方式
+type Action string
+
+const (
+	ActionMask   Action = "mask"   // 替换为 ***
+	ActionReview Action = "review" // 保存但转人工审核
+	ActionBlock  Action = "block"  // 拒绝提交
+)
+
+// severity 处理方式的严重程度，多个词命中时按最严重的处理
+func (a Action) severity() int {
+	switch a {
+	case ActionBlock:
+		return 3
+	case ActionReview:
+		return 2
+	case ActionMask:
+		return 1
+	}
+	return 0
+}
+
+// Valid 是否为支持的处理方式
+func (a Action) Valid() bool {
+	return a.severity() > 0
+}
+
+// Entry 词库中的一个词
+type Entry struct {
+	Word     string
+	Category string
+	Action   Action
+}
+
+// Match 一次命中，Start、End 为原文中的字符（rune）下标，区间左闭右开，包含词中插入的分隔符
+type Match struct {
+	Entry
+	Start, End int
+}
+
+// Result 一段文本的检查结果
+type Result struct {
+	Matches []Match
+	Action  Action // 命中词中最严重的处理方式，未命中时为空
+	Masked  string // 将 mask 类命中替换为 *** 后的文本
+}
+
+type node struct {
+	next    map[rune]int32
+	fail    int32
+	outputs []int32 // 以该节点结尾的词（含经失败链可达的词）在 entries 中的下标
+}
+
+// Matcher 不可变的敏感词自动机，可在多个 goroutine 中并发使用
+type Matcher struct {
+	nodes   []node
+	entries []Entry
+	lengths []int // 各词归一化后的字符数
+}
+
+// Normalize 返回敏感词归一化后的形式，词库按此去重
+func Normalize(word string) string {
+	var b strings.Builder
+	for _, r := range word {
+		if r, ok := fold(r); ok {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// fold 归一化单个字符，返回 false 表示该字符是分隔符，匹配时跳过
+func fold(r rune) (rune, bool) {
+	switch {
+	case r == '\u3000':
+		return 0, false
+	case r >= '\uFF01' && r <= '\uFF5E':
+		r -= 0xFEE0
+	case r >= '\u200B' && r <= '\u200F', r == '\u2060', r == '\uFEFF':
+		return 0, false
+	}
+	if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.Is(unicode.Mn, r) || unicode.IsControl(r) {
+		return 0, false
+	}
+	return unicode.ToLower(r), true
+}
+
+// New 构建自动机，归一化后为空的词会被忽略，重复的词保留第一个
+func New(entries []Entry) *Matcher {
+	m := &Matcher{nodes: []node{{}}}
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		word := []rune(Normalize(e.Word))
+		if len(word) == 0 || seen[string(word)] {
+			continue
+		}
+		seen[string(word)] = true
+
+		cur := int32(0)
+		for _, r := range word {
+			next, ok := m.nodes[cur].next[r]
+			if !ok {
+				if m.nodes[cur].next == nil {
+					m.nodes[cur].next = make(map[rune]int32)
+				}
+				next = int32(len(m.nodes))
+				m.nodes[cur].next[r] = next
+				m.nodes = append(m.nodes, node{})
+			}
+			cur = next
+		}
+		m.nodes[cur].outputs = append(m.nodes[cur].outputs, int32(len(m.entries)))
+		m.entries = append(m.entries, e)
+		m.lengths = append(m.lengths, len(word))
+	}
+	m.buildFailLinks()
+	return m
+}
+
+// buildFailLinks 按层次遍历建立失败指针，并把失败节点的输出合并到当前节点
+func (m *Matcher) buildFailLinks() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].next {
+			fail := m.nodes[cur].fail
+			for {
+				if next, ok := m.nodes[fail].next[r]; ok {
+					m.nodes[child].fail = next
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if f := m.nodes[child].fail; len(m.nodes[f].outputs) > 0 {
+				m.nodes[child].outputs = append(m.nodes[child].outputs, m.nodes[f].outputs...)
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// Len 词库中的词数
+func (m *Matcher) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.entries)
+}
+
+// Find 返回文本中所有命中，按起始位置排序
+func (m *Matcher) Find(text string) []Match {
+	if m == nil || len(m.entries) == 0 {
+		return nil
+	}
+	var matches []Match
+	positions := make([]int, 0, len(text)) // 归一化后第 i 个字符在原文中的下标
+	cur := int32(0)
+	index := 0
+	for _, raw := range text {
+		pos := index
+		index++
+		r, ok := fold(raw)
+		if !ok {
+			continue
+		}
+		positions = append(positions, pos)
+		for {
+			if next, ok := m.nodes[cur].next[r]; ok {
+				cur = next
+				break
+			}
+			if cur == 0 {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		for _, out := range m.nodes[cur].outputs {
+			start := positions[len(positions)-m.lengths[out]]
+			matches = append(matches, Match{Entry: m.entries[out], Start: start, End: pos + 1})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+	return matches
+}
+
+// Check 检查文本，返回命中、最严重的处理方式以及打码后的文本
+func (m *Matcher) Check(text string) Result {
+	result := Result{Matches: m.Find(text), Masked: text}
+	var masks []Match
+	for _, match := range result.Matches {
+		if match.Action.severity() > result.Action.severity() {
+			result.Action = match.Action
+		}
+		if match.Action == ActionMask {
+			masks = append(masks, match)
+		}
+	}
+	if len(masks) > 0 {
+		result.Masked = Mask(text, masks)
+	}
+	return result
+}
+
+// Mask 将命中区间替换为 ***，重叠或相邻的区间合并为一处
+func Mask(text string, matches []Match) string {
+	if len(matches) == 0 {
+		return text
+	}
+	spans := make([]Match, len(matches))
+	copy(spans, matches)
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+
+	runes := []rune(text)
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(spans); {
+		start, end := spans[i].Start, spans[i].End
+		for i++; i < len(spans) && spans[i].Start <= end; i++ {
+			if spans[i].End > end {
+				end = spans[i].End
+			}
+		}
+		b.WriteString(string(runes[last:start]))
+		b.WriteString("***")
+		last = end
+	}
+	b.WriteString(string(runes[last:]))
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package wordfilter
+
+import "testing"
+
+func newTestMatcher() *Matcher {
+	return New([]Entry{
+		{Word: "代写", Category: "spam", Action: ActionMask},
+		{Word: "代写论文", Category: "spam", Action: ActionReview},
+		{Word: "赌博", Category: "illegal", Action: ActionBlock},
+		{Word: "Spam", Category: "spam", Action: ActionMask},
+		{Word: "he", Category: "test", Action: ActionMask},
+		{Word: "she", Category: "test", Action: ActionMask},
+	})
+}
+
+func TestFind(t *testing.T) {
+	m := newTestMatcher()
+	matches := m.Find("专业代写论文")
+	if len(matches) != 2 {
+		t.Fatalf("Find = %+v, want 2 matches", matches)
+	}
+	if matches[0].Word != "代写" || matches[0].Start != 2 || matches[0].End != 4 {
+		t.Errorf("first match = %+v", matches[0])
+	}
+	if matches[1].Word != "代写论文" || matches[1].Start != 2 || matches[1].End != 6 {
+		t.Errorf("second match = %+v", matches[1])
+	}
+
+	// 失败指针：she 命中后 he 也应命中
+	if got := m.Find("ushers"); len(got) != 2 {
+		t.Errorf("Find(ushers) = %+v, want she and he", got)
+	}
+}
+
+func TestFindEvasion(t *testing.T) {
+	m := newTestMatcher()
+	cases := []string{"赌 博", "赌*博", "赌\u200b博", "ＳＰＡＭ", "s.p.a.m", "S p A m"}
+	for _, text := range cases {
+		if len(m.Find(text)) == 0 {
+			t.Errorf("Find(%q) found nothing", text)
+		}
+	}
+	if got := m.Find("赌场博物馆"); len(got) != 0 {
+		t.Errorf("Find(赌场博物馆) = %+v, want none", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	m := newTestMatcher()
+
+	r := m.Check("找人代 写作业")
+	if r.Action != ActionMask || r.Masked != "找人***作业" {
+		t.Errorf("Check mask = %+v", r)
+	}
+
+	r = m.Check("代写论文，赌博")
+	if r.Action != ActionBlock {
+		t.Errorf("Check action = %q, want block", r.Action)
+	}
+
+	r = m.Check("正常内容")
+	if r.Action != "" || r.Masked != "正常内容" || len(r.Matches) != 0 {
+		t.Errorf("Check clean = %+v", r)
+	}
+}
+
+func TestMaskMergesOverlaps(t *testing.T) {
+	got := Mask("abcdef", []Match{{Start: 3, End: 5}, {Start: 1, End: 3}, {Start: 2, End: 4}})
+	if got != "a***f" {
+		t.Errorf("Mask = %q", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize(" Ｄａｉ-Xie "); got != "daixie" {
+		t.Errorf("Normalize = %q", got)
+	}
+	var empty *Matcher
+	if empty.Find("任何内容") != nil {
+		t.Error("nil matcher should match nothing")
+	}
+}